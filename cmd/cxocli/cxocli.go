@@ -3,11 +3,14 @@ package main
 import (
 	"crypto/tls"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -20,6 +23,7 @@ import (
 	"github.com/skycoin/cxo/node"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
+	"github.com/skycoin/cxo/skyobject/statutil"
 )
 
 // defaults
@@ -75,6 +79,41 @@ var (
 		"root info ",
 		"root tree ",
 		"last root ",
+		"query ",
+
+		// preview
+
+		"preview root ",
+		"preview object ",
+		"preview refs ",
+
+		// events
+
+		"watch ",
+
+		// publish
+
+		"register registry ",
+		"new head ",
+		"publish ",
+
+		// heads
+
+		"list heads ",
+		"head label ",
+		"head by label ",
+		"active head ",
+		"reset active head ",
+		"head keep ",
+		"fork head ",
+		"del head ",
+
+		// keys
+
+		"list keys ",
+		"generate key ",
+		"add key ",
+		"del key ",
 
 		// stat
 
@@ -281,6 +320,10 @@ type client struct {
 	r *node.RPCClient
 	m map[string]func(in []string) (err error)
 
+	// last Root of the 'preview root' command to get
+	// objects and pages of Refs from the same Root
+	pin *registry.Root
+
 	// TODO (kostyarin): autocomplite feeds, nonces, seq numbers,
 	//                   connections
 }
@@ -315,6 +358,31 @@ func (c *client) mapping() map[string]func(in []string) (err error) {
 		"root info": c.rootInfo,
 		"root tree": c.rootTree,
 		"last root": c.lastRoot,
+		"query":     c.query,
+
+		"preview root":   c.previewRoot,
+		"preview object": c.previewObject,
+		"preview refs":   c.previewRefs,
+
+		"watch": c.watch,
+
+		"register registry": c.registerRegistry,
+		"new head":          c.newHead,
+		"publish":           c.publish,
+
+		"list heads":        c.listHeads,
+		"head label":        c.headLabel,
+		"head by label":     c.headByLabel,
+		"active head":       c.activeHead,
+		"reset active head": c.resetActiveHead,
+		"head keep":         c.headKeep,
+		"fork head":         c.forkHead,
+		"del head":          c.delHead,
+
+		"list keys":    c.listKeys,
+		"generate key": c.generateKey,
+		"add key":      c.addKey,
+		"del key":      c.delKey,
 
 		"stat": c.stat,
 
@@ -462,6 +530,97 @@ func (c *client) argsRoot(in []string) (rs node.RootSelector, err error) {
 
 }
 
+func (c *client) argsPreview(
+	in []string,
+	refs bool,
+) (
+	ps node.PreviewSelector,
+	err error,
+) {
+
+	var expected = "expected address, public key, schema and hash"
+	var want = 4
+
+	if refs == true {
+		expected = "expected address, public key, schema, hash, from and to"
+		want = 6
+	}
+
+	if len(in) < want {
+		err = errors.New("missing arguments: " + expected)
+		return
+	}
+
+	if len(in) > want {
+		err = errors.New("too many arguments: " + expected)
+		return
+	}
+
+	ps.Address = in[0]
+
+	if ps.Feed, err = pubKeyFromHex(in[1]); err != nil {
+		return
+	}
+
+	var sr cipher.SHA256
+	if sr, err = cipher.SHA256FromHex(in[2]); err != nil {
+		return
+	}
+	ps.Schema = registry.SchemaRef(sr)
+
+	if ps.Hash, err = cipher.SHA256FromHex(in[3]); err != nil {
+		return
+	}
+
+	if refs == false {
+		return
+	}
+
+	if ps.From, err = strconv.Atoi(in[4]); err != nil {
+		return
+	}
+
+	ps.To, err = strconv.Atoi(in[5])
+	return
+
+}
+
+// public key, nonce and given number of other arguments
+func (c *client) argsHead(
+	in []string,
+	expected string,
+	more int,
+) (
+	hs node.HeadSelector,
+	rest []string,
+	err error,
+) {
+
+	expected = "expected public key, nonce" + expected
+
+	if len(in) < 2+more {
+		err = errors.New("missing arguments: " + expected)
+		return
+	}
+
+	if len(in) > 2+more {
+		err = errors.New("too many arguments: " + expected)
+		return
+	}
+
+	if hs.Feed, err = pubKeyFromHex(in[0]); err != nil {
+		return
+	}
+
+	if hs.Nonce, err = strconv.ParseUint(in[1], 10, 64); err != nil {
+		return
+	}
+
+	rest = in[2:]
+	return
+
+}
+
 func (c *client) argsNo(in []string) (err error) {
 	if len(in) != 0 {
 		err = errors.New("unexpected arguments, expected nothing")
@@ -747,6 +906,578 @@ func (c *client) lastRoot(in []string) (err error) {
 	return
 }
 
+func (c *client) query(in []string) (err error) {
+
+	if len(in) < 4 {
+		return errors.New(
+			"missing arguments: expected public key, nonce, seq and query")
+	}
+
+	var sl node.RootSelector
+	if sl, err = c.argsRoot(in[:3]); err != nil {
+		return
+	}
+
+	var n int // number of results
+
+	err = c.r.Root().Query(sl.Feed, sl.Nonce, sl.Seq,
+		strings.Join(in[3:], " "), func(it *registry.Item) (_ error) {
+			n++
+			printItem(it, "  ")
+			return
+		})
+
+	if err == nil && n == 0 {
+		fmt.Fprintln(out, "  (empty)")
+	}
+
+	return
+}
+
+//
+// preview
+//
+
+func printItem(it *registry.Item, indent string) {
+
+	var name = it.Name
+
+	if name != "" {
+		name += ": "
+	}
+
+	switch {
+	case it.Err != "":
+		fmt.Fprintf(out, "%s%s(err) %s\n", indent, name, it.Err)
+	case it.IsBlank() == true:
+		fmt.Fprintf(out, "%s%s%s nil\n", indent, name, it.Schema)
+	case it.Ref != registry.ReferenceTypeNone:
+		fmt.Fprintf(out, "%s%s%s %s (schema %s)\n", indent, name, it.Schema,
+			it.Hash.Hex(), it.Elem.String())
+	case len(it.Items) > 0:
+		fmt.Fprintf(out, "%s%s%s\n", indent, name, it.Schema)
+	default:
+		fmt.Fprintf(out, "%s%s%s (%s)\n", indent, name, it.Value, it.Schema)
+	}
+
+	for _, x := range it.Items {
+		printItem(x, indent+"  ")
+	}
+
+}
+
+func printItems(items []*registry.Item) {
+	if len(items) == 0 {
+		fmt.Fprintln(out, "  (empty)")
+		return
+	}
+	for _, it := range items {
+		printItem(it, "  ")
+	}
+}
+
+func (c *client) previewRoot(in []string) (err error) {
+	var cf node.ConnFeed
+	if cf, err = c.argsConnFeed(in); err != nil {
+		return
+	}
+	var (
+		z     *registry.Root
+		items []*registry.Item
+	)
+	if z, items, err = c.r.Preview().Root(cf.Address, cf.Feed); err != nil {
+		return
+	}
+	c.pin = z
+	c.printRoot(z)
+	printItems(items)
+	return
+}
+
+// Root of last 'preview root' command, if it is Root of given feed
+func (c *client) pinned(feed cipher.PubKey) (pin *registry.Root) {
+	if c.pin != nil && c.pin.Pub == feed {
+		pin = c.pin
+	}
+	return
+}
+
+func (c *client) previewObject(in []string) (err error) {
+	var ps node.PreviewSelector
+	if ps, err = c.argsPreview(in, false); err != nil {
+		return
+	}
+	var it *registry.Item
+	it, err = c.r.Preview().Object(ps.Address, ps.Feed, ps.Schema, ps.Hash,
+		c.pinned(ps.Feed))
+	if err != nil {
+		return
+	}
+	printItem(it, "  ")
+	return
+}
+
+func (c *client) previewRefs(in []string) (err error) {
+	var ps node.PreviewSelector
+	if ps, err = c.argsPreview(in, true); err != nil {
+		return
+	}
+	var (
+		length int
+		items  []*registry.Item
+	)
+	length, items, err = c.r.Preview().Refs(ps.Address, ps.Feed, ps.Schema,
+		ps.Hash, ps.From, ps.To, c.pinned(ps.Feed))
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(out, "  length:", length)
+	printItems(items)
+	return
+}
+
+//
+// events
+//
+
+// long-poll timeout of the watch command
+const watchPollTimeout = 10 * time.Second
+
+func printEvent(ev *node.Event) {
+
+	fmt.Fprintf(out, "  %d %s %s", ev.Seq, ev.Time.Format(time.StampMilli),
+		ev.Type)
+
+	if ev.Conn != "" {
+		fmt.Fprintf(out, " %s", ev.Conn)
+	}
+
+	if ev.Feed != (cipher.PubKey{}) {
+		fmt.Fprintf(out, " %s", ev.Feed.Hex()[:7])
+	}
+
+	switch ev.Type {
+	case node.EventRootReceived, node.EventRootFilled, node.EventFillingBreaks:
+		fmt.Fprintf(out, " %d/%d %s", ev.Nonce, ev.RootSeq,
+			ev.Hash.Hex()[:7])
+	}
+
+	if ev.Err != "" {
+		fmt.Fprintf(out, " (%s)", ev.Err)
+	}
+
+	fmt.Fprintln(out)
+}
+
+func (c *client) watch(in []string) (err error) {
+
+	var rq node.EventsRequest
+
+	for _, arg := range in {
+		var pk cipher.PubKey
+		if pk, err = pubKeyFromHex(arg); err != nil {
+			return
+		}
+		rq.Feeds = append(rq.Feeds, pk)
+	}
+
+	if rq.From, err = c.r.Events().Last(); err != nil {
+		return
+	}
+
+	rq.Timeout = watchPollTimeout
+
+	var (
+		sig  = make(chan os.Signal, 1)
+		quit = make(chan struct{})
+		errc = make(chan error, 1)
+	)
+
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+
+	fmt.Fprintln(out, "  watching events, press Ctrl+C to stop")
+
+	go func() {
+
+		var (
+			reply *node.EventsReply
+			err   error
+		)
+
+		for {
+
+			if reply, err = c.r.Events().Poll(&rq); err != nil {
+				errc <- err
+				return
+			}
+
+			select {
+			case <-quit:
+				return
+			default:
+			}
+
+			if reply.Missed == true {
+				fmt.Fprintln(out, "  (some events has been missed)")
+			}
+
+			for i := range reply.Events {
+				printEvent(&reply.Events[i])
+			}
+
+			rq.From = reply.Next
+
+		}
+
+	}()
+
+	select {
+	case <-sig:
+		close(quit)
+	case err = <-errc:
+	}
+
+	return
+}
+
+//
+// publish
+//
+
+func (c *client) registerRegistry(in []string) (err error) {
+
+	var file string
+	if file, err = c.argsOne(in, "path to JSON file"); err != nil {
+		return
+	}
+
+	var val []byte
+	if val, err = ioutil.ReadFile(file); err != nil {
+		return
+	}
+
+	var ds []registry.StructDescription
+	if err = json.Unmarshal(val, &ds); err != nil {
+		return
+	}
+
+	var rr registry.RegistryRef
+	if rr, err = c.r.Publish().Registry(ds); err != nil {
+		return
+	}
+
+	fmt.Fprintln(out, "  registry:", rr.String())
+	return
+}
+
+func (c *client) newHead(in []string) (err error) {
+
+	var pk cipher.PubKey
+	if pk, err = c.argsFeed(in); err != nil {
+		return
+	}
+
+	var nonce uint64
+	if nonce, err = c.r.Publish().NewHead(pk); err != nil {
+		return
+	}
+
+	fmt.Fprintln(out, "  nonce:", nonce)
+	return
+}
+
+// JSON file of the publish command
+type publishFile struct {
+	Feed       string          // hex-encoded public key
+	Nonce      uint64          // head, optional
+	Reg        string          // hex-encoded registry, optional
+	Descriptor string          // optional
+	Refs       json.RawMessage // JSON array of Dynamic references
+}
+
+func (c *client) publish(in []string) (err error) {
+
+	var file string
+	if file, err = c.argsOne(in, "path to JSON file"); err != nil {
+		return
+	}
+
+	var val []byte
+	if val, err = ioutil.ReadFile(file); err != nil {
+		return
+	}
+
+	var pf publishFile
+	if err = json.Unmarshal(val, &pf); err != nil {
+		return
+	}
+
+	var rq node.PublishRequest
+
+	if rq.Feed, err = pubKeyFromHex(pf.Feed); err != nil {
+		return
+	}
+
+	if pf.Reg != "" {
+		var rr cipher.SHA256
+		if rr, err = cipher.SHA256FromHex(pf.Reg); err != nil {
+			return
+		}
+		rq.Reg = registry.RegistryRef(rr)
+	}
+
+	rq.Nonce = pf.Nonce
+	rq.Descriptor = []byte(pf.Descriptor)
+	rq.Refs = []byte(pf.Refs)
+
+	var r *registry.Root
+	if r, err = c.r.Publish().Publish(&rq); err != nil {
+		return
+	}
+
+	fmt.Fprintln(out, "  published:", r.Short())
+	fmt.Fprintln(out, "  hash:     ", r.Hash.Hex())
+	return
+}
+
+//
+// heads
+//
+
+func (c *client) listHeads(in []string) (err error) {
+
+	var pk cipher.PubKey
+	if pk, err = c.argsFeed(in); err != nil {
+		return
+	}
+
+	var heads []node.Head
+	if heads, err = c.r.Head().List(pk); err != nil {
+		return
+	}
+
+	if len(heads) == 0 {
+		fmt.Fprintln(out, "  no heads")
+		return
+	}
+
+	for _, h := range heads {
+
+		fmt.Fprint(out, "  ", h.Nonce)
+
+		if h.Label != "" {
+			fmt.Fprintf(out, " label: %q", h.Label)
+		}
+
+		if h.Keep > 0 {
+			fmt.Fprint(out, " keep: ", h.Keep)
+		}
+
+		if h.Active == true {
+			fmt.Fprint(out, " active")
+		}
+
+		if h.Selected == true {
+			fmt.Fprint(out, " (selected)")
+		}
+
+		fmt.Fprintln(out)
+	}
+
+	return
+}
+
+func (c *client) headLabel(in []string) (err error) {
+
+	var more = 1
+
+	if len(in) < 3 {
+		more = 0 // remove label
+	}
+
+	var (
+		hs   node.HeadSelector
+		rest []string
+	)
+
+	if hs, rest, err = c.argsHead(in, " and label", more); err != nil {
+		return
+	}
+
+	var label string
+
+	if len(rest) == 1 {
+		label = rest[0]
+	}
+
+	return c.r.Head().SetLabel(hs.Feed, hs.Nonce, label)
+}
+
+func (c *client) headByLabel(in []string) (err error) {
+
+	const expected = "expected public key and label"
+
+	switch len(in) {
+	case 0, 1:
+		return errors.New("missing arguments: " + expected)
+	case 2:
+	default:
+		return errors.New("too many arguments: " + expected)
+	}
+
+	var pk cipher.PubKey
+	if pk, err = pubKeyFromHex(in[0]); err != nil {
+		return
+	}
+
+	var nonce uint64
+	if nonce, err = c.r.Head().ByLabel(pk, in[1]); err != nil {
+		return
+	}
+
+	fmt.Fprintln(out, "  nonce:", nonce)
+	return
+}
+
+func (c *client) activeHead(in []string) (err error) {
+
+	var hs node.HeadSelector
+	if hs, _, err = c.argsHead(in, "", 0); err != nil {
+		return
+	}
+
+	return c.r.Head().SetActive(hs.Feed, hs.Nonce)
+}
+
+func (c *client) resetActiveHead(in []string) (err error) {
+
+	var pk cipher.PubKey
+	if pk, err = c.argsFeed(in); err != nil {
+		return
+	}
+
+	return c.r.Head().ResetActive(pk)
+}
+
+func (c *client) headKeep(in []string) (err error) {
+
+	var (
+		hs   node.HeadSelector
+		rest []string
+	)
+
+	if hs, rest, err = c.argsHead(in, " and keep", 1); err != nil {
+		return
+	}
+
+	var keep uint64
+	if keep, err = strconv.ParseUint(rest[0], 10, 32); err != nil {
+		return
+	}
+
+	return c.r.Head().SetKeep(hs.Feed, hs.Nonce, uint32(keep))
+}
+
+func (c *client) forkHead(in []string) (err error) {
+
+	var sl node.RootSelector
+	if sl, err = c.argsRoot(in); err != nil {
+		return
+	}
+
+	var z *registry.Root
+	if z, err = c.r.Head().Fork(sl.Feed, sl.Nonce, sl.Seq); err != nil {
+		return
+	}
+
+	c.printRoot(z)
+	return
+}
+
+func (c *client) delHead(in []string) (err error) {
+
+	var hs node.HeadSelector
+	if hs, _, err = c.argsHead(in, "", 0); err != nil {
+		return
+	}
+
+	return c.r.Head().Del(hs.Feed, hs.Nonce)
+}
+
+//
+// keys
+//
+
+func (c *client) listKeys(in []string) (err error) {
+
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+
+	var pks []cipher.PubKey
+	if pks, err = c.r.Publish().Keys(); err != nil {
+		return
+	}
+
+	if len(pks) == 0 {
+		fmt.Fprintln(out, "  no keys")
+		return
+	}
+
+	for _, pk := range pks {
+		fmt.Fprintln(out, " ", pk.Hex())
+	}
+
+	return
+}
+
+func (c *client) generateKey(in []string) (err error) {
+
+	if err = c.argsNo(in); err != nil {
+		return
+	}
+
+	var pk cipher.PubKey
+	if pk, err = c.r.Publish().GenerateKey(); err != nil {
+		return
+	}
+
+	fmt.Fprintln(out, " ", pk.Hex())
+	return
+}
+
+func (c *client) addKey(in []string) (err error) {
+
+	var one string
+	if one, err = c.argsOne(in, "secret key"); err != nil {
+		return
+	}
+
+	var sk cipher.SecKey
+	if sk, err = cipher.SecKeyFromHex(one); err != nil {
+		return
+	}
+
+	var pk cipher.PubKey
+	if pk, err = c.r.Publish().AddKey(sk); err != nil {
+		return
+	}
+
+	fmt.Fprintln(out, " ", pk.Hex())
+	return
+}
+
+func (c *client) delKey(in []string) (err error) {
+
+	var pk cipher.PubKey
+	if pk, err = c.argsFeed(in); err != nil {
+		return
+	}
+
+	return c.r.Publish().DelKey(pk)
+}
+
 //
 // stat
 //
@@ -795,8 +1526,40 @@ func (c *client) stat(in []string) (err error) {
 	fmt.Fprintln(out, "  volume of used objects:         ",
 		s.UsedObjects.Volume.String())
 
+	if t := s.Tiers; t != nil {
+		fmt.Fprintln(out, "  hot tier:                       ",
+			t.Hot.Amount.String(), t.Hot.Volume.String())
+		fmt.Fprintln(out, "  cold tier:                      ",
+			t.Cold.Amount.String(), t.Cold.Volume.String())
+		fmt.Fprintln(out, "  promoted:                       ",
+			t.Promoted.Amount.String(), t.Promoted.Volume.String())
+		fmt.Fprintln(out, "  demoted:                        ",
+			t.Demoted.Amount.String(), t.Demoted.Volume.String())
+		fmt.Fprintln(out, "  last migration:                 ",
+			t.LastMigration)
+		if t.MigrationError != "" {
+			fmt.Fprintln(out, "  migration error:                ",
+				t.MigrationError)
+		}
+	}
+
 	fmt.Fprintln(out, "  new Root objects per second:    ", s.RootsPerSecond)
 
+	if len(s.Scores) == 0 {
+		fmt.Fprintln(out, "  no connections")
+	}
+
+	for cs, sc := range s.Scores {
+		fmt.Fprintln(out, " ", cs)
+		fmt.Fprintln(out, "    score:     ", round(sc.Value))
+		fmt.Fprintln(out, "    RTT:       ", sc.RTT)
+		fmt.Fprintln(out, "    throughput:",
+			statutil.Volume(sc.Throughput).String()+"/s")
+		fmt.Fprintln(out, "    requests:  ", sc.Requests)
+		fmt.Fprintln(out, "    failures:  ", sc.Failures)
+		fmt.Fprintln(out, "    timeouts:  ", sc.Timeouts)
+	}
+
 	if len(s.Feeds) == 0 {
 		fmt.Fprintln(out, "  no feeds")
 		return
@@ -881,6 +1644,65 @@ func (c *client) help(in []string) (err error) {
   last root <public key>
     show info about last Root of given feed
 
+  query <public key> <nonce> <seq> <query>
+    find values of selected Root, for example
+    query <pk> 1 0 Refs[0].Members[*].Name where Age > 30
+
+
+  preview root <connection address> <public key>
+    show last Root of feed of peer, without subscription
+  preview object <connection address> <public key> <schema> <hash>
+    show object of feed of peer, without subscription
+  preview refs <connection address> <public key> <schema> <hash> <from> <to>
+    show elements of Refs of feed of peer from 'from' to 'to' (exclusive)
+    (objects and Refs are taken from Root of last 'preview root'
+    of the feed, or from latest Root if there was not such command)
+
+
+  watch [public key ...]
+    print events of node live, filtered by given feeds if any,
+    press Ctrl+C to stop
+
+
+  register registry <file.json>
+    register registry described by JSON file, the file is
+    array of {"Name": "pkg.Type", "Fields": [{"Name", "Schema"}]}
+  new head <public key>
+    create new head of given feed
+  publish <file.json>
+    publish new Root described by JSON file, the file is
+    {"Feed", "Nonce", "Reg", "Descriptor", "Refs": [Dynamic]},
+    where Nonce and Reg are optional
+
+
+  list heads <public key>
+    list heads of given feed with their labels and retention
+  head label <public key> <nonce> [label]
+    set label of given head, remove the label if it's omitted
+  head by label <public key> <label>
+    show nonce of head with given label
+  active head <public key> <nonce>
+    select active head of given feed explicitly
+  reset active head <public key>
+    use head with newest Root as active head of given feed
+  head keep <public key> <nonce> <keep>
+    keep given number of last Root objects of given head,
+    removing older, zero to keep all
+  fork head <public key> <nonce> <seq>
+    create and publish new head with content of given Root
+  del head <public key> <nonce>
+    delete given head with all its Root objects
+
+
+  list keys
+    list feeds the node has secret keys of
+  generate key
+    generate new key pair, keeping the secret key in the node
+  add key <secret key>
+    add secret key to the node
+  del key <public key>
+    remove secret key of given feed from the node
+
 
   stat
     show statistic of node
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/migrate"
+
+	cxbadger "github.com/skycoin/cxo/data/cxds/badger"
+	cxbolt "github.com/skycoin/cxo/data/cxds/bolt"
+	cxredis "github.com/skycoin/cxo/data/cxds/redis"
+
+	idxbadger "github.com/skycoin/cxo/data/idx/badger"
+	idxbolt "github.com/skycoin/cxo/data/idx/bolt"
+	idxredis "github.com/skycoin/cxo/data/idx/redis"
+)
+
+// engines
+const (
+	engineBolt   string = "bolt"
+	engineBadger string = "badger"
+	engineRedis  string = "redis"
+)
+
+func init() {
+	log.SetFlags(log.Lshortfile)
+}
+
+// a dbFlags represents flags of a DB
+type dbFlags struct {
+	engine string
+	path   string
+}
+
+func (d *dbFlags) fromFlags(prefix, desc string) {
+	flag.StringVar(&d.engine,
+		prefix+"-engine",
+		engineBolt,
+		desc+" DB engine: bolt, badger or redis")
+	flag.StringVar(&d.path,
+		prefix+"-path",
+		"",
+		desc+" DB path (path.cxds and path.idx), or address of Redis")
+}
+
+// open the DB
+func (d *dbFlags) open() (db *data.DB, err error) {
+
+	if d.path == "" {
+		return nil, fmt.Errorf("missing path of %s DB", d.engine)
+	}
+
+	var (
+		cx  data.CXDS
+		idx data.IdxDB
+	)
+
+	switch d.engine {
+	case engineBolt:
+		if cx, err = cxbolt.NewBolt(d.path+".cxds", 0644, nil, 0); err != nil {
+			return
+		}
+		idx, err = idxbolt.NewBolt(d.path+".idx", 0644, nil, 0)
+	case engineBadger:
+		cx, err = cxbadger.NewBadger(badgerOptions(d.path+".cxds"), 0)
+		if err != nil {
+			return
+		}
+		idx, err = idxbadger.NewBadger(badgerOptions(d.path+".idx"), 0)
+	case engineRedis:
+		var conf = cxredis.NewConfig()
+		if cx, err = cxredis.NewRedis("tcp", d.path, conf); err != nil {
+			return
+		}
+		idx, err = idxredis.NewRedis("tcp", d.path, idxredis.NewConfig())
+	default:
+		return nil, fmt.Errorf("unknown DB engine %q", d.engine)
+	}
+
+	if err != nil {
+		cx.Close()
+		return
+	}
+
+	return data.NewDB(cx, idx), nil
+}
+
+// badger options with given directory
+func badgerOptions(dir string) (opts badger.Options) {
+	opts = badger.DefaultOptions
+	opts.Dir = dir
+	opts.ValueDir = dir
+	return
+}
+
+func main() {
+
+	var (
+		src, dst dbFlags
+
+		conf     migrate.Config
+		interval time.Duration
+	)
+
+	src.fromFlags("src", "source")
+	dst.fromFlags("dst", "destination")
+
+	flag.BoolVar(&conf.Prune,
+		"prune",
+		false,
+		"remove from destination all that doesn't exist in source")
+	flag.BoolVar(&conf.Verify,
+		"verify",
+		false,
+		"verify destination after migration (the source should be stopped)")
+	flag.DurationVar(&interval,
+		"interval",
+		0,
+		"repeat migration with this interval until SIGINT")
+
+	flag.Parse()
+
+	if err := run(&src, &dst, conf, interval); err != nil {
+		log.Fatal(err)
+	}
+
+}
+
+func run(src, dst *dbFlags, conf migrate.Config, interval time.Duration) (
+	err error,
+) {
+
+	var sdb, ddb *data.DB
+
+	if sdb, err = src.open(); err != nil {
+		return
+	}
+	defer sdb.Close()
+
+	if ddb, err = dst.open(); err != nil {
+		return
+	}
+	defer ddb.Close()
+
+	if err = migrateOnce(ddb, sdb, conf); err != nil || interval <= 0 {
+		return
+	}
+
+	var (
+		tk  = time.NewTicker(interval)
+		sig = make(chan os.Signal, 1)
+	)
+	defer tk.Stop()
+
+	signal.Notify(sig, os.Interrupt)
+
+	for {
+		select {
+		case <-tk.C:
+			if err = migrateOnce(ddb, sdb, conf); err != nil {
+				return
+			}
+		case <-sig:
+			return
+		}
+	}
+
+}
+
+func migrateOnce(dst, src *data.DB, conf migrate.Config) (err error) {
+
+	var (
+		tp   = time.Now()
+		stat migrate.Stat
+	)
+
+	if stat, err = migrate.Migrate(dst, src, conf); err != nil {
+		return
+	}
+
+	log.Printf("copied %d (%d bytes), skipped %d (%d bytes), pruned %d"+
+		" (%d bytes) objects; %d feeds, %d heads, %d Root objects; %s",
+		stat.Copied.Amount, stat.Copied.Volume,
+		stat.Skipped.Amount, stat.Skipped.Volume,
+		stat.Pruned.Amount, stat.Pruned.Volume,
+		stat.Feeds, stat.Heads, stat.Roots,
+		time.Since(tp))
+
+	return
+}
@@ -13,6 +13,94 @@ import (
 // An IterateKeysFunc used to iterate over keys.
 type IterateKeysFunc func(key cipher.SHA256) (err error)
 
+//
+// Zero-copy reads.
+//
+
+// A ViewFunc used to access value of an object in
+// place. The val is valid only inside the ViewFunc
+// and must not be modified or retained. Copy it
+// if you need it after. The ViewFunc must not call
+// methods of the CXDS, because the CXDS can hold a
+// lock or a transaction during the call
+type ViewFunc func(val []byte, rc int64) (err error)
+
+// A Viewer is optional interface of a CXDS that
+// provides zero-copy reads. The View method calls
+// given ViewFunc with value that points to memory
+// of the DB (e.g. to a memory-mapped page) or to
+// memory of the CXDS. The View never updates last
+// access time and never changes RC. It returns
+// ErrNotFound if object doesn't exist, or error
+// returned by the ViewFunc
+type Viewer interface {
+	View(key cipher.SHA256, viewFunc ViewFunc) (err error)
+}
+
+// View is zero-copy read if given CXDS implements
+// the Viewer interface. Otherwise, the View uses
+// GetNotTouch method and calls the ViewFunc with
+// value of the Object
+func View(ds CXDS, key cipher.SHA256, viewFunc ViewFunc) (err error) {
+
+	if v, ok := ds.(Viewer); ok == true {
+		return v.View(key, viewFunc)
+	}
+
+	var obj *Object
+	if obj, err = ds.GetNotTouch(key); err != nil {
+		return
+	}
+
+	return viewFunc(obj.Val, obj.RC)
+}
+
+//
+// Batch writes.
+//
+
+// A BatchItem is an object to save by SetBatch
+type BatchItem struct {
+	Key    cipher.SHA256 // hash of the object
+	Val    []byte        // encoded object
+	IncrBy int64         // inc- or decrement RC by this value
+}
+
+// A Batcher is optional interface of a CXDS that
+// saves many objects in one write transaction. The
+// SetBatch is the same as SetIncr called for every
+// item, but all items are saved together, or none
+// of them saved. The SetBatch returns new RCs of
+// the objects in order of given items
+type Batcher interface {
+	SetBatch(items []BatchItem) (rcs []int64, err error)
+}
+
+// SetBatch saves given items in one write transaction
+// if given CXDS implements the Batcher interface.
+// Otherwise, the SetBatch calls SetIncr for every
+// item, and if it fails, then some items can be
+// already saved
+func SetBatch(ds CXDS, items []BatchItem) (rcs []int64, err error) {
+
+	if b, ok := ds.(Batcher); ok == true {
+		return b.SetBatch(items)
+	}
+
+	rcs = make([]int64, 0, len(items))
+
+	var obj *Object
+
+	for _, it := range items {
+		if obj, err = ds.SetIncr(it.Key, it.Val, it.IncrBy); err != nil {
+			return
+		}
+		rcs = append(rcs, obj.RC)
+	}
+
+	return
+}
+
 //
 // CXDS in person.
 //
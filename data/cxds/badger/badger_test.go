@@ -6,13 +6,15 @@ import (
 
 	"github.com/dgraph-io/badger"
 
+	"github.com/skycoin/skycoin/src/cipher"
+
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/data/tests/cxds"
 )
 
 var dbDirName = "test.bolt.go.ignore"
 
-func newBadger(t *testing.T) (b *Badger) {
+func newBadger(t testing.TB) (b *Badger) {
 	os.RemoveAll(dbDirName)
 
 	var opts = badger.DefaultOptions
@@ -27,7 +29,7 @@ func newBadger(t *testing.T) (b *Badger) {
 }
 
 // clean up db after all
-func closeBadger(t *testing.T, b *Badger) {
+func closeBadger(t testing.TB, b *Badger) {
 	defer os.RemoveAll(dbDirName)
 	if err := b.Close(); err != nil {
 		t.Error(err)
@@ -83,6 +85,9 @@ func TestBadger_GetIncrNotTouch(t *testing.T) {
 	runTestCase(t, cxds.GetIncrNotTouch)
 }
 
+func TestBadger_View(t *testing.T)     { runTestCase(t, cxds.View) }
+func TestBadger_SetBatch(t *testing.T) { runTestCase(t, cxds.SetBatch) }
+
 func TestBadger_Set(t *testing.T)         { runTestCase(t, cxds.Set) }
 func TestBadger_SetIncr(t *testing.T)     { runTestCase(t, cxds.SetIncr) }
 func TestBadger_SetNotTouch(t *testing.T) { runTestCase(t, cxds.SetNotTouch) }
@@ -139,3 +144,60 @@ func TestBadger_IsSafeClosed(t *testing.T) {
 }
 
 func TestBadger_Close(t *testing.T) { runTestCase(t, cxds.Close) }
+
+//
+// benchmarks
+//
+
+func benchmarkRead(b *testing.B, ds data.CXDS, read func(key cipher.SHA256)) {
+
+	var val = make([]byte, 32*1024) // 32K object
+	for i := range val {
+		val[i] = byte(i)
+	}
+
+	var key = cipher.SumSHA256(val)
+
+	if _, err := ds.Set(key, val); err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(val)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		read(key)
+	}
+}
+
+func BenchmarkBadger_GetNotTouch(b *testing.B) {
+
+	var ds = newBadger(b)
+	defer closeBadger(b, ds)
+
+	benchmarkRead(b, ds, func(key cipher.SHA256) {
+		if _, err := ds.GetNotTouch(key); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkBadger_View(b *testing.B) {
+
+	var (
+		ds  = newBadger(b)
+		sum int
+	)
+	defer closeBadger(b, ds)
+
+	benchmarkRead(b, ds, func(key cipher.SHA256) {
+		var err = ds.View(key, func(val []byte, _ int64) (_ error) {
+			sum += int(val[len(val)-1]) // use the value
+			return
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	})
+}
@@ -327,6 +327,31 @@ func (b *Bolt) GetIncrNotTouch(
 	return
 }
 
+// View implements data.Viewer interface. The val
+// points to memory-mapped page of the DB and it's
+// valid only inside the viewFunc. The View holds
+// read-only transaction during the viewFunc call
+func (b *Bolt) View(key cipher.SHA256, viewFunc data.ViewFunc) error {
+	return b.b.View(func(t *bolt.Tx) (err error) {
+
+		var p = t.Bucket(objsBucket).Get(key[:])
+		if p == nil {
+			return data.ErrNotFound
+		}
+
+		var (
+			val []byte
+			rc  int64
+		)
+
+		if val, rc, err = data.ViewObject(p); err != nil {
+			return
+		}
+
+		return viewFunc(val, rc)
+	})
+}
+
 // Set* methods used to create new object. If obejct is
 // alredy exists, then Set* method updates access time,
 // and increments RC. The Set and SetNotTouch methods
@@ -352,39 +377,102 @@ func (b *Bolt) SetIncr(
 	err error, //         : error if any
 ) {
 
+	var created bool
+
 	err = b.do(func(objs *bolt.Bucket) (err error) {
+		obj, created, err = setIncr(objs, key, val, incrBy)
+		return
+	})
+
+	if err == nil {
+		b.changeStatAfter(created, obj.RC, incrBy, vol(val))
+	}
+
+	return
+}
+
+// SetBatch implements data.Batcher interface
+func (b *Bolt) SetBatch(items []data.BatchItem) (rcs []int64, err error) {
+
+	var (
+		objs    = make([]*data.Object, 0, len(items))
+		created = make([]bool, 0, len(items))
+	)
+
+	err = b.do(func(t *bolt.Bucket) (err error) {
+
+		objs, created = objs[:0], created[:0] // if retried
 
 		var (
-			now     = time.Now()
-			created bool
-			access  time.Time
+			obj *data.Object
+			crt bool
 		)
 
-		if obj, err = getObject(objs, key); err != nil {
-			if err != data.ErrNotFound {
-				return // DB failure
+		for _, it := range items {
+			obj, crt, err = setIncr(t, it.Key, it.Val, it.IncrBy)
+			if err != nil {
+				return
 			}
-
-			created = true
-			obj = new(data.Object)
-			obj.Create = now
-			obj.Access = time.Unix(0, 0)
+			objs, created = append(objs, obj), append(created, crt)
 		}
 
-		access = obj.Access // last access or 0 nano since epoch
+		return
+	})
 
-		obj.RC += incrBy
-		obj.Val = val
-		obj.Access = now
+	if err != nil {
+		return
+	}
 
-		if err = setObject(objs, key, obj); err != nil {
-			return
+	rcs = make([]int64, 0, len(items))
+
+	for i, it := range items {
+		b.changeStatAfter(created[i], objs[i].RC, it.IncrBy, vol(it.Val))
+		rcs = append(rcs, objs[i].RC)
+	}
+
+	return
+}
+
+// set or create object in given transaction, the
+// obj contains previous access time
+func setIncr(
+	objs *bolt.Bucket, // : transaction
+	key cipher.SHA256, // : hash of the object
+	val []byte, //        : encoded object
+	incrBy int64, //      : inc- or decrement RC by this value
+) (
+	obj *data.Object, //  : object with new RC
+	created bool, //      : the object is new
+	err error, //         : DB failure
+) {
+
+	var (
+		now    = time.Now()
+		access time.Time
+	)
+
+	if obj, err = getObject(objs, key); err != nil {
+		if err != data.ErrNotFound {
+			return // DB failure
 		}
 
-		obj.Access = access
-		b.changeStatAfter(created, obj.RC, incrBy, vol(val))
+		created = true
+		obj = new(data.Object)
+		obj.Create = now
+		obj.Access = time.Unix(0, 0)
+	}
+
+	access = obj.Access // last access or 0 nano since epoch
+
+	obj.RC += incrBy
+	obj.Val = val
+	obj.Access = now
+
+	if err = setObject(objs, key, obj); err != nil {
 		return
-	})
+	}
+
+	obj.Access = access
 	return
 }
 
@@ -4,13 +4,15 @@ import (
 	"os"
 	"testing"
 
+	"github.com/skycoin/skycoin/src/cipher"
+
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/data/tests/cxds"
 )
 
 var dbFileName = "test.bolt.go.ignore"
 
-func newBolt(t *testing.T) (b *Bolt) {
+func newBolt(t testing.TB) (b *Bolt) {
 	os.Remove(dbFileName)
 	var err error
 	if b, err = NewBolt(dbFileName, 0644, nil, ScanBy); err != nil {
@@ -20,7 +22,7 @@ func newBolt(t *testing.T) (b *Bolt) {
 }
 
 // clean up db after all
-func closeBolt(t *testing.T, b *Bolt) {
+func closeBolt(t testing.TB, b *Bolt) {
 	defer os.Remove(dbFileName)
 	if err := b.Close(); err != nil {
 		t.Error(err)
@@ -76,6 +78,9 @@ func TestBolt_GetIncrNotTouch(t *testing.T) {
 	runTestCase(t, cxds.GetIncrNotTouch)
 }
 
+func TestBolt_View(t *testing.T)     { runTestCase(t, cxds.View) }
+func TestBolt_SetBatch(t *testing.T) { runTestCase(t, cxds.SetBatch) }
+
 func TestBolt_Set(t *testing.T)         { runTestCase(t, cxds.Set) }
 func TestBolt_SetIncr(t *testing.T)     { runTestCase(t, cxds.SetIncr) }
 func TestBolt_SetNotTouch(t *testing.T) { runTestCase(t, cxds.SetNotTouch) }
@@ -123,3 +128,60 @@ func TestBolt_IsSafeClosed(t *testing.T) {
 }
 
 func TestBolt_Close(t *testing.T) { runTestCase(t, cxds.Close) }
+
+//
+// benchmarks
+//
+
+func benchmarkRead(b *testing.B, ds data.CXDS, read func(key cipher.SHA256)) {
+
+	var val = make([]byte, 32*1024) // 32K object
+	for i := range val {
+		val[i] = byte(i)
+	}
+
+	var key = cipher.SumSHA256(val)
+
+	if _, err := ds.Set(key, val); err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(val)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		read(key)
+	}
+}
+
+func BenchmarkBolt_GetNotTouch(b *testing.B) {
+
+	var ds = newBolt(b)
+	defer closeBolt(b, ds)
+
+	benchmarkRead(b, ds, func(key cipher.SHA256) {
+		if _, err := ds.GetNotTouch(key); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkBolt_View(b *testing.B) {
+
+	var (
+		ds  = newBolt(b)
+		sum int
+	)
+	defer closeBolt(b, ds)
+
+	benchmarkRead(b, ds, func(key cipher.SHA256) {
+		var err = ds.View(key, func(val []byte, _ int64) (_ error) {
+			sum += int(val[len(val)-1]) // use the value
+			return
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,1028 @@
+package fs
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// names of files and directories
+const (
+	objectsDir = "objects" // sharded objects
+	infoFile   = "info"    // stat and safe closed flag
+	metaExt    = ".meta"   // extension of sidecar files
+	tmpExt     = ".tmp"    // extension of temporary files
+)
+
+func appendInt(p []byte, i int64) []byte {
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], uint64(i))
+	return append(p, t[:]...)
+}
+
+func appendBool(p []byte, t bool) []byte {
+	if t {
+		return append(p, 0xff)
+	}
+	return append(p, 0x00)
+}
+
+func getInt(p []byte) int64 {
+	return int64(binary.BigEndian.Uint64(p))
+}
+
+func vol(val []byte) int64 {
+	return int64(len(val))
+}
+
+type stat struct {
+	all, used int64
+}
+
+type metaInfo struct {
+	amount, volume stat
+	isSafeClosed   bool
+}
+
+func (m *metaInfo) encode() (p []byte) {
+	p = make([]byte, 0, 33)
+
+	p = appendInt(p, m.amount.all)  // 8
+	p = appendInt(p, m.amount.used) // 16
+
+	p = appendInt(p, m.volume.all)  // 24
+	p = appendInt(p, m.volume.used) // 32
+
+	p = appendBool(p, m.isSafeClosed) // 33
+	return
+}
+
+func (m *metaInfo) decode(p []byte) (err error) {
+	if len(p) != 33 {
+		return fmt.Errorf("invalid length of encoded metaInfo %d", len(p))
+	}
+	m.amount.all = getInt(p)
+	m.amount.used = getInt(p[8:])
+	m.volume.all = getInt(p[16:])
+	m.volume.used = getInt(p[24:])
+	m.isSafeClosed = p[32] > 0
+	return
+}
+
+// meta information of an object,
+// the sidecar file contains it
+type meta struct {
+	rc     int64
+	access time.Time
+	create time.Time
+}
+
+func (m *meta) encode() (p []byte) {
+	p = make([]byte, 0, 24)
+
+	p = appendInt(p, m.rc)                // 8
+	p = appendInt(p, m.access.UnixNano()) // 16
+	p = appendInt(p, m.create.UnixNano()) // 24
+	return
+}
+
+func (m *meta) decode(p []byte) (err error) {
+	if len(p) != 24 {
+		return fmt.Errorf("invalid length of encoded meta %d", len(p))
+	}
+	m.rc = getInt(p)
+	m.access = time.Unix(0, getInt(p[8:]))
+	m.create = time.Unix(0, getInt(p[16:]))
+	return
+}
+
+// write file atomically creating
+// directories if need
+func writeFile(path string, p []byte) (err error) {
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	var tmp = path + tmpExt
+
+	if err = ioutil.WriteFile(tmp, p, 0600); err != nil {
+		return
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// A FS implements data.CXDS interface. The FS
+// stores every object as a file on a filesystem.
+// The FS is designed for debugging, rsync-based
+// replication and very large objects. Files are
+// sharded by first two bytes of hash of an object
+//
+//     dir/objects/ab/cd/abcd...ef      - value
+//     dir/objects/ab/cd/abcd...ef.meta - RC and times
+//     dir/info                         - stat
+//
+// A value file contains the value as is. A
+// sidecar (.meta) file contains RC, last access
+// time and creating time of the object. An object
+// exists if its sidecar file exists. The FS keeps
+// stat in memory and saves it on Close. If the FS
+// has not been closed successfully, then the stat
+// recalculated walking all objects
+type FS struct {
+	dir string // root directory
+
+	mx sync.Mutex // lock
+	metaInfo
+
+	hooks data.HooksKeepper // hooks
+
+	closeo sync.Once
+}
+
+// NewFS creates new DB or opens existing
+// in given directory. The directory will
+// be created if it doesn't exist
+func NewFS(dir string) (f *FS, err error) {
+
+	if err = os.MkdirAll(filepath.Join(dir, objectsDir), 0700); err != nil {
+		return
+	}
+
+	var x = new(FS)
+	x.dir = dir
+
+	if err = x.getInfo(); err != nil {
+		return
+	}
+
+	if x.isSafeClosed == false {
+		if err = x.recount(); err != nil {
+			return
+		}
+	}
+
+	var sc = x.isSafeClosed // temporary
+	x.isSafeClosed = false
+	if err = x.setInfo(); err != nil {
+		return
+	}
+
+	x.isSafeClosed = sc // restore
+	return x, nil
+}
+
+func (f *FS) getInfo() (err error) {
+
+	var p []byte
+	if p, err = ioutil.ReadFile(filepath.Join(f.dir, infoFile)); err != nil {
+		if os.IsNotExist(err) == true {
+			f.isSafeClosed = true // fresh DB
+			err = nil
+		}
+		return
+	}
+
+	return f.decode(p)
+}
+
+func (f *FS) setInfo() (err error) {
+	return writeFile(filepath.Join(f.dir, infoFile), f.encode())
+}
+
+// recount stat walking all objects
+func (f *FS) recount() (err error) {
+
+	f.amount, f.volume = stat{}, stat{}
+
+	return f.walk(func(key cipher.SHA256) (err error) {
+
+		var m meta
+		if m, err = f.getMeta(key); err != nil {
+			return
+		}
+
+		var fi os.FileInfo
+		if fi, err = os.Stat(f.path(key)); err != nil {
+			return
+		}
+
+		f.amount.all++
+		f.volume.all += fi.Size()
+
+		if m.rc > 0 {
+			f.amount.used++
+			f.volume.used += fi.Size()
+		}
+
+		return
+	})
+}
+
+// path to value file of an object
+func (f *FS) path(key cipher.SHA256) string {
+	var h = key.Hex()
+	return filepath.Join(f.dir, objectsDir, h[0:2], h[2:4], h)
+}
+
+func (f *FS) getMeta(key cipher.SHA256) (m meta, err error) {
+
+	var p []byte
+	if p, err = ioutil.ReadFile(f.path(key) + metaExt); err != nil {
+		if os.IsNotExist(err) == true {
+			err = data.ErrNotFound
+		}
+		return
+	}
+
+	err = m.decode(p)
+	return
+}
+
+func (f *FS) setMeta(key cipher.SHA256, m *meta) (err error) {
+	return writeFile(f.path(key)+metaExt, m.encode())
+}
+
+func (f *FS) getVal(key cipher.SHA256) (val []byte, err error) {
+	return ioutil.ReadFile(f.path(key))
+}
+
+func (f *FS) setVal(key cipher.SHA256, val []byte) (err error) {
+	return writeFile(f.path(key), val)
+}
+
+// size of value of an object
+func (f *FS) size(key cipher.SHA256) (size int64, err error) {
+	var fi os.FileInfo
+	if fi, err = os.Stat(f.path(key)); err != nil {
+		return
+	}
+	return fi.Size(), nil
+}
+
+// remove value and sidecar of an object,
+// the sidecar removed first
+func (f *FS) remove(key cipher.SHA256) (err error) {
+	var path = f.path(key)
+	if err = os.Remove(path + metaExt); err != nil {
+		return
+	}
+	return os.Remove(path)
+}
+
+// walk all objects; the walk doesn't lock the FS
+// and calls given function after every shard
+func (f *FS) walk(walkFunc data.IterateKeysFunc) (err error) {
+
+	var (
+		root   = filepath.Join(f.dir, objectsDir)
+		shards []os.FileInfo
+	)
+
+	if shards, err = ioutil.ReadDir(root); err != nil {
+		return
+	}
+
+	var keys []cipher.SHA256
+
+	for _, shard := range shards {
+
+		if shard.IsDir() == false {
+			continue
+		}
+
+		keys = keys[:0]
+
+		var subs []os.FileInfo
+		if subs, err = ioutil.ReadDir(filepath.Join(root,
+			shard.Name())); err != nil {
+
+			return
+		}
+
+		for _, sub := range subs {
+
+			if sub.IsDir() == false {
+				continue
+			}
+
+			var files []os.FileInfo
+			if files, err = ioutil.ReadDir(filepath.Join(root, shard.Name(),
+				sub.Name())); err != nil {
+
+				return
+			}
+
+			for _, file := range files {
+
+				var name = file.Name()
+
+				if strings.HasSuffix(name, metaExt) == false {
+					continue // value or temporary file
+				}
+
+				var key cipher.SHA256
+				if key, err = keyFromName(name); err != nil {
+					continue // foreign file
+				}
+
+				keys = append(keys, key)
+			}
+
+		}
+
+		for _, key := range keys {
+			if err = walkFunc(key); err != nil {
+				return
+			}
+		}
+
+	}
+
+	return
+}
+
+// key from name of a sidecar file
+func keyFromName(name string) (key cipher.SHA256, err error) {
+
+	var p []byte
+	if p, err = hex.DecodeString(strings.TrimSuffix(name, metaExt)); err != nil {
+		return
+	}
+
+	if len(p) != len(cipher.SHA256{}) {
+		return key, fmt.Errorf("invalid length of key %d", len(p))
+	}
+
+	copy(key[:], p)
+	return
+}
+
+func (f *FS) changeStatAfter(created bool, rc, incrBy, volume int64) {
+	// under lock
+
+	// set methods
+	if created == true {
+		f.amount.all++
+		f.volume.all += volume
+		if rc > 0 {
+			f.amount.used++
+			f.volume.used += volume
+		}
+		return
+	}
+
+	if incrBy == 0 {
+		return // no changes
+	}
+	if rc <= 0 {
+		if rc-incrBy > 0 {
+			f.amount.used--         // } one of objects,
+			f.volume.used -= volume // }  turns to be not used
+		}
+		return
+	}
+	// rc > 0
+	if rc-incrBy <= 0 {
+		f.amount.used++         // } reborn
+		f.volume.used += volume // }
+	}
+	return
+}
+
+//
+// hooks
+//
+
+func (f *FS) beforeTouch(key cipher.SHA256) (err error) {
+	defer f.hooks.BeforeTouchHooksClose()
+	for _, hook := range f.hooks.BeforeTouchHooks() {
+		if _, err = hook(key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (f *FS) beforeGet(key cipher.SHA256, incrBy int64) (err error) {
+	defer f.hooks.BeforeGetHooksClose()
+	for _, hook := range f.hooks.BeforeGetHooks() {
+		if _, err = hook(key, incrBy); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (f *FS) beforeSet(
+	key cipher.SHA256, // :
+	val []byte, //        :
+	incrBy int64, //      :
+) (
+	err error, //         :
+) {
+	defer f.hooks.BeforeSetHooksClose()
+	for _, hook := range f.hooks.BeforeSetHooks() {
+		if _, err = hook(key, val, incrBy); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (f *FS) beforeIncr(key cipher.SHA256, incrBy int64) (err error) {
+	defer f.hooks.BeforeIncrHooksClose()
+	for _, hook := range f.hooks.BeforeIncrHooks() {
+		if _, err = hook(key, incrBy); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (f *FS) beforeDel(key cipher.SHA256) (err error) {
+	defer f.hooks.BeforeDelHooksClose()
+	for _, hook := range f.hooks.BeforeDelHooks() {
+		if _, err = hook(key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Hooks returns hooks of the FS. The FS
+// calls Before-hooks before every call,
+// and After-hooks after every call even
+// if a Before-hook returns an error
+func (f *FS) Hooks() (hooks data.Hooks) {
+	return &f.hooks
+}
+
+//
+// Touch
+//
+
+func (f *FS) touch(key cipher.SHA256) (access time.Time, err error) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var m meta
+	if m, err = f.getMeta(key); err != nil {
+		return
+	}
+
+	access = m.access     // <- get last access time
+	m.access = time.Now() // < and touch
+
+	err = f.setMeta(key, &m)
+	return
+}
+
+// Touch object by its key updating its last access time.
+// The Touch method returns ErrNotFound if object doesn't
+// exist. The Touch returns previous last access time.
+func (f *FS) Touch(key cipher.SHA256) (access time.Time, err error) {
+	if err = f.beforeTouch(key); err == nil {
+		access, err = f.touch(key)
+	}
+	f.hooks.CallAfterTouchHooks(key, access, err)
+	return
+}
+
+//
+// Get
+//
+
+func (f *FS) getIncr(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inc- or decrement RC by this value
+	touch bool, //        : update last access time
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var m meta
+	if m, err = f.getMeta(key); err != nil {
+		return
+	}
+
+	var val []byte
+	if val, err = f.getVal(key); err != nil {
+		return
+	}
+
+	m.rc += incrBy
+
+	obj = &data.Object{
+		Val:    val,
+		RC:     m.rc,
+		Access: m.access, // previous
+		Create: m.create,
+	}
+
+	if touch == true {
+		m.access = time.Now()
+	}
+
+	if touch == true || incrBy != 0 {
+		if err = f.setMeta(key, &m); err != nil {
+			return nil, err
+		}
+	}
+
+	f.changeStatAfter(false, m.rc, incrBy, vol(val))
+	return
+}
+
+// Get Object by key updating its last access time.
+func (f *FS) Get(key cipher.SHA256) (*data.Object, error) {
+	return f.GetIncr(key, 0)
+}
+
+// GetIncr is the same as the Get but it changes
+// RC using provided argument. The argument can
+// be zero, actually.
+func (f *FS) GetIncr(
+	key cipher.SHA256, incrBy int64,
+) (obj *data.Object, err error) {
+
+	if err = f.beforeGet(key, incrBy); err == nil {
+		obj, err = f.getIncr(key, incrBy, true)
+	}
+	f.hooks.CallAfterGetHooks(key, obj, err)
+	return
+}
+
+// GetNotTouch is the same as the Get but it
+// doesn't update last access time.
+func (f *FS) GetNotTouch(key cipher.SHA256) (*data.Object, error) {
+	return f.GetIncrNotTouch(key, 0)
+}
+
+// GetIncrNotTouch is the same as the GetIncr but
+// it doesn't update last access time.
+func (f *FS) GetIncrNotTouch(
+	key cipher.SHA256, incrBy int64,
+) (obj *data.Object, err error) {
+
+	if err = f.beforeGet(key, incrBy); err == nil {
+		obj, err = f.getIncr(key, incrBy, false)
+	}
+	f.hooks.CallAfterGetHooks(key, obj, err)
+	return
+}
+
+//
+// Set
+//
+
+func (f *FS) setIncr(
+	key cipher.SHA256, // : hash of the object
+	val []byte, //        : encoded object
+	incrBy int64, //      : inc- or decrement RC by this value
+	touch bool, //        : update last access time
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var (
+		m       meta
+		now     = time.Now()
+		created bool
+	)
+
+	if m, err = f.getMeta(key); err != nil {
+		if err != data.ErrNotFound {
+			return // DB failure
+		}
+
+		created = true
+		m.create = now
+		m.access = time.Unix(0, 0)
+
+		// the value is the same for existing
+		// object, thus write it only once
+		if err = f.setVal(key, val); err != nil {
+			return
+		}
+	}
+
+	var access = m.access // last access or 0 nano since epoch
+
+	m.rc += incrBy
+
+	if touch == true || created == true {
+		m.access = now
+	}
+
+	if err = f.setMeta(key, &m); err != nil {
+		return
+	}
+
+	obj = &data.Object{
+		Val:    val,
+		RC:     m.rc,
+		Access: access,
+		Create: m.create,
+	}
+
+	f.changeStatAfter(created, m.rc, incrBy, vol(val))
+	return
+}
+
+// Set creates new object or updates existsing. The Set
+// method equal to the SetIncr method with `incrBy = 1`.
+func (f *FS) Set(key cipher.SHA256, val []byte) (*data.Object, error) {
+	return f.SetIncr(key, val, 1)
+}
+
+// SetIncr uses provided inrBy argument to change
+// RC of object. If object already exists, then
+// no auto +1 added. The SetIncr with `incrBy = 1`
+// is the same as the Set.
+func (f *FS) SetIncr(
+	key cipher.SHA256, // : hash of the object
+	val []byte, //        : encoded object
+	incrBy int64, //      : inc- or decrement RC by this value
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	if err = f.beforeSet(key, val, incrBy); err == nil {
+		obj, err = f.setIncr(key, val, incrBy, true)
+	}
+	f.hooks.CallAfterSetHooks(key, obj, err)
+	return
+}
+
+// SetNotTouch is the same as the Set but it
+// doesn't update last access time.
+func (f *FS) SetNotTouch(
+	key cipher.SHA256, val []byte,
+) (*data.Object, error) {
+	return f.SetIncrNotTouch(key, val, 1)
+}
+
+// SetIncrNotTouch is the same as the SetIncr but
+// it doesn't update last access time.
+func (f *FS) SetIncrNotTouch(
+	key cipher.SHA256, // : hash of the object
+	val []byte, //        : encoded object
+	incrBy int64, //      : inc- or decrement RC by this value
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	if err = f.beforeSet(key, val, incrBy); err == nil {
+		obj, err = f.setIncr(key, val, incrBy, false)
+	}
+	f.hooks.CallAfterSetHooks(key, obj, err)
+	return
+}
+
+// call under lock
+func (f *FS) changeStatAfterSetRaw(
+	overwritten bool, // : is overwritten
+	pvol, prc int64, //  : previous
+	vol, rc int64, //    : new
+) {
+
+	if overwritten == true {
+		f.volume.all += (vol - pvol) // diff
+		if prc <= 0 {                // was dead
+			if rc > 0 { // reborn
+				f.amount.used++
+				f.volume.used += vol
+			}
+			// else -> still dead (do nothing)
+		} else { // was alive
+			if rc <= 0 { // kill
+				f.amount.used--
+				f.volume.used -= pvol
+			} else { // still alive
+				f.volume.used += (vol - pvol) // diff
+			}
+		}
+	} else { // new object created
+		f.volume.all += vol
+		f.amount.all++
+		if rc > 0 { // alive object
+			f.volume.used += vol
+			f.amount.used++
+		}
+	}
+
+}
+
+func (f *FS) setRaw(key cipher.SHA256, obj *data.Object) (err error) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var (
+		m               meta
+		overwritten     bool
+		prevVol, prevRC int64
+	)
+
+	if m, err = f.getMeta(key); err != nil {
+		if err != data.ErrNotFound {
+			return // DB failure
+		}
+	} else {
+		overwritten, prevRC = true, m.rc
+		if prevVol, err = f.size(key); err != nil {
+			return
+		}
+	}
+
+	if err = f.setVal(key, obj.Val); err != nil {
+		return
+	}
+
+	m.rc, m.access, m.create = obj.RC, obj.Access, obj.Create
+
+	if err = f.setMeta(key, &m); err != nil {
+		return
+	}
+
+	f.changeStatAfterSetRaw(overwritten, prevVol, prevRC, vol(obj.Val),
+		obj.RC)
+	return
+}
+
+// SetRaw sets given object as is. If object alreday exists,
+// then the SetRaw method overwrites existing one.
+func (f *FS) SetRaw(key cipher.SHA256, obj *data.Object) (err error) {
+	if err = f.beforeSet(key, obj.Val, obj.RC); err == nil {
+		err = f.setRaw(key, obj)
+	}
+	f.hooks.CallAfterSetHooks(key, obj, err)
+	return
+}
+
+//
+// Incr
+//
+
+func (f *FS) incr(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inr- or decrement by
+	touch bool, //        : update last access time
+) (
+	rc int64, //          : new RC
+	access time.Time, //  : previous last access time
+	err error, //         : error if any
+) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var m meta
+	if m, err = f.getMeta(key); err != nil {
+		return
+	}
+
+	var size int64
+	if size, err = f.size(key); err != nil {
+		return
+	}
+
+	access = m.access
+
+	if touch == true {
+		m.access = time.Now()
+	}
+
+	m.rc += incrBy
+	rc = m.rc
+
+	if err = f.setMeta(key, &m); err != nil {
+		return
+	}
+
+	f.changeStatAfter(false, rc, incrBy, size)
+	return
+}
+
+// Incr inc- or decrements RC of object with given
+// key using provided value. The Incr returns new
+// RC or error if any.
+func (f *FS) Incr(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inr- or decrement by
+) (
+	rc int64, //          : new RC
+	access time.Time, //  : previous last access time
+	err error, //         : error if any
+) {
+
+	if err = f.beforeIncr(key, incrBy); err == nil {
+		rc, access, err = f.incr(key, incrBy, true)
+	}
+	f.hooks.CallAfterIncrHooks(key, rc, access, err)
+	return
+}
+
+// IncrNotTouch is the same as the Incr but it
+// doesn't update last access time.
+func (f *FS) IncrNotTouch(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inr- or decrement by
+) (
+	rc int64, //          : new RC
+	access time.Time, //  : previous last access time
+	err error, //         : error if any
+) {
+
+	if err = f.beforeIncr(key, incrBy); err == nil {
+		rc, access, err = f.incr(key, incrBy, false)
+	}
+	f.hooks.CallAfterIncrHooks(key, rc, access, err)
+	return
+}
+
+//
+// Del
+//
+
+func (f *FS) changeStatAfterDel(rc, vol int64) {
+	f.amount.all--
+	f.volume.all -= vol
+
+	if rc > 0 {
+		f.amount.used--
+		f.volume.used -= vol
+	}
+}
+
+func (f *FS) take(key cipher.SHA256) (obj *data.Object, err error) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var m meta
+	if m, err = f.getMeta(key); err != nil {
+		return
+	}
+
+	var val []byte
+	if val, err = f.getVal(key); err != nil {
+		return
+	}
+
+	if err = f.remove(key); err != nil {
+		return
+	}
+
+	f.changeStatAfterDel(m.rc, vol(val))
+
+	obj = &data.Object{
+		Val:    val,
+		RC:     m.rc,
+		Access: m.access,
+		Create: m.create,
+	}
+	return
+}
+
+// Take deletes an object unconditionally returinig:
+// (1) deleted object, (2) ErrNotFound if object
+// doesn't exist (3) any other error (DB failure,
+// for exmple).
+func (f *FS) Take(key cipher.SHA256) (obj *data.Object, err error) {
+	if err = f.beforeDel(key); err == nil {
+		obj, err = f.take(key)
+	}
+	f.hooks.CallAfterDelHooks(key, obj, err)
+	return
+}
+
+func (f *FS) del(key cipher.SHA256) (err error) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var m meta
+	if m, err = f.getMeta(key); err != nil {
+		return
+	}
+
+	var size int64
+	if size, err = f.size(key); err != nil {
+		return
+	}
+
+	if err = f.remove(key); err != nil {
+		return
+	}
+
+	f.changeStatAfterDel(m.rc, size)
+	return
+}
+
+// Del deletes an object unconditionally. The Del
+// method returns ErrNotFound error if object doens't
+// exist in DB.
+func (f *FS) Del(key cipher.SHA256) (err error) {
+	if err = f.beforeDel(key); err == nil {
+		err = f.del(key)
+	}
+	f.hooks.CallAfterDelHooks(key, nil, err)
+	return
+}
+
+//
+// Iterate
+//
+
+// Iterate all keys in CXDS. Use ErrStopIteration to stop
+// an iteration. The Iterate method never lock DB and any
+// parallel Get-/Set-/Incr-/Del/etc call can be performed
+// with call of the Iterate at the same time.
+//
+// Iterate never updates last access time.
+//
+// Iterate can skip new objects, and use deleted objects.
+func (f *FS) Iterate(iterateFunc data.IterateKeysFunc) (err error) {
+	if err = f.walk(iterateFunc); err == data.ErrStopIteration {
+		err = nil
+	}
+	return
+}
+
+//
+// Stat
+//
+
+// Amount of objects. The 'all' means amount of all objects
+// and the 'used' is amount of objects with RC greater then
+// zero.
+func (f *FS) Amount() (all, used int64) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	return f.amount.all, f.amount.used
+}
+
+// Volume of objects. The volume measured
+// in bytes. The volume consist of payload
+// only and not includes keys and any other
+// meta information like references counter
+// etc. The 'all' is volume of all objects,
+// and the 'used' is volume of objects with
+// RC greater then zero.
+func (f *FS) Volume() (all, used int64) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	return f.volume.all, f.volume.used
+}
+
+// IsSafeClosed is flag that means that DB has been
+// closed successfully last time. If the IsSafeClosed
+// returns false, then stat of the FS has been
+// recalculated.
+func (f *FS) IsSafeClosed() bool {
+	return f.isSafeClosed // no locks needed
+}
+
+// Dir returns root directory of the FS
+func (f *FS) Dir() string {
+	return f.dir
+}
+
+// Close the FS
+func (f *FS) Close() (err error) {
+	f.closeo.Do(func() {
+
+		f.mx.Lock()
+		defer f.mx.Unlock()
+
+		f.isSafeClosed = true
+		err = f.setInfo()
+	})
+	return
+}
@@ -0,0 +1,184 @@
+package fs
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/tests/cxds"
+)
+
+var dbDirName = "test.fs.go.ignore"
+
+func newFS(t *testing.T) (f *FS) {
+	os.RemoveAll(dbDirName)
+
+	var err error
+	if f, err = NewFS(dbDirName); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+// clean up db after all
+func closeFS(t *testing.T, f *FS) {
+	defer os.RemoveAll(dbDirName)
+	if err := f.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func runTestCase(t *testing.T, testCase func(t *testing.T, ds data.CXDS)) {
+	var f = newFS(t)
+	defer closeFS(t, f)
+
+	testCase(t, f)
+}
+
+func reopenFS(f **FS) func() (data.CXDS, error) {
+	return func() (data.CXDS, error) {
+		var err error
+		*f, err = NewFS(dbDirName)
+		return *f, err
+	}
+}
+
+func Test_encodeDecode(t *testing.T) {
+	var inf1 metaInfo
+	inf1.amount.all = 10
+	inf1.amount.used = 11
+	inf1.volume.all = 100
+	inf1.volume.used = 101
+	inf1.isSafeClosed = true
+
+	var p = inf1.encode()
+
+	var inf2 metaInfo
+	if err := inf2.decode(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if inf1 != inf2 {
+		t.Fatal("wrong")
+	}
+}
+
+func TestFS_Hooks(t *testing.T) { runTestCase(t, cxds.Hooks) }
+
+func TestFS_hooks(t *testing.T) {
+	var f = newFS(t)
+	defer closeFS(t, f)
+
+	var (
+		key, val = cipher.SumSHA256([]byte("x")), []byte("x")
+		errStop  = errors.New("stop")
+		called   int
+	)
+
+	var hooks = f.Hooks().(*data.HooksKeepper)
+
+	var before = func(cipher.SHA256, []byte, int64) (interface{}, error) {
+		return nil, errStop
+	}
+	var after = func(_ cipher.SHA256, _ *data.Object, err error) {
+		if err != errStop {
+			t.Error("unexpected error:", err)
+		}
+		called++
+	}
+
+	hooks.AddBeforeSetHook(before)
+	hooks.AddAfterSetHook(after)
+
+	if _, err := f.Set(key, val); err != errStop {
+		t.Error("unexpected error:", err)
+	}
+
+	if called != 1 {
+		t.Error("after hook is not called")
+	}
+
+	if all, _ := f.Amount(); all != 0 {
+		t.Error("object created")
+	}
+}
+
+func TestFS_Touch(t *testing.T) { runTestCase(t, cxds.Touch) }
+
+func TestFS_Get(t *testing.T)         { runTestCase(t, cxds.Get) }
+func TestFS_GetIncr(t *testing.T)     { runTestCase(t, cxds.GetIncr) }
+func TestFS_GetNotTouch(t *testing.T) { runTestCase(t, cxds.GetNotTouch) }
+func TestFS_GetIncrNotTouch(t *testing.T) {
+	runTestCase(t, cxds.GetIncrNotTouch)
+}
+
+func TestFS_View(t *testing.T)     { runTestCase(t, cxds.View) }
+func TestFS_SetBatch(t *testing.T) { runTestCase(t, cxds.SetBatch) }
+
+func TestFS_Set(t *testing.T)         { runTestCase(t, cxds.Set) }
+func TestFS_SetIncr(t *testing.T)     { runTestCase(t, cxds.SetIncr) }
+func TestFS_SetNotTouch(t *testing.T) { runTestCase(t, cxds.SetNotTouch) }
+func TestFS_SetIncrNotTouch(t *testing.T) {
+	runTestCase(t, cxds.SetIncrNotTouch)
+}
+func TestFS_SetRaw(t *testing.T) { runTestCase(t, cxds.SetRaw) }
+
+func TestFS_Incr(t *testing.T)         { runTestCase(t, cxds.Incr) }
+func TestFS_IncrNotTouch(t *testing.T) { runTestCase(t, cxds.IncrNotTouch) }
+
+func TestFS_Take(t *testing.T) { runTestCase(t, cxds.Take) }
+func TestFS_Del(t *testing.T)  { runTestCase(t, cxds.Del) }
+
+func TestFS_Iterate(t *testing.T) { runTestCase(t, cxds.Iterate) }
+
+func TestFS_Amount(t *testing.T) {
+	var f = newFS(t)
+	defer closeFS(t, f)
+	cxds.Amount(t, f, reopenFS(&f))
+}
+
+func TestFS_Volume(t *testing.T) {
+	var f = newFS(t)
+	defer closeFS(t, f)
+	cxds.Volume(t, f, reopenFS(&f))
+}
+
+func TestFS_IsSafeClosed(t *testing.T) {
+	var f = newFS(t)
+	defer closeFS(t, f)
+	cxds.IsSafeClosed(t, f, reopenFS(&f))
+}
+
+func TestFS_recount(t *testing.T) {
+	var f = newFS(t)
+	defer closeFS(t, f)
+
+	var key, val = cipher.SumSHA256([]byte("x")), []byte("x")
+
+	if _, err := f.Set(key, val); err != nil {
+		t.Fatal(err)
+	}
+
+	// don't close, drop
+	var err error
+	if f, err = NewFS(dbDirName); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.IsSafeClosed() == true {
+		t.Error("safe closed")
+	}
+
+	if all, used := f.Amount(); all != 1 || used != 1 {
+		t.Error("wrong amount", all, used)
+	}
+
+	if all, used := f.Volume(); all != 1 || used != 1 {
+		t.Error("wrong volume", all, used)
+	}
+}
+
+func TestFS_Close(t *testing.T) { runTestCase(t, cxds.Close) }
@@ -134,6 +134,21 @@ func (m *Memory) GetIncrNotTouch(
 	return copyObject(obj), nil
 }
 
+// View implements data.Viewer interface. The View
+// holds lock of the Memory during the viewFunc call
+func (m *Memory) View(key cipher.SHA256, viewFunc data.ViewFunc) error {
+
+	m.Lock()
+	defer m.Unlock()
+
+	var obj, ok = m.kvs[key]
+	if ok == false {
+		return data.ErrNotFound
+	}
+
+	return viewFunc(obj.Val, obj.RC)
+}
+
 // Set is SetIncr(key, val, 1)
 func (m *Memory) Set(key cipher.SHA256, val []byte) (*data.Object, error) {
 	return m.SetIncr(key, val, 1)
@@ -151,6 +166,18 @@ func (m *Memory) SetIncr(
 	m.Lock()
 	defer m.Unlock()
 
+	return m.setIncr(key, val, incrBy), nil
+}
+
+// under lock
+func (m *Memory) setIncr(
+	key cipher.SHA256,
+	val []byte,
+	incrBy int64,
+) (
+	cp *data.Object,
+) {
+
 	var obj, ok = m.kvs[key]
 	if ok == false {
 		obj = new(data.Object)
@@ -160,7 +187,7 @@ func (m *Memory) SetIncr(
 	obj.Val = val
 	obj.RC += incrBy
 
-	var cp = copyObject(obj)
+	cp = copyObject(obj)
 	obj.Access = time.Now()
 
 	if ok == false {
@@ -173,7 +200,23 @@ func (m *Memory) SetIncr(
 	cp.Val, obj.Val = obj.Val, cp.Val // swap (copy in DB, argument in reply)
 
 	m.changeStatAfter(!ok, obj.RC, incrBy, vol(val))
-	return cp, nil
+	return
+}
+
+// SetBatch implements data.Batcher interface. The
+// SetBatch holds lock of the Memory during the call
+func (m *Memory) SetBatch(items []data.BatchItem) ([]int64, error) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	var rcs = make([]int64, 0, len(items))
+
+	for _, it := range items {
+		rcs = append(rcs, m.setIncr(it.Key, it.Val, it.IncrBy).RC)
+	}
+
+	return rcs, nil
 }
 
 // SetNotTouch ... balh
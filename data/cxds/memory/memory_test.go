@@ -22,6 +22,9 @@ func TestMemory_GetIncrNotTouch(t *testing.T) {
 	runTestCase(t, cxds.GetIncrNotTouch)
 }
 
+func TestMemory_View(t *testing.T)     { runTestCase(t, cxds.View) }
+func TestMemory_SetBatch(t *testing.T) { runTestCase(t, cxds.SetBatch) }
+
 func TestMemory_Set(t *testing.T)         { runTestCase(t, cxds.Set) }
 func TestMemory_SetIncr(t *testing.T)     { runTestCase(t, cxds.SetIncr) }
 func TestMemory_SetNotTouch(t *testing.T) { runTestCase(t, cxds.SetNotTouch) }
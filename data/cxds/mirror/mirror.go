@@ -0,0 +1,746 @@
+package mirror
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// default configurations
+const (
+	ResyncInterval time.Duration = 5 * time.Second // retry interval
+)
+
+// A Config represents configurations
+// of the Mirror
+type Config struct {
+	// Async replication. If it's false, then
+	// every change replicated before a method
+	// returns. Otherwise, changes replicated
+	// by background goroutines.
+	Async bool
+	// ResyncInterval is interval to retry
+	// replication to a replica that has
+	// fallen behind. Zero means that failed
+	// replication will be retried after next
+	// change only.
+	ResyncInterval time.Duration
+	// Resync all objects when the Mirror
+	// created. Use it if replicas are new
+	// or can be out of date. See also the
+	// Resync method of the Mirror.
+	Resync bool
+}
+
+// NewConfig returns default configurations
+func NewConfig() (c Config) {
+	c.ResyncInterval = ResyncInterval
+	return
+}
+
+// A ReplicaStat represents
+// statistic of a replica
+type ReplicaStat struct {
+	Pending int    // objects to replicate
+	Error   string // last error of the replica, if any
+}
+
+// a replica
+type replica struct {
+	ds data.CXDS
+
+	mx      sync.Mutex               // lock pending and error
+	pending map[cipher.SHA256]uint64 // key -> seq
+	seq     uint64                   // seq of last change
+	err     error                    // last error
+
+	smx sync.Mutex // lock synchronization
+
+	wake chan struct{} // wake up background goroutine
+}
+
+func newReplica(ds data.CXDS) (r *replica) {
+	r = new(replica)
+	r.ds = ds
+	r.pending = make(map[cipher.SHA256]uint64)
+	r.wake = make(chan struct{}, 1)
+	return
+}
+
+// mark object as changed
+func (r *replica) mark(key cipher.SHA256) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.seq++
+	r.pending[key] = r.seq
+}
+
+// object replicated, if it has not been
+// changed after, then remove it from pending
+func (r *replica) done(key cipher.SHA256, seq uint64) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if r.pending[key] == seq {
+		delete(r.pending, key)
+	}
+}
+
+// next pending object
+func (r *replica) next(skip map[cipher.SHA256]struct{}) (
+	key cipher.SHA256,
+	seq uint64,
+	ok bool,
+) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	for key, seq = range r.pending {
+		if _, ok = skip[key]; ok == false {
+			return key, seq, true
+		}
+	}
+
+	return key, 0, false
+}
+
+// is the replica consistent for given object
+func (r *replica) isHealthy(key cipher.SHA256) (ok bool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if r.err != nil {
+		return false
+	}
+
+	_, ok = r.pending[key]
+	return ok == false
+}
+
+func (r *replica) setErr(err error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.err = err
+}
+
+func (r *replica) stat() (rs ReplicaStat) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	rs.Pending = len(r.pending)
+	if r.err != nil {
+		rs.Error = r.err.Error()
+	}
+	return
+}
+
+// wake up background goroutine
+func (r *replica) wakeUp() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// copy state of given object from primary to the replica
+func (r *replica) syncObject(
+	primary data.CXDS,
+	key cipher.SHA256,
+) (
+	err error,
+) {
+
+	var obj *data.Object
+	if obj, err = primary.GetNotTouch(key); err != nil {
+		if err != data.ErrNotFound {
+			return
+		}
+		if err = r.ds.Del(key); err == data.ErrNotFound {
+			err = nil // already deleted
+		}
+		return
+	}
+
+	return r.ds.SetRaw(key, obj)
+}
+
+// replicate all pending objects; the replication stops
+// on first error, and the error kept by the replica
+func (r *replica) sync(primary data.CXDS) (err error) {
+
+	r.smx.Lock()
+	defer r.smx.Unlock()
+
+	// objects changed during the sync,
+	// will be replicated next time
+	var synced = make(map[cipher.SHA256]struct{})
+
+	for {
+
+		var key, seq, ok = r.next(synced)
+
+		if ok == false {
+			break
+		}
+
+		if err = r.syncObject(primary, key); err != nil {
+			r.setErr(err)
+			return
+		}
+
+		r.done(key, seq)
+		synced[key] = struct{}{}
+	}
+
+	r.setErr(nil)
+	return
+}
+
+// A Mirror implements data.CXDS interface. The
+// Mirror replicates all changes of primary CXDS
+// to replicas. The replication can be synchronous
+// or asynchronous (see Config). Reads that don't
+// change objects (GetNotTouch and GetIncrNotTouch
+// with zero incrBy) served by primary or by any
+// healthy replica in round-robin order. A replica
+// is healthy for an object if the object is
+// replicated and last replication of the replica
+// has not failed. All other calls goes to the
+// primary.
+//
+// If a replica fails, then it falls behind. The
+// Mirror keeps changed objects and replicates
+// them later (see Config.ResyncInterval). A
+// failure of a replica never breaks the Mirror.
+// Errors of replicas can be obtained using the
+// Stat method.
+//
+// The replication copies state of an object
+// from the primary, thus replicas has the same
+// RC, last access time and create time. Amount,
+// Volume and Iterate use the primary only.
+//
+// The Mirror closes primary and all replicas
+// on Close
+type Mirror struct {
+	primary  data.CXDS
+	replicas []*replica
+
+	conf Config
+
+	rr uint32 // round-robin reads
+
+	hooks data.HooksKeepper // hooks
+
+	quit   chan struct{}
+	await  sync.WaitGroup
+	closeo sync.Once
+}
+
+// NewMirror creates Mirror using given primary CXDS,
+// given replicas and configurations. If Config.Resync
+// is true, then the NewMirror replicates all objects
+// and removes objects that is not in the primary from
+// replicas. The replication performed synchronously
+// if Config.Async is false
+func NewMirror(
+	primary data.CXDS, //    : primary CXDS
+	replicas []data.CXDS, // : replicas
+	conf Config, //          : configurations
+) (
+	m *Mirror, //            : the Mirror
+	err error, //            : error of initial resync
+) {
+
+	m = new(Mirror)
+
+	m.primary = primary
+	m.conf = conf
+
+	for _, ds := range replicas {
+		m.replicas = append(m.replicas, newReplica(ds))
+	}
+
+	m.quit = make(chan struct{})
+
+	for _, r := range m.replicas {
+		m.await.Add(1)
+		go m.syncLoop(r)
+	}
+
+	if conf.Resync == true {
+		if err = m.Resync(); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// Primary returns primary CXDS
+func (m *Mirror) Primary() data.CXDS {
+	return m.primary
+}
+
+// Stat returns statistic of replicas
+func (m *Mirror) Stat() (rss []ReplicaStat) {
+	rss = make([]ReplicaStat, 0, len(m.replicas))
+	for _, r := range m.replicas {
+		rss = append(rss, r.stat())
+	}
+	return
+}
+
+// Resync marks all objects of the primary and
+// all objects of replicas as changed, and
+// replicates them. Thus, all replicas will be
+// the same as the primary. If Config.Async is
+// true, then the Resync returns after marking
+func (m *Mirror) Resync() (err error) {
+
+	var mark = func(r *replica) data.IterateKeysFunc {
+		return func(key cipher.SHA256) (_ error) {
+			r.mark(key)
+			return
+		}
+	}
+
+	for _, r := range m.replicas {
+
+		if err = m.primary.Iterate(mark(r)); err != nil {
+			return
+		}
+
+		// if the replica fails, then it will be synchronized later
+		if rerr := r.ds.Iterate(mark(r)); rerr != nil {
+			r.setErr(rerr)
+		}
+
+	}
+
+	m.replicate()
+	return
+}
+
+func (m *Mirror) syncLoop(r *replica) {
+	defer m.await.Done()
+
+	var tc <-chan time.Time
+
+	if m.conf.ResyncInterval > 0 {
+		var tk = time.NewTicker(m.conf.ResyncInterval)
+		defer tk.Stop()
+
+		tc = tk.C
+	}
+
+	for {
+		select {
+		case <-r.wake:
+		case <-tc:
+		case <-m.quit:
+			return
+		}
+		r.sync(m.primary) // error kept by the replica
+	}
+}
+
+// replicate changes
+func (m *Mirror) replicate() {
+	for _, r := range m.replicas {
+		if m.conf.Async == true {
+			r.wakeUp()
+			continue
+		}
+		r.sync(m.primary) // error kept by the replica
+	}
+}
+
+// object has been changed
+func (m *Mirror) changed(key cipher.SHA256, err error) {
+
+	// if err is not nil, then it's unknown has an
+	// object been changed or not, thus replicate
+	// it anyway
+
+	if err == data.ErrNotFound {
+		return // not found, no changes
+	}
+
+	for _, r := range m.replicas {
+		r.mark(key)
+	}
+
+	m.replicate()
+}
+
+//
+// hooks
+//
+
+func (m *Mirror) beforeTouch(key cipher.SHA256) (err error) {
+	defer m.hooks.BeforeTouchHooksClose()
+	for _, hook := range m.hooks.BeforeTouchHooks() {
+		if _, err = hook(key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (m *Mirror) beforeGet(key cipher.SHA256, incrBy int64) (err error) {
+	defer m.hooks.BeforeGetHooksClose()
+	for _, hook := range m.hooks.BeforeGetHooks() {
+		if _, err = hook(key, incrBy); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (m *Mirror) beforeSet(
+	key cipher.SHA256, // :
+	val []byte, //        :
+	incrBy int64, //      :
+) (
+	err error, //         :
+) {
+	defer m.hooks.BeforeSetHooksClose()
+	for _, hook := range m.hooks.BeforeSetHooks() {
+		if _, err = hook(key, val, incrBy); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (m *Mirror) beforeIncr(key cipher.SHA256, incrBy int64) (err error) {
+	defer m.hooks.BeforeIncrHooksClose()
+	for _, hook := range m.hooks.BeforeIncrHooks() {
+		if _, err = hook(key, incrBy); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (m *Mirror) beforeDel(key cipher.SHA256) (err error) {
+	defer m.hooks.BeforeDelHooksClose()
+	for _, hook := range m.hooks.BeforeDelHooks() {
+		if _, err = hook(key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Hooks returns hooks of the Mirror. Hooks of
+// the primary and replicas are not called by
+// the Mirror
+func (m *Mirror) Hooks() (hooks data.Hooks) {
+	return &m.hooks
+}
+
+//
+// Touch
+//
+
+// Touch object by its key updating its last access time.
+// The Touch method returns ErrNotFound if object doesn't
+// exist. The Touch returns previous last access time.
+func (m *Mirror) Touch(key cipher.SHA256) (access time.Time, err error) {
+	if err = m.beforeTouch(key); err == nil {
+		access, err = m.primary.Touch(key)
+		m.changed(key, err)
+	}
+	m.hooks.CallAfterTouchHooks(key, access, err)
+	return
+}
+
+//
+// Get
+//
+
+// read object from primary or from a healthy replica
+func (m *Mirror) read(key cipher.SHA256) (obj *data.Object, err error) {
+
+	var (
+		ln = uint32(len(m.replicas) + 1)
+		rr = atomic.AddUint32(&m.rr, 1)
+	)
+
+	for i := uint32(0); i < ln; i++ {
+
+		var n = (rr + i) % ln
+
+		if n == 0 {
+			break // primary
+		}
+
+		var r = m.replicas[n-1]
+
+		if r.isHealthy(key) == false {
+			continue
+		}
+
+		if obj, err = r.ds.GetNotTouch(key); err == nil {
+			return
+		}
+
+	}
+
+	return m.primary.GetNotTouch(key)
+}
+
+// Get Object by key updating its last access time.
+func (m *Mirror) Get(key cipher.SHA256) (*data.Object, error) {
+	return m.GetIncr(key, 0)
+}
+
+// GetIncr is the same as the Get but it changes
+// RC using provided argument. The argument can
+// be zero, actually.
+func (m *Mirror) GetIncr(
+	key cipher.SHA256, incrBy int64,
+) (obj *data.Object, err error) {
+
+	if err = m.beforeGet(key, incrBy); err == nil {
+		obj, err = m.primary.GetIncr(key, incrBy)
+		m.changed(key, err)
+	}
+	m.hooks.CallAfterGetHooks(key, obj, err)
+	return
+}
+
+// GetNotTouch is the same as the Get but it
+// doesn't update last access time. The
+// GetNotTouch can be served by a replica.
+func (m *Mirror) GetNotTouch(key cipher.SHA256) (*data.Object, error) {
+	return m.GetIncrNotTouch(key, 0)
+}
+
+// GetIncrNotTouch is the same as the GetIncr but
+// it doesn't update last access time. If incrBy
+// is zero, then the call can be served by a
+// replica.
+func (m *Mirror) GetIncrNotTouch(
+	key cipher.SHA256, incrBy int64,
+) (obj *data.Object, err error) {
+
+	if err = m.beforeGet(key, incrBy); err == nil {
+		if incrBy == 0 {
+			obj, err = m.read(key)
+		} else {
+			obj, err = m.primary.GetIncrNotTouch(key, incrBy)
+			m.changed(key, err)
+		}
+	}
+	m.hooks.CallAfterGetHooks(key, obj, err)
+	return
+}
+
+//
+// Set
+//
+
+// Set creates new object or updates existsing. The Set
+// method equal to the SetIncr method with `incrBy = 1`.
+func (m *Mirror) Set(key cipher.SHA256, val []byte) (*data.Object, error) {
+	return m.SetIncr(key, val, 1)
+}
+
+// SetIncr uses provided inrBy argument to change
+// RC of object. If object already exists, then
+// no auto +1 added. The SetIncr with `incrBy = 1`
+// is the same as the Set.
+func (m *Mirror) SetIncr(
+	key cipher.SHA256, // : hash of the object
+	val []byte, //        : encoded object
+	incrBy int64, //      : inc- or decrement RC by this value
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	if err = m.beforeSet(key, val, incrBy); err == nil {
+		obj, err = m.primary.SetIncr(key, val, incrBy)
+		m.changed(key, err)
+	}
+	m.hooks.CallAfterSetHooks(key, obj, err)
+	return
+}
+
+// SetNotTouch is the same as the Set but it
+// doesn't update last access time.
+func (m *Mirror) SetNotTouch(
+	key cipher.SHA256, val []byte,
+) (*data.Object, error) {
+	return m.SetIncrNotTouch(key, val, 1)
+}
+
+// SetIncrNotTouch is the same as the SetIncr but
+// it doesn't update last access time.
+func (m *Mirror) SetIncrNotTouch(
+	key cipher.SHA256, // : hash of the object
+	val []byte, //        : encoded object
+	incrBy int64, //      : inc- or decrement RC by this value
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	if err = m.beforeSet(key, val, incrBy); err == nil {
+		obj, err = m.primary.SetIncrNotTouch(key, val, incrBy)
+		m.changed(key, err)
+	}
+	m.hooks.CallAfterSetHooks(key, obj, err)
+	return
+}
+
+// SetRaw sets given object as is. If object alreday exists,
+// then the SetRaw method overwrites existing one.
+func (m *Mirror) SetRaw(key cipher.SHA256, obj *data.Object) (err error) {
+	if err = m.beforeSet(key, obj.Val, obj.RC); err == nil {
+		err = m.primary.SetRaw(key, obj)
+		m.changed(key, err)
+	}
+	m.hooks.CallAfterSetHooks(key, obj, err)
+	return
+}
+
+//
+// Incr
+//
+
+// Incr inc- or decrements RC of object with given
+// key using provided value. The Incr returns new
+// RC or error if any.
+func (m *Mirror) Incr(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inr- or decrement by
+) (
+	rc int64, //          : new RC
+	access time.Time, //  : previous last access time
+	err error, //         : error if any
+) {
+
+	if err = m.beforeIncr(key, incrBy); err == nil {
+		rc, access, err = m.primary.Incr(key, incrBy)
+		m.changed(key, err)
+	}
+	m.hooks.CallAfterIncrHooks(key, rc, access, err)
+	return
+}
+
+// IncrNotTouch is the same as the Incr but it
+// doesn't update last access time.
+func (m *Mirror) IncrNotTouch(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inr- or decrement by
+) (
+	rc int64, //          : new RC
+	access time.Time, //  : previous last access time
+	err error, //         : error if any
+) {
+
+	if err = m.beforeIncr(key, incrBy); err == nil {
+		rc, access, err = m.primary.IncrNotTouch(key, incrBy)
+		m.changed(key, err)
+	}
+	m.hooks.CallAfterIncrHooks(key, rc, access, err)
+	return
+}
+
+//
+// Del
+//
+
+// Take deletes an object unconditionally returinig:
+// (1) deleted object, (2) ErrNotFound if object
+// doesn't exist (3) any other error (DB failure,
+// for exmple).
+func (m *Mirror) Take(key cipher.SHA256) (obj *data.Object, err error) {
+	if err = m.beforeDel(key); err == nil {
+		obj, err = m.primary.Take(key)
+		m.changed(key, err)
+	}
+	m.hooks.CallAfterDelHooks(key, obj, err)
+	return
+}
+
+// Del deletes an object unconditionally. The Del
+// method returns ErrNotFound error if object doens't
+// exist in DB.
+func (m *Mirror) Del(key cipher.SHA256) (err error) {
+	if err = m.beforeDel(key); err == nil {
+		err = m.primary.Del(key)
+		m.changed(key, err)
+	}
+	m.hooks.CallAfterDelHooks(key, nil, err)
+	return
+}
+
+//
+// Iterate
+//
+
+// Iterate all keys of the primary.
+func (m *Mirror) Iterate(iterateFunc data.IterateKeysFunc) (err error) {
+	return m.primary.Iterate(iterateFunc)
+}
+
+//
+// Stat
+//
+
+// Amount of objects of the primary.
+func (m *Mirror) Amount() (all, used int64) {
+	return m.primary.Amount()
+}
+
+// Volume of objects of the primary.
+func (m *Mirror) Volume() (all, used int64) {
+	return m.primary.Volume()
+}
+
+// IsSafeClosed returns true if the primary and all
+// replicas have been closed successfully last time.
+func (m *Mirror) IsSafeClosed() bool {
+	for _, r := range m.replicas {
+		if r.ds.IsSafeClosed() == false {
+			return false
+		}
+	}
+	return m.primary.IsSafeClosed()
+}
+
+// Close the Mirror, the primary and all replicas.
+// The Close tries to replicate pending changes
+// before closing. The Close returns error of the
+// primary or first error of replicas
+func (m *Mirror) Close() (err error) {
+	m.closeo.Do(func() {
+
+		close(m.quit)
+		m.await.Wait()
+
+		for _, r := range m.replicas {
+			r.sync(m.primary) // error kept by the replica
+			if rerr := r.ds.Close(); rerr != nil && err == nil {
+				err = rerr
+			}
+		}
+
+		if perr := m.primary.Close(); perr != nil {
+			err = perr
+		}
+	})
+	return
+}
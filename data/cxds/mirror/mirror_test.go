@@ -0,0 +1,264 @@
+package mirror
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds/memory"
+	"github.com/skycoin/cxo/data/tests/cxds"
+)
+
+func newMirror(t *testing.T, conf Config) (m *Mirror) {
+	var err error
+	m, err = NewMirror(memory.NewMemory(), []data.CXDS{
+		memory.NewMemory(),
+		memory.NewMemory(),
+	}, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func runTestCase(t *testing.T, testCase func(t *testing.T, ds data.CXDS)) {
+	t.Run("sync", func(t *testing.T) {
+		testCase(t, newMirror(t, NewConfig()))
+	})
+	t.Run("async", func(t *testing.T) {
+		var conf = NewConfig()
+		conf.Async = true
+		testCase(t, newMirror(t, conf))
+	})
+}
+
+func TestMirror_Hooks(t *testing.T) { runTestCase(t, cxds.Hooks) }
+
+func TestMirror_Touch(t *testing.T) { runTestCase(t, cxds.Touch) }
+
+func TestMirror_Get(t *testing.T)         { runTestCase(t, cxds.Get) }
+func TestMirror_GetIncr(t *testing.T)     { runTestCase(t, cxds.GetIncr) }
+func TestMirror_GetNotTouch(t *testing.T) { runTestCase(t, cxds.GetNotTouch) }
+func TestMirror_GetIncrNotTouch(t *testing.T) {
+	runTestCase(t, cxds.GetIncrNotTouch)
+}
+
+func TestMirror_View(t *testing.T)     { runTestCase(t, cxds.View) }
+func TestMirror_SetBatch(t *testing.T) { runTestCase(t, cxds.SetBatch) }
+
+func TestMirror_Set(t *testing.T)         { runTestCase(t, cxds.Set) }
+func TestMirror_SetIncr(t *testing.T)     { runTestCase(t, cxds.SetIncr) }
+func TestMirror_SetNotTouch(t *testing.T) { runTestCase(t, cxds.SetNotTouch) }
+func TestMirror_SetIncrNotTouch(t *testing.T) {
+	runTestCase(t, cxds.SetIncrNotTouch)
+}
+func TestMirror_SetRaw(t *testing.T) { runTestCase(t, cxds.SetRaw) }
+
+func TestMirror_Incr(t *testing.T)         { runTestCase(t, cxds.Incr) }
+func TestMirror_IncrNotTouch(t *testing.T) { runTestCase(t, cxds.IncrNotTouch) }
+
+func TestMirror_Take(t *testing.T) { runTestCase(t, cxds.Take) }
+func TestMirror_Del(t *testing.T)  { runTestCase(t, cxds.Del) }
+
+func TestMirror_Iterate(t *testing.T) { runTestCase(t, cxds.Iterate) }
+
+func TestMirror_Amount(t *testing.T) {
+	cxds.Amount(t, newMirror(t, NewConfig()), nil)
+}
+
+func TestMirror_Volume(t *testing.T) {
+	cxds.Volume(t, newMirror(t, NewConfig()), nil)
+}
+
+func TestMirror_IsSafeClosed(t *testing.T) {
+	cxds.IsSafeClosed(t, newMirror(t, NewConfig()), nil)
+}
+
+func TestMirror_Close(t *testing.T) { runTestCase(t, cxds.Close) }
+
+// replica that can fail
+type failing struct {
+	data.CXDS
+	fail int32 // atomic
+}
+
+func (f *failing) setFail(fail bool) {
+	if fail == true {
+		atomic.StoreInt32(&f.fail, 1)
+	} else {
+		atomic.StoreInt32(&f.fail, 0)
+	}
+}
+
+var errFail = errors.New("fail")
+
+func (f *failing) SetRaw(key cipher.SHA256, obj *data.Object) (err error) {
+	if atomic.LoadInt32(&f.fail) == 1 {
+		return errFail
+	}
+	return f.CXDS.SetRaw(key, obj)
+}
+
+// replica should have the same object as primary
+func shouldBeReplicated(
+	t *testing.T,
+	primary, replica data.CXDS,
+	key cipher.SHA256,
+) {
+	t.Helper()
+
+	var (
+		po, perr = primary.GetNotTouch(key)
+		ro, rerr = replica.GetNotTouch(key)
+	)
+
+	if perr != rerr {
+		t.Fatal("different errors:", perr, rerr)
+	}
+
+	if perr != nil {
+		return
+	}
+
+	if string(po.Val) != string(ro.Val) || po.RC != ro.RC ||
+		po.Access.Equal(ro.Access) == false ||
+		po.Create.Equal(ro.Create) == false {
+
+		t.Fatal("different objects")
+	}
+}
+
+func TestMirror_replication(t *testing.T) {
+
+	var (
+		primary = memory.NewMemory()
+		replica = &failing{CXDS: memory.NewMemory()}
+
+		key, val = cipher.SumSHA256([]byte("x")), []byte("x")
+	)
+
+	var m, err = NewMirror(primary, []data.CXDS{replica}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if _, err = m.Set(key, val); err != nil {
+		t.Fatal(err)
+	}
+	shouldBeReplicated(t, primary, replica, key)
+
+	if _, _, err = m.Incr(key, 2); err != nil {
+		t.Fatal(err)
+	}
+	shouldBeReplicated(t, primary, replica, key)
+
+	// fall behind
+
+	replica.setFail(true)
+
+	if _, _, err = m.Incr(key, 1); err != nil {
+		t.Fatal(err) // the Mirror never fails because of a replica
+	}
+
+	var rss = m.Stat()
+	if rss[0].Pending != 1 || rss[0].Error != errFail.Error() {
+		t.Fatal("wrong stat", rss)
+	}
+
+	// the replica is not healthy, the primary is used
+	for i := 0; i < 4; i++ {
+		var obj *data.Object
+		if obj, err = m.GetNotTouch(key); err != nil {
+			t.Fatal(err)
+		} else if obj.RC != 4 {
+			t.Fatal("wrong RC", obj.RC)
+		}
+	}
+
+	// resync after next change
+
+	replica.setFail(false)
+
+	if err = m.Del(key); err != nil {
+		t.Fatal(err)
+	}
+	shouldBeReplicated(t, primary, replica, key)
+
+	if rss = m.Stat(); rss[0].Pending != 0 || rss[0].Error != "" {
+		t.Fatal("wrong stat", rss)
+	}
+}
+
+func TestMirror_async(t *testing.T) {
+
+	var (
+		primary = memory.NewMemory()
+		replica = &failing{CXDS: memory.NewMemory(), fail: 1}
+
+		key, val = cipher.SumSHA256([]byte("x")), []byte("x")
+	)
+
+	var m, err = NewMirror(primary, []data.CXDS{replica}, Config{
+		Async:          true,
+		ResyncInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if _, err = m.Set(key, val); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if rss := m.Stat(); rss[0].Pending != 1 {
+		t.Fatal("wrong stat", rss)
+	}
+
+	replica.setFail(false)
+
+	time.Sleep(50 * time.Millisecond)
+
+	if rss := m.Stat(); rss[0].Pending != 0 {
+		t.Fatal("wrong stat", rss)
+	}
+
+	shouldBeReplicated(t, primary, replica, key)
+}
+
+func TestMirror_Resync(t *testing.T) {
+
+	var (
+		primary = memory.NewMemory()
+		replica = memory.NewMemory()
+
+		key, val     = cipher.SumSHA256([]byte("x")), []byte("x")
+		extra, evals = cipher.SumSHA256([]byte("y")), []byte("y")
+	)
+
+	if _, err := primary.Set(key, val); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := replica.Set(extra, evals); err != nil {
+		t.Fatal(err)
+	}
+
+	var m, err = NewMirror(primary, []data.CXDS{replica}, Config{
+		Resync: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	shouldBeReplicated(t, primary, replica, key)
+	shouldBeReplicated(t, primary, replica, extra)
+}
@@ -88,6 +88,9 @@ func TestRedis_GetIncrNotTouch(t *testing.T) {
 	runTestCase(t, cxds.GetIncrNotTouch)
 }
 
+func TestRedis_View(t *testing.T)     { runTestCase(t, cxds.View) }
+func TestRedis_SetBatch(t *testing.T) { runTestCase(t, cxds.SetBatch) }
+
 func TestRedis_Set(t *testing.T)         { runTestCase(t, cxds.Set) }
 func TestRedis_SetIncr(t *testing.T)     { runTestCase(t, cxds.SetIncr) }
 func TestRedis_SetNotTouch(t *testing.T) { runTestCase(t, cxds.SetNotTouch) }
@@ -0,0 +1,755 @@
+package tiered
+
+import (
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// A Config represents configurations
+// of the Tiered
+type Config struct {
+	// MaxAge is max duration an object can be
+	// kept in hot tier without access. Objects
+	// older then the MaxAge moved to cold tier
+	// by migration. Zero MaxAge means that all
+	// objects will be moved.
+	MaxAge time.Duration
+	// Interval of migrations. Set it to zero
+	// to turn migrations off. Use Migrate
+	// method manually in this case.
+	Interval time.Duration
+}
+
+// An Objects represents amount
+// and volume of objects
+type Objects struct {
+	Amount int64 // amount of objects
+	Volume int64 // volume of objects in bytes
+}
+
+func (o *Objects) add(val []byte) {
+	o.Amount++
+	o.Volume += int64(len(val))
+}
+
+// A Stat represents statistic of the Tiered
+type Stat struct {
+	Hot  Objects // all objects of hot tier
+	Cold Objects // all objects of cold tier
+
+	Promoted Objects // moved from cold tier to hot
+	Demoted  Objects // moved from hot tier to cold
+
+	// LastMigration is time of last migration,
+	// zero if there was not a migration
+	LastMigration time.Time
+	// MigrationError is error of last migration,
+	// if any
+	MigrationError error
+}
+
+// A Tiered implements data.CXDS interface. The
+// Tiered layers a hot (fast) CXDS over a cold
+// (archival) one. Every object is kept in one
+// of the tiers. New objects created in hot tier.
+// Objects that is not accessed for a long time
+// (see Config.MaxAge) moved to cold tier by
+// migration. An object moved back to hot tier
+// when it's accessed using methods that update
+// last access time (e.g. Get, GetIncr, Set,
+// Touch, Incr, etc). The *NotTouch methods never
+// move objects, and work with an object in its
+// tier. Since an object can't be in two tiers,
+// RC of the object is always consistent. An
+// operation locks only its object, and operations
+// with different objects are not serialized.
+//
+// The Tiered closes both tiers on Close
+type Tiered struct {
+	hot, cold data.CXDS
+
+	conf Config
+
+	mx  sync.RWMutex         // read lock for operations, lock for Close
+	kmx [keyLocks]sync.Mutex // locks of objects, by first byte of key
+
+	smx  sync.Mutex // stat lock
+	stat Stat
+
+	hooks data.HooksKeepper // hooks
+
+	quit   chan struct{}
+	await  sync.WaitGroup
+	closeo sync.Once
+}
+
+// NewTiered creates Tiered CXDS using given hot
+// and cold CXDS and given configurations
+func NewTiered(hot, cold data.CXDS, conf Config) (t *Tiered) {
+
+	t = new(Tiered)
+
+	t.hot, t.cold = hot, cold
+	t.conf = conf
+
+	t.quit = make(chan struct{})
+
+	if conf.Interval > 0 {
+		t.await.Add(1)
+		go t.migrateLoop()
+	}
+
+	return
+}
+
+// Hot returns hot tier
+func (t *Tiered) Hot() data.CXDS {
+	return t.hot
+}
+
+// Cold returns cold tier
+func (t *Tiered) Cold() data.CXDS {
+	return t.cold
+}
+
+// Stat returns statistic of the Tiered
+func (t *Tiered) Stat() (s Stat) {
+
+	t.smx.Lock()
+	s = t.stat
+	t.smx.Unlock()
+
+	s.Hot.Amount, _ = t.hot.Amount()
+	s.Hot.Volume, _ = t.hot.Volume()
+
+	s.Cold.Amount, _ = t.cold.Amount()
+	s.Cold.Volume, _ = t.cold.Volume()
+
+	return
+}
+
+// number of locks of objects
+const keyLocks = 256
+
+// lock object with given key, an operation
+// with the object and moving of the object
+// can't be performed concurrently
+func (t *Tiered) lock(key cipher.SHA256) {
+	t.mx.RLock()
+	t.kmx[key[0]].Lock()
+}
+
+// unlock object with given key
+func (t *Tiered) unlock(key cipher.SHA256) {
+	t.kmx[key[0]].Unlock()
+	t.mx.RUnlock()
+}
+
+//
+// moving
+//
+
+// move object from one tier to another, the object is
+// copied first, thus it can't be lost by a failure
+func (t *Tiered) move(
+	key cipher.SHA256, // : key of the object
+	from data.CXDS, //    : source
+	to data.CXDS, //      : destination
+) (
+	obj *data.Object, //  : moved object
+	err error, //         : error if any
+) {
+	// under lock
+
+	if obj, err = from.GetNotTouch(key); err != nil {
+		return
+	}
+
+	if err = to.SetRaw(key, obj); err != nil {
+		return
+	}
+
+	if err = from.Del(key); err != nil {
+		to.Del(key) // rollback (ignore error)
+		return nil, err
+	}
+
+	return
+}
+
+// move object from cold tier to hot
+func (t *Tiered) promote(key cipher.SHA256) (err error) {
+	// under lock
+
+	var obj *data.Object
+	if obj, err = t.move(key, t.cold, t.hot); err != nil {
+		return
+	}
+
+	t.smx.Lock()
+	t.stat.Promoted.add(obj.Val)
+	t.smx.Unlock()
+
+	return
+}
+
+// move object from hot tier to cold if it's old
+func (t *Tiered) demote(key cipher.SHA256, before time.Time) (err error) {
+
+	t.lock(key)
+	defer t.unlock(key)
+
+	var obj *data.Object
+	if obj, err = t.hot.GetNotTouch(key); err != nil {
+		if err == data.ErrNotFound {
+			err = nil // removed or moved after
+		}
+		return
+	}
+
+	if obj.Access.Before(before) == false {
+		return // keep
+	}
+
+	if obj, err = t.move(key, t.hot, t.cold); err != nil {
+		return
+	}
+
+	t.smx.Lock()
+	t.stat.Demoted.add(obj.Val)
+	t.smx.Unlock()
+
+	return
+}
+
+// Migrate objects that is not accessed from
+// given time from hot tier to cold. The Migrate
+// called by the Tiered if Config.Interval is not
+// zero. It's possible to call it manually
+func (t *Tiered) Migrate(before time.Time) (err error) {
+
+	err = t.hot.Iterate(func(key cipher.SHA256) error {
+		return t.demote(key, before)
+	})
+
+	t.smx.Lock()
+	t.stat.LastMigration = time.Now()
+	t.stat.MigrationError = err
+	t.smx.Unlock()
+
+	return
+}
+
+func (t *Tiered) migrateLoop() {
+	defer t.await.Done()
+
+	var tk = time.NewTicker(t.conf.Interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			t.Migrate(time.Now().Add(-t.conf.MaxAge)) // error kept in Stat
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+//
+// hooks
+//
+
+func (t *Tiered) beforeTouch(key cipher.SHA256) (err error) {
+	defer t.hooks.BeforeTouchHooksClose()
+	for _, hook := range t.hooks.BeforeTouchHooks() {
+		if _, err = hook(key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (t *Tiered) beforeGet(key cipher.SHA256, incrBy int64) (err error) {
+	defer t.hooks.BeforeGetHooksClose()
+	for _, hook := range t.hooks.BeforeGetHooks() {
+		if _, err = hook(key, incrBy); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (t *Tiered) beforeSet(
+	key cipher.SHA256, // :
+	val []byte, //        :
+	incrBy int64, //      :
+) (
+	err error, //         :
+) {
+	defer t.hooks.BeforeSetHooksClose()
+	for _, hook := range t.hooks.BeforeSetHooks() {
+		if _, err = hook(key, val, incrBy); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (t *Tiered) beforeIncr(key cipher.SHA256, incrBy int64) (err error) {
+	defer t.hooks.BeforeIncrHooksClose()
+	for _, hook := range t.hooks.BeforeIncrHooks() {
+		if _, err = hook(key, incrBy); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (t *Tiered) beforeDel(key cipher.SHA256) (err error) {
+	defer t.hooks.BeforeDelHooksClose()
+	for _, hook := range t.hooks.BeforeDelHooks() {
+		if _, err = hook(key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Hooks returns hooks of the Tiered. Hooks
+// of tiers are not called by the Tiered
+func (t *Tiered) Hooks() (hooks data.Hooks) {
+	return &t.hooks
+}
+
+//
+// Touch
+//
+
+func (t *Tiered) touch(key cipher.SHA256) (access time.Time, err error) {
+
+	t.lock(key)
+	defer t.unlock(key)
+
+	if access, err = t.hot.Touch(key); err != data.ErrNotFound {
+		return
+	}
+
+	if err = t.promote(key); err != nil {
+		return
+	}
+
+	return t.hot.Touch(key)
+}
+
+// Touch object by its key updating its last access time.
+// The Touch method returns ErrNotFound if object doesn't
+// exist. The Touch returns previous last access time.
+func (t *Tiered) Touch(key cipher.SHA256) (access time.Time, err error) {
+	if err = t.beforeTouch(key); err == nil {
+		access, err = t.touch(key)
+	}
+	t.hooks.CallAfterTouchHooks(key, access, err)
+	return
+}
+
+//
+// Get
+//
+
+func (t *Tiered) getIncr(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inc- or decrement RC by this value
+	touch bool, //        : update last access time
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	t.lock(key)
+	defer t.unlock(key)
+
+	if touch == false {
+		obj, err = t.hot.GetIncrNotTouch(key, incrBy)
+		if err == data.ErrNotFound {
+			obj, err = t.cold.GetIncrNotTouch(key, incrBy)
+		}
+		return
+	}
+
+	if obj, err = t.hot.GetIncr(key, incrBy); err != data.ErrNotFound {
+		return
+	}
+
+	if err = t.promote(key); err != nil {
+		return
+	}
+
+	return t.hot.GetIncr(key, incrBy)
+}
+
+// Get Object by key updating its last access time.
+func (t *Tiered) Get(key cipher.SHA256) (*data.Object, error) {
+	return t.GetIncr(key, 0)
+}
+
+// GetIncr is the same as the Get but it changes
+// RC using provided argument. The argument can
+// be zero, actually.
+func (t *Tiered) GetIncr(
+	key cipher.SHA256, incrBy int64,
+) (obj *data.Object, err error) {
+
+	if err = t.beforeGet(key, incrBy); err == nil {
+		obj, err = t.getIncr(key, incrBy, true)
+	}
+	t.hooks.CallAfterGetHooks(key, obj, err)
+	return
+}
+
+// GetNotTouch is the same as the Get but it
+// doesn't update last access time.
+func (t *Tiered) GetNotTouch(key cipher.SHA256) (*data.Object, error) {
+	return t.GetIncrNotTouch(key, 0)
+}
+
+// GetIncrNotTouch is the same as the GetIncr but
+// it doesn't update last access time.
+func (t *Tiered) GetIncrNotTouch(
+	key cipher.SHA256, incrBy int64,
+) (obj *data.Object, err error) {
+
+	if err = t.beforeGet(key, incrBy); err == nil {
+		obj, err = t.getIncr(key, incrBy, false)
+	}
+	t.hooks.CallAfterGetHooks(key, obj, err)
+	return
+}
+
+//
+// Set
+//
+
+// has object
+func has(ds data.CXDS, key cipher.SHA256) (ok bool, err error) {
+	if _, err = ds.GetNotTouch(key); err == data.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (t *Tiered) setIncr(
+	key cipher.SHA256, // : hash of the object
+	val []byte, //        : encoded object
+	incrBy int64, //      : inc- or decrement RC by this value
+	touch bool, //        : update last access time
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	t.lock(key)
+	defer t.unlock(key)
+
+	var ok bool
+	if ok, err = has(t.hot, key); err != nil {
+		return
+	}
+
+	// new objects created in hot tier, existing
+	// objects updated in their tier, or promoted
+
+	if ok == false {
+		if ok, err = has(t.cold, key); err != nil {
+			return
+		}
+		if ok == true {
+			if touch == false {
+				return t.cold.SetIncrNotTouch(key, val, incrBy)
+			}
+			if err = t.promote(key); err != nil {
+				return
+			}
+		}
+	}
+
+	if touch == false {
+		return t.hot.SetIncrNotTouch(key, val, incrBy)
+	}
+
+	return t.hot.SetIncr(key, val, incrBy)
+}
+
+// Set creates new object or updates existsing. The Set
+// method equal to the SetIncr method with `incrBy = 1`.
+func (t *Tiered) Set(key cipher.SHA256, val []byte) (*data.Object, error) {
+	return t.SetIncr(key, val, 1)
+}
+
+// SetIncr uses provided inrBy argument to change
+// RC of object. If object already exists, then
+// no auto +1 added. The SetIncr with `incrBy = 1`
+// is the same as the Set.
+func (t *Tiered) SetIncr(
+	key cipher.SHA256, // : hash of the object
+	val []byte, //        : encoded object
+	incrBy int64, //      : inc- or decrement RC by this value
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	if err = t.beforeSet(key, val, incrBy); err == nil {
+		obj, err = t.setIncr(key, val, incrBy, true)
+	}
+	t.hooks.CallAfterSetHooks(key, obj, err)
+	return
+}
+
+// SetNotTouch is the same as the Set but it
+// doesn't update last access time.
+func (t *Tiered) SetNotTouch(
+	key cipher.SHA256, val []byte,
+) (*data.Object, error) {
+	return t.SetIncrNotTouch(key, val, 1)
+}
+
+// SetIncrNotTouch is the same as the SetIncr but
+// it doesn't update last access time.
+func (t *Tiered) SetIncrNotTouch(
+	key cipher.SHA256, // : hash of the object
+	val []byte, //        : encoded object
+	incrBy int64, //      : inc- or decrement RC by this value
+) (
+	obj *data.Object, //  : object with new RC and previous last access time
+	err error, //         : error if any
+) {
+
+	if err = t.beforeSet(key, val, incrBy); err == nil {
+		obj, err = t.setIncr(key, val, incrBy, false)
+	}
+	t.hooks.CallAfterSetHooks(key, obj, err)
+	return
+}
+
+func (t *Tiered) setRaw(key cipher.SHA256, obj *data.Object) (err error) {
+
+	t.lock(key)
+	defer t.unlock(key)
+
+	// remove from cold tier, if any
+	if err = t.cold.Del(key); err != nil && err != data.ErrNotFound {
+		return
+	}
+
+	return t.hot.SetRaw(key, obj)
+}
+
+// SetRaw sets given object as is. If object alreday exists,
+// then the SetRaw method overwrites existing one. The object
+// is placed to hot tier.
+func (t *Tiered) SetRaw(key cipher.SHA256, obj *data.Object) (err error) {
+	if err = t.beforeSet(key, obj.Val, obj.RC); err == nil {
+		err = t.setRaw(key, obj)
+	}
+	t.hooks.CallAfterSetHooks(key, obj, err)
+	return
+}
+
+//
+// Incr
+//
+
+func (t *Tiered) incr(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inr- or decrement by
+	touch bool, //        : update last access time
+) (
+	rc int64, //          : new RC
+	access time.Time, //  : previous last access time
+	err error, //         : error if any
+) {
+
+	t.lock(key)
+	defer t.unlock(key)
+
+	if touch == false {
+		rc, access, err = t.hot.IncrNotTouch(key, incrBy)
+		if err == data.ErrNotFound {
+			rc, access, err = t.cold.IncrNotTouch(key, incrBy)
+		}
+		return
+	}
+
+	if rc, access, err = t.hot.Incr(key, incrBy); err != data.ErrNotFound {
+		return
+	}
+
+	if err = t.promote(key); err != nil {
+		return
+	}
+
+	return t.hot.Incr(key, incrBy)
+}
+
+// Incr inc- or decrements RC of object with given
+// key using provided value. The Incr returns new
+// RC or error if any.
+func (t *Tiered) Incr(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inr- or decrement by
+) (
+	rc int64, //          : new RC
+	access time.Time, //  : previous last access time
+	err error, //         : error if any
+) {
+
+	if err = t.beforeIncr(key, incrBy); err == nil {
+		rc, access, err = t.incr(key, incrBy, true)
+	}
+	t.hooks.CallAfterIncrHooks(key, rc, access, err)
+	return
+}
+
+// IncrNotTouch is the same as the Incr but it
+// doesn't update last access time.
+func (t *Tiered) IncrNotTouch(
+	key cipher.SHA256, // : hash of the object
+	incrBy int64, //      : inr- or decrement by
+) (
+	rc int64, //          : new RC
+	access time.Time, //  : previous last access time
+	err error, //         : error if any
+) {
+
+	if err = t.beforeIncr(key, incrBy); err == nil {
+		rc, access, err = t.incr(key, incrBy, false)
+	}
+	t.hooks.CallAfterIncrHooks(key, rc, access, err)
+	return
+}
+
+//
+// Del
+//
+
+func (t *Tiered) take(key cipher.SHA256) (obj *data.Object, err error) {
+
+	t.lock(key)
+	defer t.unlock(key)
+
+	if obj, err = t.hot.Take(key); err != data.ErrNotFound {
+		return
+	}
+
+	return t.cold.Take(key)
+}
+
+// Take deletes an object unconditionally returinig:
+// (1) deleted object, (2) ErrNotFound if object
+// doesn't exist (3) any other error (DB failure,
+// for exmple).
+func (t *Tiered) Take(key cipher.SHA256) (obj *data.Object, err error) {
+	if err = t.beforeDel(key); err == nil {
+		obj, err = t.take(key)
+	}
+	t.hooks.CallAfterDelHooks(key, obj, err)
+	return
+}
+
+func (t *Tiered) del(key cipher.SHA256) (err error) {
+
+	t.lock(key)
+	defer t.unlock(key)
+
+	if err = t.hot.Del(key); err != data.ErrNotFound {
+		return
+	}
+
+	return t.cold.Del(key)
+}
+
+// Del deletes an object unconditionally. The Del
+// method returns ErrNotFound error if object doens't
+// exist in DB.
+func (t *Tiered) Del(key cipher.SHA256) (err error) {
+	if err = t.beforeDel(key); err == nil {
+		err = t.del(key)
+	}
+	t.hooks.CallAfterDelHooks(key, nil, err)
+	return
+}
+
+//
+// Iterate
+//
+
+// Iterate all keys in CXDS, hot tier first. Use
+// ErrStopIteration to stop an iteration. The Iterate
+// method never lock DB. The Iterate can skip objects
+// moved between tiers during the iteration, or use
+// some of them twice.
+func (t *Tiered) Iterate(iterateFunc data.IterateKeysFunc) (err error) {
+
+	var stop bool
+
+	var iterate = func(key cipher.SHA256) (err error) {
+		if err = iterateFunc(key); err == data.ErrStopIteration {
+			stop = true
+		}
+		return
+	}
+
+	if err = t.hot.Iterate(iterate); err != nil || stop == true {
+		return
+	}
+
+	return t.cold.Iterate(iterate)
+}
+
+//
+// Stat
+//
+
+// Amount of objects of both tiers.
+func (t *Tiered) Amount() (all, used int64) {
+	var hotAll, hotUsed = t.hot.Amount()
+	var coldAll, coldUsed = t.cold.Amount()
+	return hotAll + coldAll, hotUsed + coldUsed
+}
+
+// Volume of objects of both tiers.
+func (t *Tiered) Volume() (all, used int64) {
+	var hotAll, hotUsed = t.hot.Volume()
+	var coldAll, coldUsed = t.cold.Volume()
+	return hotAll + coldAll, hotUsed + coldUsed
+}
+
+// IsSafeClosed returns true if both tiers
+// have been closed successfully last time.
+func (t *Tiered) IsSafeClosed() bool {
+	return t.hot.IsSafeClosed() && t.cold.IsSafeClosed()
+}
+
+// Close the Tiered and both tiers
+func (t *Tiered) Close() (err error) {
+	t.closeo.Do(func() {
+
+		close(t.quit)
+		t.await.Wait()
+
+		t.mx.Lock()
+		defer t.mx.Unlock()
+
+		var coldErr = t.cold.Close()
+
+		if err = t.hot.Close(); err == nil {
+			err = coldErr
+		}
+	})
+	return
+}
@@ -0,0 +1,290 @@
+package tiered
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds/memory"
+	"github.com/skycoin/cxo/data/tests/cxds"
+)
+
+func newTiered() *Tiered {
+	return NewTiered(memory.NewMemory(), memory.NewMemory(), Config{})
+}
+
+func runTestCase(t *testing.T, testCase func(t *testing.T, ds data.CXDS)) {
+	testCase(t, newTiered())
+}
+
+// run test case with all objects moved to cold tier
+// after every call of the CXDS
+type coldTiered struct {
+	*Tiered
+}
+
+func (c coldTiered) demote() {
+	c.Migrate(time.Now().Add(time.Hour))
+}
+
+func (c coldTiered) Set(key cipher.SHA256, val []byte) (*data.Object, error) {
+	defer c.demote()
+	return c.Tiered.Set(key, val)
+}
+
+func (c coldTiered) SetIncr(
+	key cipher.SHA256, val []byte, incrBy int64,
+) (*data.Object, error) {
+	defer c.demote()
+	return c.Tiered.SetIncr(key, val, incrBy)
+}
+
+func runColdTestCase(
+	t *testing.T,
+	testCase func(t *testing.T, ds data.CXDS),
+) {
+	testCase(t, coldTiered{newTiered()})
+}
+
+func TestTiered_Hooks(t *testing.T) { runTestCase(t, cxds.Hooks) }
+
+func TestTiered_Touch(t *testing.T) {
+	runTestCase(t, cxds.Touch)
+	runColdTestCase(t, cxds.Touch)
+}
+
+func TestTiered_Get(t *testing.T) {
+	runTestCase(t, cxds.Get)
+	runColdTestCase(t, cxds.Get)
+}
+
+func TestTiered_GetIncr(t *testing.T) {
+	runTestCase(t, cxds.GetIncr)
+	runColdTestCase(t, cxds.GetIncr)
+}
+
+func TestTiered_GetNotTouch(t *testing.T) {
+	runTestCase(t, cxds.GetNotTouch)
+	runColdTestCase(t, cxds.GetNotTouch)
+}
+
+func TestTiered_GetIncrNotTouch(t *testing.T) {
+	runTestCase(t, cxds.GetIncrNotTouch)
+	runColdTestCase(t, cxds.GetIncrNotTouch)
+}
+
+func TestTiered_View(t *testing.T) {
+	runTestCase(t, cxds.View)
+	runColdTestCase(t, cxds.View)
+}
+
+func TestTiered_SetBatch(t *testing.T) { runTestCase(t, cxds.SetBatch) }
+
+func TestTiered_Set(t *testing.T)         { runTestCase(t, cxds.Set) }
+func TestTiered_SetIncr(t *testing.T)     { runTestCase(t, cxds.SetIncr) }
+func TestTiered_SetNotTouch(t *testing.T) { runTestCase(t, cxds.SetNotTouch) }
+func TestTiered_SetIncrNotTouch(t *testing.T) {
+	runTestCase(t, cxds.SetIncrNotTouch)
+}
+func TestTiered_SetRaw(t *testing.T) { runTestCase(t, cxds.SetRaw) }
+
+func TestTiered_Incr(t *testing.T) {
+	runTestCase(t, cxds.Incr)
+	runColdTestCase(t, cxds.Incr)
+}
+
+func TestTiered_IncrNotTouch(t *testing.T) {
+	runTestCase(t, cxds.IncrNotTouch)
+	runColdTestCase(t, cxds.IncrNotTouch)
+}
+
+func TestTiered_Take(t *testing.T) {
+	runTestCase(t, cxds.Take)
+	runColdTestCase(t, cxds.Take)
+}
+
+func TestTiered_Del(t *testing.T) {
+	runTestCase(t, cxds.Del)
+	runColdTestCase(t, cxds.Del)
+}
+
+func TestTiered_Iterate(t *testing.T) {
+	runTestCase(t, cxds.Iterate)
+	runColdTestCase(t, cxds.Iterate)
+}
+
+func TestTiered_Amount(t *testing.T) {
+	cxds.Amount(t, newTiered(), nil)
+}
+
+func TestTiered_Volume(t *testing.T) {
+	cxds.Volume(t, newTiered(), nil)
+}
+
+func TestTiered_IsSafeClosed(t *testing.T) {
+	cxds.IsSafeClosed(t, newTiered(), nil)
+}
+
+func TestTiered_Close(t *testing.T) { runTestCase(t, cxds.Close) }
+
+func TestTiered_Migrate(t *testing.T) {
+
+	var (
+		tr = newTiered()
+
+		old, oldVal = cipher.SumSHA256([]byte("old")), []byte("old")
+		hot, hotVal = cipher.SumSHA256([]byte("hot")), []byte("hot")
+		tierAmount  = func(ds data.CXDS) (all int64) { all, _ = ds.Amount(); return }
+		before      time.Time
+		err         error
+		obj         *data.Object
+	)
+	defer tr.Close()
+
+	if _, err = tr.Set(old, oldVal); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	before = time.Now()
+
+	if _, err = tr.Set(hot, hotVal); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tr.Migrate(before); err != nil {
+		t.Fatal(err)
+	}
+
+	if tierAmount(tr.Hot()) != 1 || tierAmount(tr.Cold()) != 1 {
+		t.Fatal("wrong amount of objects in tiers")
+	}
+
+	if _, err = tr.Cold().GetNotTouch(old); err != nil {
+		t.Fatal("old object is not demoted:", err)
+	}
+
+	var s = tr.Stat()
+
+	if s.Demoted.Amount != 1 || s.Demoted.Volume != int64(len(oldVal)) {
+		t.Error("wrong stat", s.Demoted)
+	}
+
+	if s.LastMigration.IsZero() == true {
+		t.Error("zero time of last migration")
+	}
+
+	// not touch doesn't promote
+
+	if obj, err = tr.GetIncrNotTouch(old, 1); err != nil {
+		t.Fatal(err)
+	} else if obj.RC != 2 {
+		t.Error("wrong RC", obj.RC)
+	}
+
+	if tierAmount(tr.Cold()) != 1 {
+		t.Error("promoted")
+	}
+
+	// promote
+
+	if obj, err = tr.Get(old); err != nil {
+		t.Fatal(err)
+	} else if obj.RC != 2 {
+		t.Error("wrong RC", obj.RC)
+	}
+
+	if tierAmount(tr.Hot()) != 2 || tierAmount(tr.Cold()) != 0 {
+		t.Fatal("not promoted")
+	}
+
+	if s = tr.Stat(); s.Promoted.Amount != 1 {
+		t.Error("wrong stat", s.Promoted)
+	}
+
+	if all, used := tr.Amount(); all != 2 || used != 2 {
+		t.Error("wrong amount", all, used)
+	}
+}
+
+func TestTiered_migrateLoop(t *testing.T) {
+
+	var tr = NewTiered(memory.NewMemory(), memory.NewMemory(), Config{
+		MaxAge:   0,
+		Interval: 10 * time.Millisecond,
+	})
+	defer tr.Close()
+
+	var key, val = cipher.SumSHA256([]byte("x")), []byte("x")
+
+	if _, err := tr.Set(key, val); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if all, _ := tr.Cold().Amount(); all != 1 {
+		t.Error("not demoted")
+	}
+}
+
+func TestTiered_lock(t *testing.T) {
+
+	var tr = newTiered()
+	defer tr.Close()
+
+	var (
+		key, val = cipher.SumSHA256([]byte("x")), []byte("x")
+		oth, ov  = cipher.SumSHA256([]byte("y")), []byte("y")
+	)
+
+	for key[0] == oth[0] {
+		ov = append(ov, 'y')
+		oth = cipher.SumSHA256(ov)
+	}
+
+	if _, err := tr.Set(key, val); err != nil {
+		t.Fatal(err)
+	}
+
+	// locked object doesn't block other objects
+
+	tr.lock(key)
+
+	var done = make(chan error, 1)
+
+	go func() {
+		var _, err = tr.Set(oth, ov)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Error("blocked by lock of other object")
+	}
+
+	// but it blocks its own operations
+
+	go func() {
+		var _, err = tr.Get(key)
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Error("not blocked by lock of the object")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tr.unlock(key)
+
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
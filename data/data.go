@@ -1,6 +1,7 @@
 package data
 
 import (
+	"encoding/binary"
 	"errors"
 	"time"
 
@@ -63,6 +64,9 @@ type Root struct {
 	// Sig contains signature
 	// of the Root
 	Sig cipher.Sig
+	// Sigs contains signatures of
+	// a Root of multisig feed, if any
+	Sigs []cipher.Sig
 
 	// Access contains last access time in DB.
 	// Zero time can be checked using
@@ -81,6 +85,12 @@ type root struct {
 	Create int64         // unix nano
 }
 
+// rootSigs are encoded after the root,
+// and only if the Root has the Sigs
+type rootSigs struct {
+	Sigs []cipher.Sig
+}
+
 // Validate the Root
 func (r *Root) Validate() (err error) {
 	if r.Hash == (cipher.SHA256{}) {
@@ -110,14 +120,19 @@ func (r *Root) Encode() (p []byte) {
 	s.Access = r.Access.UnixNano()
 	s.Create = r.Create.UnixNano()
 
-	return encoder.Serialize(&s)
+	p = encoder.Serialize(&s)
+
+	if len(r.Sigs) > 0 {
+		p = append(p, encoder.Serialize(&rootSigs{r.Sigs})...)
+	}
+
+	return
 }
 
 // Decode given encoded Root to this one.
-// The Decode method never check input
-// length and if the input is longer
-// then encoded value, then no error
-// returned
+// If the input is longer then encoded value,
+// then rest of the input is signatures of
+// multisig Root (see Sigs field)
 func (r *Root) Decode(p []byte) (err error) {
 
 	var s root
@@ -127,6 +142,15 @@ func (r *Root) Decode(p []byte) (err error) {
 
 	r.Hash = s.Hash
 	r.Sig = s.Sig
+	r.Sigs = nil
+
+	if n := len(encoder.Serialize(&s)); len(p) > n {
+		var rs rootSigs
+		if err = encoder.DeserializeRaw(p[n:], &rs); err != nil {
+			return
+		}
+		r.Sigs = rs.Sigs
+	}
 
 	r.Access = time.Unix(0, s.Access)
 	r.Create = time.Unix(0, s.Create)
@@ -204,3 +228,30 @@ func (o *Object) Incr(incrBy int64) (rc int64) {
 	o.RC += incrBy
 	return o.RC
 }
+
+// ViewObject returns value and RC of encoded Object
+// without copying. The val is a part of given p. E.g.
+// it's the same as the Decode, but the ViewObject
+// doesn't allocate anything. The ViewObject returns
+// ErrInvalidSize if the p is too short
+func ViewObject(p []byte) (val []byte, rc int64, err error) {
+
+	// encoded object is
+	//
+	//     [4 bytes length][val][8 rc][8 access][8 create]
+	//
+
+	if len(p) < 4 {
+		return nil, 0, ErrInvalidSize
+	}
+
+	var ln = uint64(binary.LittleEndian.Uint32(p))
+
+	if uint64(len(p)) < 4+ln+8+8+8 {
+		return nil, 0, ErrInvalidSize
+	}
+
+	val = p[4 : 4+ln : 4+ln] // don't allow append to the val
+	rc = int64(binary.LittleEndian.Uint64(p[4+ln:]))
+	return
+}
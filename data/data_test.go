@@ -83,6 +83,7 @@ func TestNewDB(t *testing.T) {
 func (r *Root) equal(s *Root) (eq bool) {
 	eq = r.Hash == s.Hash &&
 		r.Sig == s.Sig &&
+		len(r.Sigs) == len(s.Sigs) &&
 		r.Access.UnixNano() == s.Access.UnixNano() &&
 		r.Create.UnixNano() == s.Create.UnixNano()
 	return
@@ -182,6 +183,18 @@ func TestRoot_Encode(t *testing.T) {
 		}
 	}
 
+	// multisig
+
+	o.Sigs = []cipher.Sig{o.Sig, {}}
+
+	if err := e.Decode(o.Encode()); err != nil {
+		t.Fatal("decoding error:", err)
+	}
+
+	if o.equal(e) == false || e.Sigs[0] != o.Sig {
+		t.Error("decoded Root is different")
+	}
+
 }
 
 func TestRoot_Decode(t *testing.T) {
@@ -259,6 +272,45 @@ func TestObject_Decode(t *testing.T) {
 	// moved to TestObject_Encode
 }
 
+func TestViewObject(t *testing.T) {
+
+	var o = &Object{
+		Val:    []byte("value"),
+		RC:     1050,
+		Access: time.Unix(0, 0),
+		Create: time.Unix(0, 0),
+	}
+
+	var p = o.Encode()
+
+	var val, rc, err = ViewObject(p)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Compare(val, o.Val) != 0 {
+		t.Error("wrong value")
+	}
+
+	if rc != o.RC {
+		t.Error("wrong rc", rc)
+	}
+
+	if &val[0] != &p[4] {
+		t.Error("value copied")
+	}
+
+	// short input
+
+	for i := len(p) - 1; i >= 0; i-- {
+		if _, _, err = ViewObject(p[:i]); err != ErrInvalidSize {
+			t.Error("missing or unexpected error", i, err)
+		}
+	}
+
+}
+
 func TestObject_Touch(t *testing.T) {
 
 	var o = new(Object)
@@ -0,0 +1,21 @@
+package data
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A FeedKeeper is optional interface of an IdxDB
+// that keeps meta information of feeds, such as
+// revoked keys of a feed. The information
+// is encoded by user of the IdxDB, and the IdxDB
+// keeps it as is. The info is removed with its
+// feed
+type FeedKeeper interface {
+	// FeedInfo returns info of a feed. It returns
+	// nil, if the info is not set. It returns
+	// ErrNoSuchFeed if the feed doesn't exist
+	FeedInfo(pk cipher.PubKey) (info []byte, err error)
+	// SetFeedInfo sets info of a feed. The feed
+	// must exist. Blank info removes the info
+	SetFeedInfo(pk cipher.PubKey, info []byte) (err error)
+}
@@ -0,0 +1,172 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A Hash represents content hash algorithm used to
+// calculate keys of objects. A DB keeps objects of
+// one hash algorithm only. Keys of all algorithms
+// are 32 bytes long and represented as the
+// cipher.SHA256, thus a key doesn't tell its
+// algorithm. That's why the hash algorithm is
+// recorded in the DB (see HashKeeper and CheckHash)
+type Hash uint8
+
+// hash algorithms
+const (
+	// SHA256 is default hash algorithm
+	SHA256 Hash = iota
+	// BLAKE2b256 is BLAKE2b with 256-bit output,
+	// that is faster then the SHA256 on 64-bit
+	// platforms
+	BLAKE2b256
+)
+
+// hash algorithms errors
+var (
+	ErrUnknownHash     = errors.New("unknown hash algorithm")
+	ErrHashNotRecorded = errors.New("hash algorithm is not recorded")
+)
+
+// Sum returns hash of given value
+func (h Hash) Sum(val []byte) (key cipher.SHA256) {
+	switch h {
+	case SHA256:
+		return cipher.SumSHA256(val)
+	case BLAKE2b256:
+		return cipher.SHA256(blake2b.Sum256(val))
+	}
+	panic(ErrUnknownHash)
+}
+
+// Validate the Hash
+func (h Hash) Validate() (err error) {
+	switch h {
+	case SHA256, BLAKE2b256:
+		return
+	}
+	return ErrUnknownHash
+}
+
+// String implements fmt.Stringer interface
+func (h Hash) String() string {
+	switch h {
+	case SHA256:
+		return "SHA256"
+	case BLAKE2b256:
+		return "BLAKE2b-256"
+	}
+	return fmt.Sprintf("Hash<%d>", h)
+}
+
+// Set implements flag.Value interface
+func (h *Hash) Set(name string) (err error) {
+	switch strings.ToUpper(strings.Replace(name, "-", "", -1)) {
+	case "SHA256":
+		*h = SHA256
+	case "BLAKE2B256":
+		*h = BLAKE2b256
+	default:
+		err = fmt.Errorf("unknown hash algorithm %q,"+
+			" choose SHA256 or BLAKE2b-256", name)
+	}
+	return
+}
+
+// A HashMismatchError occurs when hash algorithm
+// of a DB or of a remote peer is different
+type HashMismatchError struct {
+	Want Hash // expected
+	Got  Hash // received or recorded
+}
+
+// Error implements error interface
+func (h *HashMismatchError) Error() string {
+	return fmt.Sprintf("hash algorithm mismatch: %s, want %s", h.Got,
+		h.Want)
+}
+
+// A HashKeeper is optional interface of an IdxDB
+// that keeps hash algorithm of a DB
+type HashKeeper interface {
+	// Hash returns recorded hash algorithm or
+	// ErrHashNotRecorded if it's not recorded
+	Hash() (hash Hash, err error)
+	// SetHash records hash algorithm
+	SetHash(hash Hash) (err error)
+}
+
+// CheckHash checks hash algorithm of given DB and records
+// it if it's not recorded yet. A DB that doesn't have a
+// recorded algorithm is SHA256 DB, created before hash
+// algorithms, or a new DB. Thus, the CheckHash records
+// given hash for a blank DB only. The CheckHash returns
+// *HashMismatchError if the DB uses another algorithm.
+// If IdxDB of the DB doesn't implement the HashKeeper,
+// then the DB can be used with SHA256 only
+func CheckHash(db *DB, hash Hash) (err error) {
+
+	if err = hash.Validate(); err != nil {
+		return
+	}
+
+	var hk, ok = db.IdxDB().(HashKeeper)
+
+	if ok == false {
+		if hash != SHA256 {
+			return fmt.Errorf("IdxDB %T can't record hash algorithm,"+
+				" only SHA256 can be used", db.IdxDB())
+		}
+		return
+	}
+
+	var recorded Hash
+
+	if recorded, err = hk.Hash(); err == nil {
+		if recorded != hash {
+			return &HashMismatchError{Want: hash, Got: recorded}
+		}
+		return
+	}
+
+	if err != ErrHashNotRecorded {
+		return
+	}
+
+	if hash != SHA256 {
+
+		var blank bool
+		if blank, err = isBlank(db); err != nil {
+			return
+		}
+
+		if blank == false {
+			return &HashMismatchError{Want: hash, Got: SHA256}
+		}
+
+	}
+
+	return hk.SetHash(hash)
+}
+
+// is the DB blank
+func isBlank(db *DB) (blank bool, err error) {
+
+	if all, _ := db.CXDS().Amount(); all != 0 {
+		return
+	}
+
+	var feeds int
+	if feeds, err = db.IdxDB().FeedsLen(); err != nil {
+		return
+	}
+
+	return feeds == 0, nil
+}
@@ -0,0 +1,145 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestHash_Sum(t *testing.T) {
+
+	var val = []byte("value")
+
+	if SHA256.Sum(val) != cipher.SumSHA256(val) {
+		t.Error("wrong SHA256")
+	}
+
+	var b = BLAKE2b256.Sum(val)
+
+	if b == SHA256.Sum(val) || b == (cipher.SHA256{}) {
+		t.Error("wrong BLAKE2b-256")
+	}
+
+	if BLAKE2b256.Sum(val) != b {
+		t.Error("not deterministic")
+	}
+
+	// BLAKE2b-256("abc")
+	const abc = "bddd813c634239723171ef3fee98579b" +
+		"94964e3bb1cb3e427262c8c068d52319"
+
+	if h := BLAKE2b256.Sum([]byte("abc")); h.Hex() != abc {
+		t.Error("wrong BLAKE2b-256 of 'abc':", h.Hex())
+	}
+
+}
+
+func TestHash_Set(t *testing.T) {
+
+	var h Hash
+
+	for name, want := range map[string]Hash{
+		"SHA256":      SHA256,
+		"sha256":      SHA256,
+		"BLAKE2b-256": BLAKE2b256,
+		"blake2b256":  BLAKE2b256,
+	} {
+		if err := h.Set(name); err != nil {
+			t.Error(err)
+		} else if h != want {
+			t.Errorf("wrong hash %s for %q", h, name)
+		}
+	}
+
+	if err := h.Set("md5"); err == nil {
+		t.Error("missing error")
+	}
+
+	if err := Hash(100).Validate(); err != ErrUnknownHash {
+		t.Error("unexpected error:", err)
+	}
+
+}
+
+// IdxDB that keeps hash
+type hashIdx struct {
+	dummyIdx
+	hash    Hash
+	hashSet bool
+}
+
+func (h *hashIdx) Hash() (hash Hash, err error) {
+	if h.hashSet == false {
+		return 0, ErrHashNotRecorded
+	}
+	return h.hash, nil
+}
+
+func (h *hashIdx) SetHash(hash Hash) (_ error) {
+	h.hash, h.hashSet = hash, true
+	return
+}
+
+// CXDS with objects
+type amountCXDS struct {
+	dummyCXDS
+	all int64
+}
+
+func (a *amountCXDS) Amount() (all, used int64) { return a.all, 0 }
+
+func TestCheckHash(t *testing.T) {
+
+	t.Run("blank", func(t *testing.T) {
+		var hi = new(hashIdx)
+		if err := CheckHash(NewDB(new(dummyCXDS), hi), BLAKE2b256); err != nil {
+			t.Fatal(err)
+		}
+		if hi.hashSet == false || hi.hash != BLAKE2b256 {
+			t.Error("hash is not recorded")
+		}
+	})
+
+	t.Run("recorded", func(t *testing.T) {
+		var db = NewDB(new(dummyCXDS), &hashIdx{hash: SHA256, hashSet: true})
+		if err := CheckHash(db, SHA256); err != nil {
+			t.Error(err)
+		}
+		var err = CheckHash(db, BLAKE2b256)
+		if hm, ok := err.(*HashMismatchError); ok == false {
+			t.Error("unexpected error:", err)
+		} else if hm.Want != BLAKE2b256 || hm.Got != SHA256 {
+			t.Error("wrong error:", hm)
+		}
+	})
+
+	t.Run("not blank", func(t *testing.T) {
+		var (
+			hi = new(hashIdx)
+			db = NewDB(&amountCXDS{all: 1}, hi)
+		)
+		if _, ok := CheckHash(db, BLAKE2b256).(*HashMismatchError); !ok {
+			t.Error("missing error")
+		}
+		if hi.hashSet == true {
+			t.Error("hash recorded")
+		}
+		if err := CheckHash(db, SHA256); err != nil {
+			t.Error(err)
+		}
+		if hi.hashSet == false || hi.hash != SHA256 {
+			t.Error("hash is not recorded")
+		}
+	})
+
+	t.Run("no keeper", func(t *testing.T) {
+		var db = NewDB(new(dummyCXDS), new(dummyIdx))
+		if err := CheckHash(db, SHA256); err != nil {
+			t.Error(err)
+		}
+		if err := CheckHash(db, BLAKE2b256); err == nil {
+			t.Error("missing error")
+		}
+	})
+
+}
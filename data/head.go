@@ -0,0 +1,51 @@
+package data
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A HeadInfo represents meta information of a head
+// of a feed. The HeadInfo is stored in IdxDB, if the
+// IdxDB implements the HeadKeeper interface
+type HeadInfo struct {
+	// Label is human readable name of the head.
+	// Labels of heads of a feed should be unique
+	Label string
+	// Active is true if the head selected as
+	// active head of its feed explicitly
+	Active bool
+	// Keep is number of last Root objects of the
+	// head to keep. Older Root objects removed. If
+	// it's zero, then all Root objects are kept
+	Keep uint32
+}
+
+// IsBlank returns true if the HeadInfo is blank
+func (h *HeadInfo) IsBlank() bool {
+	return *h == HeadInfo{}
+}
+
+// Encode the HeadInfo
+func (h *HeadInfo) Encode() (p []byte) {
+	return encoder.Serialize(h)
+}
+
+// Decode given encoded HeadInfo to this one
+func (h *HeadInfo) Decode(p []byte) (err error) {
+	return encoder.DeserializeRaw(p, h)
+}
+
+// A HeadKeeper is optional interface of an IdxDB
+// that keeps meta information of heads. The info
+// is removed with its head or feed
+type HeadKeeper interface {
+	// HeadInfo returns info of a head. It returns
+	// blank info, if the info is not set. It
+	// returns ErrNoSuchFeed or ErrNoSuchHead if
+	// the head doesn't exist
+	HeadInfo(pk cipher.PubKey, nonce uint64) (info HeadInfo, err error)
+	// SetHeadInfo sets info of a head. The head
+	// must exist. Blank info removes the info
+	SetHeadInfo(pk cipher.PubKey, nonce uint64, info HeadInfo) (err error)
+}
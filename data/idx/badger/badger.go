@@ -0,0 +1,854 @@
+package badger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// ScanBy used by iterators
+const ScanBy int = 100
+
+// keys layout
+//
+//     i                    - safe closed
+//     a                    - hash algorithm
+//     f + pk               - feed (value is info of the feed)
+//     h + pk + nonce       - head (value is data.HeadInfo)
+//     r + pk + nonce + seq - Root
+//
+var (
+	infoKey = []byte("i") // safe closed
+	hashKey = []byte("a") // hash algorithm
+
+	feedPrefix = byte('f')
+	headPrefix = byte('h')
+	rootPrefix = byte('r')
+)
+
+// upper bound of suffix of a key, used
+// to seek to last key with a prefix
+var upper = bytes.Repeat([]byte{0xff}, 64)
+
+func appendUint(b []byte, u uint64) []byte {
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], u)
+	return append(b, t[:]...)
+}
+
+func btou(b []byte) (u uint64) {
+	u = binary.BigEndian.Uint64(b)
+	return
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func feedKey(pk cipher.PubKey) []byte {
+	return append([]byte{feedPrefix}, pk[:]...)
+}
+
+// prefix of heads of a feed
+func headsPrefix(pk cipher.PubKey) []byte {
+	return append([]byte{headPrefix}, pk[:]...)
+}
+
+func headKey(pk cipher.PubKey, nonce uint64) []byte {
+	return appendUint(headsPrefix(pk), nonce)
+}
+
+// prefix of Root objects of a feed (all heads)
+func feedRootsPrefix(pk cipher.PubKey) []byte {
+	return append([]byte{rootPrefix}, pk[:]...)
+}
+
+// prefix of Root objects of a head
+func rootsPrefix(pk cipher.PubKey, nonce uint64) []byte {
+	return appendUint(feedRootsPrefix(pk), nonce)
+}
+
+func rootKey(pk cipher.PubKey, nonce, seq uint64) []byte {
+	return appendUint(rootsPrefix(pk, nonce), seq)
+}
+
+// has the Txn given key
+func has(t *badger.Txn, key []byte) (ok bool, err error) {
+	if _, err = t.Get(key); err != nil {
+		if err == badger.ErrKeyNotFound {
+			err = nil
+		}
+		return
+	}
+	return true, nil
+}
+
+// value by key, or data.ErrNotFound
+func get(t *badger.Txn, key []byte) (val []byte, err error) {
+	var it *badger.Item
+	if it, err = t.Get(key); err != nil {
+		if err == badger.ErrKeyNotFound {
+			err = data.ErrNotFound
+		}
+		return
+	}
+	return it.Value()
+}
+
+// returns data.ErrNoSuchFeed if feed doesn't exist
+func checkFeed(t *badger.Txn, pk cipher.PubKey) (err error) {
+	var ok bool
+	if ok, err = has(t, feedKey(pk)); err == nil && ok == false {
+		err = data.ErrNoSuchFeed
+	}
+	return
+}
+
+// returns data.ErrNoSuchFeed or data.ErrNoSuchHead
+// if feed or head doesn't exist
+func checkHead(t *badger.Txn, pk cipher.PubKey, nonce uint64) (err error) {
+	if err = checkFeed(t, pk); err != nil {
+		return
+	}
+	var ok bool
+	if ok, err = has(t, headKey(pk, nonce)); err == nil && ok == false {
+		err = data.ErrNoSuchHead
+	}
+	return
+}
+
+// keys with given prefix (copies)
+func keys(t *badger.Txn, prefix []byte) (ks [][]byte) {
+	var it = t.NewIterator(badger.IteratorOptions{})
+	defer it.Close()
+
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		ks = append(ks, append([]byte{}, it.Item().Key()...))
+	}
+	return
+}
+
+// number of keys with given prefix
+func count(t *badger.Txn, prefix []byte) (length int) {
+	var it = t.NewIterator(badger.IteratorOptions{})
+	defer it.Close()
+
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		length++
+	}
+	return
+}
+
+// delete all keys with given prefix
+func delPrefix(t *badger.Txn, prefix []byte) (err error) {
+	for _, key := range keys(t, prefix) {
+		if err = t.Delete(key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// A Badger implements data.IdxDB interface.
+// The Badger based on <github.com/dgraph-io/badger>.
+// The Badger keeps feeds, heads and Root objects
+// in the same key-value space using prefixes
+type Badger struct {
+	b *badger.DB
+
+	scanBy       int
+	isSafeClosed bool
+
+	closeo sync.Once
+}
+
+// NewBadger creates new IdxDB or opens existing.
+// The scanBy argument used by iterators. If the
+// scanBy is zero, then default value used. Opts
+// is badger db options
+func NewBadger(
+	opts badger.Options, // : badger options
+	scanBy int, //          : elements in Iterate loop (zero is default)
+) (
+	b *Badger, //           : the Badger
+	err error, //           : error
+) {
+
+	var db *badger.DB
+	if db, err = badger.Open(opts); err != nil {
+		return
+	}
+
+	var x = new(Badger)
+	x.b = db
+
+	if scanBy <= 0 {
+		x.scanBy = ScanBy
+	} else {
+		x.scanBy = scanBy
+	}
+
+	if x.isSafeClosed, err = x.getSafeClosed(); err != nil {
+		db.Close()
+		return
+	}
+
+	if err = x.setSafeClosed(false); err != nil {
+		db.Close()
+		return
+	}
+
+	return x, nil
+}
+
+func (b *Badger) getSafeClosed() (t bool, err error) {
+	err = b.b.View(func(tx *badger.Txn) (err error) {
+		var val []byte
+		if val, err = get(tx, infoKey); err != nil {
+			if err == data.ErrNotFound {
+				t, err = true, nil // fresh DB
+			}
+			return
+		}
+		t = len(val) > 0 && val[0] > 0
+		return
+	})
+	return
+}
+
+func (b *Badger) setSafeClosed(t bool) (err error) {
+	err = b.b.Update(func(tx *badger.Txn) (err error) {
+		if t == true {
+			return tx.Set(infoKey, []byte{0xff})
+		}
+		return tx.Set(infoKey, []byte{0x00})
+	})
+	return
+}
+
+// scan keys with given prefix ascending or descending
+// order by scanBy keys per transaction. The iterateFunc
+// called outside transactions with suffix of a key.
+// The check function called inside every transaction
+// and can be nil
+func (b *Badger) scan(
+	prefix []byte, //                      : prefix of keys
+	reverse bool, //                       : descending order
+	check func(t *badger.Txn) error, //    : check a feed or a head
+	iterateFunc func(suffix []byte) error, // : the function
+) (
+	err error, //                          : error
+) {
+
+	var (
+		last []byte // last key of previous pass
+		end  bool
+
+		scan = make([][]byte, 0, b.scanBy)
+	)
+
+	for end == false {
+
+		err = b.b.View(func(t *badger.Txn) (err error) {
+
+			if check != nil {
+				if err = check(t); err != nil {
+					return
+				}
+			}
+
+			var it = t.NewIterator(badger.IteratorOptions{
+				Reverse: reverse,
+			})
+			defer it.Close()
+
+			var seek = last
+
+			if seek == nil {
+				if reverse == true {
+					seek = append(append([]byte{}, prefix...), upper...)
+				} else {
+					seek = prefix
+				}
+			}
+
+			for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+
+				var key = it.Item().Key()
+
+				if last != nil && bytes.Equal(key, last) == true {
+					continue // already scanned
+				}
+
+				if len(scan) == b.scanBy {
+					return // next pass
+				}
+
+				scan = append(scan, append([]byte{}, key...))
+			}
+
+			end = true
+			return
+		})
+
+		if err != nil {
+			return
+		}
+
+		for _, key := range scan {
+			if err = iterateFunc(key[len(prefix):]); err != nil {
+				if err == data.ErrStopIteration {
+					err = nil
+				}
+				return
+			}
+		}
+
+		if len(scan) > 0 {
+			last = scan[len(scan)-1]
+		}
+
+		scan = scan[:0]
+		// continue
+	}
+
+	return
+}
+
+//
+// Feeds
+//
+
+// AddFeed. Adding a feed twice or more times
+// does nothing.
+func (b *Badger) AddFeed(pk cipher.PubKey) (err error) {
+	err = b.b.Update(func(t *badger.Txn) (err error) {
+		var ok bool
+		if ok, err = has(t, feedKey(pk)); err != nil || ok == true {
+			return // keep info (the value) of existing feed
+		}
+		return t.Set(feedKey(pk), []byte{})
+	})
+	return
+}
+
+// DelFeed with all heads and Root objects
+// unconditionally. If feed doesn't exist
+// then the Del returns ErrNoSuchFeed.
+func (b *Badger) DelFeed(pk cipher.PubKey) (err error) {
+	err = b.b.Update(func(t *badger.Txn) (err error) {
+		if err = checkFeed(t, pk); err != nil {
+			return
+		}
+		if err = delPrefix(t, feedRootsPrefix(pk)); err != nil {
+			return
+		}
+		if err = delPrefix(t, headsPrefix(pk)); err != nil {
+			return
+		}
+		return t.Delete(feedKey(pk))
+	})
+	return
+}
+
+// IterateFeeds all feeds. Use ErrStopIteration to
+// stop iteration. The Iterate passes any error
+// returned from given function through. Except
+// ErrStopIteration that turns nil. It's possible
+// to mutate the IdxDB inside the Iterate
+func (b *Badger) IterateFeeds(iterateFunc data.IterateFeedsFunc) (err error) {
+	return b.scan([]byte{feedPrefix}, false, nil,
+		func(suffix []byte) error {
+			return iterateFunc(cipher.NewPubKey(suffix))
+		})
+}
+
+// HasFeed returns true if the IdxDB contains
+// feed with given public key
+func (b *Badger) HasFeed(pk cipher.PubKey) (ok bool, err error) {
+	err = b.b.View(func(t *badger.Txn) (err error) {
+		ok, err = has(t, feedKey(pk))
+		return
+	})
+	return
+}
+
+// FeedsLen is number of feeds in DB
+func (b *Badger) FeedsLen() (length int, err error) {
+	err = b.b.View(func(t *badger.Txn) (_ error) {
+		length = count(t, []byte{feedPrefix})
+		return
+	})
+	return
+}
+
+//
+// Heads
+//
+
+// AddHead new head with given nonce.
+// If a head with given nonce already
+// exists, then this method does nothing.
+func (b *Badger) AddHead(pk cipher.PubKey, nonce uint64) (err error) {
+	err = b.b.Update(func(t *badger.Txn) (err error) {
+		if err = checkFeed(t, pk); err != nil {
+			return
+		}
+		var ok bool
+		if ok, err = has(t, headKey(pk, nonce)); err != nil || ok == true {
+			return // keep data.HeadInfo (the value) of existing head
+		}
+		return t.Set(headKey(pk, nonce), []byte{})
+	})
+	return
+}
+
+// DelHead deletes head with given nonce and
+// all its Root objects. The method returns
+// ErrNoSuchHead if a head with given nonce
+// doesn't exist.
+func (b *Badger) DelHead(pk cipher.PubKey, nonce uint64) (err error) {
+	err = b.b.Update(func(t *badger.Txn) (err error) {
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+		if err = delPrefix(t, rootsPrefix(pk, nonce)); err != nil {
+			return
+		}
+		return t.Delete(headKey(pk, nonce))
+	})
+	return
+}
+
+// HasHead returns true if a head with given
+// nonce exits in the DB
+func (b *Badger) HasHead(pk cipher.PubKey, nonce uint64) (ok bool, err error) {
+	err = b.b.View(func(t *badger.Txn) (err error) {
+		if err = checkFeed(t, pk); err != nil {
+			return
+		}
+		ok, err = has(t, headKey(pk, nonce))
+		return
+	})
+	return
+}
+
+// IterateHeads iterates over all heads
+func (b *Badger) IterateHeads(
+	pk cipher.PubKey, //                  :
+	iterateFunc data.IterateHeadsFunc, // :
+) (err error) {
+
+	return b.scan(headsPrefix(pk), false,
+		func(t *badger.Txn) error {
+			return checkFeed(t, pk)
+		},
+		func(suffix []byte) error {
+			return iterateFunc(btou(suffix))
+		})
+}
+
+// HeadsLen is number of heads stored
+func (b *Badger) HeadsLen(pk cipher.PubKey) (length int, err error) {
+	err = b.b.View(func(t *badger.Txn) (err error) {
+		if err = checkFeed(t, pk); err != nil {
+			return
+		}
+		length = count(t, headsPrefix(pk))
+		return
+	})
+	return
+}
+
+//
+// Roots
+//
+
+func (b *Badger) iterateRoots(
+	pk cipher.PubKey, //                  : feed
+	nonce uint64, //                      : head
+	reverse bool, //                      : descending order
+	iterateFunc data.IterateRootsFunc, // : the function
+) (
+	err error, //                         : error
+) {
+
+	return b.scan(rootsPrefix(pk, nonce), reverse,
+		func(t *badger.Txn) error {
+			return checkHead(t, pk, nonce)
+		},
+		func(suffix []byte) error {
+			return iterateFunc(btou(suffix))
+		})
+}
+
+// AscendRoots iterates all Root object ascending order.
+// Use ErrStopIteration to stop iteration. Any error
+// (except the ErrStopIteration) returned by given
+// IterateRootsFunc will be passed through. The
+// AscendRoots doesn't update access time of a Root.
+// See also IterateRootsFunc docs.
+func (b *Badger) AscendRoots(
+	pk cipher.PubKey, nonce uint64, iterateFunc data.IterateRootsFunc,
+) (err error) {
+	return b.iterateRoots(pk, nonce, false, iterateFunc)
+}
+
+// DescendRoots is the same as the Ascend, but it iterates
+// decending order. Use ErrStopIteration to stop
+// iteration. The DescendRoots doesn't update access time.
+// See also IterateRootsFunc docs.
+func (b *Badger) DescendRoots(
+	pk cipher.PubKey, nonce uint64, iterateFunc data.IterateRootsFunc,
+) (err error) {
+	return b.iterateRoots(pk, nonce, true, iterateFunc)
+}
+
+// HasRoot returns true if Root with given seq exists. The HasRoot
+// never updates access time.
+func (b *Badger) HasRoot(
+	pk cipher.PubKey, nonce uint64, seq uint64,
+) (ok bool, err error) {
+
+	err = b.b.View(func(t *badger.Txn) (err error) {
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+		ok, err = has(t, rootKey(pk, nonce, seq))
+		return
+	})
+	return
+}
+
+// RootsLen is number of Root objects stored
+func (b *Badger) RootsLen(
+	pk cipher.PubKey, nonce uint64,
+) (length int, err error) {
+
+	err = b.b.View(func(t *badger.Txn) (err error) {
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+		length = count(t, rootsPrefix(pk, nonce))
+		return
+	})
+	return
+}
+
+// set Root touching it or not
+func (b *Badger) setRoot(
+	pk cipher.PubKey, //   : feed
+	nonce uint64, //       : head
+	seq uint64, //         : seq of Root
+	hash cipher.SHA256, // : hash of Root
+	sig cipher.Sig, //     : signarure of Root
+	touch bool, //         : update access time
+) (
+	root *data.Root, //    : root with previous access time
+	err error, //          : error
+) {
+
+	err = b.b.Update(func(t *badger.Txn) (err error) {
+
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+
+		root = new(data.Root)
+
+		var (
+			key    = rootKey(pk, nonce, seq)
+			now    = time.Now()
+			access time.Time // previous access time
+			val    []byte
+		)
+
+		if val, err = get(t, key); err == nil {
+			must(root.Decode(val))
+			access = root.Access // get previous
+		} else if err == data.ErrNotFound {
+			access = time.Unix(0, 0) // never been
+			root.Access = access
+			root.Create = now
+		} else {
+			return // DB failure
+		}
+
+		root.Hash = hash
+		root.Sig = sig
+
+		if touch == true {
+			root.Access = now
+		}
+
+		if err = t.Set(key, root.Encode()); err != nil {
+			root = nil
+			return
+		}
+
+		root.Access = access // previous (to return)
+		return
+	})
+	return
+}
+
+// SetRoot add or touch Root if exists
+func (b *Badger) SetRoot(
+	pk cipher.PubKey, //   : feed
+	nonce uint64, //       : head
+	seq uint64, //         : seq of Root
+	hash cipher.SHA256, // : hash of Root
+	sig cipher.Sig, //     : signarure of Root
+) (root *data.Root, err error) {
+	return b.setRoot(pk, nonce, seq, hash, sig, true)
+}
+
+// SetNotTouchRoot add Root or do nothing if exists
+func (b *Badger) SetNotTouchRoot(
+	pk cipher.PubKey, //   : feed
+	nonce uint64, //       : head
+	seq uint64, //         : seq of Root
+	hash cipher.SHA256, // : hash of Root
+	sig cipher.Sig, //     : signarure of Root
+) (root *data.Root, err error) {
+	return b.setRoot(pk, nonce, seq, hash, sig, false)
+}
+
+// GetRoot returns root and touches stored.
+func (b *Badger) GetRoot(
+	pk cipher.PubKey, nonce uint64, seq uint64,
+) (root *data.Root, err error) {
+
+	err = b.b.Update(func(t *badger.Txn) (err error) {
+
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+
+		var (
+			key = rootKey(pk, nonce, seq)
+			val []byte
+		)
+
+		if val, err = get(t, key); err != nil {
+			return
+		}
+
+		root = new(data.Root)
+		must(root.Decode(val))
+
+		var access = root.Access // get previous
+		root.Access = time.Now() // touch
+
+		if err = t.Set(key, root.Encode()); err != nil {
+			root = nil
+			return
+		}
+
+		root.Access = access // previous (to return)
+		return
+	})
+	return
+}
+
+// GetNotTouchRoot returns root.
+func (b *Badger) GetNotTouchRoot(
+	pk cipher.PubKey, nonce uint64, seq uint64,
+) (root *data.Root, err error) {
+
+	err = b.b.View(func(t *badger.Txn) (err error) {
+
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+
+		var val []byte
+		if val, err = get(t, rootKey(pk, nonce, seq)); err != nil {
+			return
+		}
+
+		root = new(data.Root)
+		must(root.Decode(val))
+		return
+	})
+	return
+}
+
+// TakeRoot deletes Root returning it.
+func (b *Badger) TakeRoot(
+	pk cipher.PubKey, nonce uint64, seq uint64,
+) (root *data.Root, err error) {
+
+	err = b.b.Update(func(t *badger.Txn) (err error) {
+
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+
+		var (
+			key = rootKey(pk, nonce, seq)
+			val []byte
+		)
+
+		if val, err = get(t, key); err != nil {
+			return
+		}
+
+		root = new(data.Root)
+		must(root.Decode(val))
+
+		return t.Delete(key)
+	})
+	return
+}
+
+// DelRoot deletes Root.
+func (b *Badger) DelRoot(pk cipher.PubKey, nonce uint64, seq uint64) error {
+
+	return b.b.Update(func(t *badger.Txn) (err error) {
+
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+
+		var key = rootKey(pk, nonce, seq)
+
+		if _, err = get(t, key); err != nil {
+			return
+		}
+
+		return t.Delete(key)
+	})
+}
+
+// IsSafeClosed retursn true if last closing
+// was successful, and no data lost.
+func (b *Badger) IsSafeClosed() bool { return b.isSafeClosed }
+
+// Hash implements data.HashKeeper interface
+func (b *Badger) Hash() (hash data.Hash, err error) {
+	err = b.b.View(func(t *badger.Txn) (err error) {
+		var val []byte
+		if val, err = get(t, hashKey); err != nil {
+			if err == data.ErrNotFound {
+				err = data.ErrHashNotRecorded
+			}
+			return
+		}
+		if len(val) == 0 {
+			return data.ErrHashNotRecorded
+		}
+		hash = data.Hash(val[0])
+		return
+	})
+	return
+}
+
+// SetHash implements data.HashKeeper interface
+func (b *Badger) SetHash(hash data.Hash) (err error) {
+	return b.b.Update(func(t *badger.Txn) error {
+		return t.Set(hashKey, []byte{byte(hash)})
+	})
+}
+
+// HeadInfo implements data.HeadKeeper interface.
+// The info is value of key of the head
+func (b *Badger) HeadInfo(
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	info data.HeadInfo,
+	err error,
+) {
+	err = b.b.View(func(t *badger.Txn) (err error) {
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+		var val []byte
+		if val, err = get(t, headKey(pk, nonce)); err != nil {
+			return
+		}
+		if len(val) == 0 {
+			return
+		}
+		return info.Decode(val)
+	})
+	return
+}
+
+// SetHeadInfo implements data.HeadKeeper interface
+func (b *Badger) SetHeadInfo(
+	pk cipher.PubKey,
+	nonce uint64,
+	info data.HeadInfo,
+) (err error) {
+	err = b.b.Update(func(t *badger.Txn) (err error) {
+		if err = checkHead(t, pk, nonce); err != nil {
+			return
+		}
+		if info.IsBlank() == true {
+			return t.Set(headKey(pk, nonce), []byte{})
+		}
+		return t.Set(headKey(pk, nonce), info.Encode())
+	})
+	return
+}
+
+// FeedInfo implements data.FeedKeeper interface.
+// The info is value of key of the feed
+func (b *Badger) FeedInfo(pk cipher.PubKey) (info []byte, err error) {
+	err = b.b.View(func(t *badger.Txn) (err error) {
+		var val []byte
+		if val, err = get(t, feedKey(pk)); err != nil {
+			if err == data.ErrNotFound {
+				err = data.ErrNoSuchFeed
+			}
+			return
+		}
+		if len(val) > 0 {
+			info = append([]byte{}, val...)
+		}
+		return
+	})
+	return
+}
+
+// SetFeedInfo implements data.FeedKeeper interface
+func (b *Badger) SetFeedInfo(pk cipher.PubKey, info []byte) (err error) {
+	err = b.b.Update(func(t *badger.Txn) (err error) {
+		if err = checkFeed(t, pk); err != nil {
+			return
+		}
+		if len(info) == 0 {
+			return t.Set(feedKey(pk), []byte{})
+		}
+		return t.Set(feedKey(pk), append([]byte{}, info...))
+	})
+	return
+}
+
+// Badger returns underlying *badger.DB
+func (b *Badger) Badger() *badger.DB {
+	return b.b
+}
+
+// Close IdxDB
+func (b *Badger) Close() (err error) {
+	b.closeo.Do(func() {
+		if err = b.setSafeClosed(true); err != nil {
+			b.b.Close() // drop error
+			return
+		}
+		err = b.b.Close()
+	})
+	return
+}
@@ -0,0 +1,138 @@
+package badger
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/tests/idx"
+)
+
+var dbDirName = "test.badger.go.ignore"
+
+func testOptions() (opts badger.Options) {
+	opts = badger.DefaultOptions
+	opts.Dir = dbDirName
+	opts.ValueDir = dbDirName
+	return
+}
+
+func newBadger(t *testing.T) (b *Badger) {
+	os.RemoveAll(dbDirName)
+	var err error
+	if b, err = NewBadger(testOptions(), ScanBy); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+// clean up db after all
+func closeBadger(t *testing.T, b *Badger) {
+	defer os.RemoveAll(dbDirName)
+	if err := b.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func runTestCase(t *testing.T, testCase func(t *testing.T, idx data.IdxDB)) {
+	var b = newBadger(t)
+	defer closeBadger(t, b)
+
+	testCase(t, b)
+}
+
+func TestBadger_AddFeed(t *testing.T)      { runTestCase(t, idx.AddFeed) }
+func TestBadger_DelFeed(t *testing.T)      { runTestCase(t, idx.DelFeed) }
+func TestBadger_IterateFeeds(t *testing.T) { runTestCase(t, idx.IterateFeeds) }
+func TestBadger_HasFeed(t *testing.T)      { runTestCase(t, idx.HasFeed) }
+func TestBadger_FeedsLen(t *testing.T)     { runTestCase(t, idx.FeedsLen) }
+func TestBadger_AddHead(t *testing.T)      { runTestCase(t, idx.AddHead) }
+func TestBadger_DelHead(t *testing.T)      { runTestCase(t, idx.DelHead) }
+func TestBadger_HasHead(t *testing.T)      { runTestCase(t, idx.HasHead) }
+func TestBadger_IterateHeads(t *testing.T) { runTestCase(t, idx.IterateHeads) }
+func TestBadger_HeadsLen(t *testing.T)     { runTestCase(t, idx.HeadsLen) }
+func TestBadger_AscendRoots(t *testing.T)  { runTestCase(t, idx.AscendRoots) }
+func TestBadger_DescendRoots(t *testing.T) { runTestCase(t, idx.DescendRoots) }
+func TestBadger_HasRoot(t *testing.T)      { runTestCase(t, idx.HasRoot) }
+func TestBadger_RootsLen(t *testing.T)     { runTestCase(t, idx.RootsLen) }
+func TestBadger_SetRoot(t *testing.T)      { runTestCase(t, idx.SetRoot) }
+
+func TestBadger_SetNotTouchRoot(t *testing.T) {
+	runTestCase(t, idx.SetNotTouchRoot)
+}
+
+func TestBadger_GetRoot(t *testing.T) { runTestCase(t, idx.GetRoot) }
+
+func TestBadger_GetNotTouchRoot(t *testing.T) {
+	runTestCase(t, idx.GetNotTouchRoot)
+}
+
+func TestBadger_TakeRoot(t *testing.T) { runTestCase(t, idx.TakeRoot) }
+func TestBadger_DelRoot(t *testing.T)  { runTestCase(t, idx.DelRoot) }
+
+func TestBadger_IsSafeClosed(t *testing.T) {
+	var b = newBadger(t)
+	defer closeBadger(t, b)
+	idx.IsSafeClosed(t, b, func() (data.IdxDB, error) {
+		var err error
+		b, err = NewBadger(testOptions(), ScanBy)
+		return b, err
+	})
+}
+
+func TestBadger_HashKeeper(t *testing.T) {
+	var b = newBadger(t)
+	defer closeBadger(t, b)
+	idx.HashKeeper(t, b, func() (data.IdxDB, error) {
+		var err error
+		b, err = NewBadger(testOptions(), ScanBy)
+		return b, err
+	})
+}
+
+func TestBadger_HeadKeeper(t *testing.T) {
+	var b = newBadger(t)
+	defer closeBadger(t, b)
+	idx.HeadKeeper(t, b, func() (data.IdxDB, error) {
+		var err error
+		b, err = NewBadger(testOptions(), ScanBy)
+		return b, err
+	})
+}
+
+func TestBadger_FeedKeeper(t *testing.T) {
+	var b = newBadger(t)
+	defer closeBadger(t, b)
+	idx.FeedKeeper(t, b, func() (data.IdxDB, error) {
+		var err error
+		b, err = NewBadger(testOptions(), ScanBy)
+		return b, err
+	})
+}
+
+func TestBadger_Close(t *testing.T) { runTestCase(t, idx.Close) }
+
+// iterators with one key per transaction
+func TestBadger_scanBy(t *testing.T) {
+
+	for _, testCase := range []func(t *testing.T, idx data.IdxDB){
+		idx.IterateFeeds,
+		idx.IterateHeads,
+		idx.AscendRoots,
+		idx.DescendRoots,
+	} {
+
+		os.RemoveAll(dbDirName)
+
+		var b, err = NewBadger(testOptions(), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testCase(t, b)
+		closeBadger(t, b)
+	}
+
+}
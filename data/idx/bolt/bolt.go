@@ -20,8 +20,20 @@ const ScanBy int = 100
 var (
 	infoBucket = []byte("i") //
 	infoKey    = infoBucket  // safe closed
+	hashKey    = []byte("h") // hash algorithm
 )
 
+// key of info of a feed in the info bucket,
+// that is prefix of keys of its data.HeadInfo
+func feedInfoKey(pk cipher.PubKey) []byte {
+	return append([]byte{}, pk[:]...)
+}
+
+// key of data.HeadInfo in the info bucket
+func headInfoKey(pk cipher.PubKey, nonce uint64) []byte {
+	return append(append([]byte{}, pk[:]...), utob(nonce)...)
+}
+
 func addOne(b []byte) {
 	for i := len(b) - 1; i >= 0; i-- {
 		if b[i] == 0xff {
@@ -156,11 +168,35 @@ func (b *Bolt) DelFeed(pk cipher.PubKey) (err error) {
 		if feed == nil {
 			return data.ErrNoSuchFeed
 		}
+		if err = delHeadInfos(tx, pk); err != nil {
+			return
+		}
 		return tx.DeleteBucket(pk[:])
 	})
 	return
 }
 
+// delete info and all data.HeadInfo of given feed
+func delHeadInfos(tx *bolt.Tx, pk cipher.PubKey) (err error) {
+	var info = tx.Bucket(infoBucket)
+	if info == nil {
+		return
+	}
+	var (
+		c    = info.Cursor()
+		keys [][]byte
+	)
+	for k, _ := c.Seek(pk[:]); bytes.HasPrefix(k, pk[:]); k, _ = c.Next() {
+		keys = append(keys, append([]byte{}, k...))
+	}
+	for _, k := range keys {
+		if err = info.Delete(k); err != nil {
+			return
+		}
+	}
+	return
+}
+
 // IterateFeeds all feeds. Use ErrStopIteration to
 // stop iteration. The Iterate passes any error
 // returned from given function through. Except
@@ -273,7 +309,12 @@ func (b *Bolt) DelHead(pk cipher.PubKey, nonce uint64) (err error) {
 			return data.ErrNoSuchFeed
 		}
 		if err = feed.DeleteBucket(utob(nonce)); err == bolt.ErrBucketNotFound {
-			err = data.ErrNoSuchHead
+			return data.ErrNoSuchHead
+		} else if err != nil {
+			return
+		}
+		if info := tx.Bucket(infoBucket); info != nil {
+			err = info.Delete(headInfoKey(pk, nonce))
 		}
 		return
 	})
@@ -786,6 +827,129 @@ func (b *Bolt) DelRoot(pk cipher.PubKey, nonce uint64, seq uint64) error {
 // was successful, and no data lost.
 func (b *Bolt) IsSafeClosed() bool { return b.isSafeClosed }
 
+// Hash implements data.HashKeeper interface
+func (b *Bolt) Hash() (hash data.Hash, err error) {
+	err = b.b.View(func(tx *bolt.Tx) (err error) {
+		var info = tx.Bucket(infoBucket)
+		if info == nil {
+			return data.ErrHashNotRecorded
+		}
+		var val = info.Get(hashKey)
+		if len(val) == 0 {
+			return data.ErrHashNotRecorded
+		}
+		hash = data.Hash(val[0])
+		return
+	})
+	return
+}
+
+// SetHash implements data.HashKeeper interface
+func (b *Bolt) SetHash(hash data.Hash) (err error) {
+	err = b.b.Update(func(tx *bolt.Tx) (err error) {
+		var info *bolt.Bucket
+		if info, err = tx.CreateBucketIfNotExists(infoBucket); err != nil {
+			return
+		}
+		return info.Put(hashKey, []byte{byte(hash)})
+	})
+	return
+}
+
+// HeadInfo implements data.HeadKeeper interface
+func (b *Bolt) HeadInfo(
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	info data.HeadInfo,
+	err error,
+) {
+	err = b.b.View(func(tx *bolt.Tx) (err error) {
+		if err = hasHead(tx, pk, nonce); err != nil {
+			return
+		}
+		var ib = tx.Bucket(infoBucket)
+		if ib == nil {
+			return
+		}
+		var val = ib.Get(headInfoKey(pk, nonce))
+		if len(val) == 0 {
+			return
+		}
+		return info.Decode(val)
+	})
+	return
+}
+
+// SetHeadInfo implements data.HeadKeeper interface
+func (b *Bolt) SetHeadInfo(
+	pk cipher.PubKey,
+	nonce uint64,
+	info data.HeadInfo,
+) (err error) {
+	err = b.b.Update(func(tx *bolt.Tx) (err error) {
+		if err = hasHead(tx, pk, nonce); err != nil {
+			return
+		}
+		var ib *bolt.Bucket
+		if ib, err = tx.CreateBucketIfNotExists(infoBucket); err != nil {
+			return
+		}
+		if info.IsBlank() == true {
+			return ib.Delete(headInfoKey(pk, nonce))
+		}
+		return ib.Put(headInfoKey(pk, nonce), info.Encode())
+	})
+	return
+}
+
+// FeedInfo implements data.FeedKeeper interface
+func (b *Bolt) FeedInfo(pk cipher.PubKey) (info []byte, err error) {
+	err = b.b.View(func(tx *bolt.Tx) (err error) {
+		if tx.Bucket(pk[:]) == nil {
+			return data.ErrNoSuchFeed
+		}
+		var ib = tx.Bucket(infoBucket)
+		if ib == nil {
+			return
+		}
+		if val := ib.Get(feedInfoKey(pk)); len(val) > 0 {
+			info = append([]byte{}, val...)
+		}
+		return
+	})
+	return
+}
+
+// SetFeedInfo implements data.FeedKeeper interface
+func (b *Bolt) SetFeedInfo(pk cipher.PubKey, info []byte) (err error) {
+	err = b.b.Update(func(tx *bolt.Tx) (err error) {
+		if tx.Bucket(pk[:]) == nil {
+			return data.ErrNoSuchFeed
+		}
+		var ib *bolt.Bucket
+		if ib, err = tx.CreateBucketIfNotExists(infoBucket); err != nil {
+			return
+		}
+		if len(info) == 0 {
+			return ib.Delete(feedInfoKey(pk))
+		}
+		return ib.Put(feedInfoKey(pk), info)
+	})
+	return
+}
+
+func hasHead(tx *bolt.Tx, pk cipher.PubKey, nonce uint64) (err error) {
+	var feed = tx.Bucket(pk[:])
+	if feed == nil {
+		return data.ErrNoSuchFeed
+	}
+	if feed.Bucket(utob(nonce)) == nil {
+		return data.ErrNoSuchHead
+	}
+	return
+}
+
 // Close IdxDB
 func (b *Bolt) Close() (err error) {
 	b.closeo.Do(func() {
@@ -73,4 +73,34 @@ func TestBolt_IsSafeClosed(t *testing.T) {
 	})
 }
 
+func TestBolt_HashKeeper(t *testing.T) {
+	var b = newBolt(t)
+	defer closeBolt(t, b)
+	idx.HashKeeper(t, b, func() (data.IdxDB, error) {
+		var err error
+		b, err = NewBolt(dbFileName, 0644, nil, ScanBy)
+		return b, err
+	})
+}
+
+func TestBolt_HeadKeeper(t *testing.T) {
+	var b = newBolt(t)
+	defer closeBolt(t, b)
+	idx.HeadKeeper(t, b, func() (data.IdxDB, error) {
+		var err error
+		b, err = NewBolt(dbFileName, 0644, nil, ScanBy)
+		return b, err
+	})
+}
+
+func TestBolt_FeedKeeper(t *testing.T) {
+	var b = newBolt(t)
+	defer closeBolt(t, b)
+	idx.FeedKeeper(t, b, func() (data.IdxDB, error) {
+		var err error
+		b, err = NewBolt(dbFileName, 0644, nil, ScanBy)
+		return b, err
+	})
+}
+
 func TestBolt_Close(t *testing.T) { runTestCase(t, idx.Close) }
@@ -16,6 +16,7 @@ func copyRoot(r *data.Root) (root *data.Root) {
 	root = new(data.Root)
 	root.Hash = r.Hash
 	root.Sig = r.Sig
+	root.Sigs = append([]cipher.Sig(nil), r.Sigs...)
 	root.Access = r.Access
 	root.Create = r.Create
 	return
@@ -28,12 +29,23 @@ type Memory struct {
 	// feeds (pk) -> heads (nonce) -> roots (seq)
 	feeds  map[cipher.PubKey]heads
 	scanBy int
+
+	hash    data.Hash // hash algorithm
+	hashSet bool      // is the hash recorded
+
+	// feeds (pk) -> heads (nonce) -> info
+	infos map[cipher.PubKey]map[uint64]data.HeadInfo
+
+	// feeds (pk) -> info
+	finfos map[cipher.PubKey][]byte
 }
 
 // NewMemory creates new Memory
 func NewMemory(scanBy int) (m *Memory) {
 	m = new(Memory)
 	m.feeds = make(map[cipher.PubKey]heads)
+	m.infos = make(map[cipher.PubKey]map[uint64]data.HeadInfo)
+	m.finfos = make(map[cipher.PubKey][]byte)
 	if scanBy <= 0 {
 		m.scanBy = ScanBy
 	} else {
@@ -67,6 +79,8 @@ func (m *Memory) DelFeed(pk cipher.PubKey) (err error) {
 		return data.ErrNoSuchFeed
 	}
 	delete(m.feeds, pk)
+	delete(m.infos, pk)
+	delete(m.finfos, pk)
 	return
 }
 
@@ -154,6 +168,7 @@ func (m *Memory) DelHead(pk cipher.PubKey, nonce uint64) (err error) {
 		return data.ErrNoSuchHead
 	}
 	delete(hs, nonce)
+	delete(m.infos[pk], nonce)
 	return
 }
 
@@ -572,6 +587,113 @@ func (m *Memory) DelRoot(
 // been closed before.
 func (m *Memory) IsSafeClosed() bool { return true }
 
+// Hash implements data.HashKeeper interface
+func (m *Memory) Hash() (hash data.Hash, err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.hashSet == false {
+		return 0, data.ErrHashNotRecorded
+	}
+	return m.hash, nil
+}
+
+// SetHash implements data.HashKeeper interface
+func (m *Memory) SetHash(hash data.Hash) (_ error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.hash, m.hashSet = hash, true
+	return
+}
+
+// HeadInfo implements data.HeadKeeper interface
+func (m *Memory) HeadInfo(
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	info data.HeadInfo,
+	err error,
+) {
+	m.Lock()
+	defer m.Unlock()
+
+	if err = m.hasHead(pk, nonce); err != nil {
+		return
+	}
+	info = m.infos[pk][nonce]
+	return
+}
+
+// SetHeadInfo implements data.HeadKeeper interface
+func (m *Memory) SetHeadInfo(
+	pk cipher.PubKey,
+	nonce uint64,
+	info data.HeadInfo,
+) (err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if err = m.hasHead(pk, nonce); err != nil {
+		return
+	}
+
+	if info.IsBlank() == true {
+		delete(m.infos[pk], nonce)
+		return
+	}
+
+	var is, ok = m.infos[pk]
+	if ok == false {
+		is = make(map[uint64]data.HeadInfo)
+		m.infos[pk] = is
+	}
+	is[nonce] = info
+	return
+}
+
+// FeedInfo implements data.FeedKeeper interface
+func (m *Memory) FeedInfo(pk cipher.PubKey) (info []byte, err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.feeds[pk]; ok == false {
+		return nil, data.ErrNoSuchFeed
+	}
+	info = append([]byte(nil), m.finfos[pk]...)
+	return
+}
+
+// SetFeedInfo implements data.FeedKeeper interface
+func (m *Memory) SetFeedInfo(pk cipher.PubKey, info []byte) (err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.feeds[pk]; ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	if len(info) == 0 {
+		delete(m.finfos, pk)
+		return
+	}
+
+	m.finfos[pk] = append([]byte(nil), info...)
+	return
+}
+
+// under lock
+func (m *Memory) hasHead(pk cipher.PubKey, nonce uint64) (err error) {
+	var hs, ok = m.feeds[pk]
+	if ok == false {
+		return data.ErrNoSuchFeed
+	}
+	if _, ok = hs[nonce]; ok == false {
+		return data.ErrNoSuchHead
+	}
+	return
+}
+
 // Close IdxDB
 func (m *Memory) Close() error {
 	m.feeds = nil
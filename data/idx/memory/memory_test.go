@@ -52,4 +52,22 @@ func TestMemory_IsSafeClosed(t *testing.T) {
 	})
 }
 
+func TestMemory_HashKeeper(t *testing.T) {
+	runTestCase(t, func(t *testing.T, m data.IdxDB) {
+		idx.HashKeeper(t, m, nil)
+	})
+}
+
+func TestMemory_HeadKeeper(t *testing.T) {
+	runTestCase(t, func(t *testing.T, m data.IdxDB) {
+		idx.HeadKeeper(t, m, nil)
+	})
+}
+
+func TestMemory_FeedKeeper(t *testing.T) {
+	runTestCase(t, func(t *testing.T, m data.IdxDB) {
+		idx.FeedKeeper(t, m, nil)
+	})
+}
+
 func TestMemory_Close(t *testing.T) { runTestCase(t, idx.Close) }
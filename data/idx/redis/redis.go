@@ -107,6 +107,30 @@ func (r *Redis) getSafeClosed() (safeClosed bool, err error) {
 	return
 }
 
+// Hash implements data.HashKeeper interface
+func (r *Redis) Hash() (hash data.Hash, err error) {
+	var exists bool
+	err = r.pool.Do(radix.Cmd(&exists, "EXISTS", "idx:hash"))
+	if err != nil {
+		return
+	}
+	if exists == false {
+		err = data.ErrHashNotRecorded
+		return
+	}
+	var h uint8
+	if err = r.pool.Do(radix.Cmd(&h, "GET", "idx:hash")); err != nil {
+		return
+	}
+	return data.Hash(h), nil
+}
+
+// SetHash implements data.HashKeeper interface
+func (r *Redis) SetHash(hash data.Hash) (err error) {
+	err = r.pool.Do(radix.FlatCmd(nil, "SET", "idx:hash", uint8(hash)))
+	return
+}
+
 func (r *Redis) loadScripts() (err error) {
 
 	type scriptHash struct {
@@ -164,6 +188,18 @@ func (r *Redis) loadScripts() (err error) {
 // idx:[hex]:[nonce] seq seq      - ZADD, ZRANGE, ZREVRANGE, ZREM, DEL
 // idx:[hex]:[nonce]:[seq] [...]  - HSET, HDEL, HMSET, HMGET, DEL
 //
+//
+// head info
+// ---------
+//
+// idx:[hex]:[nonce]:info [...]   - GET, SET, DEL
+//
+//
+// feed info
+// ---------
+//
+// idx:[hex]:info [...]           - GET, SET, DEL
+//
 
 // AddFeed. Adding a feed twice or more times does nothing.
 func (r *Redis) AddFeed(pk cipher.PubKey) (err error) {
@@ -326,6 +362,121 @@ func (r *Redis) HasHead(pk cipher.PubKey, nonce uint64) (ok bool, err error) {
 	return
 }
 
+func headInfoKey(pk cipher.PubKey, nonce uint64) string {
+	return "idx:" + pk.Hex() + ":" + strconv.FormatUint(nonce, 10) + ":info"
+}
+
+// hasHeadInfo returns ErrNoSuchFeed or
+// ErrNoSuchHead if the head doesn't exist
+func (r *Redis) hasHeadInfo(pk cipher.PubKey, nonce uint64) (err error) {
+
+	var ok bool
+	if ok, err = r.HasHead(pk, nonce); err != nil {
+		return
+	}
+
+	if ok == false {
+		err = data.ErrNoSuchHead
+	}
+
+	return
+}
+
+// HeadInfo implements data.HeadKeeper interface
+func (r *Redis) HeadInfo(
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	info data.HeadInfo,
+	err error,
+) {
+
+	if err = r.hasHeadInfo(pk, nonce); err != nil {
+		return
+	}
+
+	var val []byte
+	err = r.pool.Do(radix.Cmd(&val, "GET", headInfoKey(pk, nonce)))
+	if err != nil || len(val) == 0 {
+		return
+	}
+
+	err = info.Decode(val)
+	return
+}
+
+// SetHeadInfo implements data.HeadKeeper interface
+func (r *Redis) SetHeadInfo(
+	pk cipher.PubKey,
+	nonce uint64,
+	info data.HeadInfo,
+) (
+	err error,
+) {
+
+	if err = r.hasHeadInfo(pk, nonce); err != nil {
+		return
+	}
+
+	if info.IsBlank() == true {
+		return r.pool.Do(radix.Cmd(nil, "DEL", headInfoKey(pk, nonce)))
+	}
+
+	return r.pool.Do(radix.Cmd(nil, "SET", headInfoKey(pk, nonce),
+		string(info.Encode())))
+}
+
+func feedInfoKey(pk cipher.PubKey) string {
+	return "idx:" + pk.Hex() + ":info"
+}
+
+// hasFeedInfo returns ErrNoSuchFeed
+// if the feed doesn't exist
+func (r *Redis) hasFeedInfo(pk cipher.PubKey) (err error) {
+
+	var ok bool
+	if ok, err = r.HasFeed(pk); err != nil {
+		return
+	}
+
+	if ok == false {
+		err = data.ErrNoSuchFeed
+	}
+
+	return
+}
+
+// FeedInfo implements data.FeedKeeper interface
+func (r *Redis) FeedInfo(pk cipher.PubKey) (info []byte, err error) {
+
+	if err = r.hasFeedInfo(pk); err != nil {
+		return
+	}
+
+	var val []byte
+	err = r.pool.Do(radix.Cmd(&val, "GET", feedInfoKey(pk)))
+	if err != nil || len(val) == 0 {
+		return
+	}
+
+	info = val
+	return
+}
+
+// SetFeedInfo implements data.FeedKeeper interface
+func (r *Redis) SetFeedInfo(pk cipher.PubKey, info []byte) (err error) {
+
+	if err = r.hasFeedInfo(pk); err != nil {
+		return
+	}
+
+	if len(info) == 0 {
+		return r.pool.Do(radix.Cmd(nil, "DEL", feedInfoKey(pk)))
+	}
+
+	return r.pool.Do(radix.Cmd(nil, "SET", feedInfoKey(pk), string(info)))
+}
+
 // IterateHeads iterates over all heads
 func (r *Redis) IterateHeads(
 	pk cipher.PubKey,
@@ -174,4 +174,40 @@ func TestRedis_IsSafeClosed(t *testing.T) {
 	})
 }
 
+func TestRedis_HashKeeper(t *testing.T) {
+
+	var r = newRedis(t)
+	defer closeRedis(t, r)
+
+	idx.HashKeeper(t, r, func() (data.IdxDB, error) {
+		var err error
+		r, err = NewRedis("tcp", Address, nil)
+		return r, err
+	})
+}
+
+func TestRedis_HeadKeeper(t *testing.T) {
+
+	var r = newRedis(t)
+	defer closeRedis(t, r)
+
+	idx.HeadKeeper(t, r, func() (data.IdxDB, error) {
+		var err error
+		r, err = NewRedis("tcp", Address, nil)
+		return r, err
+	})
+}
+
+func TestRedis_FeedKeeper(t *testing.T) {
+
+	var r = newRedis(t)
+	defer closeRedis(t, r)
+
+	idx.FeedKeeper(t, r, func() (data.IdxDB, error) {
+		var err error
+		r, err = NewRedis("tcp", Address, nil)
+		return r, err
+	})
+}
+
 func TestRedis_Close(t *testing.T) { runTestCase(t, idx.Close) }
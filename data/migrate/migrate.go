@@ -0,0 +1,688 @@
+// Package migrate implements copying of a data.DB
+// from one backend to another. E.g. from BoltDB to
+// Badger or Redis. The Migrate copies objects with
+// their RC and timestamps, feeds, heads and Root
+// objects. Infos of feeds and heads are copied if
+// both IdxDB implement data.FeedKeeper and
+// data.HeadKeeper interfaces. The Migrate can be
+// used incrementally,
+// while source DB is in use. Every next call copies
+// only changes. Use the Verify to check result
+// after last call, when source DB is not in use.
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// A Config represents configurations
+// of the Migrate
+type Config struct {
+	// Prune removes from destination DB objects,
+	// feeds, heads and Root objects that don't
+	// exist in source DB. It's useful for
+	// incremental migration, if some of them
+	// has been removed from the source DB since
+	// previous call
+	Prune bool
+	// Verify calls the Verify after migration
+	Verify bool
+}
+
+// An Objects represents amount
+// and volume of objects
+type Objects struct {
+	Amount int64 // amount of objects
+	Volume int64 // volume of objects in bytes
+}
+
+func (o *Objects) add(val []byte) {
+	o.Amount++
+	o.Volume += int64(len(val))
+}
+
+// A Stat represents result of the Migrate
+type Stat struct {
+	Copied  Objects // copied or updated objects
+	Skipped Objects // objects that already exist
+	Pruned  Objects // removed from destination
+
+	Feeds int // feeds copied
+	Heads int // heads copied
+	Roots int // Root objects copied
+}
+
+// A VerifyError represents difference between
+// source and destination DB found by the Verify
+type VerifyError struct {
+	What string // what is different
+	Src  int64  // source value
+	Dst  int64  // destination value
+}
+
+// Error implements error interface
+func (v *VerifyError) Error() string {
+	return fmt.Sprintf("migrate: %s mismatch: source %d, destination %d",
+		v.What, v.Src, v.Dst)
+}
+
+// Hash returns hash algorithm of given DB. If IdxDB of
+// the DB is not a data.HashKeeper or the hash algorithm
+// is not recorded, then it's data.SHA256
+func Hash(db *data.DB) (hash data.Hash, err error) {
+
+	var hk, ok = db.IdxDB().(data.HashKeeper)
+
+	if ok == false {
+		return data.SHA256, nil
+	}
+
+	if hash, err = hk.Hash(); err == data.ErrHashNotRecorded {
+		return data.SHA256, nil
+	}
+
+	return
+}
+
+// Migrate copies all data from src to dst. The dst
+// can be blank or can be result of previous call
+// of the Migrate with the same src. Objects copied
+// as is, using SetRaw, with RC and timestamps. An
+// object that already exists in the dst with the
+// same RC is skipped. Root objects copied using
+// SetNotTouchRoot, thus they have new timestamps.
+//
+// The Migrate records hash algorithm of the src in
+// the dst (see data.CheckHash) and returns
+// *data.HashMismatchError if they are different.
+//
+// The src can be in use while the Migrate works.
+// In this case some of new objects and Root objects
+// can be skipped, and removed ones can be copied.
+// Use incremental migration and call the Migrate
+// last time after stopping the source node
+func Migrate(
+	dst *data.DB, //   : destination
+	src *data.DB, //   : source
+	conf Config, //    : configurations
+) (
+	stat Stat, //      : result
+	err error, //      : error if any
+) {
+
+	var hash data.Hash
+	if hash, err = Hash(src); err != nil {
+		return
+	}
+
+	if err = data.CheckHash(dst, hash); err != nil {
+		return
+	}
+
+	// Root objects refer to objects, thus,
+	// the objects should be copied first
+
+	if err = copyObjects(dst.CXDS(), src.CXDS(), &stat); err != nil {
+		return
+	}
+
+	if err = copyFeeds(dst.IdxDB(), src.IdxDB(), &stat); err != nil {
+		return
+	}
+
+	if conf.Prune == true {
+		if err = pruneFeeds(dst.IdxDB(), src.IdxDB()); err != nil {
+			return
+		}
+		if err = pruneObjects(dst.CXDS(), src.CXDS(), &stat); err != nil {
+			return
+		}
+	}
+
+	if conf.Verify == true {
+		err = Verify(dst, src)
+	}
+
+	return
+}
+
+//
+// objects
+//
+
+func copyObjects(dst, src data.CXDS, stat *Stat) (err error) {
+
+	return src.Iterate(func(key cipher.SHA256) (err error) {
+
+		var obj *data.Object
+		if obj, err = src.GetNotTouch(key); err != nil {
+			if err == data.ErrNotFound {
+				err = nil // removed during the Iterate
+			}
+			return
+		}
+
+		var have *data.Object
+		if have, err = dst.GetNotTouch(key); err == nil {
+			if have.RC == obj.RC {
+				stat.Skipped.add(obj.Val)
+				return
+			}
+		} else if err != data.ErrNotFound {
+			return
+		}
+
+		if err = dst.SetRaw(key, obj); err != nil {
+			return
+		}
+
+		stat.Copied.add(obj.Val)
+		return
+	})
+
+}
+
+func pruneObjects(dst, src data.CXDS, stat *Stat) (err error) {
+
+	var del []cipher.SHA256
+
+	err = dst.Iterate(func(key cipher.SHA256) (err error) {
+		if _, err = src.GetNotTouch(key); err == data.ErrNotFound {
+			del, err = append(del, key), nil
+		}
+		return
+	})
+
+	if err != nil {
+		return
+	}
+
+	for _, key := range del {
+		var obj *data.Object
+		if obj, err = dst.Take(key); err != nil {
+			if err == data.ErrNotFound {
+				err = nil
+				continue
+			}
+			return
+		}
+		stat.Pruned.add(obj.Val)
+	}
+
+	return
+}
+
+//
+// feeds, heads and Root objects
+//
+
+func copyFeeds(dst, src data.IdxDB, stat *Stat) (err error) {
+
+	return src.IterateFeeds(func(pk cipher.PubKey) (err error) {
+
+		var ok bool
+		if ok, err = dst.HasFeed(pk); err != nil {
+			return
+		}
+
+		if ok == false {
+			if err = dst.AddFeed(pk); err != nil {
+				return
+			}
+			stat.Feeds++
+		}
+
+		if err = copyFeedInfo(dst, src, pk); err != nil {
+			if err == data.ErrNoSuchFeed {
+				err = nil // removed during the IterateFeeds
+			}
+			return
+		}
+
+		err = src.IterateHeads(pk, func(nonce uint64) (err error) {
+			return copyHead(dst, src, pk, nonce, stat)
+		})
+
+		if err == data.ErrNoSuchFeed {
+			err = nil // removed during the IterateFeeds
+		}
+
+		return
+	})
+
+}
+
+func copyHead(
+	dst data.IdxDB,
+	src data.IdxDB,
+	pk cipher.PubKey,
+	nonce uint64,
+	stat *Stat,
+) (
+	err error,
+) {
+
+	var ok bool
+	if ok, err = dst.HasHead(pk, nonce); err != nil {
+		return
+	}
+
+	if ok == false {
+		if err = dst.AddHead(pk, nonce); err != nil {
+			return
+		}
+		stat.Heads++
+	}
+
+	err = src.AscendRoots(pk, nonce, func(seq uint64) (err error) {
+
+		if ok, err = dst.HasRoot(pk, nonce, seq); err != nil || ok == true {
+			return
+		}
+
+		var r *data.Root
+		if r, err = src.GetNotTouchRoot(pk, nonce, seq); err != nil {
+			if err == data.ErrNotFound {
+				err = nil // removed during the AscendRoots
+			}
+			return
+		}
+
+		if _, err = dst.SetNotTouchRoot(pk, nonce, seq, r.Hash,
+			r.Sig); err != nil {
+
+			return
+		}
+
+		stat.Roots++
+		return
+	})
+
+	if err == nil {
+		err = copyHeadInfo(dst, src, pk, nonce)
+	}
+
+	if err == data.ErrNoSuchFeed || err == data.ErrNoSuchHead {
+		err = nil // removed during the IterateHeads
+	}
+
+	return
+}
+
+// feedKeepers returns given IdxDBs as data.FeedKeeper,
+// if both implement the interface
+func feedKeepers(dst, src data.IdxDB) (dk, sk data.FeedKeeper, ok bool) {
+	if dk, ok = dst.(data.FeedKeeper); ok == false {
+		return
+	}
+	sk, ok = src.(data.FeedKeeper)
+	return
+}
+
+// headKeepers returns given IdxDBs as data.HeadKeeper,
+// if both implement the interface
+func headKeepers(dst, src data.IdxDB) (dk, sk data.HeadKeeper, ok bool) {
+	if dk, ok = dst.(data.HeadKeeper); ok == false {
+		return
+	}
+	sk, ok = src.(data.HeadKeeper)
+	return
+}
+
+func copyFeedInfo(dst, src data.IdxDB, pk cipher.PubKey) (err error) {
+
+	var dk, sk, ok = feedKeepers(dst, src)
+
+	if ok == false {
+		return
+	}
+
+	var si, di []byte
+
+	if si, err = sk.FeedInfo(pk); err != nil {
+		return
+	}
+
+	if di, err = dk.FeedInfo(pk); err != nil {
+		return
+	}
+
+	if bytes.Equal(si, di) == true {
+		return
+	}
+
+	return dk.SetFeedInfo(pk, si)
+}
+
+func copyHeadInfo(
+	dst data.IdxDB,
+	src data.IdxDB,
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	err error,
+) {
+
+	var dk, sk, ok = headKeepers(dst, src)
+
+	if ok == false {
+		return
+	}
+
+	var si, di data.HeadInfo
+
+	if si, err = sk.HeadInfo(pk, nonce); err != nil {
+		return
+	}
+
+	if di, err = dk.HeadInfo(pk, nonce); err != nil {
+		return
+	}
+
+	if si == di {
+		return
+	}
+
+	return dk.SetHeadInfo(pk, nonce, si)
+}
+
+func pruneFeeds(dst, src data.IdxDB) (err error) {
+
+	return dst.IterateFeeds(func(pk cipher.PubKey) (err error) {
+
+		var ok bool
+		if ok, err = src.HasFeed(pk); err != nil {
+			return
+		}
+
+		if ok == false {
+			return dst.DelFeed(pk)
+		}
+
+		return dst.IterateHeads(pk, func(nonce uint64) (err error) {
+			return pruneHead(dst, src, pk, nonce)
+		})
+	})
+
+}
+
+func pruneHead(
+	dst data.IdxDB,
+	src data.IdxDB,
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	err error,
+) {
+
+	var ok bool
+	if ok, err = src.HasHead(pk, nonce); err != nil {
+		return
+	}
+
+	if ok == false {
+		return dst.DelHead(pk, nonce)
+	}
+
+	var del []uint64
+
+	err = dst.AscendRoots(pk, nonce, func(seq uint64) (err error) {
+		if ok, err = src.HasRoot(pk, nonce, seq); err == nil && ok == false {
+			del = append(del, seq)
+		}
+		return
+	})
+
+	if err != nil {
+		return
+	}
+
+	for _, seq := range del {
+		if err = dst.DelRoot(pk, nonce, seq); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+//
+// verify
+//
+
+// Verify compares given DBs. The Verify checks
+// amount and volume of objects, RC of every object,
+// number of feeds, heads and Root objects, infos of
+// feeds and heads (if both IdxDB keep them). And it
+// checks keys of all objects of the dst using hash
+// algorithm of the src. The Verify returns
+// *VerifyError, *data.HashMismatchError or an error
+// of DB. The src should not be in use while the
+// Verify works
+func Verify(dst, src *data.DB) (err error) {
+
+	var hash data.Hash
+	if hash, err = Hash(src); err != nil {
+		return
+	}
+
+	var dh data.Hash
+	if dh, err = Hash(dst); err != nil {
+		return
+	}
+
+	if dh != hash {
+		return &data.HashMismatchError{Want: hash, Got: dh}
+	}
+
+	if err = verifyObjects(dst.CXDS(), src.CXDS(), hash); err != nil {
+		return
+	}
+
+	return verifyFeeds(dst.IdxDB(), src.IdxDB())
+}
+
+func verifyObjects(dst, src data.CXDS, hash data.Hash) (err error) {
+
+	var sa, _ = src.Amount()
+	var da, _ = dst.Amount()
+
+	if sa != da {
+		return &VerifyError{"amount of objects", sa, da}
+	}
+
+	var sv, _ = src.Volume()
+	var dv, _ = dst.Volume()
+
+	if sv != dv {
+		return &VerifyError{"volume of objects", sv, dv}
+	}
+
+	return dst.Iterate(func(key cipher.SHA256) (err error) {
+
+		var obj *data.Object
+		if obj, err = dst.GetNotTouch(key); err != nil {
+			return
+		}
+
+		if hash.Sum(obj.Val) != key {
+			return fmt.Errorf("migrate: invalid object %s: hash of value is %s",
+				key.Hex()[:7], hash.Sum(obj.Val).Hex()[:7])
+		}
+
+		var so *data.Object
+		if so, err = src.GetNotTouch(key); err != nil {
+			if err == data.ErrNotFound {
+				err = fmt.Errorf("migrate: object %s not found in source",
+					key.Hex()[:7])
+			}
+			return
+		}
+
+		if so.RC != obj.RC {
+			return &VerifyError{"RC of " + key.Hex()[:7], so.RC, obj.RC}
+		}
+
+		return
+	})
+
+}
+
+func verifyFeeds(dst, src data.IdxDB) (err error) {
+
+	var sl, dl int
+
+	if sl, err = src.FeedsLen(); err != nil {
+		return
+	}
+
+	if dl, err = dst.FeedsLen(); err != nil {
+		return
+	}
+
+	if sl != dl {
+		return &VerifyError{"number of feeds", int64(sl), int64(dl)}
+	}
+
+	return src.IterateFeeds(func(pk cipher.PubKey) (err error) {
+
+		if sl, err = src.HeadsLen(pk); err != nil {
+			return
+		}
+
+		if dl, err = dst.HeadsLen(pk); err != nil {
+			return
+		}
+
+		if sl != dl {
+			return &VerifyError{"number of heads of " + pk.Hex()[:7],
+				int64(sl), int64(dl)}
+		}
+
+		if err = verifyFeedInfo(dst, src, pk); err != nil {
+			return
+		}
+
+		return src.IterateHeads(pk, func(nonce uint64) (err error) {
+			return verifyHead(dst, src, pk, nonce)
+		})
+	})
+
+}
+
+func verifyHead(
+	dst data.IdxDB,
+	src data.IdxDB,
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	err error,
+) {
+
+	var sl, dl int
+
+	if sl, err = src.RootsLen(pk, nonce); err != nil {
+		return
+	}
+
+	if dl, err = dst.RootsLen(pk, nonce); err != nil {
+		return
+	}
+
+	if sl != dl {
+		return &VerifyError{
+			fmt.Sprintf("number of Root objects of %s/%d", pk.Hex()[:7], nonce),
+			int64(sl),
+			int64(dl),
+		}
+	}
+
+	if err = verifyHeadInfo(dst, src, pk, nonce); err != nil {
+		return
+	}
+
+	return src.AscendRoots(pk, nonce, func(seq uint64) (err error) {
+
+		var sr, dr *data.Root
+
+		if sr, err = src.GetNotTouchRoot(pk, nonce, seq); err != nil {
+			return
+		}
+
+		if dr, err = dst.GetNotTouchRoot(pk, nonce, seq); err != nil {
+			return
+		}
+
+		if sr.Hash != dr.Hash || sr.Sig != dr.Sig {
+			return fmt.Errorf("migrate: Root %s/%d/%d is different",
+				pk.Hex()[:7], nonce, seq)
+		}
+
+		return
+	})
+
+}
+
+func verifyFeedInfo(dst, src data.IdxDB, pk cipher.PubKey) (err error) {
+
+	var dk, sk, ok = feedKeepers(dst, src)
+
+	if ok == false {
+		return
+	}
+
+	var si, di []byte
+
+	if si, err = sk.FeedInfo(pk); err != nil {
+		return
+	}
+
+	if di, err = dk.FeedInfo(pk); err != nil {
+		return
+	}
+
+	if bytes.Equal(si, di) == false {
+		return fmt.Errorf("migrate: info of feed %s is different",
+			pk.Hex()[:7])
+	}
+
+	return
+}
+
+func verifyHeadInfo(
+	dst data.IdxDB,
+	src data.IdxDB,
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	err error,
+) {
+
+	var dk, sk, ok = headKeepers(dst, src)
+
+	if ok == false {
+		return
+	}
+
+	var si, di data.HeadInfo
+
+	if si, err = sk.HeadInfo(pk, nonce); err != nil {
+		return
+	}
+
+	if di, err = dk.HeadInfo(pk, nonce); err != nil {
+		return
+	}
+
+	if si != di {
+		return fmt.Errorf("migrate: info of head %s/%d is different",
+			pk.Hex()[:7], nonce)
+	}
+
+	return
+}
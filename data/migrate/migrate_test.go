@@ -0,0 +1,297 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds/memory"
+	idx "github.com/skycoin/cxo/data/idx/memory"
+)
+
+func newDB() *data.DB {
+	return data.NewDB(memory.NewMemory(), idx.NewMemory(0))
+}
+
+// fill the DB with given values, a feed, a head
+// and a Root per value
+func fill(t *testing.T, db *data.DB, pk cipher.PubKey, vals ...string) {
+	t.Helper()
+
+	var (
+		ds = db.CXDS()
+		id = db.IdxDB()
+	)
+
+	if err := id.AddFeed(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := id.AddHead(pk, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var seq, err = id.RootsLen(pk, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, val := range vals {
+		var key = cipher.SumSHA256([]byte(val))
+		if _, err = ds.SetIncr(key, []byte(val), int64(i+1)); err != nil {
+			t.Fatal(err)
+		}
+		_, err = id.SetRoot(pk, 1, uint64(seq+i), key, cipher.Sig{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+
+		src = newDB()
+		dst = newDB()
+	)
+
+	fill(t, src, pk, "one", "two", "three")
+
+	var key = cipher.SumSHA256([]byte("one"))
+
+	var stat, err = Migrate(dst, src, Config{Verify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stat.Copied.Amount != 3 || stat.Skipped.Amount != 0 {
+		t.Error("wrong stat", stat)
+	}
+
+	if stat.Feeds != 1 || stat.Heads != 1 || stat.Roots != 3 {
+		t.Error("wrong stat", stat)
+	}
+
+	var so, do *data.Object
+	if so, err = src.CXDS().GetNotTouch(key); err != nil {
+		t.Fatal(err)
+	}
+	if do, err = dst.CXDS().GetNotTouch(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if so.RC != do.RC || so.Access.Equal(do.Access) == false ||
+		so.Create.Equal(do.Create) == false {
+
+		t.Error("object is not copied as is")
+	}
+
+	t.Run("incremental", func(t *testing.T) {
+
+		fill(t, src, pk, "four")
+
+		if stat, err = Migrate(dst, src, Config{Verify: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		if stat.Copied.Amount != 1 || stat.Skipped.Amount != 3 {
+			t.Error("wrong stat", stat)
+		}
+
+		if stat.Feeds != 0 || stat.Heads != 0 || stat.Roots != 1 {
+			t.Error("wrong stat", stat)
+		}
+
+	})
+
+	t.Run("prune", func(t *testing.T) {
+
+		if err = src.CXDS().Del(key); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = src.IdxDB().DelRoot(pk, 1, 2); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = Verify(dst, src); err == nil {
+			t.Fatal("missing error")
+		}
+
+		stat, err = Migrate(dst, src, Config{Prune: true, Verify: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if stat.Pruned.Amount != 1 {
+			t.Error("wrong stat", stat)
+		}
+
+		if ok, _ := dst.IdxDB().HasRoot(pk, 1, 2); ok == true {
+			t.Error("Root is not pruned")
+		}
+
+	})
+
+}
+
+func TestMigrate_hash(t *testing.T) {
+
+	var (
+		src = newDB()
+		dst = newDB()
+	)
+
+	if err := data.CheckHash(src, data.BLAKE2b256); err != nil {
+		t.Fatal(err)
+	}
+
+	var val = []byte("value")
+	_, err := src.CXDS().Set(data.BLAKE2b256.Sum(val), val)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = Migrate(dst, src, Config{Verify: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if hash, err := Hash(dst); err != nil {
+		t.Fatal(err)
+	} else if hash != data.BLAKE2b256 {
+		t.Error("hash algorithm is not copied:", hash)
+	}
+
+	// SHA256 destination that is not blank
+	var other = newDB()
+	other.CXDS().Set(cipher.SumSHA256(val), val)
+
+	if _, err = Migrate(other, src, Config{}); err == nil {
+		t.Error("missing error")
+	} else if _, ok := err.(*data.HashMismatchError); ok == false {
+		t.Error("unexpected error:", err)
+	}
+
+}
+
+func TestVerify(t *testing.T) {
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+
+		src = newDB()
+		dst = newDB()
+	)
+
+	fill(t, src, pk, "one", "two")
+
+	if _, err := Migrate(dst, src, Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(dst, src); err != nil {
+		t.Fatal(err)
+	}
+
+	// change RC in destination
+	var key = cipher.SumSHA256([]byte("two"))
+	if _, _, err := dst.CXDS().Incr(key, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := Verify(dst, src).(*VerifyError); ok == false {
+		t.Error("missing *VerifyError")
+	}
+
+	// invalid key
+	var val = []byte("val")
+	dst.CXDS().Set(cipher.SHA256{1}, val)
+	src.CXDS().Set(cipher.SHA256{1}, val)
+
+	if _, err := Migrate(dst, src, Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(dst, src); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestMigrate_info(t *testing.T) {
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+
+		src = newDB()
+		dst = newDB()
+
+		info = data.HeadInfo{Label: "main", Active: true, Keep: 10}
+	)
+
+	fill(t, src, pk, "one", "two")
+
+	var (
+		shk = src.IdxDB().(data.HeadKeeper)
+		sfk = src.IdxDB().(data.FeedKeeper)
+		dhk = dst.IdxDB().(data.HeadKeeper)
+		dfk = dst.IdxDB().(data.FeedKeeper)
+	)
+
+	if err := shk.SetHeadInfo(pk, 1, info); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sfk.SetFeedInfo(pk, []byte("info")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Migrate(dst, src, Config{Verify: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var check = func() {
+		t.Helper()
+
+		if hi, err := dhk.HeadInfo(pk, 1); err != nil {
+			t.Fatal(err)
+		} else if hi != info {
+			t.Errorf("wrong head info: %v", hi)
+		}
+
+		if fi, err := dfk.FeedInfo(pk); err != nil {
+			t.Fatal(err)
+		} else if string(fi) != "info" {
+			t.Errorf("wrong feed info: %q", fi)
+		}
+	}
+
+	check()
+
+	// change infos in destination
+	if err := dhk.SetHeadInfo(pk, 1, data.HeadInfo{Label: "other"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(dst, src); err == nil {
+		t.Error("missing error")
+	}
+
+	if err := dfk.SetFeedInfo(pk, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Migrate(dst, src, Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(dst, src); err != nil {
+		t.Fatal(err)
+	}
+
+	check()
+
+}
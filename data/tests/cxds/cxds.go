@@ -366,6 +366,141 @@ func GetIncrNotTouch(t *testing.T, ds data.CXDS) {
 	})
 }
 
+// View test case. The View uses data.View, thus it
+// tests the data.Viewer implementation if the ds
+// implements it, and the fallback otherwise.
+func View(t *testing.T, ds data.CXDS) {
+	// View(key cipher.SHA256, viewFunc ViewFunc) (err error)
+
+	var (
+		key, val = keyValueByString("something")
+		vol      = int64(len(val))
+
+		errView = errors.New("view error")
+	)
+
+	t.Run("not exist", func(t *testing.T) {
+		var err = data.View(ds, key, func([]byte, int64) (_ error) {
+			t.Error("called")
+			return
+		})
+		if err == nil {
+			t.Error("missing error")
+		} else if err != data.ErrNotFound {
+			t.Error("unexpected error")
+		}
+		dsShouldBeBlank(t, ds)
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		var obj, err = ds.Set(key, val)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = data.View(ds, key, func(v []byte, rc int64) (_ error) {
+			if bytes.Compare(v, val) != 0 {
+				t.Error("wrong value")
+			}
+			if rc != 1 {
+				t.Error("wrong rc", rc)
+			}
+			return
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var gobj *data.Object
+		if gobj, err = ds.GetNotTouch(key); err != nil {
+			t.Error(err)
+			return
+		}
+		obj.Access = obj.Create // to compare easy way
+		if areObjectsEqual(obj, gobj) == false {
+			t.Error("object has been changed")
+		}
+		statShouldBe(t, ds, stat{1, 1}, stat{vol, vol})
+		dsShouldHave(t, ds, key)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var err = data.View(ds, key, func([]byte, int64) error {
+			return errView
+		})
+		if err != errView {
+			t.Error("wrong error", err)
+		}
+	})
+}
+
+// SetBatch test case. The SetBatch uses data.SetBatch,
+// thus it tests the data.Batcher implementation if the
+// ds implements it, and the fallback otherwise.
+func SetBatch(t *testing.T, ds data.CXDS) {
+	// SetBatch(items []BatchItem) (rcs []int64, err error)
+
+	var (
+		k1, v1 = keyValueByString("something")
+		k2, v2 = keyValueByString("another")
+		vol    = int64(len(v1) + len(v2))
+	)
+
+	t.Run("create", func(t *testing.T) {
+		var rcs, err = data.SetBatch(ds, []data.BatchItem{
+			{Key: k1, Val: v1, IncrBy: 1},
+			{Key: k2, Val: v2, IncrBy: 2},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(rcs) != 2 || rcs[0] != 1 || rcs[1] != 2 {
+			t.Error("wrong rcs", rcs)
+		}
+		statShouldBe(t, ds, stat{2, 2}, stat{vol, vol})
+		dsShouldHave(t, ds, k1, k2)
+	})
+
+	if t.Failed() == true {
+		t.Skip("can't continue, because of previous test")
+		return
+	}
+
+	t.Run("update", func(t *testing.T) {
+		var rcs, err = data.SetBatch(ds, []data.BatchItem{
+			{Key: k1, Val: v1, IncrBy: -1},
+			{Key: k2, Val: v2, IncrBy: 1},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(rcs) != 2 || rcs[0] != 0 || rcs[1] != 3 {
+			t.Error("wrong rcs", rcs)
+		}
+		var obj *data.Object
+		if obj, err = ds.GetNotTouch(k2); err != nil {
+			t.Error(err)
+			return
+		}
+		if bytes.Compare(obj.Val, v2) != 0 || obj.RC != 3 {
+			t.Error("wrong object", string(obj.Val), obj.RC)
+		}
+		statShouldBe(t, ds, stat{2, 1}, stat{vol, int64(len(v2))})
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var rcs, err = data.SetBatch(ds, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(rcs) != 0 {
+			t.Error("wrong rcs", rcs)
+		}
+	})
+}
+
 // Set test case.
 func Set(t *testing.T, ds data.CXDS) {
 	// Set(key cipher.SHA256, val []byte) (obj *Object, err error)
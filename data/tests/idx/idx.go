@@ -1410,6 +1410,298 @@ func IsSafeClosed(
 
 }
 
+// HashKeeper test case. The idx must implement the
+// data.HashKeeper. The reopen fucntion can be nil if
+// DB is in-memory.
+func HashKeeper(
+	t *testing.T, //                      : the T pointer
+	idx data.IdxDB, //                    : idx already opened
+	reopen func() (data.IdxDB, error), // : reopen idx to check the hash
+) {
+	// Hash() (hash data.Hash, err error)
+	// SetHash(hash data.Hash) (err error)
+
+	var hk, ok = idx.(data.HashKeeper)
+
+	if ok == false {
+		t.Fatalf("%T doesn't implement data.HashKeeper", idx)
+	}
+
+	if _, err := hk.Hash(); err != data.ErrHashNotRecorded {
+		t.Error("unexpected error:", err)
+	}
+
+	if err := hk.SetHash(data.BLAKE2b256); err != nil {
+		t.Fatal(err)
+	}
+
+	if hash, err := hk.Hash(); err != nil {
+		t.Error(err)
+	} else if hash != data.BLAKE2b256 {
+		t.Error("wrong hash:", hash)
+	}
+
+	if reopen == nil {
+		return
+	}
+
+	var err error
+	if err = idx.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if idx, err = reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	if hash, err := idx.(data.HashKeeper).Hash(); err != nil {
+		t.Error(err)
+	} else if hash != data.BLAKE2b256 {
+		t.Error("wrong hash after reopenning:", hash)
+	}
+
+}
+
+// HeadKeeper test case. The idx must implement
+// data.HeadKeeper interface
+func HeadKeeper(
+	t *testing.T, //                      : the T pointer
+	idx data.IdxDB, //                    : idx already opened
+	reopen func() (data.IdxDB, error), // : reopen idx to check the info
+) {
+	// HeadInfo(pk cipher.PubKey, nonce uint64) (info HeadInfo, err error)
+	// SetHeadInfo(pk cipher.PubKey, nonce uint64, info HeadInfo) (err error)
+
+	var hk, ok = idx.(data.HeadKeeper)
+
+	if ok == false {
+		t.Fatalf("%T doesn't implement data.HeadKeeper", idx)
+	}
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		nonce = uint64(1)
+		info  = data.HeadInfo{Label: "main", Active: true, Keep: 10}
+
+		err error
+	)
+
+	t.Run("no such feed", func(t *testing.T) {
+		if _, err = hk.HeadInfo(pk, nonce); err != data.ErrNoSuchFeed {
+			t.Error("wrong error:", err)
+		}
+		err = hk.SetHeadInfo(pk, nonce, info)
+		if err != data.ErrNoSuchFeed {
+			t.Error("wrong error:", err)
+		}
+	})
+
+	if err = idx.AddFeed(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no such head", func(t *testing.T) {
+		if _, err = hk.HeadInfo(pk, nonce); err != data.ErrNoSuchHead {
+			t.Error("wrong error:", err)
+		}
+		err = hk.SetHeadInfo(pk, nonce, info)
+		if err != data.ErrNoSuchHead {
+			t.Error("wrong error:", err)
+		}
+	})
+
+	if err = idx.AddHead(pk, nonce); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("set", func(t *testing.T) {
+		var got data.HeadInfo
+		if got, err = hk.HeadInfo(pk, nonce); err != nil {
+			t.Fatal(err)
+		} else if got.IsBlank() == false {
+			t.Error("not blank info:", got)
+		}
+		if err = hk.SetHeadInfo(pk, nonce, info); err != nil {
+			t.Fatal(err)
+		}
+		// adding existing head doesn't remove the info
+		if err = idx.AddHead(pk, nonce); err != nil {
+			t.Fatal(err)
+		}
+		if got, err = hk.HeadInfo(pk, nonce); err != nil {
+			t.Fatal(err)
+		} else if got != info {
+			t.Errorf("wrong info %v, want %v", got, info)
+		}
+	})
+
+	t.Run("blank", func(t *testing.T) {
+		var got data.HeadInfo
+		if err = hk.SetHeadInfo(pk, nonce, data.HeadInfo{}); err != nil {
+			t.Fatal(err)
+		}
+		if got, err = hk.HeadInfo(pk, nonce); err != nil {
+			t.Fatal(err)
+		} else if got.IsBlank() == false {
+			t.Error("not blank info:", got)
+		}
+	})
+
+	t.Run("del head", func(t *testing.T) {
+		var got data.HeadInfo
+		if err = hk.SetHeadInfo(pk, nonce, info); err != nil {
+			t.Fatal(err)
+		}
+		if err = idx.DelHead(pk, nonce); err != nil {
+			t.Fatal(err)
+		}
+		if err = idx.AddHead(pk, nonce); err != nil {
+			t.Fatal(err)
+		}
+		if got, err = hk.HeadInfo(pk, nonce); err != nil {
+			t.Fatal(err)
+		} else if got.IsBlank() == false {
+			t.Error("info not removed with head:", got)
+		}
+	})
+
+	if err = hk.SetHeadInfo(pk, nonce, info); err != nil {
+		t.Fatal(err)
+	}
+
+	if reopen == nil {
+		return
+	}
+
+	if err = idx.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if idx, err = reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got data.HeadInfo
+	if got, err = idx.(data.HeadKeeper).HeadInfo(pk, nonce); err != nil {
+		t.Error(err)
+	} else if got != info {
+		t.Errorf("wrong info after reopenning %v, want %v", got, info)
+	}
+
+}
+
+// FeedKeeper test case. The idx must implement
+// data.FeedKeeper interface
+func FeedKeeper(
+	t *testing.T, //                      : the T pointer
+	idx data.IdxDB, //                    : idx already opened
+	reopen func() (data.IdxDB, error), // : reopen idx to check the info
+) {
+	// FeedInfo(pk cipher.PubKey) (info []byte, err error)
+	// SetFeedInfo(pk cipher.PubKey, info []byte) (err error)
+
+	var fk, ok = idx.(data.FeedKeeper)
+
+	if ok == false {
+		t.Fatalf("%T doesn't implement data.FeedKeeper", idx)
+	}
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		info  = []byte("feed info")
+
+		err error
+	)
+
+	t.Run("no such feed", func(t *testing.T) {
+		if _, err = fk.FeedInfo(pk); err != data.ErrNoSuchFeed {
+			t.Error("wrong error:", err)
+		}
+		if err = fk.SetFeedInfo(pk, info); err != data.ErrNoSuchFeed {
+			t.Error("wrong error:", err)
+		}
+	})
+
+	if err = idx.AddFeed(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("set", func(t *testing.T) {
+		var got []byte
+		if got, err = fk.FeedInfo(pk); err != nil {
+			t.Fatal(err)
+		} else if len(got) != 0 {
+			t.Errorf("not blank info: %q", got)
+		}
+		if err = fk.SetFeedInfo(pk, info); err != nil {
+			t.Fatal(err)
+		}
+		// adding existing feed doesn't remove the info
+		if err = idx.AddFeed(pk); err != nil {
+			t.Fatal(err)
+		}
+		if got, err = fk.FeedInfo(pk); err != nil {
+			t.Fatal(err)
+		} else if string(got) != string(info) {
+			t.Errorf("wrong info %q, want %q", got, info)
+		}
+	})
+
+	t.Run("blank", func(t *testing.T) {
+		var got []byte
+		if err = fk.SetFeedInfo(pk, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got, err = fk.FeedInfo(pk); err != nil {
+			t.Fatal(err)
+		} else if len(got) != 0 {
+			t.Errorf("not blank info: %q", got)
+		}
+	})
+
+	t.Run("del feed", func(t *testing.T) {
+		var got []byte
+		if err = fk.SetFeedInfo(pk, info); err != nil {
+			t.Fatal(err)
+		}
+		if err = idx.DelFeed(pk); err != nil {
+			t.Fatal(err)
+		}
+		if err = idx.AddFeed(pk); err != nil {
+			t.Fatal(err)
+		}
+		if got, err = fk.FeedInfo(pk); err != nil {
+			t.Fatal(err)
+		} else if len(got) != 0 {
+			t.Errorf("info not removed with feed: %q", got)
+		}
+	})
+
+	if err = fk.SetFeedInfo(pk, info); err != nil {
+		t.Fatal(err)
+	}
+
+	if reopen == nil {
+		return
+	}
+
+	if err = idx.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if idx, err = reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []byte
+	if got, err = idx.(data.FeedKeeper).FeedInfo(pk); err != nil {
+		t.Error(err)
+	} else if string(got) != string(info) {
+		t.Errorf("wrong info after reopenning %q, want %q", got, info)
+	}
+
+}
+
 // Close test case.
 func Close(t *testing.T, idx data.IdxDB) {
 	// Close() (err error)
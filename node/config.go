@@ -21,15 +21,28 @@ const (
 	MaxFillingTime time.Duration = 10 * time.Minute
 	MaxHeads       int           = 10
 
+	HeadsPolicy MaxHeadsPolicy = MaxHeadsDropEarliest
+
+	MaxParallelRequests int = 4    // per connection
+	MaxEvents           int = 1024 // events to keep for RPC
+
 	ListenTCP string = ":8870"
 	ListenUDP string = "" // don't listen by default
 
-	RPCAddress string = ":8873"
+	RPCAddress  string = ":8873"
+	HTTPAddress string = "" // don't listen by default
+
+	KeystorePath string = "" // in-memory
 
 	ResponseTimeout time.Duration = 59 * time.Second
 	Features        msg.Features  = msg.CreatedObjects
 
 	Public bool = false
+
+	FeedRefs    FeedRefPolicy = FeedRefIgnore
+	MaxFeedRefs int           = 64
+
+	GossipEquivocations bool = false
 )
 
 // Addresses are discovery addresses
@@ -79,6 +92,24 @@ type OnRootFilledFunc func(n *Node, r *registry.Root)
 // then the Root can be filled (or can be not).
 type OnFillingBreaksFunc func(n *Node, r *registry.Root, err error)
 
+// OnEquivocationFunc represents callback that
+// called when the Node detects conflicting Root
+// objects of a feed (the same feed, head and seq,
+// but different Root objects), or when the Node
+// receives such evidence from a peer. The
+// Conn is connection the conflicting Root or the
+// evidence received from. The callback called
+// once per evidence
+type OnEquivocationFunc func(c *Conn, e *skyobject.Equivocation)
+
+// OnFeedRefFunc represents callback that called
+// before the Node follows a feed referenced by a
+// registry.FeedRef of a filled Root (see FeedRefPolicy).
+// The Conn is connection the Root received from. Return
+// false to ignore the feed. It's possible to use the
+// callback as allow-list of feeds to follow
+type OnFeedRefFunc func(c *Conn, fr registry.FeedRef) (allow bool)
+
 // OnConnectFunc represents callback that called
 // when a connection created and established. It's
 // possible to terminate connection returning error
@@ -236,6 +267,26 @@ type RPCConfig struct {
 	TLSConfig        // TLS configurations
 }
 
+// An HTTPConfig represents configurations of
+// HTTP/JSON gateway (see HTTPServer). Set Listen
+// to empty string to disable the gateway. TLS
+// configurations are the same as for RPC
+type HTTPConfig struct {
+	Listen    string // listening address, use ":0" for OS-choosed
+	TLSConfig        // TLS configurations
+
+	// Origins is list of origins (e.g. "https://example.com")
+	// allowed to perform state-changing requests. A request
+	// from the same host is always allowed. A request without
+	// Origin header (not from a browser) is allowed too. Use
+	// "*" to allow any origin
+	Origins []string
+
+	// Token, if set, required by state-changing requests
+	// as "Authorization: Bearer <token>" header
+	Token string
+}
+
 // A Config represents configurations
 // of the Node. To create Config filled
 // with default values use NewConfig
@@ -263,23 +314,27 @@ type Config struct {
 	// this limit required to protect the Node against
 	// misuse and against intentional DoS attacks. A
 	// user can generate infinity number of heads.
-	// This limit dismiss heads Root objects of
-	// which received most early. It's impossible to
-	// know which heads will be rejected, and which
-	// not. But in reality, it is not a problem.
+	// What the Node does reaching the limit, is
+	// described by the MaxHeadsPolicy.
 	//
 	// For example, if a node subscribed to feed A,
 	// and a peer receive 10 Root object one by one
 	// with different heads. And, if the MaxHeads
-	// limit is 5, then first 5 received Root objects
-	// will be rejected when last 5 received.
-	//
-	// In reality, this porblem can't occur or be
-	// a real problem
+	// limit is 5, then, by default, first 5 received
+	// Root objects will be rejected when last 5
+	// received.
 	//
 	// Set the limit to zero, to turn it off.
 	MaxHeads int
 
+	// MaxHeadsPolicy describes what the Node does with
+	// Root objects of new heads of a feed reached the
+	// MaxHeads limit. By default, the Node drops heads
+	// Root objects of which received most early, except
+	// heads with labels and explicitly selected active
+	// head. See MaxHeadsPolicy for details
+	MaxHeadsPolicy MaxHeadsPolicy
+
 	// MaxFillingTime is time limit for filling of
 	// a Root object. If a Root object fills too
 	// long (longe then this limit), then it will
@@ -287,9 +342,59 @@ type Config struct {
 	// limit.
 	MaxFillingTime time.Duration
 
+	// MaxParallelRequests is limit of parallel object
+	// requests to a connection during filling. The
+	// Node scores connections (see ConnScore) and
+	// the connection with best score gets this limit,
+	// while slower connections get less. If it's zero
+	// or less, then every connection gets one request
+	// at a time
+	MaxParallelRequests int
+
+	// MaxEvents is number of last events the Node
+	// keeps for long-poll requests (see PollEvents).
+	// Slow requesters can miss events if there are
+	// too many events. Set it to zero to turn the
+	// events off
+	MaxEvents int
+
+	// Keystore is path to file with secret keys of
+	// feeds the Node can publish Root objects of
+	// remotely (see Keystore and PublishJSON). If
+	// it's blank, then the keystore is in-memory and
+	// keys are lost when the Node closed. Keep the
+	// file in secret
+	Keystore string
+
+	// FeedRefPolicy describes what the Node does with
+	// feeds referenced by registry.FeedRef fields of
+	// filled Root objects. By default, the Node ignores
+	// them. The Node can subscribe to the feeds or just
+	// prefetch latest Root objects of the feeds. In both
+	// cases the Node uses connection the filled Root
+	// received from. See FeedRefPolicy for details
+	FeedRefPolicy FeedRefPolicy
+
+	// MaxFeedRefs is limit of feeds the Node follows by
+	// the FeedRefPolicy. Feeds of Root objects of feeds
+	// followed this way can be followed too, and this
+	// limit protects the Node against infinite chain of
+	// references. Feeds the Node already shares are not
+	// counted. Set it to zero to turn the limit off
+	MaxFeedRefs int
+
+	// GossipEquivocations turns on sending evidences
+	// of forks (see skyobject.Equivocation) to peers
+	// subscribed to feed of an evidence. Received
+	// evidences are verified and forwarded too
+	GossipEquivocations bool
+
 	// RPC configurations
 	RPC RPCConfig
 
+	// HTTP configurations
+	HTTP HTTPConfig
+
 	//
 	// Networks
 	//
@@ -362,6 +467,16 @@ type Config struct {
 	// when new Root object filled and can be
 	// used. See OnRootFilledFunc for details.
 	OnFillingBreaks OnFillingBreaksFunc
+
+	// OnEquivocation is a callback that called
+	// when the Node detects a fork of a feed. See
+	// OnEquivocationFunc for details.
+	OnEquivocation OnEquivocationFunc
+
+	// OnFeedRef is a callback that called before
+	// the Node follows a referenced feed. See
+	// OnFeedRefFunc for details.
+	OnFeedRef OnFeedRefFunc
 }
 
 // NewConfig returns new Config with
@@ -381,6 +496,13 @@ func NewConfig() (c *Config) {
 	c.MaxConnections = MaxConnections
 	c.MaxFillingTime = MaxFillingTime
 	c.MaxHeads = MaxHeads
+	c.MaxHeadsPolicy = HeadsPolicy
+	c.MaxParallelRequests = MaxParallelRequests
+	c.MaxEvents = MaxEvents
+	c.Keystore = KeystorePath
+	c.FeedRefPolicy = FeedRefs
+	c.MaxFeedRefs = MaxFeedRefs
+	c.GossipEquivocations = GossipEquivocations
 
 	c.TCP.Listen = ListenTCP
 	//c.TCP.Pings = Pings
@@ -390,6 +512,7 @@ func NewConfig() (c *Config) {
 	c.UDP.ResponseTimeout = ResponseTimeout
 
 	c.RPC.Listen = RPCAddress
+	c.HTTP.Listen = HTTPAddress
 	c.Public = Public
 
 	return
@@ -442,6 +565,39 @@ func (c *Config) FromFlags() {
 		c.MaxHeads,
 		"max heads of a feed allowed")
 
+	flag.Var(&c.MaxHeadsPolicy,
+		"max-heads-policy",
+		"max heads policy, use 'drop-earliest' or 'reject-new'")
+
+	flag.IntVar(&c.MaxParallelRequests,
+		"max-parallel-requests",
+		c.MaxParallelRequests,
+		"max parallel object requests to a connection")
+
+	flag.IntVar(&c.MaxEvents,
+		"max-events",
+		c.MaxEvents,
+		"max events to keep for RPC, set to zero to turn off")
+
+	flag.StringVar(&c.Keystore,
+		"keystore",
+		c.Keystore,
+		"path to file with secret keys, blank for in-memory")
+
+	flag.Var(&c.FeedRefPolicy,
+		"feed-refs",
+		"referenced feeds policy, use 'ignore', 'prefetch' or 'subscribe'")
+
+	flag.IntVar(&c.MaxFeedRefs,
+		"max-feed-refs",
+		c.MaxFeedRefs,
+		"max feeds to follow by the feed-refs policy, zero for no limit")
+
+	flag.BoolVar(&c.GossipEquivocations,
+		"gossip-equivocations",
+		c.GossipEquivocations,
+		"send evidences of forks of feeds to peers")
+
 	flag.StringVar(&c.RPC.Listen,
 		"rpc",
 		c.RPC.Listen,
@@ -449,6 +605,22 @@ func (c *Config) FromFlags() {
 
 	c.RPC.TLSConfig.FromFlags("rpc", "RPC")
 
+	flag.StringVar(&c.HTTP.Listen,
+		"http",
+		c.HTTP.Listen,
+		"HTTP/JSON gateway listening address")
+
+	flag.Var((*Addresses)(&c.HTTP.Origins),
+		"http-origin",
+		"origin allowed to change state by HTTP/JSON gateway (allow many)")
+
+	flag.StringVar(&c.HTTP.Token,
+		"http-token",
+		c.HTTP.Token,
+		"token required by state-changing HTTP/JSON requests")
+
+	c.HTTP.TLSConfig.FromFlags("http", "HTTP")
+
 	// TCP
 
 	c.TCP.FromFlags("tcp", "TCP")
@@ -483,6 +655,18 @@ func (c *Config) Validate() (err error) {
 		return
 	}
 
+	if err = c.FeedRefPolicy.Validate(); err != nil {
+		return
+	}
+
+	if c.MaxFeedRefs < 0 {
+		return fmt.Errorf("negative MaxFeedRefs %d", c.MaxFeedRefs)
+	}
+
+	if err = c.MaxHeadsPolicy.Validate(); err != nil {
+		return
+	}
+
 	return
 }
 
@@ -496,5 +680,11 @@ func (c *Config) initTLS() (err error) {
 		}
 	}
 
+	if c.HTTP.Listen != "" {
+		if err = c.HTTP.TLSConfig.Init(); err != nil {
+			return
+		}
+	}
+
 	return
 }
@@ -38,6 +38,8 @@ type Conn struct {
 
 	sendq chan<- []byte // channel from factory.Connection
 
+	score *connScore // statistic of object requests
+
 	await  sync.WaitGroup // wait for receiving loop
 	closeq chan struct{}  //
 	closeo sync.Once      // close once
@@ -58,6 +60,7 @@ func (n *Node) newConnection(
 	c.n = n
 
 	c.reqs = make(map[uint32]chan<- msg.Msg)
+	c.score = newConnScore(n.rollAvgSamples)
 
 	c.sendq = fc.GetChanOut()
 	c.closeq = make(chan struct{})
@@ -195,7 +198,8 @@ func (c *Conn) sendRoot(r *registry.Root, ch []cipher.SHA256, co [][]byte) {
 
 		Value: r.Encode(),
 
-		Sig: r.Sig,
+		Sig:  r.Sig,
+		Sigs: r.Sigs,
 
 		CreatedHashes:  ch,
 		CreatedObjects: co,
@@ -258,6 +262,7 @@ func (c *Conn) Subscribe(feed cipher.PubKey) (err error) {
 	}
 
 	c.n.fs.addConnFeed(c, feed)
+	c.n.eventFeed(c, EventSubscribe, feed)
 	c.sendLastRoot(feed)
 	return
 }
@@ -272,6 +277,7 @@ func (c *Conn) unsubscribe(pk cipher.PubKey) {
 // Unsubscribe from given feed of remote peer
 func (c *Conn) Unsubscribe(feed cipher.PubKey) {
 	c.n.fs.delConnFeed(c, feed)
+	c.n.eventFeed(c, EventUnsubscribe, feed)
 	c.unsubscribe(feed) // notify peer
 	return
 }
@@ -306,13 +312,48 @@ func (c *Conn) Preview(
 	case *msg.Err:
 		return errors.New("error: " + x.Err)
 	case *msg.Root:
-		if r, err = c.n.c.PreviewRoot(x.Feed, x.Sig, x.Value); err != nil {
+		r, err = c.n.c.PreviewRoot(x.Feed, x.Sig, x.Value, x.Sigs...)
+		if err != nil {
 			return
 		}
 	default:
 		return fmt.Errorf("invalid msg type received: %T", reply)
 	}
 
+	return c.preview(r, previewFunc)
+}
+
+// PreviewPinned is the same as the Preview, but it uses
+// given Root instead of latest one. The Root should be
+// a Root received by the Preview before. Hash, Sig and
+// Sigs of the Root are used to get and verify the Root
+// again. Thus, a feed can be explored step by step
+// using the same Root, even if the feed has newer Root
+// objects
+func (c *Conn) PreviewPinned(
+	pin *registry.Root, //      : Root to preview
+	previewFunc PreviewFunc, // : the function
+) (
+	err error, //               : first error
+) {
+
+	var r *registry.Root
+	r, err = c.n.c.PreviewRootByHash(pin.Pub, pin.Hash, pin.Sig, pin.Sigs,
+		c.getter())
+	if err != nil {
+		return
+	}
+
+	return c.preview(r, previewFunc)
+}
+
+func (c *Conn) preview(
+	r *registry.Root, //        : Root to preview
+	previewFunc PreviewFunc, // : the function
+) (
+	err error, //               : first error
+) {
+
 	var p *skyobject.Preview
 	if p, err = c.n.c.Preview(r, c.getter()); err != nil {
 		return
@@ -336,7 +377,7 @@ func (c *cget) Get(key cipher.SHA256) (val []byte, err error) {
 
 	switch x := reply.(type) {
 	case *msg.Object:
-		if cipher.SumSHA256(x.Value) != key {
+		if c.c.n.c.Sum(x.Value) != key {
 			return nil, errors.New("wrong object received (different hash)")
 		}
 		val = x.Value
@@ -405,7 +446,7 @@ func (c *Conn) Objects(keys ...cipher.SHA256) (vals [][]byte, err error) {
 
 		// check hashes
 		for i, val := range x.Values {
-			if cipher.SumSHA256(val) != keys[i] {
+			if c.n.c.Sum(val) != keys[i] {
 				return nil, errors.New("wrong object received (different hash)")
 			}
 		}
@@ -452,14 +493,84 @@ func (c *Conn) encodeMsg(seq, rseq uint32, m msg.Msg) (raw []byte) {
 	var em = m.Encode()
 
 	raw = make([]byte, 8, 8+len(em))
+	putSeq(raw, seq, rseq)
+
+	raw = append(raw, em...)
+
+	return
+
+}
 
+// put seq and rseq to head of raw message
+func putSeq(raw []byte, seq, rseq uint32) {
 	binary.LittleEndian.PutUint32(raw, seq)
 	binary.LittleEndian.PutUint32(raw[4:], rseq)
+}
 
-	raw = append(raw, em...)
+// encodeObject encodes Object message reading value of
+// the object in place; e.g. the value copied once, from
+// DB directly to the message; it returns data.ErrNotFound
+// if the object doesn't exist
+func (c *Conn) encodeObject(
+	rseq uint32,
+	key cipher.SHA256,
+) (
+	raw []byte,
+	err error,
+) {
 
+	err = c.n.c.View(key, func(val []byte) (_ error) {
+		raw = make([]byte, 8, 8+msg.ObjectSize(len(val)))
+		raw = msg.AppendObject(raw, val)
+		return
+	})
+
+	if err != nil {
+		return
+	}
+
+	putSeq(raw, c.nextSeq(), rseq)
 	return
+}
+
+// encodeObjects encodes Objects message reading values
+// of the objects in place; it returns data.ErrNotFound
+// if at least one of the objects doesn't exist
+func (c *Conn) encodeObjects(
+	rseq uint32,
+	keys []cipher.SHA256,
+) (
+	raw []byte,
+	err error,
+) {
+
+	raw = make([]byte, 8, 8+msg.ObjectsHeadSize)
+	raw = msg.AppendObjectsHead(raw, len(keys))
+
+	for _, key := range keys {
 
+		err = c.n.c.View(key, func(val []byte) (_ error) {
+			raw = msg.AppendObjectsValue(raw, val)
+			return
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+	}
+
+	putSeq(raw, c.nextSeq(), rseq)
+	return
+}
+
+// send message encoded by encodeObject or encodeObjects
+func (c *Conn) sendEncoded(rseq uint32, raw []byte, m msg.Msg) {
+
+	c.n.Debugf(MsgSendPin, "[%s] send %d %T (zero-copy)", c.String(), rseq,
+		m)
+
+	c.sendRaw(raw)
 }
 
 func (c *Conn) sendMsg(seq, rseq uint32, m msg.Msg) {
@@ -677,6 +788,11 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 	case *msg.RqPreview: // -> RqPreview (feed)
 		return c.handleRqPreview(seq, x)
 
+	// fork
+
+	case *msg.Equivocation: // <- Equivocation (a, b)
+		return c.handleEquivocation(x)
+
 	//
 	// delayed messeges (ignore them)
 	//
@@ -740,6 +856,8 @@ func (c *Conn) handleSub(seq uint32, sub *msg.Sub) (_ error) {
 	c.n.fs.addConnFeed(c, sub.Feed)
 	c.sendOk(seq)
 
+	c.n.eventFeed(c, EventSubscribeRemote, sub.Feed)
+
 	c.sendLastRoot(sub.Feed) // and push last Root
 
 	return
@@ -756,6 +874,7 @@ func (c *Conn) handleUnsub(seq uint32, unsub *msg.Unsub) (err error) {
 	}
 
 	c.n.fs.delConnFeed(c, unsub.Feed) // delete
+	c.n.onUnsubscribeRemote(c, unsub.Feed)
 	return
 }
 
@@ -805,10 +924,12 @@ func (c *Conn) handleRoot(root *msg.Root) (_ error) {
 
 	var r *registry.Root
 
-	r, err = c.n.c.ReceivedRoot(root.Feed, root.Sig, root.Value)
+	r, err = c.n.c.ReceivedRoot(root.Feed, root.Sig, root.Value,
+		root.Sigs...)
 
 	if err != nil {
 		c.n.Printf("[ERR] [%s] received Root error: %s", c.String(), err)
+		c.n.handleEquivocationError(c, err)
 		return // keep connection ?
 	}
 
@@ -830,6 +951,15 @@ func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
 	c.n.Debugf(MsgReceivePin, "[%s] handleRqObject %s", c.String(),
 		rq.Key.Hex()[:7])
 
+	// fast path: the object exists, send it from DB directly
+
+	if raw, err := c.encodeObject(seq, rq.Key); err == nil {
+		c.sendEncoded(seq, raw, (*msg.Object)(nil))
+		return
+	} else if err != data.ErrNotFound {
+		c.n.Fatal("DB failure: ", err)
+	}
+
 	var (
 		gc = make(chan skyobject.Object, 1)
 
@@ -884,6 +1014,15 @@ func (c *Conn) handleRqObjects(seq uint32, rq *msg.RqObjects) {
 	c.n.Debugf(MsgReceivePin, "[%s] handleRqObjects %d", c.String(),
 		len(rq.Keys))
 
+	// fast path: all objects exist, send them from DB directly
+
+	if raw, err := c.encodeObjects(seq, rq.Keys); err == nil {
+		c.sendEncoded(seq, raw, (*msg.Objects)(nil))
+		return
+	} else if err != data.ErrNotFound {
+		c.n.Fatal("DB failure: ", err)
+	}
+
 	var (
 		gc = make(chan skyobject.Object, len(rq.Keys)) // buffered
 
@@ -952,7 +1091,8 @@ func (c *Conn) handleRqPreview(seq uint32, rqp *msg.RqPreview) (_ error) {
 
 		Value: r.Encode(),
 
-		Sig: r.Sig,
+		Sig:  r.Sig,
+		Sigs: r.Sigs,
 	})
 
 	return
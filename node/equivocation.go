@@ -0,0 +1,117 @@
+package node
+
+import (
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// onEquivocation called when the Node detects a fork
+// of a feed or receives evidence of a fork from a peer;
+// the Conn is connection the evidence came from or nil
+func (n *Node) onEquivocation(c *Conn, e *skyobject.Equivocation) {
+
+	var ev = Event{
+		Type:    EventEquivocation,
+		Feed:    e.Feed,
+		Nonce:   e.Nonce,
+		RootSeq: e.Seq,
+	}
+
+	if c != nil {
+		ev.Conn = c.String()
+	}
+
+	n.Printf("[ERR] [%s] equivocation (fork) of feed: %s", ev.Conn,
+		e.Short())
+
+	if oe := n.config.OnEquivocation; oe != nil {
+		oe(c, e)
+	}
+
+	n.events.push(ev)
+
+	if n.config.GossipEquivocations == false {
+		return
+	}
+
+	// async, since the connectionsOfFeed can block
+	n.await.Add(1)
+	go n.gossipEquivocation(c, e)
+}
+
+// check out error of received or filled
+// Root, and handle it, if it's evidence
+// of a fork
+func (n *Node) handleEquivocationError(c *Conn, err error) {
+
+	if ee, ok := err.(*skyobject.EquivocationError); ok == true {
+		n.onEquivocation(c, ee.Equivocation())
+	}
+
+}
+
+// (async) send evidence to peers subscribed
+// to the feed, except the source
+func (n *Node) gossipEquivocation(src *Conn, e *skyobject.Equivocation) {
+
+	defer n.await.Done()
+
+	var m = &msg.Equivocation{
+		A: msg.SignedRoot{Value: e.A.Value, Sig: e.A.Sig, Sigs: e.A.Sigs},
+		B: msg.SignedRoot{Value: e.B.Value, Sig: e.B.Sig, Sigs: e.B.Sigs},
+	}
+
+	for _, c := range n.fs.connectionsOfFeed(e.Feed) {
+
+		select {
+		case <-n.closeq:
+			return
+		default:
+		}
+
+		if c == src {
+			continue
+		}
+
+		c.sendMsg(c.nextSeq(), 0, m)
+	}
+
+}
+
+// got evidence of a fork from peer
+func (c *Conn) handleEquivocation(x *msg.Equivocation) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleEquivocation", c.String())
+
+	var e = &skyobject.Equivocation{
+		A: skyobject.SignedRoot{Value: x.A.Value, Sig: x.A.Sig, Sigs: x.A.Sigs},
+		B: skyobject.SignedRoot{Value: x.B.Value, Sig: x.B.Sig, Sigs: x.B.Sigs},
+	}
+
+	// the Feed, Nonce and Seq are not transmitted
+
+	var r, err = registry.DecodeRoot(e.A.Value)
+
+	if err != nil {
+		c.n.Printf("[ERR] [%s] invalid equivocation: %v", c.String(), err)
+		return // keep connection ?
+	}
+
+	e.Feed, e.Nonce, e.Seq = r.Pub, r.Nonce, r.Seq
+
+	var added bool
+	if added, err = c.n.c.AddEquivocation(e); err != nil {
+		if err != data.ErrNoSuchFeed {
+			c.n.Printf("[ERR] [%s] invalid equivocation: %v", c.String(), err)
+		}
+		return // keep connection ?
+	}
+
+	if added == true {
+		c.n.onEquivocation(c, e)
+	}
+
+	return
+}
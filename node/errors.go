@@ -14,6 +14,10 @@ var (
 	ErrInvalidResponse         = errors.New("invalid response")
 	ErrNoConnectionsToFillFrom = errors.New("no connections to fill from")
 	ErrMaxHeadsLimit           = errors.New("max heads limit")
+	ErrMaxFeedRefsLimit        = errors.New("max feed refs limit")
+	ErrFeedRefRejected         = errors.New("feed ref rejected")
 	ErrUnsubscribe             = errors.New("unsubscribe")
 	ErrBlankFeed               = errors.New("blank feed")
+	ErrNoSecretKey             = errors.New("no secret key of the feed")
+	ErrNoSuchRegistry          = errors.New("no such registry")
 )
@@ -0,0 +1,327 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// max time of a long-poll request (see Node.PollEvents)
+const maxPollTimeout = time.Minute
+
+// An EventType represents type of an Event
+type EventType int
+
+// event types
+const (
+	EventConnect           EventType = iota + 1 // connection established
+	EventDisconnect                             // connection closed
+	EventSubscribe                              // subscribed to feed of peer
+	EventUnsubscribe                            // unsubscribed from feed of peer
+	EventSubscribeRemote                        // peer subscribed to a feed
+	EventUnsubscribeRemote                      // peer unsubscribed from a feed
+	EventRootReceived                           // new Root received
+	EventRootFilled                             // new Root filled
+	EventFillingBreaks                          // a Root can't be filled
+	EventEquivocation                           // fork of a feed detected
+)
+
+// String implements fmt.Stringer interface
+func (e EventType) String() string {
+	switch e {
+	case EventConnect:
+		return "connect"
+	case EventDisconnect:
+		return "disconnect"
+	case EventSubscribe:
+		return "subscribe"
+	case EventUnsubscribe:
+		return "unsubscribe"
+	case EventSubscribeRemote:
+		return "subscribe remote"
+	case EventUnsubscribeRemote:
+		return "unsubscribe remote"
+	case EventRootReceived:
+		return "root received"
+	case EventRootFilled:
+		return "root filled"
+	case EventFillingBreaks:
+		return "filling breaks"
+	case EventEquivocation:
+		return "equivocation"
+	}
+	return fmt.Sprintf("EventType<%d>", e)
+}
+
+// An Event represents an event of the Node. The Events
+// are the same as Config callbacks, but they can be
+// received through RPC (see Node.PollEvents). The
+// Event designed to be transmitted through RPC
+type Event struct {
+	Seq  uint64    // sequence number of the event
+	Type EventType // type of the event
+	Time time.Time // time of the event
+
+	Conn string        // connection (*Conn).String(), if any
+	Feed cipher.PubKey // feed, if any
+
+	// Root related fields
+
+	Nonce   uint64        // head of the Root
+	RootSeq uint64        // seq number of the Root
+	Hash    cipher.SHA256 // hash of the Root
+
+	Err string // closing reason or filling error, if any
+}
+
+// is the event match given feeds
+func (e *Event) match(feeds []cipher.PubKey) bool {
+
+	if len(feeds) == 0 {
+		return true
+	}
+
+	for _, pk := range feeds {
+		if pk == e.Feed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keeps last events for long-poll requests
+type eventHub struct {
+	mx sync.Mutex
+
+	max  int           // max events to keep
+	seq  uint64        // seq of last event, never zero
+	evs  []Event       // last events
+	wake chan struct{} // closed on new event
+}
+
+func newEventHub(max int) (e *eventHub) {
+	e = new(eventHub)
+	e.max = max
+	e.seq = 1 // zero is "now" (see EventsRequest)
+	e.wake = make(chan struct{})
+	return
+}
+
+func (e *eventHub) push(ev Event) {
+
+	if e.max <= 0 {
+		return // disabled
+	}
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	e.seq++
+
+	ev.Seq = e.seq
+	ev.Time = time.Now()
+
+	if len(e.evs) >= e.max {
+		copy(e.evs, e.evs[1:])
+		e.evs = e.evs[:len(e.evs)-1]
+	}
+
+	e.evs = append(e.evs, ev)
+
+	close(e.wake) // wake up waiters
+	e.wake = make(chan struct{})
+}
+
+func (e *eventHub) last() (seq uint64) {
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	return e.seq
+}
+
+// collect events after given seq,
+// zero seq means "now"
+func (e *eventHub) collect(
+	from uint64,
+	feeds []cipher.PubKey,
+	max int,
+) (
+	evs []Event,
+	next uint64,
+	missed bool,
+	wake <-chan struct{},
+) {
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	next, wake = e.seq, e.wake
+
+	if from == 0 {
+		return // start from now
+	}
+
+	if len(e.evs) > 0 && e.evs[0].Seq > from+1 {
+		missed = true // some events has been dropped
+	}
+
+	for _, ev := range e.evs {
+
+		if ev.Seq <= from {
+			continue
+		}
+
+		if ev.match(feeds) == false {
+			continue
+		}
+
+		evs = append(evs, ev)
+
+		if max > 0 && len(evs) == max {
+			next = ev.Seq // continue from the event
+			return
+		}
+
+	}
+
+	return
+}
+
+// An EventsRequest represents long-poll
+// request of events (see Node.PollEvents)
+type EventsRequest struct {
+	// From is seq number of last received event,
+	// the PollEvents returns events after it. Zero
+	// From means "now", e.g. the PollEvents returns
+	// only events that happen during the request.
+	// Use Next of the reply as From of next request
+	From uint64
+	// Feeds to filter events. If it's empty, then
+	// all events are returned. Otherwise, events
+	// of given feeds only. E.g. connection events
+	// that are not related to a feed are skipped
+	Feeds []cipher.PubKey
+	// Max is max number of events to return. Use
+	// zero for no limit
+	Max int
+	// Timeout of the request. The PollEvents waits
+	// this time for new events if there are no
+	// events. It can't be greater then a minute
+	Timeout time.Duration
+}
+
+// An EventsReply represents reply
+// for the EventsRequest
+type EventsReply struct {
+	Events []Event // events
+	Next   uint64  // use it as From for next request
+	Missed bool    // some events has been dropped (too many)
+}
+
+// LastEvent returns seq number of last event. The
+// seq used to poll events (see PollEvents)
+func (n *Node) LastEvent() (seq uint64) {
+	return n.events.last()
+}
+
+// PollEvents is long-poll request of events of the
+// Node. The PollEvents returns events immediately if
+// there are events after rq.From. Otherwise, it waits
+// for new events for rq.Timeout. The Node keeps
+// Config.MaxEvents last events. If a requester is
+// too slow, then some events can be dropped. In this
+// case the Missed field of the reply is true. The
+// PollEvents designed for RPC. Use Config callbacks
+// in Go code
+func (n *Node) PollEvents(rq *EventsRequest) (reply *EventsReply) {
+
+	reply = new(EventsReply)
+
+	var timeout = rq.Timeout
+
+	if timeout > maxPollTimeout {
+		timeout = maxPollTimeout
+	}
+
+	var (
+		tm   = time.NewTimer(timeout)
+		from = rq.From
+
+		wake <-chan struct{}
+	)
+
+	defer tm.Stop()
+
+	for {
+
+		reply.Events, reply.Next, reply.Missed, wake = n.events.collect(
+			from, rq.Feeds, rq.Max)
+
+		if len(reply.Events) > 0 {
+			return
+		}
+
+		from = reply.Next // if the From is zero
+
+		select {
+		case <-wake:
+		case <-tm.C:
+			return
+		case <-n.closeq:
+			return
+		}
+
+	}
+
+}
+
+//
+// events
+//
+
+func (n *Node) eventConn(c *Conn, et EventType, reason error) {
+
+	var ev = Event{Type: et, Conn: c.String()}
+
+	if reason != nil {
+		ev.Err = reason.Error()
+	}
+
+	n.events.push(ev)
+}
+
+func (n *Node) eventFeed(c *Conn, et EventType, feed cipher.PubKey) {
+	n.events.push(Event{Type: et, Conn: c.String(), Feed: feed})
+}
+
+func (n *Node) eventRoot(
+	c *Conn, //          : connection or nil
+	et EventType, //     : type
+	r *registry.Root, // : the Root
+	reason error, //     : filling error
+) {
+
+	var ev = Event{
+		Type:    et,
+		Feed:    r.Pub,
+		Nonce:   r.Nonce,
+		RootSeq: r.Seq,
+		Hash:    r.Hash,
+	}
+
+	if c != nil {
+		ev.Conn = c.String()
+	}
+
+	if reason != nil {
+		ev.Err = reason.Error()
+	}
+
+	n.events.push(ev)
+}
@@ -0,0 +1,93 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func Test_eventHub(t *testing.T) {
+
+	var (
+		eh = newEventHub(2)
+
+		pk, _ = cipher.GenerateKeyPair()
+	)
+
+	eh.push(Event{Type: EventConnect})
+	eh.push(Event{Type: EventSubscribe, Feed: pk})
+
+	if eh.last() != 3 {
+		t.Fatal("wrong last seq:", eh.last())
+	}
+
+	var evs, next, missed, _ = eh.collect(1, nil, 0)
+
+	if len(evs) != 2 || next != 3 || missed == true {
+		t.Fatal("wrong events:", len(evs), next, missed)
+	}
+
+	if evs[0].Seq != 2 || evs[1].Seq != 3 || evs[1].Time.IsZero() {
+		t.Error("wrong seq or time")
+	}
+
+	// filter
+	evs, _, _, _ = eh.collect(1, []cipher.PubKey{pk}, 0)
+
+	if len(evs) != 1 || evs[0].Type != EventSubscribe {
+		t.Error("wrong filtered events:", len(evs))
+	}
+
+	// max
+	evs, next, _, _ = eh.collect(1, nil, 1)
+
+	if len(evs) != 1 || next != 2 {
+		t.Error("wrong limited events:", len(evs), next)
+	}
+
+	// drop oldest
+	eh.push(Event{Type: EventDisconnect})
+
+	if evs, _, missed, _ = eh.collect(1, nil, 0); missed == false {
+		t.Error("missed events not detected")
+	} else if len(evs) != 2 || evs[0].Seq != 3 {
+		t.Error("wrong events after dropping")
+	}
+
+	if _, _, missed, _ = eh.collect(2, nil, 0); missed == true {
+		t.Error("unexpected missed events")
+	}
+
+	// from now
+	if evs, next, missed, _ = eh.collect(0, nil, 0); len(evs) != 0 ||
+		next != 4 || missed == true {
+
+		t.Error("wrong events from now:", len(evs), next, missed)
+	}
+
+	// wake up
+	var wake <-chan struct{}
+	evs, _, _, wake = eh.collect(4, nil, 0)
+
+	if len(evs) != 0 {
+		t.Error("unexpected events:", len(evs))
+	}
+
+	eh.push(Event{Type: EventConnect})
+
+	select {
+	case <-wake:
+	case <-time.After(time.Second):
+		t.Error("slow")
+	}
+
+	// disabled
+	eh = newEventHub(0)
+	eh.push(Event{Type: EventConnect})
+
+	if evs, _, _, _ = eh.collect(1, nil, 0); len(evs) != 0 {
+		t.Error("disabled hub keeps events")
+	}
+
+}
@@ -60,27 +60,20 @@ func (n *nodeFeed) receivedRoot(cr connRoot) {
 
 	if ok == false {
 
-		// max heads limit
-		if mh := n.node().config.MaxHeads; mh > 0 && len(n.ho) == mh {
+		// max heads limit (see MaxHeadsPolicy)
+		if mh := n.node().config.MaxHeads; mh > 0 && len(n.ho) >= mh {
 
-			// TODO (kostyarin): container/list or own doubly linked list ?
-
-			// max heads
-			var torm = n.ho[0]             // to remove
-			copy(n.ho, n.ho[1:])           // shift all
-			n.ho[len(n.ho)-1] = cr.r.Nonce // push
-
-			var tormh = n.hs[torm]
-
-			tormh.errq <- ErrMaxHeadsLimit
-			tormh.close() // wait
-
-			delete(n.hs, torm) // remove
+			if n.evictHead() == false {
+				n.node().Debugf(FeedPin, "[%s] %s rejected by MaxHeads limit",
+					cr.c.String(), cr.r.Short())
+				return
+			}
 
 		}
 
 		nh = newNodeHead(n)
 		n.hs[cr.r.Nonce] = nh
+		n.ho = append(n.ho, cr.r.Nonce) // push
 	}
 
 	nh.receivedRoot(cr)
@@ -0,0 +1,193 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A FeedRefPolicy describes what the Node does with
+// feeds referenced by registry.FeedRef fields of
+// filled Root objects
+type FeedRefPolicy int
+
+// possible policies
+const (
+	// FeedRefIgnore is default policy, the
+	// Node does nothing with referenced feeds
+	FeedRefIgnore FeedRefPolicy = iota
+	// FeedRefPrefetch turns on preloading of
+	// latest Root objects of referenced feeds,
+	// using connection the Root received from.
+	// Objects of the Root objects are kept in
+	// preview cache of the Container
+	FeedRefPrefetch
+	// FeedRefSubscribe subscribes the Node and
+	// the connection the Root received from to
+	// referenced feeds
+	FeedRefSubscribe
+)
+
+// String implements fmt.Stringer interface
+func (f FeedRefPolicy) String() string {
+	switch f {
+	case FeedRefIgnore:
+		return "ignore"
+	case FeedRefPrefetch:
+		return "prefetch"
+	case FeedRefSubscribe:
+		return "subscribe"
+	}
+	return fmt.Sprintf("FeedRefPolicy<%d>", f)
+}
+
+// Set implements flag.Value interface
+func (f *FeedRefPolicy) Set(policy string) (err error) {
+	switch policy {
+	case "ignore":
+		*f = FeedRefIgnore
+	case "prefetch":
+		*f = FeedRefPrefetch
+	case "subscribe":
+		*f = FeedRefSubscribe
+	default:
+		err = errors.New("unknown FeedRef policy: " + policy)
+	}
+	return
+}
+
+// Validate the FeedRefPolicy
+func (f FeedRefPolicy) Validate() (err error) {
+	if f < FeedRefIgnore || f > FeedRefSubscribe {
+		err = fmt.Errorf("invalid FeedRefPolicy %d", f)
+	}
+	return
+}
+
+// onFeedRefs applies FeedRefPolicy to FeedRefs
+// of a filled Root (async, since the Subscribe
+// and the Preview are blocking)
+func (n *Node) onFeedRefs(c *Conn, frs []registry.FeedRef) {
+
+	if c == nil || len(frs) == 0 {
+		return
+	}
+
+	var policy = n.config.FeedRefPolicy
+
+	if policy == FeedRefIgnore {
+		return
+	}
+
+	n.await.Add(1)
+	go n.followFeedRefs(c, policy, frs)
+}
+
+// (async)
+func (n *Node) followFeedRefs(
+	c *Conn, //                : connection the Root received from
+	policy FeedRefPolicy, //   : what to do
+	frs []registry.FeedRef, // : referenced feeds
+) {
+
+	defer n.await.Done()
+
+	var (
+		seen = make(map[cipher.PubKey]struct{}, len(frs))
+		err  error
+	)
+
+	for _, fr := range frs {
+
+		select {
+		case <-n.closeq:
+			return
+		default:
+		}
+
+		if _, ok := seen[fr.Pub]; ok == true {
+			continue // the same feed, different heads or seqs
+		}
+
+		seen[fr.Pub] = struct{}{}
+
+		if err = n.allowFeedRef(c, fr); err != nil {
+			n.Debugf(FeedPin, "[%s] FeedRef %s (%s): %v", c.String(),
+				fr.Short(), policy, err)
+			continue
+		}
+
+		if policy == FeedRefSubscribe {
+			err = n.subscribeFeedRef(c, fr.Pub)
+		} else {
+			err = n.prefetchFeedRef(c, fr.Pub)
+		}
+
+		if err != nil {
+			n.Debugf(FeedPin, "[%s] FeedRef %s (%s): %v", c.String(),
+				fr.Short(), policy, err)
+		}
+
+	}
+
+}
+
+// allowFeedRef checks the OnFeedRef callback and
+// the MaxFeedRefs limit, and remembers the feed if
+// it's allowed; feeds the Node already shares and
+// feeds followed before are not counted
+func (n *Node) allowFeedRef(c *Conn, fr registry.FeedRef) (err error) {
+
+	if onFeedRef := n.config.OnFeedRef; onFeedRef != nil {
+		if onFeedRef(c, fr) == false {
+			return ErrFeedRefRejected
+		}
+	}
+
+	var sharing = n.IsSharing(fr.Pub)
+
+	n.frmx.Lock()
+	defer n.frmx.Unlock()
+
+	if _, ok := n.frs[fr.Pub]; ok == true || sharing == true {
+		return // already followed or shared
+	}
+
+	if max := n.config.MaxFeedRefs; max > 0 && len(n.frs) >= max {
+		return ErrMaxFeedRefsLimit
+	}
+
+	n.frs[fr.Pub] = struct{}{}
+	return
+}
+
+// subscribe the Node and the connection to the feed
+func (n *Node) subscribeFeedRef(c *Conn, pk cipher.PubKey) (err error) {
+
+	if n.fs.hasConnFeed(c, pk) == true {
+		return // already subscribed
+	}
+
+	return c.Subscribe(pk)
+}
+
+// walk latest Root of the feed to get all objects
+// of the Root to preview cache
+func (n *Node) prefetchFeedRef(c *Conn, pk cipher.PubKey) (err error) {
+
+	if n.IsSharing(pk) == true {
+		return // the feed is replicated by the Node
+	}
+
+	return c.Preview(pk,
+		func(pack registry.Pack, r *registry.Root) (err error) {
+			return r.Walk(pack,
+				func(cipher.SHA256, int) (deepper bool, _ error) {
+					deepper = true
+					return
+				})
+		})
+}
@@ -0,0 +1,58 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_allowFeedRef(t *testing.T) {
+
+	var (
+		conf = getTestConfigNotListen("feed-ref")
+
+		rejected, _ = cipher.GenerateKeyPair()
+		shared, _   = cipher.GenerateKeyPair()
+	)
+
+	conf.FeedRefPolicy = FeedRefSubscribe
+	conf.MaxFeedRefs = 2
+	conf.OnFeedRef = func(_ *Conn, fr registry.FeedRef) bool {
+		return fr.Pub != rejected // allow-list
+	}
+
+	var n, err = NewNode(conf)
+	assertNil(t, err)
+	defer n.Close()
+
+	assertNil(t, n.Share(shared))
+
+	var feedRef = func(pk cipher.PubKey) registry.FeedRef {
+		return registry.FeedRef{Pub: pk}
+	}
+
+	err = n.allowFeedRef(nil, feedRef(rejected))
+	if err != ErrFeedRefRejected {
+		t.Error("wrong error:", err)
+	}
+
+	// shared feeds are not counted
+	assertNil(t, n.allowFeedRef(nil, feedRef(shared)))
+
+	var (
+		a, _ = cipher.GenerateKeyPair()
+		b, _ = cipher.GenerateKeyPair()
+		c, _ = cipher.GenerateKeyPair()
+	)
+
+	assertNil(t, n.allowFeedRef(nil, feedRef(a)))
+	assertNil(t, n.allowFeedRef(nil, feedRef(b)))
+	assertNil(t, n.allowFeedRef(nil, feedRef(a))) // followed before
+
+	if err = n.allowFeedRef(nil, feedRef(c)); err != ErrMaxFeedRefsLimit {
+		t.Error("wrong error:", err)
+	}
+
+}
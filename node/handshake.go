@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/node/msg"
 )
 
@@ -50,6 +51,7 @@ func (c *Conn) performHandshake(nodeCloseq <-chan struct{}) (err error) {
 			Protocol: msg.Version,
 			NodeID:   c.n.idpk,
 			Features: c.n.features,
+			Hash:     uint8(c.n.c.Hash()),
 		}),
 		nodeCloseq,
 	)
@@ -95,6 +97,10 @@ func (c *Conn) performHandshake(nodeCloseq <-chan struct{}) (err error) {
 
 		case *msg.Ack:
 
+			if hash := data.Hash(x.Hash); hash != c.n.c.Hash() {
+				return &data.HashMismatchError{Want: c.n.c.Hash(), Got: hash}
+			}
+
 			c.peerID = x.NodeID
 			c.features = x.Features
 
@@ -177,6 +183,21 @@ func (c *Conn) acceptHandshake(nodeCloseq <-chan struct{}) (err error) {
 
 		}
 
+		if hash := data.Hash(x.Hash); hash != c.n.c.Hash() {
+
+			err = &data.HashMismatchError{Want: c.n.c.Hash(), Got: hash}
+
+			// send Err back
+
+			c.sendNodeCloseq(
+				c.encodeMsg(c.nextSeq(), seq, &msg.Err{Err: err.Error()}),
+				nodeCloseq,
+			)
+
+			return
+
+		}
+
 		c.peerID = x.NodeID
 		c.features = x.Features
 
@@ -186,6 +207,7 @@ func (c *Conn) acceptHandshake(nodeCloseq <-chan struct{}) (err error) {
 			c.encodeMsg(c.nextSeq(), seq, &msg.Ack{
 				NodeID:   c.n.idpk,
 				Features: c.n.features,
+				Hash:     uint8(c.n.c.Hash()),
 			}),
 			nodeCloseq,
 		)
@@ -130,6 +130,8 @@ type fillHead struct {
 	rqo *list.List // request objects (cipher.SHA256)
 	fc  *list.List // connections to fill from (*Conn)
 
+	busy map[*Conn]int // running requests per connection
+
 	requesting int // number of running requests
 }
 
@@ -244,7 +246,7 @@ func (f *fillHead) handleSuccess(c *Conn) {
 	f.node().Debugln(FillPin, "[fill] handleSuccess", c.String())
 
 	f.requesting--
-	f.fc.PushBack(c) // push
+	f.busy[c]-- // release
 	f.triggerRequest()
 }
 
@@ -253,6 +255,12 @@ func (f *fillHead) handleRequestFailure(fr failedRequest) {
 		fr.key.Hex()[:7])
 
 	f.requesting--
+	f.busy[fr.c]-- // release
+
+	// don't request from the connection anymore
+	// for this Root (it's removed from f.cs too
+	// if it's closed or sends invalid responses)
+	f.removeFillConn(fr.c)
 
 	switch fr.err {
 	case ErrInvalidResponse:
@@ -308,7 +316,7 @@ func (f *fillHead) handleReceivedRoot(cr connRoot) {
 				return // the same seq, but another hash (drop)
 			}
 
-			f.fc.PushBack(cr.c) // add to filling connections
+			f.addFillConn(cr.c) // add to filling connections
 			f.triggerRequest()
 			return
 		}
@@ -385,6 +393,7 @@ func (f *fillHead) createFiller(cr connRoot) {
 
 	f.rqo = list.New()                   // create list of keys
 	f.fc = f.cs.buildConnsList(cr.r.Seq) // create list of connections
+	f.busy = make(map[*Conn]int)         // running requests
 
 	f.await.Add(1)
 	go f.runFiller(f.f, cr.co, cr.ch)
@@ -426,7 +435,7 @@ func (f *fillHead) closeFiller() {
 
 	f.f.Close()
 
-	f.rqo, f.fc, f.rq = nil, nil, nil
+	f.rqo, f.fc, f.rq, f.busy = nil, nil, nil, nil
 
 	f.r = connRoot{}
 	f.requesting = 0
@@ -438,11 +447,13 @@ func (f *fillHead) handleFillingResult(err error) {
 	f.node().Debugf(FillPin, "handleFillingResult %s: %v", f.r.r.Short(), err)
 
 	if err == nil {
-		f.node().onRootFilled(f.r.r)     // callback
-		f.favg.Add(time.Now().Sub(f.tp)) // average time
-		f.cs.moveForward(f.r.r.Seq + 1)  // move forward
+		f.node().onRootFilled(f.r.r)               // callback
+		f.node().onFeedRefs(f.r.c, f.f.FeedRefs()) // FeedRef policy
+		f.favg.Add(time.Now().Sub(f.tp))           // average time
+		f.cs.moveForward(f.r.r.Seq + 1)            // move forward
 	} else {
-		f.node().onFillingBreaks(f.r.r, err) // callback
+		f.node().onFillingBreaks(f.r.r, err)         // callback
+		f.node().handleEquivocationError(f.r.c, err) // fork
 	}
 
 	f.closeFiller() // close the filler and wait it's goroutines
@@ -476,42 +487,103 @@ func (f *fillHead) triggerRequest() {
 // request objects from anymore, neither busy nor idle
 func (f *fillHead) tryRequest() (fatal bool) {
 
-	if f.rqo.Len() == 0 {
-		return // no objects to request
-	}
+	for f.rqo.Len() > 0 {
+
+		var c = f.chooseConn()
+
+		if c == nil {
+			fatal = (f.fc.Len() == 0 && f.requesting == 0)
+			return // no connections or all connections are busy
+		}
+
+		var key = f.rqo.Remove(f.rqo.Front()).(cipher.SHA256) // unshift
+
+		// do the request
+
+		f.requesting++
+		f.busy[c]++
+
+		f.await.Add(1) // nodeHead.await
+		go f.request(c, f.r.r.Seq, key)
 
-	if f.fc.Len() == 0 {
-		fatal = (f.requesting == 0)
-		return // no connections to request from
 	}
 
-	var c = f.fc.Remove(f.fc.Front()).(*Conn) // unshift
+	return // no objects to request
+}
 
-	// the c can be removed from the head, let's check it out
+// chooseConn returns connection with best score that has
+// free slots for requests; it returns nil if all connections
+// are busy; it removes connections that are not known anymore
+func (f *fillHead) chooseConn() (c *Conn) {
 
-	for _, ok := f.cs[c]; ok == false; _, ok = f.cs[c] {
+	var cs = make([]*Conn, 0, f.fc.Len())
 
-		if f.fc.Len() == 0 {
-			fatal = (f.requesting == 0)
-			return // no connections
+	for e := f.fc.Front(); e != nil; {
+
+		var (
+			x    = e.Value.(*Conn)
+			next = e.Next()
+		)
+
+		// the x can be removed from the head, let's check it out
+		if _, ok := f.cs[x]; ok == false {
+			f.fc.Remove(e)
+		} else {
+			cs = append(cs, x)
 		}
 
-		c = f.fc.Remove(f.fc.Front()).(*Conn) // unshift next
+		e = next
 
 	}
 
-	var key = f.rqo.Remove(f.rqo.Front()).(cipher.SHA256) // unshift
+	var (
+		vals, best = connsValues(cs)
+		max        = f.node().config.MaxParallelRequests
+
+		cval float64
+	)
+
+	for _, x := range cs {
 
-	// do the request
+		var val = vals[x]
 
-	f.requesting++
+		if f.busy[x] >= parallelRequests(val, best, max) {
+			continue // busy
+		}
 
-	f.await.Add(1) // nodeHead.await
-	go f.request(c, f.r.r.Seq, key)
+		if c == nil || val > cval {
+			c, cval = x, val
+		}
+
+	}
 
 	return
 }
 
+// add connection to list of connections to fill from
+func (f *fillHead) addFillConn(c *Conn) {
+
+	for e := f.fc.Front(); e != nil; e = e.Next() {
+		if e.Value.(*Conn) == c {
+			return // already have
+		}
+	}
+
+	f.fc.PushBack(c)
+}
+
+// remove connection from list of connections to fill from
+func (f *fillHead) removeFillConn(c *Conn) {
+
+	for e := f.fc.Front(); e != nil; e = e.Next() {
+		if e.Value.(*Conn) == c {
+			f.fc.Remove(e)
+			return
+		}
+	}
+
+}
+
 // code readability
 func (f *fillHead) node() *Node {
 	return f.n.fs.n
@@ -524,22 +596,29 @@ func (f *fillHead) request(c *Conn, seq uint64, key cipher.SHA256) {
 	f.node().Debugf(FillPin, "[fill] request from [%s] %d %s", c.String(), seq,
 		key.Hex()[:7])
 
-	var reply, err = c.sendRequest(&msg.RqObject{Key: key})
+	var (
+		tp         = time.Now()
+		reply, err = c.sendRequest(&msg.RqObject{Key: key})
+	)
 
 	if err != nil {
+		c.score.failure(err)
 		f.failureq <- failedRequest{c, seq, key, err}
 		return
 	}
 
 	switch x := reply.(type) {
 	case *msg.Object:
-		var rk = cipher.SumSHA256(x.Value)
+		var rk = c.n.c.Sum(x.Value)
 
 		if rk != key {
+			c.score.failure(ErrInvalidResponse)
 			f.failureq <- failedRequest{c, seq, key, ErrInvalidResponse}
 			return
 		}
 
+		c.score.success(time.Now().Sub(tp), len(x.Value))
+
 		// incremented by the Want call(s)
 		if _, err := f.node().c.SetWanted(key, x.Value); err != nil {
 			f.node().Fatal("DB failure:", err)
@@ -549,8 +628,10 @@ func (f *fillHead) request(c *Conn, seq uint64, key cipher.SHA256) {
 		f.successq <- c
 
 	case *msg.Err:
+		c.score.failure(errors.New(x.Err))
 		f.failureq <- failedRequest{c, seq, key, errors.New(x.Err)}
 	default:
+		c.score.failure(ErrInvalidResponse)
 		f.failureq <- failedRequest{c, seq, key, ErrInvalidResponse}
 	}
 
@@ -577,14 +658,26 @@ func (f *fillHead) handleRequests(keys []cipher.SHA256) {
 
 	// chose connection to request from (so it can be only one connection
 	// from which related Root received in most cases) and response with
-	// nil if no such connection
+	// nil if no such connection; if there are many such connections, then
+	// connection with best score is used
 
-	var c *Conn
+	var (
+		c    *Conn
+		cval float64
+		cs   []*Conn
+	)
 
 	for e := f.fc.Front(); e != nil; e = e.Next() {
 		if x := e.Value.(*Conn); x.features&msg.CreatedHashes != 0 {
-			c = x // found
-			break
+			cs = append(cs, x)
+		}
+	}
+
+	var vals, _ = connsValues(cs)
+
+	for _, x := range cs {
+		if c == nil || vals[x] > cval {
+			c, cval = x, vals[x]
 		}
 	}
 
@@ -593,9 +686,13 @@ func (f *fillHead) handleRequests(keys []cipher.SHA256) {
 		return
 	}
 
-	var reply, err = c.sendRequest(&msg.RqObjects{Keys: keys})
+	var (
+		tp         = time.Now()
+		reply, err = c.sendRequest(&msg.RqObjects{Keys: keys})
+	)
 
 	if err != nil {
+		c.score.failure(err)
 		f.rps <- nil
 		return
 	}
@@ -610,9 +707,16 @@ func (f *fillHead) handleRequests(keys []cipher.SHA256) {
 
 		// TODO (kostyarin): do it using wrapper around [][]byte
 
+		var size int
+		for _, val := range x.Values {
+			size += len(val)
+		}
+		c.score.success(time.Now().Sub(tp), size)
+
 		f.rps <- x.Values
 
 	default:
+		c.score.failure(ErrInvalidResponse)
 		f.rps <- nil // failure
 
 		// TODO (kostyarin): see TODO above
@@ -702,23 +806,32 @@ func (k knownRoots) moveForward(seq uint64) {
 
 }
 
-// build list of connections to fill Root with given seq
+// build list of connections to fill Root with given seq,
+// the list is sorted by score of connections (best first)
 func (k knownRoots) buildConnsList(seq uint64) (l *list.List) {
 
-	l = list.New()
+	var cs []*Conn
 
 	for c, known := range k {
 
 		for _, ks := range known {
 
 			if ks == seq {
-				l.PushBack(c)
+				cs = append(cs, c)
 				break
 			}
 
 		}
 	}
 
+	sortConnsByScore(cs)
+
+	l = list.New()
+
+	for _, c := range cs {
+		l.PushBack(c)
+	}
+
 	return
 }
 
@@ -0,0 +1,170 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A MaxHeadsPolicy describes what the Node does with
+// Root objects of new heads of a feed, if the feed
+// reaches MaxHeads limit (see Config)
+type MaxHeadsPolicy int
+
+// possible policies
+const (
+	// MaxHeadsDropEarliest is default policy, the Node
+	// stops filling of a head Root objects of which
+	// received most early. Heads with labels and the
+	// explicitly selected active head are never
+	// dropped (see skyobject.Index.SetHeadLabel and
+	// skyobject.Index.SetActiveHead). If all heads are
+	// protected this way, then new head is rejected
+	MaxHeadsDropEarliest MaxHeadsPolicy = iota
+	// MaxHeadsRejectNew turns on rejecting Root
+	// objects of new heads, keeping heads the Node
+	// already fills
+	MaxHeadsRejectNew
+)
+
+// String implements fmt.Stringer interface
+func (m MaxHeadsPolicy) String() string {
+	switch m {
+	case MaxHeadsDropEarliest:
+		return "drop-earliest"
+	case MaxHeadsRejectNew:
+		return "reject-new"
+	}
+	return fmt.Sprintf("MaxHeadsPolicy<%d>", m)
+}
+
+// Set implements flag.Value interface
+func (m *MaxHeadsPolicy) Set(policy string) (err error) {
+	switch policy {
+	case "drop-earliest":
+		*m = MaxHeadsDropEarliest
+	case "reject-new":
+		*m = MaxHeadsRejectNew
+	default:
+		err = errors.New("unknown MaxHeads policy: " + policy)
+	}
+	return
+}
+
+// Validate the MaxHeadsPolicy
+func (m MaxHeadsPolicy) Validate() (err error) {
+	if m < MaxHeadsDropEarliest || m > MaxHeadsRejectNew {
+		err = fmt.Errorf("invalid MaxHeadsPolicy %d", m)
+	}
+	return
+}
+
+// isProtected returns true if given head
+// can't be dropped by the MaxHeads limit
+func (n *nodeFeed) isProtected(nonce uint64) bool {
+
+	var info, err = n.node().c.HeadInfo(n.this, nonce)
+
+	if err != nil {
+		return false // the head doesn't exist in DB yet
+	}
+
+	return info.Label != "" || info.Active == true
+}
+
+// evictHead drops a head regarding MaxHeadsPolicy
+// to release a place for a new head; it returns
+// false if the new head should be rejected
+func (n *nodeFeed) evictHead() (ok bool) {
+
+	if n.node().config.MaxHeadsPolicy == MaxHeadsRejectNew {
+		return false
+	}
+
+	for i, nonce := range n.ho {
+
+		if n.isProtected(nonce) == true {
+			continue
+		}
+
+		n.ho = append(n.ho[:i], n.ho[i+1:]...)
+
+		var nh = n.hs[nonce]
+
+		nh.errq <- ErrMaxHeadsLimit
+		nh.close() // wait
+
+		delete(n.hs, nonce) // remove
+
+		n.node().Debugf(FeedPin, "[%s] head %d dropped by MaxHeads limit",
+			n.this.Hex()[:7], nonce)
+
+		return true
+	}
+
+	return false // all heads are protected
+}
+
+// ForkHead creates new head of given feed. The first
+// Root of the head has the same Refs, Descriptor and
+// Registry as Root with given nonce and seq. The new
+// Root is published. The Node should have secret key
+// of the feed in its Keystore. See also ForkHead
+// method of the skyobject.Container
+func (n *Node) ForkHead(
+	feed cipher.PubKey, //  : feed
+	nonce uint64, //        : head of Root to fork
+	seq uint64, //          : seq of Root to fork
+) (
+	r *registry.Root, //    : first Root of the new head
+	err error, //           : an error
+) {
+
+	var sk cipher.SecKey
+	if sk, err = n.ks.secKey(feed); err != nil {
+		return
+	}
+
+	if err = n.Share(feed); err != nil {
+		return
+	}
+
+	var heads []uint64
+	if heads, err = n.c.Heads(feed); err != nil {
+		return
+	}
+
+	if n.config.MaxHeads > 0 && len(heads) >= n.config.MaxHeads {
+		return nil, ErrMaxHeadsLimit
+	}
+
+	var src *registry.Root
+	if src, err = n.c.Root(feed, nonce, seq); err != nil {
+		return
+	}
+
+	var reg *registry.Registry
+	if reg, err = n.registry(src.Reg); err != nil {
+		return
+	}
+
+	var up *skyobject.Unpack
+	if up, err = n.c.Unpack(sk, reg); err != nil {
+		return
+	}
+	defer up.Close()
+
+	if r, err = n.c.ForkHead(up, src, 0); err != nil {
+		return
+	}
+
+	if err = n.Publish(r, up); err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,770 @@
+package node
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// An HTTPServer represents optional HTTP/JSON gateway
+// of the Node. The gateway exposes the same operations
+// as the RPCServer, but using JSON. Thus, it can be used
+// by browsers and services not written in Go. Arguments
+// of requests are passed by URL query. All hashes and
+// public keys are hex-encoded. Endpoints are
+//
+//     GET  /node/feeds
+//     POST /node/share?feed=<pk>
+//     POST /node/dont_share?feed=<pk>
+//     GET  /node/is_sharing?feed=<pk>
+//     GET  /node/connections
+//     GET  /node/connections_of_feed?feed=<pk>
+//     GET  /node/stat
+//
+//     POST /tcp/connect?address=<address>
+//     POST /tcp/disconnect?address=<address>
+//     POST /tcp/subscribe?address=<address>&feed=<pk>
+//     POST /tcp/unsubscribe?address=<address>&feed=<pk>
+//     GET  /tcp/remote_feeds?address=<address>
+//     GET  /tcp/address
+//
+//     (the same for /udp/)
+//
+//     GET  /root/show?feed=<pk>&nonce=<nonce>&seq=<seq>
+//     GET  /root/tree?feed=<pk>&nonce=<nonce>&seq=<seq>
+//     GET  /root/last?feed=<pk>
+//
+//     GET  /object/<hash>
+//     GET  /blob/<hash of registry.Refs>
+//
+// The /object/ returns encoded object as is. The /blob/
+// returns data splitted by (*skyobject.Container).Split
+// and supports HTTP Range requests. Both endpoints are
+// read-only and obtain objects from local DB only.
+//
+// Errors are returned as JSON object with "error" field.
+//
+// A POST request should have "Content-Type: application/json"
+// header (the body is ignored and can be empty). Such request
+// can't be sent by a foreign web page without CORS preflight.
+// If a POST request has Origin header, then the origin should
+// be the same host or should be listed in HTTPConfig.Origins.
+// If HTTPConfig.Token is set, then a POST request should have
+// "Authorization: Bearer <token>" header. Otherwise, the
+// request is rejected with 403 or 415 status code.
+//
+// It's possible to add own handler using Handle method
+type HTTPServer struct {
+	l    net.Listener   // underlying listener
+	s    *http.Server   //
+	mux  *http.ServeMux //
+	n    *Node          // back reference
+	conf *HTTPConfig    // origins and token
+}
+
+// create HTTP server
+func (n *Node) newHTTP() (h *HTTPServer) {
+	h = new(HTTPServer)
+	h.n = n
+	h.mux = http.NewServeMux()
+	h.s = &http.Server{Handler: h.mux}
+	return
+}
+
+// Handle allows to add own handler. See
+// https://godoc.org/net/http#ServeMux.Handle
+// for details
+func (h *HTTPServer) Handle(pattern string, handler http.Handler) {
+	h.mux.Handle(pattern, handler)
+}
+
+// Listen used to start HTTP listener using provided configurations
+func (h *HTTPServer) Listen(conf *HTTPConfig) (err error) {
+
+	h.conf = conf
+	h.routes()
+
+	if conf.TLS == nil {
+		h.l, err = net.Listen("tcp", conf.Listen) // TCP
+	} else {
+		h.l, err = tls.Listen("tcp", conf.Listen, conf.TLS) // TLS over TCP
+	}
+
+	if err != nil {
+		return
+	}
+
+	h.n.await.Add(1)
+	go h.run()
+
+	return
+}
+
+func (h *HTTPServer) run() {
+	defer h.n.await.Done()
+	h.s.Serve(h.l)
+}
+
+// Address of related HTTP listener or blank string
+// if the HTTP server is not listening
+func (h *HTTPServer) Address() (address string) {
+	if h.l != nil {
+		address = h.l.Addr().String()
+	}
+	return
+}
+
+// Close the HTTP server
+func (h *HTTPServer) Close() (err error) {
+	if h.l != nil {
+		err = h.s.Close()
+	}
+	return
+}
+
+func (h *HTTPServer) routes() {
+
+	var (
+		node = &RPC{h.n}
+		tcp  = &TCPRPC{h.n}
+		udp  = &UDPRPC{h.n}
+		root = &RootRPC{h.n}
+	)
+
+	// node
+
+	h.handle(http.MethodGet, "/node/feeds", func(*http.Request) (
+		reply interface{}, err error) {
+
+		var fs []cipher.PubKey
+		err = node.Feeds(struct{}{}, &fs)
+		return fs, err
+	})
+
+	h.handle(http.MethodPost, "/node/share", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var pk cipher.PubKey
+		if pk, err = argFeed(r); err != nil {
+			return
+		}
+		return nil, node.Share(pk, nil)
+	})
+
+	h.handle(http.MethodPost, "/node/dont_share", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var pk cipher.PubKey
+		if pk, err = argFeed(r); err != nil {
+			return
+		}
+		return nil, node.DontShare(pk, nil)
+	})
+
+	h.handle(http.MethodGet, "/node/is_sharing", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var pk cipher.PubKey
+		if pk, err = argFeed(r); err != nil {
+			return
+		}
+		var yep bool
+		err = node.IsSharing(pk, &yep)
+		return yep, err
+	})
+
+	h.handle(http.MethodGet, "/node/connections", func(*http.Request) (
+		reply interface{}, err error) {
+
+		var cs []string
+		err = node.Connections(struct{}{}, &cs)
+		return cs, err
+	})
+
+	h.handle(http.MethodGet, "/node/connections_of_feed", func(
+		r *http.Request) (reply interface{}, err error) {
+
+		var pk cipher.PubKey
+		if pk, err = argFeed(r); err != nil {
+			return
+		}
+		var cs []string
+		err = node.ConnectionsOfFeed(pk, &cs)
+		return cs, err
+	})
+
+	h.handle(http.MethodGet, "/node/stat", func(*http.Request) (
+		reply interface{}, err error) {
+
+		var stat Stat
+		err = node.Stat(struct{}{}, &stat)
+		return stat, err
+	})
+
+	// tcp and udp
+
+	h.transport("tcp", tcp)
+	h.transport("udp", udp)
+
+	// root
+
+	h.handle(http.MethodGet, "/root/show", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var rs RootSelector
+		if rs, err = argRoot(r); err != nil {
+			return
+		}
+		var z registry.Root
+		err = root.Show(rs, &z)
+		return z, err
+	})
+
+	h.handle(http.MethodGet, "/root/tree", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var rs RootSelector
+		if rs, err = argRoot(r); err != nil {
+			return
+		}
+		var tree string
+		err = root.Tree(rs, &tree)
+		return tree, err
+	})
+
+	h.handle(http.MethodGet, "/root/last", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var pk cipher.PubKey
+		if pk, err = argFeed(r); err != nil {
+			return
+		}
+		var z registry.Root
+		err = root.Last(pk, &z)
+		return z, err
+	})
+
+	// objects
+
+	h.mux.HandleFunc("/object/", h.object)
+	h.mux.HandleFunc("/blob/", h.blob)
+
+}
+
+// a transport represents TCPRPC and UDPRPC
+type transportRPC interface {
+	Connect(address string, _ *struct{}) (err error)
+	Disconnect(address string, _ *struct{}) (err error)
+	Subscribe(cf ConnFeed, _ *struct{}) (err error)
+	Unsubscribe(cf ConnFeed, _ *struct{}) (err error)
+	RemoteFeeds(address string, rfs *[]cipher.PubKey) (err error)
+	Address(_ struct{}, address *string) (_ error)
+}
+
+func (h *HTTPServer) transport(name string, t transportRPC) {
+
+	var pfx = "/" + name + "/"
+
+	h.handle(http.MethodPost, pfx+"connect", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var address string
+		if address, err = argAddress(r); err != nil {
+			return
+		}
+		return nil, t.Connect(address, nil)
+	})
+
+	h.handle(http.MethodPost, pfx+"disconnect", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var address string
+		if address, err = argAddress(r); err != nil {
+			return
+		}
+		return nil, t.Disconnect(address, nil)
+	})
+
+	h.handle(http.MethodPost, pfx+"subscribe", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var cf ConnFeed
+		if cf, err = argConnFeed(r); err != nil {
+			return
+		}
+		return nil, t.Subscribe(cf, nil)
+	})
+
+	h.handle(http.MethodPost, pfx+"unsubscribe", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var cf ConnFeed
+		if cf, err = argConnFeed(r); err != nil {
+			return
+		}
+		return nil, t.Unsubscribe(cf, nil)
+	})
+
+	h.handle(http.MethodGet, pfx+"remote_feeds", func(r *http.Request) (
+		reply interface{}, err error) {
+
+		var address string
+		if address, err = argAddress(r); err != nil {
+			return
+		}
+		var rfs []cipher.PubKey
+		err = t.RemoteFeeds(address, &rfs)
+		return rfs, err
+	})
+
+	h.handle(http.MethodGet, pfx+"address", func(*http.Request) (
+		reply interface{}, err error) {
+
+		var address string
+		err = t.Address(struct{}{}, &address)
+		return address, err
+	})
+
+}
+
+// GET /object/<hash>
+func (h *HTTPServer) object(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		httpError(w, errMethodNotAllowed)
+		return
+	}
+
+	var (
+		key cipher.SHA256
+		val []byte
+		err error
+	)
+
+	if key, err = argPathHash(r, "/object/"); err != nil {
+		httpError(w, err)
+		return
+	}
+
+	if val, _, err = h.n.c.Get(key, 0); err != nil {
+		httpError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(val)))
+	w.Write(val)
+}
+
+// GET /blob/<hash>
+func (h *HTTPServer) blob(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		httpError(w, errMethodNotAllowed)
+		return
+	}
+
+	var (
+		key cipher.SHA256
+		b   *skyobject.Blob
+		err error
+	)
+
+	if key, err = argPathHash(r, "/blob/"); err != nil {
+		httpError(w, err)
+		return
+	}
+
+	if b, err = h.n.c.Blob(key); err != nil {
+		httpError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+
+	// the content is immutable, thus the hash is ETag
+	w.Header().Set("ETag", `"`+key.Hex()+`"`)
+
+	http.ServeContent(w, r, "", time.Time{}, b)
+}
+
+//
+// handlers helpers
+//
+
+var (
+	errMethodNotAllowed = errors.New("method not allowed")
+	errForbidden        = errors.New("forbidden")
+	errNotJSON          = errors.New(
+		"unsupported media type, application/json required")
+)
+
+// an httpArgError represents invalid argument error
+type httpArgError struct {
+	name string
+	err  error
+}
+
+func (h *httpArgError) Error() string {
+	return "invalid argument " + h.name + ": " + h.err.Error()
+}
+
+// status code of given error
+func httpStatus(err error) (code int) {
+
+	if _, ok := err.(*httpArgError); ok == true {
+		return http.StatusBadRequest
+	}
+
+	switch err {
+	case errMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case errForbidden:
+		return http.StatusForbidden
+	case errNotJSON:
+		return http.StatusUnsupportedMediaType
+	case data.ErrNotFound:
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
+
+func httpError(w http.ResponseWriter, err error) {
+	httpWriteJSON(w, httpStatus(err), map[string]string{
+		"error": err.Error(),
+	})
+}
+
+func httpWriteJSON(w http.ResponseWriter, code int, reply interface{}) {
+
+	var val, err = json.Marshal(jsonValue(reflect.ValueOf(reply)))
+
+	if err != nil {
+		code = http.StatusInternalServerError
+		val, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(val)
+}
+
+// handler that returns JSON
+type httpFunc func(r *http.Request) (reply interface{}, err error)
+
+func (h *HTTPServer) handle(method, path string, fn httpFunc) {
+
+	h.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != method {
+			httpError(w, errMethodNotAllowed)
+			return
+		}
+
+		var (
+			reply interface{}
+			err   error
+		)
+
+		if method == http.MethodPost {
+			if err = h.allow(r); err != nil {
+				httpError(w, err)
+				return
+			}
+		}
+
+		reply, err = fn(r)
+
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+
+		if reply == nil {
+			reply = struct{}{} // {}
+		}
+
+		httpWriteJSON(w, http.StatusOK, reply)
+	})
+
+}
+
+// allow checks state-changing request against CSRF
+func (h *HTTPServer) allow(r *http.Request) (err error) {
+
+	var ct = r.Header.Get("Content-Type")
+
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i] // strip charset
+	}
+
+	if strings.TrimSpace(ct) != "application/json" {
+		return errNotJSON
+	}
+
+	if h.allowOrigin(r.Header.Get("Origin"), r.Host) == false {
+		return errForbidden
+	}
+
+	if h.conf != nil && h.conf.Token != "" {
+		if r.Header.Get("Authorization") != "Bearer "+h.conf.Token {
+			return errForbidden
+		}
+	}
+
+	return
+}
+
+// allowOrigin returns true if given origin
+// is allowed to perform state-changing requests
+func (h *HTTPServer) allowOrigin(origin, host string) (ok bool) {
+
+	if origin == "" {
+		return true // not a browser
+	}
+
+	if origin == "http://"+host || origin == "https://"+host {
+		return true // the same host
+	}
+
+	if h.conf == nil {
+		return false
+	}
+
+	for _, allowed := range h.conf.Origins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+
+	return false
+}
+
+//
+// arguments
+//
+
+func argString(r *http.Request, name string) (val string, err error) {
+	if val = r.URL.Query().Get(name); val == "" {
+		err = &httpArgError{name, errors.New("missing")}
+	}
+	return
+}
+
+func argFeed(r *http.Request) (pk cipher.PubKey, err error) {
+
+	var val string
+	if val, err = argString(r, "feed"); err != nil {
+		return
+	}
+
+	if pk, err = cipher.PubKeyFromHex(val); err != nil {
+		err = &httpArgError{"feed", err}
+	}
+
+	return
+}
+
+func argAddress(r *http.Request) (address string, err error) {
+	return argString(r, "address")
+}
+
+func argConnFeed(r *http.Request) (cf ConnFeed, err error) {
+
+	if cf.Address, err = argAddress(r); err != nil {
+		return
+	}
+
+	cf.Feed, err = argFeed(r)
+	return
+}
+
+func argUint(r *http.Request, name string) (u uint64, err error) {
+
+	var val string
+	if val, err = argString(r, name); err != nil {
+		return
+	}
+
+	if u, err = strconv.ParseUint(val, 10, 64); err != nil {
+		err = &httpArgError{name, err}
+	}
+
+	return
+}
+
+func argRoot(r *http.Request) (rs RootSelector, err error) {
+
+	if rs.Feed, err = argFeed(r); err != nil {
+		return
+	}
+
+	if rs.Nonce, err = argUint(r, "nonce"); err != nil {
+		return
+	}
+
+	rs.Seq, err = argUint(r, "seq")
+	return
+}
+
+// hash after given prefix of path
+func argPathHash(r *http.Request, pfx string) (key cipher.SHA256, err error) {
+
+	var val = strings.TrimPrefix(r.URL.Path, pfx)
+
+	if key, err = cipher.SHA256FromHex(val); err != nil {
+		err = &httpArgError{"hash", err}
+	}
+
+	return
+}
+
+//
+// JSON
+//
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// jsonValue converts given value to JSON friendly
+// value. The jsonValue encodes byte arrays (hashes,
+// public keys, signatures, etc) to hex strings,
+// including keys of maps. Because encoding/json
+// can't use arrays as keys of maps, and encodes
+// byte arrays as arrays of numbers
+func jsonValue(v reflect.Value) (j interface{}) {
+
+	if v.IsValid() == false {
+		return nil
+	}
+
+	if v.Type().Implements(jsonMarshalerType) == true {
+		return v.Interface() // e.g. time.Time
+	}
+
+	switch v.Kind() {
+
+	case reflect.Ptr, reflect.Interface:
+
+		if v.IsNil() == true {
+			return nil
+		}
+		return jsonValue(v.Elem())
+
+	case reflect.Array:
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			var b = make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hex.EncodeToString(b)
+		}
+		return jsonSlice(v)
+
+	case reflect.Slice:
+
+		if v.IsNil() == true {
+			return nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes() // base64
+		}
+		return jsonSlice(v)
+
+	case reflect.Map:
+
+		if v.IsNil() == true {
+			return nil
+		}
+
+		var m = make(map[string]interface{}, v.Len())
+
+		for _, k := range v.MapKeys() {
+			m[jsonKey(k)] = jsonValue(v.MapIndex(k))
+		}
+
+		return m
+
+	case reflect.Struct:
+
+		var m = make(map[string]interface{}, v.NumField())
+		jsonStruct(v, m)
+		return m
+
+	}
+
+	return v.Interface()
+}
+
+func jsonSlice(v reflect.Value) (s []interface{}) {
+
+	s = make([]interface{}, 0, v.Len())
+
+	for i := 0; i < v.Len(); i++ {
+		s = append(s, jsonValue(v.Index(i)))
+	}
+
+	return
+}
+
+func jsonKey(k reflect.Value) (key string) {
+
+	if s, ok := jsonValue(k).(string); ok == true {
+		return s
+	}
+
+	var val, _ = json.Marshal(k.Interface())
+	return string(val)
+}
+
+// fields of struct to given map, fields of
+// embedded structures are joined
+func jsonStruct(v reflect.Value, m map[string]interface{}) {
+
+	var t = v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+
+		var (
+			sf = t.Field(i)
+			fv = v.Field(i)
+		)
+
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+
+		if sf.Anonymous == true {
+
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() == true {
+					continue
+				}
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() == reflect.Struct {
+				jsonStruct(fv, m)
+				continue
+			}
+
+		}
+
+		m[sf.Name] = jsonValue(fv)
+
+	}
+
+}
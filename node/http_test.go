@@ -0,0 +1,195 @@
+package node
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func Test_jsonValue(t *testing.T) {
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+
+		val = jsonValue(reflect.ValueOf(struct {
+			Feed  cipher.PubKey
+			Feeds map[cipher.PubKey]int
+			Data  []byte
+			hide  int
+		}{
+			Feed:  pk,
+			Feeds: map[cipher.PubKey]int{pk: 1},
+			Data:  []byte("data"),
+		}))
+
+		m, ok = val.(map[string]interface{})
+	)
+
+	if ok == false {
+		t.Fatalf("wrong type %T", val)
+	}
+
+	if m["Feed"] != pk.Hex() {
+		t.Error("wrong Feed:", m["Feed"])
+	}
+
+	if fs, ok := m["Feeds"].(map[string]interface{}); ok == false {
+		t.Errorf("wrong type of Feeds %T", m["Feeds"])
+	} else if fs[pk.Hex()] != 1 {
+		t.Error("wrong Feeds:", fs)
+	}
+
+	if _, ok := m["hide"]; ok == true {
+		t.Error("unexported field encoded")
+	}
+
+	if _, err := json.Marshal(val); err != nil {
+		t.Error(err)
+	}
+
+}
+
+func Test_http(t *testing.T) {
+
+	var n = getTestNodeNotListen("http")
+	defer n.Close()
+
+	var h = n.newHTTP()
+	h.routes()
+
+	var serve = func(method, url string) (rec *httptest.ResponseRecorder) {
+		var req = httptest.NewRequest(method, url, nil)
+		req.Header.Set("Content-Type", "application/json")
+		rec = httptest.NewRecorder()
+		h.mux.ServeHTTP(rec, req)
+		return
+	}
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		rec   = serve(http.MethodPost, "/node/share?feed="+pk.Hex())
+	)
+
+	if rec.Code != http.StatusOK {
+		t.Fatal("wrong status:", rec.Code, rec.Body.String())
+	}
+
+	if n.IsSharing(pk) == false {
+		t.Error("not shared")
+	}
+
+	if rec = serve(http.MethodGet, "/node/feeds"); rec.Code != http.StatusOK {
+		t.Fatal("wrong status:", rec.Code, rec.Body.String())
+	}
+
+	var feeds []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &feeds); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(feeds) != 1 || feeds[0] != pk.Hex() {
+		t.Error("wrong feeds:", feeds)
+	}
+
+	// errors
+
+	for _, tc := range []struct {
+		method, url string
+		code        int
+	}{
+		{http.MethodGet, "/node/share?feed=" + pk.Hex(),
+			http.StatusMethodNotAllowed},
+		{http.MethodPost, "/node/share?feed=xyz",
+			http.StatusBadRequest},
+		{http.MethodGet, "/object/" + cipher.SHA256{1}.Hex(),
+			http.StatusNotFound},
+	} {
+		if rec = serve(tc.method, tc.url); rec.Code != tc.code {
+			t.Error("wrong status:", tc.url, rec.Code)
+		}
+	}
+
+}
+
+func Test_httpCSRF(t *testing.T) {
+
+	var n = getTestNodeNotListen("http-csrf")
+	defer n.Close()
+
+	var h = n.newHTTP()
+	h.conf = &HTTPConfig{Origins: []string{"https://allowed.com"}}
+	h.routes()
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		url   = "/node/share?feed=" + pk.Hex()
+	)
+
+	var serve = func(header map[string]string) (code int) {
+		var req = httptest.NewRequest(http.MethodPost, url, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		var rec = httptest.NewRecorder()
+		h.mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	const ct = "Content-Type"
+
+	for _, tc := range []struct {
+		header map[string]string
+		code   int
+	}{
+		{nil, http.StatusUnsupportedMediaType},
+		{map[string]string{ct: "application/x-www-form-urlencoded"},
+			http.StatusUnsupportedMediaType},
+		{map[string]string{ct: "application/json",
+			"Origin": "https://evil.com"}, http.StatusForbidden},
+	} {
+		if code := serve(tc.header); code != tc.code {
+			t.Error("wrong status:", tc.header, code)
+		}
+	}
+
+	if n.IsSharing(pk) == true {
+		t.Fatal("shared by rejected request")
+	}
+
+	for _, origin := range []string{
+		"",                    // not a browser
+		"http://example.com",  // the same host
+		"https://allowed.com", // allowed
+	} {
+		var code = serve(map[string]string{
+			ct:       "application/json; charset=utf-8",
+			"Origin": origin,
+		})
+		if code != http.StatusOK {
+			t.Error("wrong status:", origin, code)
+		}
+	}
+
+	// token
+
+	h.conf.Token = "secret"
+
+	if code := serve(map[string]string{ct: "application/json"}); code !=
+		http.StatusForbidden {
+		t.Error("wrong status:", code)
+	}
+
+	var code = serve(map[string]string{
+		ct:              "application/json",
+		"Authorization": "Bearer secret",
+	})
+
+	if code != http.StatusOK {
+		t.Error("wrong status:", code)
+	}
+
+}
@@ -0,0 +1,208 @@
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A Keystore keeps secret keys of feeds the Node
+// can publish Root objects of (see PublishJSON).
+// The Keystore can be saved in a file (see
+// Config.Keystore), otherwise it's in-memory.
+// The file contains hex-encoded secret keys,
+// one per line. The Keystore designed to be used
+// through RPC, and secret keys never returned
+type Keystore struct {
+	mx   sync.Mutex
+	path string                          // file or blank
+	keys map[cipher.PubKey]cipher.SecKey // pk -> sk
+}
+
+// create Keystore loading keys
+// from given file if it exists
+func newKeystore(path string) (k *Keystore, err error) {
+
+	k = new(Keystore)
+	k.path = path
+	k.keys = make(map[cipher.PubKey]cipher.SecKey)
+
+	if path == "" {
+		return // in-memory
+	}
+
+	var val []byte
+	if val, err = ioutil.ReadFile(path); err != nil {
+		if os.IsNotExist(err) == true {
+			return k, nil // will be created
+		}
+		return nil, err
+	}
+
+	var sc = bufio.NewScanner(bytes.NewReader(val))
+
+	for sc.Scan() {
+
+		var line = strings.TrimSpace(sc.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") == true {
+			continue // skip blank lines and comments
+		}
+
+		var sk cipher.SecKey
+		if sk, err = cipher.SecKeyFromHex(line); err != nil {
+			return nil, err
+		}
+
+		k.keys[cipher.PubKeyFromSecKey(sk)] = sk
+	}
+
+	if err = sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// save keys to file, if any
+func (k *Keystore) save() (err error) {
+
+	if k.path == "" {
+		return // in-memory
+	}
+
+	var (
+		buf bytes.Buffer
+		sks = make([]string, 0, len(k.keys))
+	)
+
+	for _, sk := range k.keys {
+		sks = append(sks, sk.Hex())
+	}
+
+	sort.Strings(sks) // keep the file stable
+
+	for _, sk := range sks {
+		buf.WriteString(sk)
+		buf.WriteByte('\n')
+	}
+
+	if err = os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return
+	}
+
+	// write to temporary file and rename it, to
+	// don't lose the keys if the writing fails
+
+	var tmp = k.path + ".tmp"
+
+	if err = ioutil.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return
+	}
+
+	return os.Rename(tmp, k.path)
+}
+
+// Add given secret key to the Keystore
+func (k *Keystore) Add(sk cipher.SecKey) (pk cipher.PubKey, err error) {
+
+	if err = sk.Verify(); err != nil {
+		return
+	}
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	pk = cipher.PubKeyFromSecKey(sk)
+
+	if _, ok := k.keys[pk]; ok == true {
+		return // already have
+	}
+
+	k.keys[pk] = sk
+
+	if err = k.save(); err != nil {
+		delete(k.keys, pk)
+	}
+
+	return
+}
+
+// Generate new key pair, add the secret key to
+// the Keystore and return public key
+func (k *Keystore) Generate() (pk cipher.PubKey, err error) {
+	var _, sk = cipher.GenerateKeyPair()
+	return k.Add(sk)
+}
+
+// Remove secret key of given feed from the Keystore
+func (k *Keystore) Remove(pk cipher.PubKey) (err error) {
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	var sk, ok = k.keys[pk]
+
+	if ok == false {
+		return // not found
+	}
+
+	delete(k.keys, pk)
+
+	if err = k.save(); err != nil {
+		k.keys[pk] = sk
+	}
+
+	return
+}
+
+// Has returns true if the Keystore
+// has secret key of given feed
+func (k *Keystore) Has(pk cipher.PubKey) (ok bool) {
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	_, ok = k.keys[pk]
+	return
+}
+
+// List of public keys of the Keystore
+func (k *Keystore) List() (pks []cipher.PubKey) {
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	pks = make([]cipher.PubKey, 0, len(k.keys))
+
+	for pk := range k.keys {
+		pks = append(pks, pk)
+	}
+
+	sort.Slice(pks, func(i, j int) bool {
+		return bytes.Compare(pks[i][:], pks[j][:]) < 0
+	})
+
+	return
+}
+
+// secret key of given feed
+func (k *Keystore) secKey(pk cipher.PubKey) (sk cipher.SecKey, err error) {
+
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	var ok bool
+	if sk, ok = k.keys[pk]; ok == false {
+		err = ErrNoSecretKey
+	}
+
+	return
+}
@@ -0,0 +1,69 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestKeystore(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-keystore")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path = filepath.Join(dir, "keys")
+		ks   *Keystore
+	)
+
+	ks, err = newKeystore(path)
+	assertNil(t, err)
+
+	assertTrue(t, len(ks.List()) == 0, "not empty")
+
+	var gk cipher.PubKey
+	gk, err = ks.Generate()
+	assertNil(t, err)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		ak     cipher.PubKey
+	)
+
+	ak, err = ks.Add(sk)
+	assertNil(t, err)
+	assertTrue(t, ak == pk, "wrong public key")
+
+	assertTrue(t, ks.Has(gk) == true, "missing generated key")
+	assertTrue(t, ks.Has(pk) == true, "missing added key")
+
+	// load
+
+	ks, err = newKeystore(path)
+	assertNil(t, err)
+
+	assertTrue(t, len(ks.List()) == 2, "wrong number of keys")
+
+	var xk cipher.SecKey
+	xk, err = ks.secKey(pk)
+	assertNil(t, err)
+	assertTrue(t, xk == sk, "wrong secret key")
+
+	// remove
+
+	assertNil(t, ks.Remove(gk))
+
+	ks, err = newKeystore(path)
+	assertNil(t, err)
+
+	assertTrue(t, ks.Has(gk) == false, "not removed")
+
+	if _, err = ks.secKey(gk); err != ErrNoSecretKey {
+		t.Error("unexpected error:", err)
+	}
+
+}
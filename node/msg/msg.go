@@ -60,9 +60,13 @@ import (
 //
 // 14. RqPreview   -> RqPreview (feed)
 //
+// # evidence of fork of a feed (push)
+//
+// 15. Equivocation <- Equivocation (a, b)
+//
 
 // Version is current protocol version
-const Version uint16 = 4
+const Version uint16 = 7
 
 // Features of a node
 type Features uint64
@@ -178,6 +182,10 @@ var (
 
 	_ Msg = &RqPreview{} // -> RqPreview (feed)
 
+	// fork
+
+	_ Msg = &Equivocation{} // <- Equivocation (a, b)
+
 )
 
 //
@@ -199,6 +207,7 @@ type Syn struct {
 	Protocol uint16        // version
 	NodeID   cipher.PubKey // node id
 	Features Features      // features flags
+	Hash     uint8         // content hash algorithm (data.Hash)
 	Data     []byte        // reserved for future
 }
 
@@ -214,6 +223,7 @@ func (s *Syn) Encode() []byte { return encode(s) }
 type Ack struct {
 	NodeID   cipher.PubKey // node id
 	Features Features      // features
+	Hash     uint8         // content hash algorithm (data.Hash)
 	Data     []byte        // reserved for future
 }
 
@@ -334,7 +344,8 @@ type Root struct {
 
 	Value []byte // encoded Root in person
 
-	Sig cipher.Sig // signature
+	Sig  cipher.Sig   // signature
+	Sigs []cipher.Sig // signatures of multisig Root
 
 	// optional fields, that depends on features
 
@@ -408,6 +419,67 @@ func (*Objects) Type() Type { return ObjectsType }
 // Encode the Objects
 func (o *Objects) Encode() []byte { return encode(o) }
 
+//
+// zero-copy encoding
+//
+
+// ObjectSize returns length of encoded
+// Object message with value of given size
+func ObjectSize(valSize int) int {
+	return 1 + 4 + valSize // type, length, value
+}
+
+// AppendObject appends encoded Object message to given
+// slice. Result is the same as result of the Encode
+// method of the Object{Value: val}, but the AppendObject
+// copies the val once, directly to the p. Use the
+// ObjectSize to allocate enough space
+func AppendObject(p, val []byte) []byte {
+	p = append(p, byte(ObjectType))
+	return appendBytes(p, val)
+}
+
+// ObjectsHeadSize is length of encoded Objects
+// message without values
+const ObjectsHeadSize = 1 + 4 // type, amount of values
+
+// ObjectsValueSize returns length of encoded value
+// of Objects message (see AppendObjectsValue)
+func ObjectsValueSize(valSize int) int {
+	return 4 + valSize // length, value
+}
+
+// AppendObjectsHead appends head of encoded Objects
+// message with given amount of values to given slice.
+// Append exactly given amount of values using the
+// AppendObjectsValue after. E.g. the
+//
+//     p = AppendObjectsHead(p, len(vals))
+//     for _, val := range vals {
+//         p = AppendObjectsValue(p, val)
+//     }
+//
+// is the same as the Objects{Values: vals}.Encode()
+func AppendObjectsHead(p []byte, amount int) []byte {
+	p = append(p, byte(ObjectsType))
+	return appendUint32(p, uint32(amount))
+}
+
+// AppendObjectsValue appends value of Objects
+// message. See AppendObjectsHead for details
+func AppendObjectsValue(p, val []byte) []byte {
+	return appendBytes(p, val)
+}
+
+func appendUint32(p []byte, u uint32) []byte {
+	return append(p, byte(u), byte(u>>8), byte(u>>16), byte(u>>24))
+}
+
+// length prefixed
+func appendBytes(p, val []byte) []byte {
+	return append(appendUint32(p, uint32(len(val))), val...)
+}
+
 //
 // preview
 //
@@ -423,6 +495,32 @@ func (*RqPreview) Type() Type { return RqPreviewType }
 // Encode the RqPreview
 func (r *RqPreview) Encode() []byte { return encode(r) }
 
+//
+// equivocation
+//
+
+// A SignedRoot represents encoded Root
+// with its signatures
+type SignedRoot struct {
+	Value []byte       // encoded Root
+	Sig   cipher.Sig   // signature
+	Sigs  []cipher.Sig // signatures of multisig Root
+}
+
+// An Equivocation represents evidence of fork of a
+// feed, two different signed Root objects with the
+// same feed, head and seq
+type Equivocation struct {
+	A SignedRoot // first Root
+	B SignedRoot // second Root
+}
+
+// Type implements Msg interface
+func (*Equivocation) Type() Type { return EquivocationType }
+
+// Encode the Equivocation
+func (e *Equivocation) Encode() []byte { return encode(e) }
+
 //
 // Type / Encode / Deocode / String()
 //
@@ -453,6 +551,8 @@ const (
 	ObjectsType   // 13
 
 	RqPreviewType // 14
+
+	EquivocationType // 15
 )
 
 // Type to string mapping
@@ -478,6 +578,8 @@ var msgTypeString = [...]string{
 	ObjectsType:   "Objects",
 
 	RqPreviewType: "RqPreview",
+
+	EquivocationType: "Equivocation",
 }
 
 // String implements fmt.Stringer interface
@@ -510,6 +612,8 @@ var forwardRegistry = [...]reflect.Type{
 	ObjectsType:   reflect.TypeOf(Objects{}),
 
 	RqPreviewType: reflect.TypeOf(RqPreview{}),
+
+	EquivocationType: reflect.TypeOf(Equivocation{}),
 }
 
 // An InvalidTypeError represents decoding error when
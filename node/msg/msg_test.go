@@ -0,0 +1,83 @@
+package msg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAppendObject(t *testing.T) {
+
+	for _, val := range [][]byte{nil, []byte("value")} {
+
+		var (
+			want = (&Object{Value: val}).Encode()
+			got  = AppendObject(make([]byte, 0, ObjectSize(len(val))), val)
+		)
+
+		if bytes.Compare(got, want) != 0 {
+			t.Errorf("wrong encoding %v, want %v", got, want)
+		}
+
+		if cap(got) != len(want) {
+			t.Error("wrong ObjectSize", cap(got), len(want))
+		}
+
+	}
+
+}
+
+func TestAppendObjectsHead(t *testing.T) {
+
+	var vals = [][]byte{[]byte("one"), nil, []byte("three")}
+
+	for _, vs := range [][][]byte{nil, vals} {
+
+		var size = ObjectsHeadSize
+		for _, val := range vs {
+			size += ObjectsValueSize(len(val))
+		}
+
+		var (
+			want = (&Objects{Values: vs}).Encode()
+			got  = AppendObjectsHead(make([]byte, 0, size), len(vs))
+		)
+
+		for _, val := range vs {
+			got = AppendObjectsValue(got, val)
+		}
+
+		if bytes.Compare(got, want) != 0 {
+			t.Errorf("wrong encoding %v, want %v", got, want)
+		}
+
+		if cap(got) != len(want) {
+			t.Error("wrong size", cap(got), len(want))
+		}
+
+	}
+
+}
+
+func BenchmarkObject_Encode(b *testing.B) {
+
+	var val = make([]byte, 32*1024)
+
+	b.SetBytes(int64(len(val)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		(&Object{Value: val}).Encode()
+	}
+}
+
+func BenchmarkAppendObject(b *testing.B) {
+
+	var val = make([]byte, 32*1024)
+
+	b.SetBytes(int64(len(val)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		AppendObject(make([]byte, 0, ObjectSize(len(val))), val)
+	}
+}
@@ -66,12 +66,39 @@ type Node struct {
 
 	fillavg *statutil.Duration // filling average
 
+	//
+	// events
+	//
+
+	events *eventHub // last events for RPC
+
+	//
+	// publishing
+	//
+
+	ks   *Keystore                                   // secret keys
+	regs map[registry.RegistryRef]*registry.Registry // registered
+	rmx  sync.Mutex                                  // lock for regs
+
+	//
+	// feed refs
+	//
+
+	frs  map[cipher.PubKey]struct{} // feeds followed by FeedRefs
+	frmx sync.Mutex                 // lock for frs
+
 	//
 	// rpc
 	//
 
 	rpc *RPCServer
 
+	//
+	// http
+	//
+
+	http *HTTPServer
+
 	//
 	//  closing
 	//
@@ -143,9 +170,18 @@ func NewNodeContainer(
 	n.config = conf              // keep
 	n.config.Config = c.Config() // actual
 
+	n.rollAvgSamples = n.config.Config.RollAvgSamples
+
 	n.fillavg = statutil.NewDuration(conf.Config.RollAvgSamples)
+	n.events = newEventHub(conf.MaxEvents)
+	n.regs = make(map[registry.RegistryRef]*registry.Registry)
+	n.frs = make(map[cipher.PubKey]struct{})
 	n.closeq = make(chan struct{})
 
+	if n.ks, err = newKeystore(conf.Keystore); err != nil {
+		return nil, err
+	}
+
 	//
 	// create
 	//
@@ -183,6 +219,19 @@ func NewNodeContainer(
 
 	}
 
+	// http
+
+	if conf.HTTP.Listen != "" {
+
+		n.http = n.newHTTP()
+
+		if err = n.http.Listen(&conf.HTTP); err != nil {
+			n.Close()
+			return
+		}
+
+	}
+
 	// discoveries
 
 	for _, address := range conf.TCP.Discovery {
@@ -232,6 +281,20 @@ func (n *Node) RPC() (r *RPCServer) {
 	return n.rpc
 }
 
+// HTTP returns related HTTPServer or nil
+// if HTTP disabled by Config. The HTTPServer
+// can be used to add own HTTP handler
+func (n *Node) HTTP() (h *HTTPServer) {
+	return n.http
+}
+
+// Keystore returns Keystore of the Node. The
+// Keystore keeps secret keys of feeds the Node
+// can publish Root objects of (see PublishJSON)
+func (n *Node) Keystore() (ks *Keystore) {
+	return n.ks
+}
+
 // Publish sends given Root object to peers that
 // subscribed to feed of the Root. The Publish used
 // to publish new Root objects. E.g. the Node sends
@@ -265,6 +328,7 @@ func (n *Node) Publish(r *registry.Root, up *skyobject.Unpack) (err error) {
 			Seq:   r.Seq,
 			Value: r.Encode(), // variable
 			Sig:   r.Sig,
+			Sigs:  r.Sigs,
 		}))
 
 		// free space to place created hashes or creatd objects
@@ -438,6 +502,7 @@ func (n *Node) onConnect(c *Conn) {
 
 	}
 
+	n.eventConn(c, EventConnect, nil)
 	n.Debugf(ConnEstPin, "[%s] established", c.Address())
 
 }
@@ -448,6 +513,8 @@ func (n *Node) onDisconenct(c *Conn, reason error) {
 		odc(c, reason)
 	}
 
+	n.eventConn(c, EventDisconnect, reason)
+
 	if reason != nil {
 		n.Debugf(CloseConnPin, "[%s] closed by %v", c.Address(), reason)
 	} else {
@@ -622,6 +689,8 @@ func (n *Node) onUnsubscribeRemote(c *Conn, feed cipher.PubKey) {
 		ousr(c, feed)
 	}
 
+	n.eventFeed(c, EventUnsubscribeRemote, feed)
+
 }
 
 // Feeds the Node share. The reply is read-only
@@ -640,6 +709,10 @@ func (n *Node) onRootReceived(c *Conn, r *registry.Root) (err error) {
 		err = orr(c, r)
 	}
 
+	if err == nil {
+		n.eventRoot(c, EventRootReceived, r, nil)
+	}
+
 	return
 }
 
@@ -649,6 +722,8 @@ func (n *Node) onRootFilled(r *registry.Root) {
 		orf(n, r)
 	}
 
+	n.eventRoot(nil, EventRootFilled, r, nil)
+
 }
 
 func (n *Node) onFillingBreaks(r *registry.Root, reason error) {
@@ -657,6 +732,8 @@ func (n *Node) onFillingBreaks(r *registry.Root, reason error) {
 		brk(n, r, reason)
 	}
 
+	n.eventRoot(nil, EventFillingBreaks, r, reason)
+
 }
 
 // has connection to peer with given id (pk)
@@ -672,6 +749,10 @@ func (n *Node) hasPeer(id cipher.PubKey) (c *Conn, yep bool) {
 type Stat struct {
 	*skyobject.Stat
 	Fillavg time.Duration
+
+	// Scores of connections (connection -> score),
+	// where connection is (*Conn).String()
+	Scores map[string]ConnScore
 }
 
 // Stat returns statistic of the Node
@@ -683,6 +764,14 @@ func (n *Node) Stat() (s *Stat) {
 	s.Stat = n.c.Stat()
 	s.Fillavg = n.fillavg.Value()
 
+	var cs = n.Connections()
+
+	s.Scores = make(map[string]ConnScore, len(cs))
+
+	for _, c := range cs {
+		s.Scores[c.String()] = c.Score()
+	}
+
 	return
 }
 
@@ -710,6 +799,10 @@ func (n *Node) Close() (err error) {
 			n.rpc.Close()
 		}
 
+		if n.http != nil {
+			n.http.Close()
+		}
+
 		n.await.Wait()
 
 	})
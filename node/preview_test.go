@@ -243,3 +243,228 @@ func Test_preview_refs(t *testing.T) {
 	}
 
 }
+
+// step by step using PreviewRPC
+func Test_preview_rpc(t *testing.T) {
+
+	var (
+		sn    = getTestNode("sender")
+		rconf = getTestConfig("receiver")
+	)
+
+	rconf.TCP.Listen = "" // don't listen
+	rconf.UDP.Listen = "" // don't listen
+
+	var rn, err = NewNode(rconf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sn.Close()
+	defer rn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, rn.Share(pk))
+
+	var (
+		reg = getTestRegistry()
+		sc  = sn.Container()
+
+		up *skyobject.Unpack
+	)
+
+	if up, err = sc.Unpack(sk, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = new(registry.Root)
+
+	r.Nonce = 9021 // random
+	r.Pub = pk     // set
+	r.Descriptor = []byte("hey-ho!")
+
+	var feed Feed
+
+	for i := 0; i < 32; i++ {
+
+		err := feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+			Time: time.Now().UnixNano(),
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	}
+
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}),
+		dynamicByValue(t, up, "test.Feed", feed),
+	)
+
+	// save the Root
+	if err = sc.Save(up, r); err != nil {
+		t.Fatal(err)
+	}
+
+	// connect the nodes between
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		pr   = &PreviewRPC{rn}
+		ps   = PreviewSelector{Address: c.Address(), Feed: pk}
+		page PreviewPage
+	)
+
+	// Root
+
+	if err = pr.Root(ps, &page); err != nil {
+		t.Fatal(err)
+	}
+
+	if page.Root.Seq != r.Seq {
+		t.Error("wrong Root received")
+	}
+
+	if page.Length != 2 || len(page.Items) != 2 {
+		t.Fatal("wrong number of items:", page.Length, len(page.Items))
+	}
+
+	// Object (the Feed)
+
+	ps.Schema, ps.Hash = page.Items[1].Elem, page.Items[1].Hash
+	page = PreviewPage{}
+
+	if err = pr.Object(ps, &page); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Items) != 1 || len(page.Items[0].Items) != 1 {
+		t.Fatal("wrong Feed received")
+	}
+
+	var posts = page.Items[0].Items[0]
+
+	if posts.Ref != registry.ReferenceTypeSlice {
+		t.Fatal("wrong reference type of Posts:", posts.Ref)
+	}
+
+	// Refs (the Posts, page 10-20)
+
+	ps.Schema, ps.Hash = posts.Elem, posts.Hash
+	ps.From, ps.To = 10, 20
+	page = PreviewPage{}
+
+	if err = pr.Refs(ps, &page); err != nil {
+		t.Fatal(err)
+	}
+
+	if page.Length != 32 {
+		t.Error("wrong length of Posts:", page.Length)
+	}
+
+	if len(page.Items) != 10 {
+		t.Fatal("wrong page length:", len(page.Items))
+	}
+
+	for i, it := range page.Items {
+		if it.Name != fmt.Sprint(10+i) {
+			t.Error("wrong index of element:", it.Name)
+		}
+	}
+
+}
+
+func TestConn_PreviewPinned(t *testing.T) {
+	// PreviewPinned(pin *registry.Root, previewFunc PreviewFunc) (err error)
+
+	var (
+		sn    = getTestNode("sender")
+		rconf = getTestConfig("receiver")
+	)
+
+	rconf.TCP.Listen = "" // don't listen
+	rconf.UDP.Listen = "" // don't listen
+
+	var rn, err = NewNode(rconf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sn.Close()
+	defer rn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+
+	var (
+		sc = sn.Container()
+		up *skyobject.Unpack
+	)
+
+	if up, err = sc.Unpack(sk, getTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = &registry.Root{Pub: pk, Nonce: 9021, Descriptor: []byte("first")}
+	assertNil(t, sc.Save(up, r))
+
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var pin *registry.Root
+
+	err = c.Preview(pk, func(_ registry.Pack, r *registry.Root) (_ error) {
+		pin = r
+		return
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// newer Root
+
+	r.Descriptor = []byte("second")
+	assertNil(t, sc.Save(up, r))
+
+	err = c.PreviewPinned(pin, func(_ registry.Pack, r *registry.Root) (
+		_ error) {
+
+		if r.Seq != 0 || string(r.Descriptor) != "first" {
+			t.Error("not pinned Root previewed:", r.Short())
+		}
+		return
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// wrong signature
+
+	var wrong = *pin
+	wrong.Sig = cipher.SignHash(pin.Hash, sk)
+	wrong.Hash = cipher.SHA256{1}
+
+	err = c.PreviewPinned(&wrong, func(registry.Pack, *registry.Root) error {
+		return nil
+	})
+
+	if err == nil {
+		t.Error("missing error")
+	}
+
+}
@@ -0,0 +1,209 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A PublishRequest represents request to publish
+// new Root object built from JSON. See PublishJSON
+// method of the Node for details
+type PublishRequest struct {
+	Feed  cipher.PubKey        // feed of the Root
+	Nonce uint64               // head of the Root, zero for active
+	Reg   registry.RegistryRef // registry, blank for registry of last Root
+
+	Descriptor []byte // descriptor of the Root
+	Refs       []byte // JSON array of Dynamic references
+}
+
+// RegisterRegistry registers given Registry. The
+// Registry can be used to publish Root objects
+// (see PublishJSON). The Registry will be saved
+// with first Root that uses it
+func (n *Node) RegisterRegistry(reg *registry.Registry) (
+	rr registry.RegistryRef,
+) {
+
+	rr = n.c.RegistryRef(reg)
+
+	n.rmx.Lock()
+	defer n.rmx.Unlock()
+
+	n.regs[rr] = reg
+	n.c.AddRegistryToCache(reg)
+
+	return
+}
+
+// registry by reference, looking registered
+// registries first
+func (n *Node) registry(rr registry.RegistryRef) (
+	reg *registry.Registry,
+	err error,
+) {
+
+	n.rmx.Lock()
+	var ok bool
+	reg, ok = n.regs[rr]
+	n.rmx.Unlock()
+
+	if ok == true {
+		return
+	}
+
+	if reg, err = n.c.Registry(rr); err != nil {
+		return nil, fmt.Errorf("%v %s: %v", ErrNoSuchRegistry, rr.Short(),
+			err)
+	}
+
+	return
+}
+
+// NewHead creates new blank head of given feed
+// returning its nonce. The Node should have secret
+// key of the feed in its Keystore. The feed will be
+// shared if it's not
+func (n *Node) NewHead(feed cipher.PubKey) (nonce uint64, err error) {
+
+	if n.ks.Has(feed) == false {
+		return 0, ErrNoSecretKey
+	}
+
+	if err = n.Share(feed); err != nil {
+		return
+	}
+
+	var heads []uint64
+	if heads, err = n.c.Heads(feed); err != nil {
+		return
+	}
+
+	if n.config.MaxHeads > 0 && len(heads) >= n.config.MaxHeads {
+		return 0, ErrMaxHeadsLimit
+	}
+
+	for nonce == 0 || n.c.HasHead(feed, nonce) == true {
+		nonce = rand.Uint64()
+	}
+
+	err = n.c.AddHead(feed, nonce)
+	return
+}
+
+// PublishJSON creates, saves and publishes new Root
+// object. The Root built from JSON using generic
+// encoder (see registry.EncodeJSON). The Refs of the
+// request is JSON array of Dynamic references, e.g.
+//
+//     [
+//         {"Schema": "pkg.User", "Object": {"Name": "Alice"}},
+//         {"Schema": "pkg.User", "Hash": "8a7f...3c"}
+//     ]
+//
+// The Root replaces previous Root of the head entirely.
+// The Node should have secret key of the feed in its
+// Keystore. If the Nonce of the request is zero, then
+// active head is used. If the feed has not heads, then
+// new head created. If the Reg of the request is blank,
+// then Registry of last Root of the head is used. The
+// Registry should be registered (see RegisterRegistry)
+// or saved with a Root
+func (n *Node) PublishJSON(rq *PublishRequest) (
+	r *registry.Root,
+	err error,
+) {
+
+	var sk cipher.SecKey
+	if sk, err = n.ks.secKey(rq.Feed); err != nil {
+		return
+	}
+
+	if err = n.Share(rq.Feed); err != nil {
+		return
+	}
+
+	var nonce = rq.Nonce
+
+	if nonce == 0 {
+		nonce = n.c.ActiveHead(rq.Feed)
+	}
+
+	if nonce == 0 {
+		if nonce, err = n.NewHead(rq.Feed); err != nil {
+			return
+		}
+	}
+
+	var rr = rq.Reg
+
+	if rr == (registry.RegistryRef{}) {
+
+		var last *registry.Root
+		if last, err = n.c.LastRoot(rq.Feed, nonce); err != nil {
+			return nil, fmt.Errorf("can't get Registry of last Root: %v",
+				err)
+		}
+
+		rr = last.Reg
+	}
+
+	var reg *registry.Registry
+	if reg, err = n.registry(rr); err != nil {
+		return
+	}
+
+	var refs []interface{}
+
+	if len(rq.Refs) != 0 {
+
+		var dec = json.NewDecoder(bytes.NewReader(rq.Refs))
+		dec.UseNumber()
+
+		if err = dec.Decode(&refs); err != nil {
+			return nil, fmt.Errorf("invalid Refs: %v", err)
+		}
+
+	}
+
+	var up *skyobject.Unpack
+	if up, err = n.c.Unpack(sk, reg); err != nil {
+		return
+	}
+	defer up.Close()
+
+	r = new(registry.Root)
+
+	r.Pub = rq.Feed
+	r.Nonce = nonce
+	r.Reg = rr
+	r.Descriptor = rq.Descriptor
+	r.Refs = make([]registry.Dynamic, 0, len(refs))
+
+	for i, val := range refs {
+
+		var dr registry.Dynamic
+		if dr, err = registry.DynamicJSON(up, val); err != nil {
+			return nil, fmt.Errorf("Refs element %d: %v", i, err)
+		}
+
+		r.Refs = append(r.Refs, dr)
+	}
+
+	if err = n.c.Save(up, r); err != nil {
+		return
+	}
+
+	if err = n.Publish(r, up); err != nil {
+		return
+	}
+
+	return
+}
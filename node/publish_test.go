@@ -0,0 +1,85 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_PublishJSON(t *testing.T) {
+
+	var n = getTestNodeNotListen("publish")
+	defer n.Close()
+
+	var reg, err = registry.NewRegistryFromDescriptions(
+		[]registry.StructDescription{
+			{
+				Name: "test.User",
+				Fields: []registry.FieldDescription{
+					{Name: "Name", Schema: "string"},
+					{Name: "Age", Schema: "uint32"},
+				},
+			},
+		})
+	assertNil(t, err)
+
+	var rr = n.RegisterRegistry(reg)
+
+	var rq = PublishRequest{
+		Reg: rr,
+		Refs: []byte(`[
+			{"Schema": "test.User", "Object": {"Name": "Alice", "Age": 21}}
+		]`),
+	}
+
+	// no secret key
+	if _, err = n.PublishJSON(&rq); err != ErrNoSecretKey {
+		t.Fatal("unexpected error:", err)
+	}
+
+	rq.Feed, err = n.Keystore().Generate()
+	assertNil(t, err)
+
+	var r *registry.Root
+	r, err = n.PublishJSON(&rq)
+	assertNil(t, err)
+
+	assertTrue(t, r.Nonce != 0, "zero nonce")
+	assertTrue(t, n.IsSharing(rq.Feed) == true, "not shared")
+	assertTrue(t, len(r.Refs) == 1, "wrong Refs length")
+
+	var last *registry.Root
+	last, err = n.Container().LastRoot(rq.Feed, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, last.Hash == r.Hash, "wrong last Root")
+
+	// use registry and head of last Root
+
+	rq.Reg = registry.RegistryRef{}
+	rq.Refs = []byte(`[
+		{"Schema": "test.User", "Hash": "` + r.Refs[0].Hash.Hex() + `"},
+		{"Schema": "test.User", "Object": {"Name": "Eva"}}
+	]`)
+
+	var next *registry.Root
+	next, err = n.PublishJSON(&rq)
+	assertNil(t, err)
+
+	assertTrue(t, next.Nonce == r.Nonce, "wrong head")
+	assertTrue(t, next.Seq == r.Seq+1, "wrong seq")
+	assertTrue(t, len(next.Refs) == 2, "wrong Refs length")
+	assertTrue(t, next.Refs[0] == r.Refs[0], "wrong first element")
+
+	// new head
+
+	var nonce uint64
+	nonce, err = n.NewHead(rq.Feed)
+	assertNil(t, err)
+	assertTrue(t, nonce != r.Nonce, "same head")
+
+	var heads []uint64
+	heads, err = n.Container().Heads(rq.Feed)
+	assertNil(t, err)
+	assertTrue(t, len(heads) == 2, "wrong number of heads")
+
+}
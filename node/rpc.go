@@ -8,6 +8,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
@@ -19,6 +20,10 @@ import (
 //     - tcp
 //     - udp
 //     - root
+//     - preview
+//     - events
+//     - publish
+//     - head
 //
 // It's possible to add own handler using RegisterName method.
 // The RPCServer based on net/rpc package and runs over TCP
@@ -56,6 +61,14 @@ func (r *RPCServer) Listen(conf *RPCConfig) (err error) {
 
 	r.r.RegisterName("root", &RootRPC{r.n})
 
+	r.r.RegisterName("preview", &PreviewRPC{r.n})
+
+	r.r.RegisterName("events", &EventsRPC{r.n})
+
+	r.r.RegisterName("publish", &PublishRPC{r.n})
+
+	r.r.RegisterName("head", &HeadRPC{r.n})
+
 	if conf.TLS == nil {
 		r.l, err = net.Listen("tcp", conf.Listen) // TCP
 	} else {
@@ -372,6 +385,62 @@ func (r *RootRPC) Tree(rs RootSelector, tree *string) (err error) {
 	return
 }
 
+// A QueryRequest represents request of RootRPC.Query.
+// Results of a query are returned page by page, the
+// After is continuation returned with previous page
+// (blank for the first page) and the Limit is maximum
+// number of results of the page
+type QueryRequest struct {
+	RootSelector
+
+	Query string // the query, see registry.Query
+
+	After registry.QueryCursor // continue after, see QueryReply
+	Limit int                  // max results, zero for DefaultQueryLimit
+}
+
+// A QueryReply represents page of results of a query
+type QueryReply struct {
+	Items []*registry.Item     // results, Name of an Item is its path
+	More  bool                 // there are more results
+	Next  registry.QueryCursor // continuation for next page
+}
+
+// DefaultQueryLimit is default number of
+// results of a page of RootRPC.Query
+const DefaultQueryLimit = 100
+
+// Query Root (RPC method)
+func (r *RootRPC) Query(rq QueryRequest, reply *QueryReply) (err error) {
+
+	var x *registry.Root
+	if x, err = r.n.c.Root(rq.Feed, rq.Nonce, rq.Seq); err != nil {
+		return
+	}
+
+	if rq.Limit <= 0 {
+		rq.Limit = DefaultQueryLimit
+	}
+
+	var items []*registry.Item
+
+	err = r.n.c.QueryAfter(x, rq.Query, rq.After,
+		func(res *registry.QueryResult) (_ error) {
+
+			if len(items) == rq.Limit {
+				reply.More = true
+				return registry.ErrStopIteration
+			}
+
+			items = append(items, res.Item())
+			reply.Next = res.Cursor
+			return
+		})
+
+	reply.Items = items
+	return
+}
+
 // Last Root of given Feed (RPC method)
 func (r *RootRPC) Last(feed cipher.PubKey, z *registry.Root) (err error) {
 	var x *registry.Root
@@ -381,3 +450,361 @@ func (r *RootRPC) Last(feed cipher.PubKey, z *registry.Root) (err error) {
 	*z = *x
 	return
 }
+
+// Equivocations returns evidences of forks
+// of given feed (RPC method)
+func (r *RootRPC) Equivocations(
+	feed cipher.PubKey,
+	es *[]*skyobject.Equivocation,
+) (_ error) {
+
+	*es = r.n.c.Equivocations(feed)
+	return
+}
+
+// A PreviewRPC represents RPC object
+// for feeds preview. The PreviewRPC
+// allows to explore a feed of remote
+// peer step by step without subscription.
+// E.g. Root, then an object, then a page
+// of a registry.Refs, etc
+type PreviewRPC struct {
+	n *Node
+}
+
+// A PreviewSelector represents selector of
+// an object of a feed of remote peer
+type PreviewSelector struct {
+	Address string        // address of connection
+	Feed    cipher.PubKey // feed to preview
+
+	Schema registry.SchemaRef // schema of object or of elements of Refs
+	Hash   cipher.SHA256      // hash of object or of Refs
+
+	From int // first element of Refs
+	To   int // last element of Refs (exclusive)
+
+	// Root pins Root of the feed. Use Root of a
+	// previous page to get all pages from the same
+	// Root. If it's nil, then latest Root is used
+	Root *registry.Root
+}
+
+// A PreviewPage represents reply of PreviewRPC
+type PreviewPage struct {
+	Root   registry.Root    // latest or pinned Root of the feed
+	Length int              // length of Refs or number of items
+	Items  []*registry.Item // items
+}
+
+// connection by address (TCP or UDP)
+func (n *Node) connByAddress(address string) (c *Conn, err error) {
+
+	if tcp := n.getTCP(); tcp != nil {
+		if c = tcp.getConn(address); c != nil {
+			return
+		}
+	}
+
+	if udp := n.getUDP(); udp != nil {
+		if c = udp.getConn(address); c != nil {
+			return
+		}
+	}
+
+	return nil, errors.New("no such connection")
+}
+
+// preview latest or pinned Root of feed of the PreviewSelector
+func (p *PreviewRPC) preview(
+	ps *PreviewSelector, //     : selector
+	page *PreviewPage, //       : reply
+	previewFunc PreviewFunc, // : the function
+) (
+	err error, //               : error
+) {
+
+	var c *Conn
+	if c, err = p.n.connByAddress(ps.Address); err != nil {
+		return
+	}
+
+	var fn = func(pack registry.Pack, r *registry.Root) (err error) {
+		page.Root = *r
+		return previewFunc(pack, r)
+	}
+
+	if ps.Root == nil {
+		return c.Preview(ps.Feed, fn)
+	}
+
+	if ps.Root.Pub != ps.Feed {
+		return skyobject.ErrFeedMismatch
+	}
+
+	return c.PreviewPinned(ps.Root, fn)
+}
+
+// Root of remote feed and its Items (RPC method). The Root
+// method uses Address and Feed fields of the PreviewSelector
+func (p *PreviewRPC) Root(ps PreviewSelector, page *PreviewPage) (err error) {
+
+	return p.preview(&ps, page, func(pack registry.Pack, r *registry.Root) (
+		err error) {
+
+		if page.Items, err = r.Items(pack); err != nil {
+			return
+		}
+
+		page.Length = len(page.Items)
+		return
+	})
+
+}
+
+// Object of remote feed (RPC method). The Object method
+// uses Address, Feed, Schema and Hash fields of the
+// PreviewSelector. The Items of the reply contains the
+// object only
+func (p *PreviewRPC) Object(ps PreviewSelector, page *PreviewPage) (err error) {
+
+	return p.preview(&ps, page, func(pack registry.Pack, _ *registry.Root) (
+		err error) {
+
+		var it *registry.Item
+		if it, err = registry.ObjectItem(pack, ps.Schema, ps.Hash); err != nil {
+			return
+		}
+
+		page.Items, page.Length = []*registry.Item{it}, 1
+		return
+	})
+
+}
+
+// Refs returns page of registry.Refs of remote feed (RPC
+// method). The Refs method uses all fields of the
+// PreviewSelector. The Schema is schema of elements of
+// the Refs. The Length of the reply is length of the Refs
+func (p *PreviewRPC) Refs(ps PreviewSelector, page *PreviewPage) (err error) {
+
+	return p.preview(&ps, page, func(pack registry.Pack, _ *registry.Root) (
+		err error) {
+
+		page.Length, page.Items, err = registry.RefsItems(pack, ps.Schema,
+			ps.Hash, ps.From, ps.To)
+		return
+	})
+
+}
+
+// An EventsRPC represents RPC object
+// for events of the Node. The EventsRPC
+// is long-poll based. See PollEvents
+// method of the Node for details
+type EventsRPC struct {
+	n *Node
+}
+
+// Poll is RPC method
+func (e *EventsRPC) Poll(rq EventsRequest, reply *EventsReply) (_ error) {
+	*reply = *e.n.PollEvents(&rq)
+	return
+}
+
+// Last is RPC method
+func (e *EventsRPC) Last(_ struct{}, seq *uint64) (_ error) {
+	*seq = e.n.LastEvent()
+	return
+}
+
+// A PublishRPC represents RPC object
+// to create and publish Root objects
+// remotely. Secret keys of feeds are
+// kept by Keystore of the Node and
+// never returned
+type PublishRPC struct {
+	n *Node
+}
+
+// Registry is RPC method. It creates Registry
+// using given descriptions and registers it.
+// See also registry.NewRegistryFromDescriptions
+func (p *PublishRPC) Registry(
+	ds []registry.StructDescription,
+	rr *registry.RegistryRef,
+) (
+	err error,
+) {
+
+	var reg *registry.Registry
+	if reg, err = registry.NewRegistryFromDescriptions(ds); err != nil {
+		return
+	}
+
+	*rr = p.n.RegisterRegistry(reg)
+	return
+}
+
+// NewHead is RPC method
+func (p *PublishRPC) NewHead(feed cipher.PubKey, nonce *uint64) (err error) {
+	*nonce, err = p.n.NewHead(feed)
+	return
+}
+
+// Publish is RPC method
+func (p *PublishRPC) Publish(rq PublishRequest, z *registry.Root) (err error) {
+
+	var r *registry.Root
+	if r, err = p.n.PublishJSON(&rq); err != nil {
+		return
+	}
+
+	*z = *r
+	return
+}
+
+// Keys is RPC method
+func (p *PublishRPC) Keys(_ struct{}, pks *[]cipher.PubKey) (_ error) {
+	*pks = p.n.ks.List()
+	return
+}
+
+// GenerateKey is RPC method
+func (p *PublishRPC) GenerateKey(_ struct{}, pk *cipher.PubKey) (err error) {
+	*pk, err = p.n.ks.Generate()
+	return
+}
+
+// AddKey is RPC method
+func (p *PublishRPC) AddKey(sk cipher.SecKey, pk *cipher.PubKey) (err error) {
+	*pk, err = p.n.ks.Add(sk)
+	return
+}
+
+// DelKey is RPC method
+func (p *PublishRPC) DelKey(pk cipher.PubKey, _ *struct{}) (err error) {
+	return p.n.ks.Remove(pk)
+}
+
+// A HeadRPC represents RPC object
+// to manage heads of feeds
+type HeadRPC struct {
+	n *Node
+}
+
+// A Head represents head of a feed with
+// its info (see HeadRPC.List)
+type Head struct {
+	Nonce    uint64 // the head
+	Label    string // label of the head
+	Active   bool   // active head of the feed
+	Selected bool   // the active head selected explicitly
+	Keep     uint32 // Root objects to keep, zero to keep all
+}
+
+// A HeadSelector represents head selector
+type HeadSelector struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+}
+
+// A HeadLabel represents request of
+// HeadRPC.SetLabel and HeadRPC.ByLabel
+type HeadLabel struct {
+	HeadSelector
+
+	Label string
+}
+
+// A HeadKeep represents request of HeadRPC.SetKeep
+type HeadKeep struct {
+	HeadSelector
+
+	Keep uint32
+}
+
+// List heads of given feed (RPC method)
+func (h *HeadRPC) List(feed cipher.PubKey, heads *[]Head) (err error) {
+
+	var nonces []uint64
+	if nonces, err = h.n.c.Heads(feed); err != nil {
+		return
+	}
+
+	var (
+		active = h.n.c.ActiveHead(feed)
+		list   = make([]Head, 0, len(nonces))
+	)
+
+	for _, nonce := range nonces {
+
+		var info, err = h.n.c.HeadInfo(feed, nonce)
+
+		if err != nil {
+			return err
+		}
+
+		list = append(list, Head{
+			Nonce:    nonce,
+			Label:    info.Label,
+			Active:   nonce == active,
+			Selected: info.Active,
+			Keep:     info.Keep,
+		})
+
+	}
+
+	*heads = list
+	return
+}
+
+// SetLabel of a head, blank label
+// removes the label (RPC method)
+func (h *HeadRPC) SetLabel(hl HeadLabel, _ *struct{}) (err error) {
+	return h.n.c.SetHeadLabel(hl.Feed, hl.Nonce, hl.Label)
+}
+
+// ByLabel returns nonce of head with given
+// label, the Nonce is ignored (RPC method)
+func (h *HeadRPC) ByLabel(hl HeadLabel, nonce *uint64) (err error) {
+	*nonce, err = h.n.c.HeadByLabel(hl.Feed, hl.Label)
+	return
+}
+
+// SetActive selects active head of
+// a feed explicitly (RPC method)
+func (h *HeadRPC) SetActive(hs HeadSelector, _ *struct{}) (err error) {
+	return h.n.c.SetActiveHead(hs.Feed, hs.Nonce)
+}
+
+// ResetActive resets explicitly selected
+// active head of a feed (RPC method)
+func (h *HeadRPC) ResetActive(feed cipher.PubKey, _ *struct{}) (err error) {
+	return h.n.c.ResetActiveHead(feed)
+}
+
+// SetKeep sets number of last Root objects
+// of a head to keep (RPC method)
+func (h *HeadRPC) SetKeep(hk HeadKeep, _ *struct{}) (err error) {
+	return h.n.c.SetHeadKeep(hk.Feed, hk.Nonce, hk.Keep)
+}
+
+// Fork creates new head using given Root and
+// publishes first Root of the head. See ForkHead
+// method of the Node for details (RPC method)
+func (h *HeadRPC) Fork(rs RootSelector, z *registry.Root) (err error) {
+
+	var r *registry.Root
+	if r, err = h.n.ForkHead(rs.Feed, rs.Nonce, rs.Seq); err != nil {
+		return
+	}
+
+	*z = *r
+	return
+}
+
+// Del deletes head with all its Root objects (RPC method)
+func (h *HeadRPC) Del(hs HeadSelector, _ *struct{}) (err error) {
+	return h.n.c.DelHead(hs.Feed, hs.Nonce)
+}
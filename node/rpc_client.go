@@ -2,11 +2,13 @@ package node
 
 import (
 	"crypto/tls"
+	"errors"
 	"net"
 	"net/rpc"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
@@ -260,6 +262,52 @@ func (r *RPCClientRoot) Tree(
 	return
 }
 
+// Query selected Root object. The Query requests results page
+// by page and calls given function for every result. Name of
+// an Item is path to the value. Use registry.ErrStopIteration
+// to stop the query. See registry.Query for details about the
+// query language
+func (r *RPCClientRoot) Query(
+	feed cipher.PubKey, //                    : feed
+	nonce uint64, //                          : head
+	seq uint64, //                            : seq
+	query string, //                          : the query
+	queryFunc func(it *registry.Item) error, // : the function
+) (
+	err error, //                             : error if any
+) {
+
+	var rq = QueryRequest{
+		RootSelector: RootSelector{feed, nonce, seq},
+		Query:        query,
+		Limit:        DefaultQueryLimit,
+	}
+
+	for {
+
+		var reply QueryReply
+		if err = r.r.c.Call("root.Query", rq, &reply); err != nil {
+			return
+		}
+
+		for _, it := range reply.Items {
+			if err = queryFunc(it); err != nil {
+				if err == registry.ErrStopIteration {
+					err = nil
+				}
+				return
+			}
+		}
+
+		if reply.More == false {
+			return
+		}
+
+		rq.After = reply.Next
+	}
+
+}
+
 // Last Root object
 func (r *RPCClientRoot) Last(
 	feed cipher.PubKey,
@@ -274,3 +322,321 @@ func (r *RPCClientRoot) Last(
 	}
 	return &x, nil
 }
+
+// Equivocations returns evidences of forks of
+// given feed, detected by the Node or received
+// from peers
+func (r *RPCClientRoot) Equivocations(
+	feed cipher.PubKey,
+) (
+	es []*skyobject.Equivocation,
+	err error,
+) {
+
+	err = r.r.c.Call("root.Equivocations", feed, &es)
+	return
+}
+
+// Preview related methods
+func (r *RPCClient) Preview() (p *RPCClientPreview) {
+	return &RPCClientPreview{r}
+}
+
+// A RPCClientPreview implements RPC
+// methods related to feeds preview
+type RPCClientPreview struct {
+	r *RPCClient
+}
+
+// Root returns latest Root of given feed of peer
+// with given address and Items of the Root
+func (r *RPCClientPreview) Root(
+	address string, //         : address of connection
+	feed cipher.PubKey, //     : feed to preview
+) (
+	z *registry.Root, //       : latest Root
+	items []*registry.Item, // : items of the Root
+	err error, //              : error
+) {
+
+	var page PreviewPage
+	err = r.r.c.Call("preview.Root", PreviewSelector{
+		Address: address,
+		Feed:    feed,
+	}, &page)
+	if err != nil {
+		return
+	}
+	return &page.Root, page.Items, nil
+}
+
+// Object of given feed of peer with given address. If
+// given pin is not nil, then the object is requested
+// using the pin Root (see PreviewSelector)
+func (r *RPCClientPreview) Object(
+	address string, //        : address of connection
+	feed cipher.PubKey, //    : feed to preview
+	sr registry.SchemaRef, // : schema of the object
+	hash cipher.SHA256, //    : hash of the object
+	pin *registry.Root, //    : Root to use or nil for latest
+) (
+	it *registry.Item, //     : the object
+	err error, //             : error
+) {
+
+	var page PreviewPage
+	err = r.r.c.Call("preview.Object", PreviewSelector{
+		Address: address,
+		Feed:    feed,
+		Schema:  sr,
+		Hash:    hash,
+		Root:    pin,
+	}, &page)
+	if err != nil {
+		return
+	}
+	if len(page.Items) != 1 {
+		return nil, errors.New("invalid response: unexpected items length")
+	}
+	return page.Items[0], nil
+}
+
+// Refs returns page of registry.Refs of given feed of peer
+// with given address. The page is elements from i (inclusive)
+// to j (exclusive). The Refs returns length of the Refs too.
+// If given pin is not nil, then the page is requested using
+// the pin Root (see PreviewSelector)
+func (r *RPCClientPreview) Refs(
+	address string, //         : address of connection
+	feed cipher.PubKey, //     : feed to preview
+	el registry.SchemaRef, //  : schema of elements
+	hash cipher.SHA256, //     : hash of the Refs
+	i int, //                  : first element
+	j int, //                  : last element (exclusive)
+	pin *registry.Root, //     : Root to use or nil for latest
+) (
+	length int, //             : length of the Refs
+	items []*registry.Item, // : the page
+	err error, //              : error
+) {
+
+	var page PreviewPage
+	err = r.r.c.Call("preview.Refs", PreviewSelector{
+		Address: address,
+		Feed:    feed,
+		Schema:  el,
+		Hash:    hash,
+		From:    i,
+		To:      j,
+		Root:    pin,
+	}, &page)
+	if err != nil {
+		return
+	}
+	return page.Length, page.Items, nil
+}
+
+// Events related methods
+func (r *RPCClient) Events() (e *RPCClientEvents) {
+	return &RPCClientEvents{r}
+}
+
+// A RPCClientEvents implements RPC
+// methods related to events of the Node
+type RPCClientEvents struct {
+	r *RPCClient
+}
+
+// Last returns seq number of last event of
+// the Node. Use it as From of first Poll
+func (r *RPCClientEvents) Last() (seq uint64, err error) {
+	err = r.r.c.Call("events.Last", struct{}{}, &seq)
+	return
+}
+
+// Poll events of the Node. See PollEvents
+// method of the Node for details
+func (r *RPCClientEvents) Poll(rq *EventsRequest) (
+	reply *EventsReply,
+	err error,
+) {
+
+	var x EventsReply
+	if err = r.r.c.Call("events.Poll", rq, &x); err != nil {
+		return
+	}
+	return &x, nil
+}
+
+// Publish returns RPC client to
+// create and publish Root objects
+func (r *RPCClient) Publish() (p *RPCClientPublish) {
+	return &RPCClientPublish{r}
+}
+
+// A RPCClientPublish implements RPC methods
+// to create and publish Root objects remotely
+type RPCClientPublish struct {
+	r *RPCClient
+}
+
+// Registry creates and registers Registry
+// by given descriptions
+func (r *RPCClientPublish) Registry(ds []registry.StructDescription) (
+	rr registry.RegistryRef,
+	err error,
+) {
+	err = r.r.c.Call("publish.Registry", ds, &rr)
+	return
+}
+
+// NewHead creates new head of given feed
+func (r *RPCClientPublish) NewHead(feed cipher.PubKey) (
+	nonce uint64,
+	err error,
+) {
+	err = r.r.c.Call("publish.NewHead", feed, &nonce)
+	return
+}
+
+// Publish new Root. See PublishJSON
+// method of the Node for details
+func (r *RPCClientPublish) Publish(rq *PublishRequest) (
+	z *registry.Root,
+	err error,
+) {
+
+	var x registry.Root
+	if err = r.r.c.Call("publish.Publish", rq, &x); err != nil {
+		return
+	}
+	return &x, nil
+}
+
+// Keys returns feeds the Node has secret keys of
+func (r *RPCClientPublish) Keys() (pks []cipher.PubKey, err error) {
+	err = r.r.c.Call("publish.Keys", struct{}{}, &pks)
+	return
+}
+
+// GenerateKey generates new key pair, keeping
+// the secret key in the Node, and returns
+// public key
+func (r *RPCClientPublish) GenerateKey() (pk cipher.PubKey, err error) {
+	err = r.r.c.Call("publish.GenerateKey", struct{}{}, &pk)
+	return
+}
+
+// AddKey adds given secret key to Keystore of the Node
+func (r *RPCClientPublish) AddKey(sk cipher.SecKey) (
+	pk cipher.PubKey,
+	err error,
+) {
+	err = r.r.c.Call("publish.AddKey", sk, &pk)
+	return
+}
+
+// DelKey removes secret key of given
+// feed from Keystore of the Node
+func (r *RPCClientPublish) DelKey(pk cipher.PubKey) (err error) {
+	return r.r.c.Call("publish.DelKey", pk, &struct{}{})
+}
+
+// Head returns RPC client
+// to manage heads of feeds
+func (r *RPCClient) Head() (h *RPCClientHead) {
+	return &RPCClientHead{r}
+}
+
+// A RPCClientHead implements RPC
+// methods to manage heads of feeds
+type RPCClientHead struct {
+	r *RPCClient
+}
+
+// List heads of given feed
+func (r *RPCClientHead) List(feed cipher.PubKey) (heads []Head, err error) {
+	err = r.r.c.Call("head.List", feed, &heads)
+	return
+}
+
+// SetLabel sets label of given head,
+// blank label removes the label
+func (r *RPCClientHead) SetLabel(
+	feed cipher.PubKey,
+	nonce uint64,
+	label string,
+) (
+	err error,
+) {
+	return r.r.c.Call("head.SetLabel",
+		HeadLabel{HeadSelector{feed, nonce}, label},
+		&struct{}{})
+}
+
+// ByLabel returns nonce of head with given label
+func (r *RPCClientHead) ByLabel(
+	feed cipher.PubKey,
+	label string,
+) (
+	nonce uint64,
+	err error,
+) {
+	err = r.r.c.Call("head.ByLabel",
+		HeadLabel{HeadSelector{Feed: feed}, label},
+		&nonce)
+	return
+}
+
+// SetActive selects active head of given feed explicitly
+func (r *RPCClientHead) SetActive(feed cipher.PubKey, nonce uint64) (
+	err error,
+) {
+	return r.r.c.Call("head.SetActive", HeadSelector{feed, nonce},
+		&struct{}{})
+}
+
+// ResetActive resets explicitly selected
+// active head of given feed
+func (r *RPCClientHead) ResetActive(feed cipher.PubKey) (err error) {
+	return r.r.c.Call("head.ResetActive", feed, &struct{}{})
+}
+
+// SetKeep sets number of last Root objects of
+// given head to keep, zero turns the limit off
+func (r *RPCClientHead) SetKeep(
+	feed cipher.PubKey,
+	nonce uint64,
+	keep uint32,
+) (
+	err error,
+) {
+	return r.r.c.Call("head.SetKeep",
+		HeadKeep{HeadSelector{feed, nonce}, keep},
+		&struct{}{})
+}
+
+// Fork creates new head of given feed using
+// Root with given nonce and seq, and publishes
+// first Root of the new head
+func (r *RPCClientHead) Fork(
+	feed cipher.PubKey,
+	nonce uint64,
+	seq uint64,
+) (
+	z *registry.Root,
+	err error,
+) {
+
+	var x registry.Root
+	err = r.r.c.Call("head.Fork", RootSelector{feed, nonce, seq}, &x)
+	if err != nil {
+		return
+	}
+	return &x, nil
+}
+
+// Del deletes given head with all its Root objects
+func (r *RPCClientHead) Del(feed cipher.PubKey, nonce uint64) (err error) {
+	return r.r.c.Call("head.Del", HeadSelector{feed, nonce}, &struct{}{})
+}
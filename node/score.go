@@ -0,0 +1,270 @@
+package node
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/skycoin/cxo/skyobject/statutil"
+)
+
+// minimal RTT used to calculate score, to
+// avoid division by zero for very fast peers
+const minScoreRTT = time.Millisecond
+
+// size of object used to convert throughput
+// (bytes per second) to objects per second
+const scoreObjectSize = 1024
+
+// A ConnScore represents statistic of a connection
+// collected during filling of Root objects. The
+// Node uses the ConnScore to choose peers to
+// request objects from. Slow peers and peers
+// that fail requests often are deprioritised,
+// and fast peers get more parallel requests
+// (see Config.MaxParallelRequests)
+type ConnScore struct {
+	RTT        time.Duration // average round-trip time of object requests
+	Throughput float64       // average throughput in bytes per second
+
+	Requests int // total object requests
+	Failures int // failed requests (excluding timeouts)
+	Timeouts int // timed out requests
+
+	// RecentFailures and RecentTimeouts are ratios of
+	// failed and timed out requests to all requests,
+	// where recent requests weigh more. Old requests
+	// fade out, thus a peer that failed early recovers,
+	// and a peer that got worse loses its priority
+	RecentFailures float64
+	RecentTimeouts float64
+
+	// Value is resulting score, that is number of
+	// objects per second the peer can provide,
+	// reduced by recent failures and timeouts. The
+	// number is mean of the one based on the RTT
+	// and the one based on the Throughput. Greater
+	// is better. The Value is zero if there are
+	// no requests
+	Value float64
+}
+
+// FailureRatio returns ratio of failed and timed out
+// requests to all requests. It returns 0 if there are
+// no requests
+func (c *ConnScore) FailureRatio() float64 {
+	if c.Requests == 0 {
+		return 0
+	}
+	return float64(c.Failures+c.Timeouts) / float64(c.Requests)
+}
+
+// internal score of connection
+type connScore struct {
+	mx sync.Mutex
+
+	rtt *statutil.Duration // average RTT
+	tp  *statutil.Float    // average throughput
+
+	samples int // window of the recent ratios
+
+	requests int // total
+	failures int // failed
+	timeouts int // timed out
+
+	recentFailures float64 // decaying ratio of failed
+	recentTimeouts float64 // decaying ratio of timed out
+}
+
+func newConnScore(samples int) (c *connScore) {
+
+	if samples <= 0 {
+		samples = 1 // can't be zero
+	}
+
+	c = new(connScore)
+	c.rtt = statutil.NewDuration(samples)
+	c.tp = statutil.NewFloat(samples)
+	c.samples = samples
+	return
+}
+
+// under lock, add outcome of a request to the recent
+// ratios; first requests are averaged, and then
+// every request has weight 1/samples, thus the
+// ratios are exponential moving averages
+func (c *connScore) addRecent(failure, timeout bool) {
+
+	c.requests++
+
+	var w = 1 / float64(c.requests)
+
+	if c.requests > c.samples {
+		w = 1 / float64(c.samples)
+	}
+
+	c.recentFailures *= 1 - w
+	c.recentTimeouts *= 1 - w
+
+	if failure == true {
+		c.recentFailures += w
+	} else if timeout == true {
+		c.recentTimeouts += w
+	}
+
+}
+
+// successful request, the size is total
+// size of received objects
+func (c *connScore) success(rtt time.Duration, size int) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.addRecent(false, false)
+	c.rtt.Add(rtt)
+
+	if rtt < minScoreRTT {
+		rtt = minScoreRTT
+	}
+
+	c.tp.Add(float64(size) / rtt.Seconds())
+}
+
+// failed request
+func (c *connScore) failure(err error) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if err == ErrTimeout {
+		c.addRecent(false, true)
+		c.timeouts++
+		return
+	}
+
+	c.addRecent(true, false)
+	c.failures++
+}
+
+// value of the score, the known is false if
+// the connection has not been used yet
+func (c *connScore) value() (val float64, known bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.valueLocked()
+}
+
+func (c *connScore) valueLocked() (val float64, known bool) {
+
+	if c.requests == 0 {
+		return // unknown
+	}
+
+	// a timeout is worse then a failure, because
+	// we have to wait a long time to get it
+	var succeeded = 1 - c.recentFailures - 2*c.recentTimeouts
+
+	if succeeded <= 0 {
+		return 0, true // the worst
+	}
+
+	var rtt = c.rtt.Value()
+
+	if rtt < minScoreRTT {
+		rtt = minScoreRTT
+	}
+
+	// objects per second based on
+	// the RTT and on the throughput
+	var (
+		byRTT = 1 / rtt.Seconds()
+		byTP  = c.tp.Value() / scoreObjectSize
+	)
+
+	val = succeeded * (byRTT + byTP) / 2
+	return val, true
+}
+
+// snapshot of the score
+func (c *connScore) score() (s ConnScore) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	s.RTT = c.rtt.Value()
+	s.Throughput = c.tp.Value()
+
+	s.Requests = c.requests
+	s.Failures = c.failures
+	s.Timeouts = c.timeouts
+
+	s.RecentFailures = c.recentFailures
+	s.RecentTimeouts = c.recentTimeouts
+
+	s.Value, _ = c.valueLocked()
+	return
+}
+
+// Score of the connection. See ConnScore for details
+func (c *Conn) Score() (s ConnScore) {
+	return c.score.score()
+}
+
+// values of scores of given connections; unknown
+// connections get the best known value, to be
+// tried as soon as possible
+func connsValues(cs []*Conn) (vals map[*Conn]float64, best float64) {
+
+	vals = make(map[*Conn]float64, len(cs))
+
+	var unknown []*Conn
+
+	for _, c := range cs {
+
+		var val, known = c.score.value()
+
+		if known == false {
+			unknown = append(unknown, c)
+			continue
+		}
+
+		if val > best {
+			best = val
+		}
+
+		vals[c] = val
+
+	}
+
+	for _, c := range unknown {
+		vals[c] = best
+	}
+
+	return
+}
+
+// sort given connections by score (the best first)
+func sortConnsByScore(cs []*Conn) {
+
+	var vals, _ = connsValues(cs)
+
+	sort.SliceStable(cs, func(i, j int) bool {
+		return vals[cs[i]] > vals[cs[j]]
+	})
+
+}
+
+// maximum parallel requests for a connection with
+// given value of score; the best connection gets
+// the max, and the worst gets one request at a time
+func parallelRequests(val, best float64, max int) (n int) {
+
+	if max <= 1 || best <= 0 {
+		return 1
+	}
+
+	return 1 + int(float64(max-1)*val/best)
+}
@@ -0,0 +1,138 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_connScore(t *testing.T) {
+
+	var cs = newConnScore(3)
+
+	if _, known := cs.value(); known == true {
+		t.Error("known score of unused connection")
+	}
+
+	cs.success(100*time.Millisecond, 1024)
+	cs.success(100*time.Millisecond, 1024)
+
+	var val, known = cs.value()
+
+	if known == false {
+		t.Fatal("unknown score")
+	}
+
+	if val < 9.9 || val > 10.1 {
+		t.Error("wrong value:", val)
+	}
+
+	cs.failure(errors.New("some error"))
+	cs.failure(ErrTimeout)
+
+	var s = cs.score()
+
+	if s.Requests != 4 || s.Failures != 1 || s.Timeouts != 1 {
+		t.Error("wrong statistic:", s.Requests, s.Failures, s.Timeouts)
+	}
+
+	if s.FailureRatio() != 0.5 {
+		t.Error("wrong failure ratio:", s.FailureRatio())
+	}
+
+	// recent failures 2/9, recent timeouts 1/3,
+	// (1 - 2/9 - 2*1/3) * 10 objects per second
+	if s.Value < 1.1 || s.Value > 1.12 {
+		t.Error("wrong value:", s.Value)
+	}
+
+}
+
+func Test_connScore_decay(t *testing.T) {
+
+	var (
+		recovered = newConnScore(3)
+		worse     = newConnScore(3)
+	)
+
+	for i := 0; i < 3; i++ {
+		recovered.failure(ErrTimeout)
+		worse.success(100*time.Millisecond, 1024)
+	}
+
+	for i := 0; i < 20; i++ {
+		recovered.success(100*time.Millisecond, 1024)
+		worse.failure(errors.New("some error"))
+	}
+
+	var rv, _ = recovered.value()
+
+	if rv < 9.9 || rv > 10.1 {
+		t.Error("failures not faded out:", rv)
+	}
+
+	if wv, _ := worse.value(); wv > 0.1 {
+		t.Error("failures not taken in account:", wv)
+	}
+
+	if s := recovered.score(); s.Timeouts != 3 || s.Requests != 23 {
+		t.Error("wrong statistic:", s.Requests, s.Timeouts)
+	}
+
+}
+
+func Test_connScore_throughput(t *testing.T) {
+
+	var (
+		small = newConnScore(1)
+		large = newConnScore(1)
+	)
+
+	small.success(100*time.Millisecond, 100)
+	large.success(100*time.Millisecond, 100*1024)
+
+	var (
+		sv, _ = small.value()
+		lv, _ = large.value()
+	)
+
+	if lv <= sv {
+		t.Error("throughput not taken in account:", sv, lv)
+	}
+
+}
+
+func Test_sortConnsByScore(t *testing.T) {
+
+	var (
+		slow    = &Conn{score: newConnScore(1)}
+		fast    = &Conn{score: newConnScore(1)}
+		unknown = &Conn{score: newConnScore(1)}
+	)
+
+	slow.score.success(time.Second, 1)
+	fast.score.success(10*time.Millisecond, 1)
+
+	var cs = []*Conn{slow, unknown, fast}
+
+	sortConnsByScore(cs)
+
+	if cs[0] != unknown || cs[1] != fast || cs[2] != slow {
+		t.Error("wrong order")
+	}
+
+	var vals, best = connsValues(cs)
+
+	if vals[unknown] != best || vals[fast] != best {
+		t.Error("wrong values")
+	}
+
+	if n := parallelRequests(vals[fast], best, 4); n != 4 {
+		t.Error("wrong number of parallel requests of fast:", n)
+	}
+
+	if n := parallelRequests(vals[slow], best, 4); n != 1 {
+		t.Error("wrong number of parallel requests of slow:", n)
+	}
+
+}
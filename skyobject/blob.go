@@ -0,0 +1,164 @@
+package skyobject
+
+import (
+	"errors"
+	"io"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// ErrInvalidSeek occurs when (*Blob).Seek
+// called with invalid whence or offset
+var ErrInvalidSeek = errors.New("invalid seek")
+
+// A Blob is io.ReadSeeker of data splitted by the
+// Split method (see Split and Concat for details).
+// The Blob loads pieces of the data from DB lazily,
+// on demand. Thus the Blob can be used to read a
+// part of big data (e.g. by net/http.ServeContent).
+// The Blob is not safe for concurrent use
+type Blob struct {
+	pack registry.Pack
+	refs registry.Refs
+
+	length int   // number of pieces
+	piece  int64 // length of piece (excluding last)
+	size   int64 // total size
+
+	offset int64  // current offset
+	index  int    // index of loaded piece, or -1
+	data   []byte // loaded piece
+}
+
+// Blob returns Blob by hash of the registry.Refs of
+// splitted data. The Blob doesn't need a Root or a
+// Registry. The Blob never changes DB. Objects of
+// the data are obtained from DB (not from remote
+// peers). If an object missing, the Blob returns
+// data.ErrNotFound from Read method
+func (c *Container) Blob(refs cipher.SHA256) (b *Blob, err error) {
+
+	b = new(Blob)
+
+	b.pack = c.getPack(nil)
+	b.refs.Hash = refs
+	b.index = -1
+
+	if b.length, err = b.refs.Len(b.pack); err != nil {
+		return nil, err
+	}
+
+	if b.length == 0 {
+		return // empty
+	}
+
+	// all pieces except last have the same length,
+	// thus we load first piece and the last piece
+	// to get total size of the data
+
+	if err = b.load(b.length - 1); err != nil {
+		return nil, err
+	}
+
+	var last = int64(len(b.data))
+
+	if b.length == 1 {
+		b.piece, b.size = last, last
+		return
+	}
+
+	if err = b.load(0); err != nil {
+		return nil, err
+	}
+
+	b.piece = int64(len(b.data))
+	b.size = b.piece*int64(b.length-1) + last
+	return
+}
+
+// load piece with given index
+func (b *Blob) load(i int) (err error) {
+
+	if b.index == i {
+		return // already loaded
+	}
+
+	var key cipher.SHA256
+	if key, err = b.refs.HashByIndex(b.pack, i); err != nil {
+		return
+	}
+
+	var val []byte
+	if val, err = b.pack.Get(key); err != nil {
+		return
+	}
+
+	var wrap struct {
+		Data []byte
+	}
+
+	if err = encoder.DeserializeRaw(val, &wrap); err != nil {
+		return
+	}
+
+	b.index, b.data = i, wrap.Data
+	return
+}
+
+// Size returns total size of the data
+func (b *Blob) Size() int64 {
+	return b.size
+}
+
+// Read implements io.Reader interface
+func (b *Blob) Read(p []byte) (n int, err error) {
+
+	if b.offset >= b.size {
+		return 0, io.EOF
+	}
+
+	if b.piece == 0 {
+		return 0, io.EOF // pieces of zero length
+	}
+
+	var i = int(b.offset / b.piece)
+
+	if err = b.load(i); err != nil {
+		return
+	}
+
+	var shift = b.offset - int64(i)*b.piece
+
+	if shift >= int64(len(b.data)) {
+		return 0, io.EOF // malformed data
+	}
+
+	n = copy(p, b.data[shift:])
+	b.offset += int64(n)
+	return
+}
+
+// Seek implements io.Seeker interface
+func (b *Blob) Seek(offset int64, whence int) (abs int64, err error) {
+
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = b.offset + offset
+	case io.SeekEnd:
+		abs = b.size + offset
+	default:
+		return 0, ErrInvalidSeek
+	}
+
+	if abs < 0 {
+		return 0, ErrInvalidSeek
+	}
+
+	b.offset = abs
+	return
+}
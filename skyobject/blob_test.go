@@ -0,0 +1,71 @@
+package skyobject
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func Test_blob(t *testing.T) {
+
+	var conf = getTestConfig()
+	conf.MaxObjectSize = MinObjectSize
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var (
+		data = make([]byte, 3000) // 3 pieces
+		refs registry.Refs
+	)
+
+	rand.Read(data)
+
+	assertNil(t, c.Split(c.getPack(nil), bytes.NewReader(data), &refs))
+
+	var b *Blob
+	b, err = c.Blob(refs.Hash)
+	assertNil(t, err)
+
+	if b.Size() != int64(len(data)) {
+		t.Fatal("wrong size:", b.Size())
+	}
+
+	var all []byte
+	all, err = ioutil.ReadAll(b)
+	assertNil(t, err)
+
+	if bytes.Equal(all, data) == false {
+		t.Error("wrong data")
+	}
+
+	// range crossing pieces
+	_, err = b.Seek(1000, io.SeekStart)
+	assertNil(t, err)
+
+	var part = make([]byte, 1500)
+	_, err = io.ReadFull(b, part)
+	assertNil(t, err)
+
+	if bytes.Equal(part, data[1000:2500]) == false {
+		t.Error("wrong range")
+	}
+
+	// empty
+	b, err = c.Blob(registry.Refs{}.Hash)
+	assertNil(t, err)
+
+	if b.Size() != 0 {
+		t.Error("wrong size of empty blob:", b.Size())
+	}
+
+	if _, err = b.Read(part); err != io.EOF {
+		t.Error("wrong error:", err)
+	}
+
+}
@@ -3,6 +3,7 @@ package skyobject
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,8 +19,10 @@ type CachePolicy int
 
 // cache policies
 const (
-	LRU CachePolicy = iota // LRU cache
-	LFU                    // LFU cache
+	LRU      CachePolicy = iota // LRU cache
+	LFU                         // LFU cache
+	ARC                         // Adaptive Replacement Cache
+	WTinyLFU                    // W-TinyLFU cache
 )
 
 // String implements fmt.Stringer interface
@@ -29,10 +32,25 @@ func (c CachePolicy) String() string {
 		return "LRU"
 	case LFU:
 		return "LFU"
+	case ARC:
+		return "ARC"
+	case WTinyLFU:
+		return "W-TinyLFU"
 	}
 	return fmt.Sprintf("CachePolicy<%d>", c)
 }
 
+// Set implements flag.Value interface
+func (c *CachePolicy) Set(s string) (err error) {
+	for _, cp := range []CachePolicy{LRU, LFU, ARC, WTinyLFU} {
+		if strings.EqualFold(s, cp.String()) == true {
+			*c = cp
+			return
+		}
+	}
+	return fmt.Errorf("unknown cache policy %q", s)
+}
+
 // An Object represents DB object
 // that is []byte, its hash and
 // references counter
@@ -50,7 +68,7 @@ type cachePoints int
 func (c *cachePoints) touch(policy CachePolicy) {
 
 	switch policy {
-	case LRU:
+	case LRU, ARC, WTinyLFU: // ARC and W-TinyLFU use LRU for Registries
 		*c = cachePoints(time.Now().Unix()) // last access
 	case LFU:
 		*c++ // access
@@ -79,6 +97,10 @@ type item struct {
 	cc  int    // cached rc
 	rc  int    // db incs
 	val []byte // value
+
+	// dirty item is not saved in DB yet (write-back
+	// mode); rc of a dirty item is zero
+	dirty bool
 }
 
 func (i *item) isWanted() (ok bool) {
@@ -112,6 +134,21 @@ type Cache struct {
 	is map[cipher.SHA256]*item
 	rs map[registry.RegistryRef]*itemRegistry
 
+	ev evictor // nil for LRU and LFU
+
+	shared *SharedCache // process-wide cache or nil
+
+	// write-back mode
+	dirty map[cipher.SHA256]*item // dirty items
+	quit  chan struct{}           // stop flushing
+	await sync.WaitGroup          // wait flushing goroutine
+
+	flushErrs string // error of last flushing on a timer (for Stat)
+
+	// error of flushing on a timer or cleaning by
+	// SharedCache, not returned yet
+	lateErr error
+
 	stat *cxdsStat
 
 	closeo sync.Once
@@ -124,6 +161,15 @@ func (c *Container) initCache() {
 
 	c.Cache.enable = !(c.conf.CacheMaxAmount == 0 || c.conf.CacheMaxVolume == 0)
 
+	var capacity = c.conf.CacheMaxAmount // capacity of evictor
+
+	if c.conf.SharedCache != nil {
+		c.Cache.shared = c.conf.SharedCache
+		c.Cache.shared.attach(&c.Cache, c.conf.CacheWeight)
+		c.Cache.enable = c.Cache.shared.isEnabled()
+		capacity = c.Cache.shared.capacity()
+	}
+
 	c.Cache.amountc = int(
 		float64(c.conf.CacheMaxAmount) * (1.0 - c.conf.CacheCleaning),
 	)
@@ -134,11 +180,22 @@ func (c *Container) initCache() {
 	c.Cache.amount = 0 // cache amount
 	c.Cache.volume = 0 // cache volume
 
-	c.Cache.is = make(map[cipher.SHA256]*item, c.conf.CacheMaxAmount)
+	c.Cache.is = make(map[cipher.SHA256]*item, capacity)
 	c.Cache.rs = make(map[registry.RegistryRef]*itemRegistry,
 		c.conf.CacheRegistries)
 
+	c.Cache.ev = newEvictor(c.conf.CachePolicy, capacity)
+
 	c.Cache.stat = newCxdsStat(c.conf.RollAvgSamples)
+
+	if c.Cache.enable == true && c.conf.CacheWriteBack == true {
+		c.Cache.dirty = make(map[cipher.SHA256]*item)
+		c.Cache.quit = make(chan struct{})
+		if c.conf.CacheFlushInterval > 0 {
+			c.Cache.await.Add(1)
+			go c.Cache.flushLoop(c.conf.CacheFlushInterval)
+		}
+	}
 }
 
 func (c *Cache) amountVolume() (a, v int) {
@@ -152,6 +209,7 @@ func (c *Cache) amountVolume() (a, v int) {
 func (c *Cache) reset() {
 	c.is = nil
 	c.rs = nil
+	c.ev = nil
 	c.stat.Close()
 	c.stat = nil
 }
@@ -166,7 +224,7 @@ func (c *Cache) addRegistryToCache(r *registry.Registry) {
 
 	// if it already exists
 
-	if ir, ok := c.rs[r.Reference()]; ok == true {
+	if ir, ok := c.rs[c.c.RegistryRef(r)]; ok == true {
 		ir.touch(c.c.conf.CachePolicy)
 		return
 	}
@@ -196,7 +254,7 @@ func (c *Cache) addRegistryToCache(r *registry.Registry) {
 	var ir = &itemRegistry{r: r}
 
 	ir.touch(c.c.conf.CachePolicy)
-	c.rs[r.Reference()] = ir
+	c.rs[c.c.RegistryRef(r)] = ir
 }
 
 // AddRegistryToCache adds given registry to Cache
@@ -256,9 +314,19 @@ func (c *Cache) Registry(
 // or nil
 func (c *Cache) Close() (err error) {
 
+	if c.quit != nil {
+		close(c.quit)
+		c.await.Wait() // stop flushing
+	}
+
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
+	// save dirty items by one write
+	if err = c.writeBack(); err != nil {
+		return
+	}
+
 	// sync items
 	for key, it := range c.is {
 
@@ -279,16 +347,48 @@ func (c *Cache) Close() (err error) {
 
 	}
 
+	if c.shared != nil {
+		c.shared.detach(c) // free limits for other Caches
+	}
+
 	// close the CXDS
-	err = c.c.db.CXDS().Close()
+	if err = c.c.db.CXDS().Close(); err == nil {
+		err = c.lateErr // not returned yet, if any
+	}
 	c.stat.Close() // close goroutine
 
 	return
 }
 
+// item with value added to the Cache, under lock
+func (c *Cache) add(key cipher.SHA256, it *item) {
+	if c.ev != nil {
+		c.ev.add(key)
+		return
+	}
+	it.touch(c.c.conf.CachePolicy)
+}
+
+// item accessed, under lock
+func (c *Cache) touch(key cipher.SHA256, it *item) {
+	if c.ev != nil {
+		c.ev.access(key)
+		return
+	}
+	it.touch(c.c.conf.CachePolicy)
+}
+
 // delete item from the Cache
 func (c *Cache) delete(key cipher.SHA256, it *item) (err error) {
 
+	// flush all dirty items by one write, since
+	// evicted dirty item should be saved anyway
+	if it.dirty == true {
+		if err = c.flush(); err != nil {
+			return
+		}
+	}
+
 	var inc = it.cc - it.rc // real rc
 
 	if inc != 0 {
@@ -305,6 +405,14 @@ func (c *Cache) delete(key cipher.SHA256, it *item) (err error) {
 	c.amount--
 	c.volume -= len(it.val)
 
+	if c.ev != nil {
+		c.ev.remove(key)
+	}
+
+	if c.shared != nil && it.isFilling() == false {
+		c.shared.release(key)
+	}
+
 	if it.fc == 0 {
 		delete(c.is, key)
 		return
@@ -328,6 +436,10 @@ func (c *Cache) cleanDown(vol int) (err error) {
 	var tp = time.Now()
 	defer func() { c.stat.addCacheCleaning(time.Now().Sub(tp)) }()
 
+	if c.ev != nil {
+		return c.evictDown(vol)
+	}
+
 	type rankItem struct {
 		key cipher.SHA256
 		it  *item
@@ -338,11 +450,11 @@ func (c *Cache) cleanDown(vol int) (err error) {
 	for key, it := range c.is {
 
 		if it.isWanted() == true {
-			return // skip wanted
+			continue // skip wanted
 		}
 
 		if it.isFilling() == true {
-			return // skip filling (where val is nil)
+			continue // skip filling (where val is nil)
 		}
 
 		rank = append(rank, &rankItem{key, it})
@@ -356,9 +468,11 @@ func (c *Cache) cleanDown(vol int) (err error) {
 		return rank[i].it.cachePoints < rank[j].it.cachePoints
 	})
 
+	var maxAmount, maxVolume, amountc, volumec = c.limits()
+
 	// clean by amount first
 
-	if c.amount+1 > c.c.conf.CacheMaxAmount {
+	if c.amount+1 > maxAmount {
 
 		var (
 			i  int       // to reduce the rank slice
@@ -367,7 +481,7 @@ func (c *Cache) cleanDown(vol int) (err error) {
 
 		for i, ri = range rank {
 
-			if c.amount < c.amountc { // actually, `amount + 1 == ...`
+			if c.amount < amountc { // actually, `amount + 1 == ...`
 				break
 			}
 
@@ -386,13 +500,13 @@ func (c *Cache) cleanDown(vol int) (err error) {
 
 	// clean by volume if need
 
-	if c.volume+vol < c.c.conf.CacheMaxVolume {
+	if c.volume+vol < maxVolume {
 		return // enough
 	}
 
 	for i, ri := range rank {
 
-		if c.volume+vol <= c.volumec {
+		if c.volume+vol <= volumec {
 			break
 		}
 
@@ -407,6 +521,117 @@ func (c *Cache) cleanDown(vol int) (err error) {
 	return
 }
 
+// clean the Cache down to lower boundary using
+// the evictor; the evictDown is O(1) per item
+func (c *Cache) evictDown(vol int) (err error) {
+
+	var (
+		key cipher.SHA256
+		ok  bool
+
+		maxAmount, maxVolume, amountc, volumec = c.limits()
+	)
+
+	// clean by amount first
+
+	if c.amount+1 > maxAmount {
+
+		for c.amount >= amountc { // actually, `amount + 1 == ...`
+
+			if key, ok = c.ev.victim(); ok == false {
+				break // nothing to evict
+			}
+
+			// delete item from the Cache
+			if err = c.delete(key, c.is[key]); err != nil {
+				return // fail on first error
+			}
+
+		}
+
+	}
+
+	// clean by volume if need
+
+	if c.volume+vol < maxVolume {
+		return // enough
+	}
+
+	for c.volume+vol > volumec {
+
+		if key, ok = c.ev.victim(); ok == false {
+			break // nothing to evict
+		}
+
+		if err = c.delete(key, c.is[key]); err != nil {
+			return // fail on first error
+		}
+
+	}
+
+	return
+}
+
+// limits of the Cache: max amount and max volume, and
+// lower boundaries to clean down to; if the Cache uses
+// a SharedCache, then the limits depend on free space
+// of the SharedCache and weight of the Cache (see
+// SharedCache); under lock
+func (c *Cache) limits() (maxAmount, maxVolume, amountc, volumec int) {
+
+	if c.shared == nil {
+		return c.c.conf.CacheMaxAmount, c.c.conf.CacheMaxVolume,
+			c.amountc, c.volumec
+	}
+
+	return c.shared.limits(c, c.amount, c.volume, c.c.conf.CacheCleaning)
+}
+
+// reclaim cleans the Cache down, if its SharedCache
+// is full and the Cache uses more then its share;
+// an error is returned by next Flush, Set, Inc,
+// Close, or Container.Save
+func (c *Cache) reclaim() {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.shared.isAttached(c) == false {
+		return // closed
+	}
+
+	if c.shared.isBorrower(c, c.amount, c.volume) == false ||
+		c.isFull(0) == false {
+
+		return
+	}
+
+	if err := c.cleanDown(0); err != nil && c.lateErr == nil {
+		c.lateErr = err
+	}
+}
+
+// is there no place for a value with given
+// size in the Cache, under lock
+func (c *Cache) isFull(vol int) bool {
+	var maxAmount, maxVolume, _, _ = c.limits()
+	return c.amount+1 > maxAmount || c.volume+vol > maxVolume
+}
+
+// is a value with given key already interned by
+// SharedCache, and it doesn't take place, under lock
+func (c *Cache) isInterned(key cipher.SHA256) bool {
+	return c.shared != nil && c.shared.has(key)
+}
+
+// deduplicate value using SharedCache, if any
+func (c *Cache) intern(key cipher.SHA256, val []byte) []byte {
+	if c.shared == nil || len(val) == 0 {
+		return val // empty values are not released (see delete)
+	}
+	return c.shared.intern(key, val)
+}
+
 // create regular item in the cache
 func (c *Cache) putItem(
 	key cipher.SHA256,
@@ -416,6 +641,23 @@ func (c *Cache) putItem(
 	err error,
 ) {
 
+	_, err = c.putItemRC(key, val, rc, rc)
+	return
+}
+
+// create regular item in the cache, the rc is rc
+// in DB and the cc is rc in the cache; the item
+// is nil if the value is not cached
+func (c *Cache) putItemRC(
+	key cipher.SHA256, // :
+	val []byte, //        :
+	rc int, //            : rc in DB
+	cc int, //            : rc in the Cache
+) (
+	it *item, //          : created item or nil
+	err error, //         : cleaning error
+) {
+
 	if c.enable == false {
 		return
 	}
@@ -424,24 +666,22 @@ func (c *Cache) putItem(
 		return
 	}
 
-	if rc == 0 {
+	if cc == 0 {
 		return // don't cache stale values
 	}
 
-	switch {
-	case c.amount+1 > c.c.conf.CacheMaxAmount,
-		c.volume+len(val) > c.c.conf.CacheMaxVolume:
-
+	if c.isInterned(key) == false && c.isFull(len(val)) == true {
 		if err = c.cleanDown(len(val)); err != nil {
 			return
 		}
-
 	}
 
-	var it = &item{rc: rc, cc: rc, val: val}
+	val = c.intern(key, val) // deduplicate
+
+	it = &item{rc: rc, cc: cc, val: val}
 
-	it.touch(c.c.conf.CachePolicy)
 	c.is[key] = it
+	c.add(key, it)
 
 	c.amount++
 	c.volume += len(val)
@@ -452,6 +692,7 @@ func (c *Cache) putItem(
 // create item with fc > 0; e.g.
 // add data to the filling item
 func (c *Cache) putFillingItem(
+	key cipher.SHA256,
 	val []byte,
 	rc int,
 	it *item, // filling item
@@ -471,21 +712,19 @@ func (c *Cache) putFillingItem(
 		return // don't cache stale values
 	}
 
-	switch {
-	case c.amount+1 > c.c.conf.CacheMaxAmount,
-		c.volume+len(val) > c.c.conf.CacheMaxVolume:
-
+	if c.isInterned(key) == false && c.isFull(len(val)) == true {
 		if err = c.cleanDown(len(val)); err != nil {
 			return
 		}
-
 	}
 
+	val = c.intern(key, val) // deduplicate
+
 	it.val = val
 	it.rc = rc // real
 	it.cc = rc // real
 
-	it.touch(c.c.conf.CachePolicy)
+	c.add(key, it)
 
 	c.amount++
 	c.volume += len(val)
@@ -540,7 +779,7 @@ func (c *Cache) getFilling(
 
 	rc = int(urc) - it.fc
 
-	err = c.putFillingItem(val, int(urc), it)
+	err = c.putFillingItem(key, val, int(urc), it)
 	return
 }
 
@@ -631,7 +870,7 @@ func (c *Cache) get(
 			c.delete(key, it)
 		} else {
 			c.stat.addCacheGet(inc) // effective cache get
-			it.touch(c.c.conf.CachePolicy)
+			c.touch(key, it)
 		}
 
 		rc = it.cc - it.fc // hard rc
@@ -702,6 +941,45 @@ func (c *Cache) Get(
 	return c.get(key, inc)
 }
 
+// View calls given function with value of object
+// with given key. If the value is cached, then the
+// View uses the cached value. Otherwise, the View
+// reads the value in place, without copying, if
+// CXDS of the Container implements data.Viewer.
+// The val is valid only inside the viewFunc and
+// must not be modified or retained. The View never
+// changes references counters and never caches the
+// value. It returns data.ErrNotFound if object
+// doesn't exist (or it's not filled yet)
+func (c *Cache) View(
+	key cipher.SHA256, //                     : hash of the object
+	viewFunc func(val []byte) (err error), // : read the value in place
+) (
+	err error, //                             : error if any
+) {
+
+	c.mx.Lock()
+
+	if it, ok := c.is[key]; ok == true && it.isFilling() == false {
+		var val = it.val // values of the Cache never change
+		c.stat.addCacheGet(0)
+		c.touch(key, it)
+		c.mx.Unlock()
+
+		return viewFunc(val)
+	}
+
+	c.mx.Unlock()
+
+	// a dirty item can't be removed from the Cache without
+	// flushing, thus it's safe to read DB out of the lock
+
+	c.stat.addDBGet(0)
+	return data.View(c.db(), key, func(val []byte, _ int64) error {
+		return viewFunc(val)
+	})
+}
+
 // never block
 func sendWanted(gc chan<- Object, obj Object) {
 	select {
@@ -763,7 +1041,7 @@ func (c *Cache) setWanted(
 	it.fwant = nil // not wanted anymore (GC)
 	it.fc += wincs // incs of fillers (of wanters)
 
-	err = c.putFillingItem(val, int(urc), it)
+	err = c.putFillingItem(key, val, int(urc), it)
 	return
 }
 
@@ -831,7 +1109,7 @@ func (c *Cache) Set(
 			c.delete(key, it) // not effective cache set
 		} else {
 			c.stat.addWritingCacheRequest() // effective cache set
-			it.touch(c.c.conf.CachePolicy)
+			c.touch(key, it)
 		}
 
 		rc = it.cc - it.fc // hard rc
@@ -846,6 +1124,10 @@ func (c *Cache) Set(
 		return
 	}
 
+	if c.isWriteBack(val) == true {
+		return c.setBack(key, val, inc)
+	}
+
 	var urc uint32
 	urc, err = c.db().Set(key, val, inc)
 	c.stat.addWritingDBRequest()
@@ -859,6 +1141,222 @@ func (c *Cache) Set(
 	return
 }
 
+//
+// write-back
+//
+
+// is the value should be written back (later)
+func (c *Cache) isWriteBack(val []byte) bool {
+	return c.dirty != nil && len(val) <= c.c.conf.CacheMaxItemSize
+}
+
+// set value in write-back mode, the value is
+// not in the cache, and it can be cached; if
+// the value exists in DB, then its rc changed
+// in the cache only (like for any cached
+// value), otherwise the value will be saved
+// in DB later
+func (c *Cache) setBack(
+	key cipher.SHA256,
+	val []byte,
+	inc int,
+) (
+	rc int,
+	err error,
+) {
+
+	var urc uint32
+	_, urc, err = c.db().Get(key, 0)
+	c.stat.addDBGet(0)
+
+	var it *item
+
+	switch err {
+	case nil:
+		// exists, change rc in the cache
+		if it, err = c.putItemRC(key, val, int(urc), int(urc)+inc); err != nil {
+			return
+		}
+	case data.ErrNotFound:
+		// new value, it's dirty
+		if it, err = c.putItemRC(key, val, 0, inc); err != nil {
+			return
+		}
+		if it != nil {
+			it.dirty = true
+			c.dirty[key] = it
+		}
+	default:
+		return // DB failure
+	}
+
+	if it == nil {
+		// not cached, write through
+		if urc, err = c.db().Set(key, val, inc); err != nil {
+			return
+		}
+		c.stat.addWritingDBRequest()
+		return int(urc), nil
+	}
+
+	c.stat.addWritingCacheRequest() // effective cache set
+	rc = it.cc
+
+	if len(c.dirty) >= c.c.conf.CacheMaxDirty {
+		err = c.flush()
+	}
+
+	return
+}
+
+// under lock, save all dirty items in DB by one
+// batched write; it returns error of flushing on
+// a timer (see flushLoop) or cleaning by SharedCache
+// (see reclaim) if the error is not returned yet,
+// even if the items saved now
+func (c *Cache) flush() (err error) {
+
+	err = c.writeBack()
+
+	if c.lateErr != nil {
+		err, c.lateErr = c.lateErr, nil
+	}
+
+	return
+}
+
+// under lock, save all dirty items
+// in DB by one batched write
+func (c *Cache) writeBack() (err error) {
+
+	if len(c.dirty) == 0 {
+		return
+	}
+
+	var (
+		keys  = make([]cipher.SHA256, 0, len(c.dirty))
+		items = make([]data.BatchItem, 0, len(c.dirty))
+	)
+
+	for key, it := range c.dirty {
+
+		// the value is not used anymore and
+		// it never will be saved in DB
+		if it.cc <= 0 {
+			it.dirty = false
+			delete(c.dirty, key)
+			continue
+		}
+
+		keys = append(keys, key)
+		items = append(items, data.BatchItem{
+			Key:    key,
+			Val:    it.val,
+			IncrBy: int64(it.cc - it.rc),
+		})
+
+	}
+
+	if len(items) == 0 {
+		return
+	}
+
+	var rcs []int64
+	rcs, err = data.SetBatch(c.db(), items)
+	c.stat.addWritingDBRequest()
+
+	if err != nil {
+		return // DB failure, the items are still dirty
+	}
+
+	for i, key := range keys {
+		var it = c.dirty[key]
+
+		it.rc = int(rcs[i])
+		it.cc = int(rcs[i])
+
+		it.dirty = false
+		delete(c.dirty, key)
+	}
+
+	return
+}
+
+// Flush saves all dirty items in DB. In write-back
+// mode (see Config.CacheWriteBack) the Cache keeps
+// new values and saves them in DB later. The values
+// are saved all together, by one write transaction
+// if the CXDS implements data.Batcher, on a timer,
+// when number of dirty values reaches the Config.
+// CacheMaxDirty, when a dirty value evicted, on
+// Close, or by the Flush. The Container.Save flushes
+// dirty values before the Save returns, thus, a Root
+// is never reported saved before its objects saved.
+// If flushing on a timer fails, then the values are
+// still dirty, and the error is returned by next
+// Flush (or Save, or Close) even if the values saved
+// by the call. See also Stat.CacheFlushError. The
+// Flush does nothing in write-through mode
+func (c *Cache) Flush() (err error) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.flush()
+}
+
+// Dirty returns number of
+// values not saved in DB yet
+func (c *Cache) Dirty() (amount int) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return len(c.dirty)
+}
+
+// flushError returns error of last
+// flushing on a timer, if any
+func (c *Cache) flushError() (err string) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.flushErrs
+}
+
+// flush dirty items on a timer; an error is kept
+// and returned by next Flush, Set, Inc, Close, or
+// Container.Save, the items are still dirty
+func (c *Cache) flushLoop(interval time.Duration) {
+	defer c.await.Done()
+
+	var tk = time.NewTicker(interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			c.timerFlush()
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+func (c *Cache) timerFlush() {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if err := c.writeBack(); err != nil {
+		c.lateErr, c.flushErrs = err, err.Error()
+		return
+	}
+
+	c.flushErrs = ""
+}
+
 func (c *Cache) incFilling(
 	key cipher.SHA256,
 	inc int,
@@ -887,7 +1385,7 @@ func (c *Cache) incFilling(
 
 	rc = int(urc) - it.fc // hard rc
 
-	err = c.putFillingItem(val, int(urc), it)
+	err = c.putFillingItem(key, val, int(urc), it)
 	return
 }
 
@@ -913,7 +1411,7 @@ func (c *Cache) incItem(
 		c.delete(key, it)
 	} else {
 		c.stat.addCacheGet(inc) // effective cache get
-		it.touch(c.c.conf.CachePolicy)
+		c.touch(key, it)
 	}
 
 	rc = it.cc - it.fc // hard rc
@@ -1050,7 +1548,7 @@ func (c *Cache) Want(
 			c.stat.addWritingCacheRequest() // effective
 		}
 
-		it.touch(c.c.conf.CachePolicy)
+		c.touch(key, it)
 		return
 
 	}
@@ -1088,7 +1586,7 @@ func (c *Cache) Want(
 	it.fc = inc
 	c.is[key] = it
 
-	err = c.putFillingItem(val, int(urc), it)
+	err = c.putFillingItem(key, val, int(urc), it)
 	return
 }
 
@@ -1205,7 +1703,7 @@ func (c *Cache) Finc(
 		// a filling items turns to be a regular (since the fc is zero)
 
 		// keep
-		it.touch(c.c.conf.CachePolicy)
+		c.touch(key, it)
 		return
 	}
 
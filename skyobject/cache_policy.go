@@ -0,0 +1,376 @@
+package skyobject
+
+import (
+	"container/list"
+	"encoding/binary"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// An evictor tracks cached values and chooses values to
+// evict in O(1). The evictor used by ARC and WTinyLFU
+// policies. The LRU and LFU use cachePoints instead.
+// The evictor tracks only items with values, e.g. not
+// wanted and not filling items. The evictor never evicts
+// items itself, the Cache asks for a victim while it
+// needs free space
+type evictor interface {
+	add(key cipher.SHA256)                // value added to the Cache
+	access(key cipher.SHA256)             // value accessed
+	remove(key cipher.SHA256)             // value removed from the Cache
+	victim() (key cipher.SHA256, ok bool) // value to evict
+}
+
+// create evictor for given policy,
+// or nil for LRU and LFU
+func newEvictor(policy CachePolicy, capacity int) evictor {
+	switch policy {
+	case ARC:
+		return newArc(capacity)
+	case WTinyLFU:
+		return newTinyLFU(capacity)
+	}
+	return nil
+}
+
+// list of keys with index
+type keyList struct {
+	l *list.List
+	m map[cipher.SHA256]*list.Element
+}
+
+func newKeyList() (k *keyList) {
+	k = new(keyList)
+	k.l = list.New()
+	k.m = make(map[cipher.SHA256]*list.Element)
+	return
+}
+
+func (k *keyList) len() int {
+	return k.l.Len()
+}
+
+func (k *keyList) has(key cipher.SHA256) (ok bool) {
+	_, ok = k.m[key]
+	return
+}
+
+// push to front (MRU)
+func (k *keyList) push(key cipher.SHA256) {
+	k.m[key] = k.l.PushFront(key)
+}
+
+// move to front (MRU)
+func (k *keyList) touch(key cipher.SHA256) (ok bool) {
+	var el *list.Element
+	if el, ok = k.m[key]; ok == true {
+		k.l.MoveToFront(el)
+	}
+	return
+}
+
+func (k *keyList) remove(key cipher.SHA256) (ok bool) {
+	var el *list.Element
+	if el, ok = k.m[key]; ok == true {
+		k.l.Remove(el)
+		delete(k.m, key)
+	}
+	return
+}
+
+// back (LRU)
+func (k *keyList) back() (key cipher.SHA256, ok bool) {
+	var el = k.l.Back()
+	if el == nil {
+		return
+	}
+	return el.Value.(cipher.SHA256), true
+}
+
+// remove and return back (LRU)
+func (k *keyList) pop() (key cipher.SHA256, ok bool) {
+	if key, ok = k.back(); ok == true {
+		k.remove(key)
+	}
+	return
+}
+
+//
+// ARC
+//
+
+// an arc implements Adaptive Replacement Cache; the
+// t1 and t2 keep recent and frequent values, and the
+// b1 and b2 are ghost lists of evicted keys; the
+// capacity used to limit ghost lists and to adapt
+// the target size of the t1
+type arc struct {
+	capacity int // capacity (amount of items)
+	p        int // target size of the t1
+
+	t1, t2 *keyList // values
+	b1, b2 *keyList // ghosts
+}
+
+func newArc(capacity int) (a *arc) {
+	a = new(arc)
+	a.capacity = capacity
+	a.t1, a.t2 = newKeyList(), newKeyList()
+	a.b1, a.b2 = newKeyList(), newKeyList()
+	return
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (a *arc) add(key cipher.SHA256) {
+
+	switch {
+	case a.b1.remove(key) == true:
+		// recently evicted from the t1, it's frequent
+		a.p = minInt(a.capacity, a.p+maxInt(a.b2.len()/(a.b1.len()+1), 1))
+		a.t2.push(key)
+	case a.b2.remove(key) == true:
+		// recently evicted from the t2
+		a.p = maxInt(0, a.p-maxInt(a.b1.len()/(a.b2.len()+1), 1))
+		a.t2.push(key)
+	default:
+		a.t1.push(key)
+	}
+
+	// limit ghosts
+	for a.t1.len()+a.b1.len() > a.capacity && a.b1.len() > 0 {
+		a.b1.pop()
+	}
+	for a.b1.len()+a.b2.len() > a.capacity && a.b2.len() > 0 {
+		a.b2.pop()
+	}
+
+}
+
+func (a *arc) access(key cipher.SHA256) {
+	if a.t1.remove(key) == true {
+		a.t2.push(key) // seen twice
+		return
+	}
+	a.t2.touch(key)
+}
+
+func (a *arc) remove(key cipher.SHA256) {
+	if a.t1.remove(key) == false {
+		a.t2.remove(key)
+	}
+}
+
+func (a *arc) victim() (key cipher.SHA256, ok bool) {
+
+	if a.t1.len() > 0 && (a.t1.len() > a.p || a.t2.len() == 0) {
+		if key, ok = a.t1.pop(); ok == true {
+			a.b1.push(key)
+		}
+		return
+	}
+
+	if key, ok = a.t2.pop(); ok == true {
+		a.b2.push(key)
+	}
+	return
+}
+
+//
+// W-TinyLFU
+//
+
+// a sketch is count-min sketch with
+// 4 rows of saturated 4-bit counters
+// (one counter per byte for simplicity)
+// and periodic aging
+type sketch struct {
+	rows    [4][]uint8
+	mask    uint64
+	adds    int // additions since last reset
+	samples int // reset after this number of additions
+}
+
+func newSketch(capacity int) (s *sketch) {
+
+	var width = 16
+	for width < capacity {
+		width <<= 1
+	}
+
+	s = new(sketch)
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	s.mask = uint64(width - 1)
+	s.samples = 10 * width
+	return
+}
+
+// the key is hash already, thus different
+// parts of the key are used for rows
+func (s *sketch) index(key cipher.SHA256, row int) uint64 {
+	return binary.LittleEndian.Uint64(key[row*8:]) & s.mask
+}
+
+func (s *sketch) increment(key cipher.SHA256) {
+
+	for i := range s.rows {
+		if c := &s.rows[i][s.index(key, i)]; *c < 15 {
+			*c++
+		}
+	}
+
+	if s.adds++; s.adds >= s.samples {
+		s.reset()
+	}
+}
+
+// estimated frequency
+func (s *sketch) estimate(key cipher.SHA256) (freq uint8) {
+	freq = 15
+	for i := range s.rows {
+		if c := s.rows[i][s.index(key, i)]; c < freq {
+			freq = c
+		}
+	}
+	return
+}
+
+// aging
+func (s *sketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.adds /= 2
+}
+
+// a tinyLFU implements W-TinyLFU; new values added to
+// a small LRU window; values evicted from the window are
+// candidates to the main segmented LRU (probation and
+// protected); if the main is full, then a candidate is
+// admitted only if its estimated frequency is greater
+// then frequency of probation victim, otherwise the
+// candidate evicted
+type tinyLFU struct {
+	freq *sketch
+
+	windowMax int // max length of the window
+	mainMax   int // max length of the main
+
+	window    *keyList // 1% of values
+	probation *keyList // 20% of main
+	protected *keyList // 80% of main
+}
+
+func newTinyLFU(capacity int) (t *tinyLFU) {
+	t = new(tinyLFU)
+	t.freq = newSketch(capacity)
+	t.windowMax = maxInt(capacity/100, 1)
+	t.mainMax = maxInt(capacity-t.windowMax, 1)
+	t.window = newKeyList()
+	t.probation = newKeyList()
+	t.protected = newKeyList()
+	return
+}
+
+func (t *tinyLFU) len() int {
+	return t.window.len() + t.probation.len() + t.protected.len()
+}
+
+func (t *tinyLFU) add(key cipher.SHA256) {
+	t.freq.increment(key)
+	t.window.push(key)
+
+	// move candidate to the main if it's not full,
+	// otherwise the candidate stays in the window
+	// until next eviction
+
+	if t.window.len() <= t.windowMax {
+		return
+	}
+
+	if t.probation.len()+t.protected.len() < t.mainMax {
+		var candidate, _ = t.window.pop()
+		t.probation.push(candidate)
+	}
+}
+
+func (t *tinyLFU) access(key cipher.SHA256) {
+
+	t.freq.increment(key)
+
+	if t.window.touch(key) == true || t.protected.touch(key) == true {
+		return
+	}
+
+	if t.probation.remove(key) == false {
+		return // not tracked
+	}
+
+	t.protected.push(key) // promote
+
+	// keep the protected segment 80% of main
+	var main = t.probation.len() + t.protected.len()
+	for t.protected.len() > main*8/10 {
+		var demoted, _ = t.protected.pop()
+		t.probation.push(demoted)
+	}
+}
+
+func (t *tinyLFU) remove(key cipher.SHA256) {
+	if t.window.remove(key) == false && t.probation.remove(key) == false {
+		t.protected.remove(key)
+	}
+}
+
+// victim of the main
+func (t *tinyLFU) mainVictim() (key cipher.SHA256, ok bool) {
+	if key, ok = t.probation.back(); ok == true {
+		return
+	}
+	return t.protected.back()
+}
+
+func (t *tinyLFU) victim() (key cipher.SHA256, ok bool) {
+
+	if t.window.len() <= t.windowMax {
+		if key, ok = t.mainVictim(); ok == true {
+			return
+		}
+		return t.window.back()
+	}
+
+	// the window is full, its LRU is candidate
+
+	var candidate, _ = t.window.back()
+
+	var victim cipher.SHA256
+	if victim, ok = t.mainVictim(); ok == false {
+		return candidate, true // main is empty
+	}
+
+	// admission filter
+
+	if t.freq.estimate(candidate) > t.freq.estimate(victim) {
+		t.window.remove(candidate)
+		t.probation.push(candidate) // admitted
+		return victim, true
+	}
+
+	return candidate, true // rejected
+}
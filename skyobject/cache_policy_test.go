@@ -0,0 +1,295 @@
+package skyobject
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func testKey(i int) (key cipher.SHA256) {
+	var p [8]byte
+	binary.LittleEndian.PutUint64(p[:], uint64(i))
+	return cipher.SumSHA256(p[:])
+}
+
+func shouldBeVictim(t *testing.T, ev evictor, want cipher.SHA256) {
+	t.Helper()
+
+	var key, ok = ev.victim()
+
+	if ok == false {
+		t.Fatal("no victim")
+	}
+
+	if key != want {
+		t.Fatalf("wrong victim %s, want %s", key.Hex()[:7], want.Hex()[:7])
+	}
+
+	ev.remove(key)
+}
+
+func TestArc(t *testing.T) {
+
+	var a = newArc(4)
+
+	if _, ok := a.victim(); ok == true {
+		t.Error("victim of empty ARC")
+	}
+
+	for i := 0; i < 4; i++ {
+		a.add(testKey(i))
+	}
+
+	a.access(testKey(0)) // move to t2
+
+	// recent first
+	shouldBeVictim(t, a, testKey(1))
+	shouldBeVictim(t, a, testKey(2))
+
+	if a.b1.has(testKey(1)) == false {
+		t.Error("missing ghost")
+	}
+
+	// ghost hit, frequent
+	a.add(testKey(1))
+
+	if a.t2.has(testKey(1)) == false {
+		t.Error("ghost hit is not in t2")
+	}
+
+	if a.p == 0 {
+		t.Error("target is not adapted")
+	}
+
+	// removed (not evicted) is not a ghost
+	a.remove(testKey(3))
+	if a.b1.has(testKey(3)) == true || a.b2.has(testKey(3)) == true {
+		t.Error("removed item is a ghost")
+	}
+}
+
+func TestTinyLFU(t *testing.T) {
+
+	var (
+		tl       = newTinyLFU(100)
+		frequent = testKey(-1)
+	)
+
+	tl.add(frequent)
+	for i := 0; i < 10; i++ {
+		tl.access(frequent)
+	}
+
+	// scan of one-time keys never evicts frequent key
+
+	var resident = map[cipher.SHA256]struct{}{frequent: {}}
+
+	for i := 0; i < 1000; i++ {
+		var key = testKey(i)
+		tl.add(key)
+		resident[key] = struct{}{}
+
+		for len(resident) > 100 {
+			var victim, ok = tl.victim()
+			if ok == false {
+				t.Fatal("no victim")
+			}
+			if victim == frequent {
+				t.Fatal("frequent key evicted")
+			}
+			tl.remove(victim)
+			delete(resident, victim)
+		}
+	}
+
+	if tl.len() != len(resident) {
+		t.Error("wrong length", tl.len(), len(resident))
+	}
+}
+
+func TestSketch(t *testing.T) {
+
+	var s = newSketch(16)
+
+	for i := 0; i < 20; i++ {
+		s.increment(testKey(1))
+	}
+
+	if f := s.estimate(testKey(1)); f != 15 {
+		t.Error("not saturated", f)
+	}
+
+	s.reset()
+
+	if f := s.estimate(testKey(1)); f != 7 {
+		t.Error("not aged", f)
+	}
+}
+
+//
+// hit rate benchmarks
+//
+
+// a policySim simulates the Cache
+type policySim interface {
+	get(key cipher.SHA256) (hit bool)
+}
+
+// ARC and W-TinyLFU
+type evictorSim struct {
+	ev       evictor
+	resident map[cipher.SHA256]struct{}
+	max, low int
+}
+
+func newEvictorSim(policy CachePolicy, max int) *evictorSim {
+	return &evictorSim{
+		ev:       newEvictor(policy, max),
+		resident: make(map[cipher.SHA256]struct{}),
+		max:      max,
+		low:      int(float64(max) * (1.0 - CacheCleaning)),
+	}
+}
+
+func (e *evictorSim) get(key cipher.SHA256) (hit bool) {
+
+	if _, hit = e.resident[key]; hit == true {
+		e.ev.access(key)
+		return
+	}
+
+	if len(e.resident)+1 > e.max {
+		for len(e.resident) >= e.low {
+			var victim, _ = e.ev.victim()
+			e.ev.remove(victim)
+			delete(e.resident, victim)
+		}
+	}
+
+	e.resident[key] = struct{}{}
+	e.ev.add(key)
+	return
+}
+
+// LRU and LFU
+type pointsSim struct {
+	policy   CachePolicy
+	clock    cachePoints // logical clock for LRU
+	resident map[cipher.SHA256]cachePoints
+	max, low int
+}
+
+func newPointsSim(policy CachePolicy, max int) *pointsSim {
+	return &pointsSim{
+		policy:   policy,
+		resident: make(map[cipher.SHA256]cachePoints),
+		max:      max,
+		low:      int(float64(max) * (1.0 - CacheCleaning)),
+	}
+}
+
+func (p *pointsSim) touch(key cipher.SHA256) {
+	if p.policy == LRU {
+		p.clock++
+		p.resident[key] = p.clock
+		return
+	}
+	p.resident[key]++
+}
+
+func (p *pointsSim) get(key cipher.SHA256) (hit bool) {
+
+	if _, hit = p.resident[key]; hit == true {
+		p.touch(key)
+		return
+	}
+
+	if len(p.resident)+1 > p.max {
+
+		var rank = make([]cipher.SHA256, 0, len(p.resident))
+		for k := range p.resident {
+			rank = append(rank, k)
+		}
+
+		sort.Slice(rank, func(i, j int) bool {
+			return p.resident[rank[i]] < p.resident[rank[j]]
+		})
+
+		for _, k := range rank[:len(rank)-p.low] {
+			delete(p.resident, k)
+		}
+
+	}
+
+	p.touch(key)
+	return
+}
+
+// feedWorkload generates keys of a feed workload: popular old
+// objects (Zipf distribution), objects of recent Root objects
+// that read many times shortly, and one-time scans of fillers
+type feedWorkload struct {
+	rnd    *rand.Rand
+	zipf   *rand.Zipf
+	recent int // seq of last new object
+	scan   int // seq of last scanned object
+}
+
+func newFeedWorkload() (f *feedWorkload) {
+	f = new(feedWorkload)
+	f.rnd = rand.New(rand.NewSource(42))
+	f.zipf = rand.NewZipf(f.rnd, 1.1, 1, 100000)
+	return
+}
+
+func (f *feedWorkload) next() cipher.SHA256 {
+
+	switch n := f.rnd.Intn(100); {
+	case n < 55: // popular
+		return testKey(int(f.zipf.Uint64()))
+	case n < 85: // recent Roots
+		if f.rnd.Intn(10) == 0 {
+			f.recent++ // new Root
+		}
+		return testKey(1000000 + f.recent - f.rnd.Intn(64))
+	default: // filling
+		f.scan++
+		return testKey(10000000 + f.scan)
+	}
+
+}
+
+func benchmarkHitRate(b *testing.B, sim policySim) {
+
+	var (
+		wl   = newFeedWorkload()
+		hits int
+	)
+
+	for i := 0; i < b.N; i++ {
+		if sim.get(wl.next()) == true {
+			hits++
+		}
+	}
+
+	b.ReportMetric(100*float64(hits)/float64(b.N), "hit%")
+}
+
+func BenchmarkCachePolicy_LRU(b *testing.B) {
+	benchmarkHitRate(b, newPointsSim(LRU, 2048))
+}
+
+func BenchmarkCachePolicy_LFU(b *testing.B) {
+	benchmarkHitRate(b, newPointsSim(LFU, 2048))
+}
+
+func BenchmarkCachePolicy_ARC(b *testing.B) {
+	benchmarkHitRate(b, newEvictorSim(ARC, 2048))
+}
+
+func BenchmarkCachePolicy_WTinyLFU(b *testing.B) {
+	benchmarkHitRate(b, newEvictorSim(WTinyLFU, 2048))
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/node/log"
@@ -27,6 +28,13 @@ const (
 	// CacheMaxItemSize is 1M (CacheMaxVolume*(1.0-CacheCleaning) / 2)
 	CacheMaxItemSize int = 1024 * 1024
 
+	CacheFlushInterval time.Duration = time.Second // write-back flushing
+	CacheMaxDirty      int           = 1024        // write-back flushing
+	CacheWeight        float64       = 1.0         // share of SharedCache
+
+	PreviewCacheMaxAmount int = 1024            // 1K
+	PreviewCacheMaxVolume int = 4 * 1024 * 1024 // 4M
+
 	// default CachePolicy is LRU
 
 	MinObjectSize int = 1024             // lower bound of the restriction
@@ -36,10 +44,17 @@ const (
 
 	MaxFillingParallel int = 10 // ten parallel subtrees
 
+	// received Root objects
+
+	MaxRootTimeSkew time.Duration = 5 * time.Minute // Time ahead of local
+
 	// DB related constants
 	CXDS  string = "cxds.db" // default CXDS file name
 	IdxDB string = "idx.db"  // default IdxDB file name
 
+	DBEngineBolt   string = "bolt"   // BoltDB for CXDS and IdxDB (default)
+	DBEngineBadger string = "badger" // Badger for CXDS and IdxDB
+
 	PackSavePin       log.Pin = 1 << iota // show time of (*Pack).Save in logs
 	CleanUpVerbosePin                     // show collecting and removing times
 	FillVerbosePin                        // show filling debug logs
@@ -114,7 +129,12 @@ type Config struct {
 	// the caceh. See also CacheCleaning field
 	CacheMaxVolume int
 	// CachePolicy is policy of the Cache. By default it's LRU,
-	// but it's possible to choose LFU if you want
+	// but it's possible to choose LFU, ARC or WTinyLFU if you
+	// want. The LRU and the LFU sort all items cleaning the
+	// Cache, that is O(n log n). The ARC and the WTinyLFU
+	// evict items one by one in O(1). The WTinyLFU has an
+	// admission filter that keeps frequently used items in
+	// the Cache rejecting one-time items
 	CachePolicy CachePolicy
 	// CacheRegistries is number of Registries the Cache
 	// will keep unpacked. A Registry is fast for access
@@ -141,6 +161,56 @@ type Config struct {
 	// CacheMaxItemSize can't be bigger then
 	// CacheMaxVolume*(1.0 - CacheCleaning)
 	CacheMaxItemSize int
+	// CacheWriteBack turns write-back mode of the Cache on.
+	// By default, the Cache writes new values to DB
+	// immediately. In write-back mode the Cache keeps new
+	// values (dirty values) and writes them all together
+	// later: every CacheFlushInterval, when number of dirty
+	// values reaches CacheMaxDirty, or on Close. Values
+	// that can't be cached are written immediately. The
+	// Save method of the Container flushes dirty values
+	// before it returns, thus a Root is never reported
+	// saved before its objects are saved. The mode is
+	// useful for bursty publishers
+	CacheWriteBack bool
+	// CacheFlushInterval is interval of flushing in
+	// write-back mode. Set it to zero to flush by the
+	// CacheMaxDirty, on Save and on Close only
+	CacheFlushInterval time.Duration
+	// CacheMaxDirty is max number of dirty
+	// values in write-back mode
+	CacheMaxDirty int
+	// SharedCache is process-wide cache that many
+	// Containers can share. If it's set, then the
+	// CacheMaxAmount and the CacheMaxVolume are
+	// ignored and limits of the SharedCache are
+	// used. Values of the SharedCache deduplicated
+	// by hash across Containers and a value used
+	// by many Containers charged once
+	SharedCache *SharedCache
+	// CacheWeight is weight of the Cache of the
+	// Container in a SharedCache. The weight is
+	// guaranteed share of the Cache in the limits
+	// of the SharedCache. A Cache can use unused
+	// shares of other Containers, but gives them
+	// back if other Cache needs its share. It's
+	// ignored if the SharedCache is nil
+	CacheWeight float64
+
+	// Preview cache configs. The preview cache keeps
+	// objects received from remote peers for feeds
+	// preview. The objects are not stored in DB. Set
+	// one of the fields to zero to switch the preview
+	// cache off. In this case every Preview keeps
+	// objects it received until it's released
+
+	// PreviewCacheMaxAmount is maximum number of objects
+	// the preview cache can fit
+	PreviewCacheMaxAmount int
+	// PreviewCacheMaxVolume is maximum total length of all
+	// objects of the preview cache. Least recently used
+	// objects are removed to fit this limit
+	PreviewCacheMaxVolume int
 
 	// limits
 
@@ -169,6 +239,14 @@ type Config struct {
 	// to number of connections that used to fill a Root.
 	MaxFillingParallel int
 
+	// MaxRootTimeSkew is max difference between Time of
+	// a received Root and local time, if the Time is
+	// ahead of the local time. Delegations and revocations
+	// are checked using the Time, that is set by signer of
+	// the Root. Thus, the signer can't move the Time far
+	// forward. Set it to zero to turn the check off
+	MaxRootTimeSkew time.Duration
+
 	// DB configs
 
 	// CheckSizes force Container to check sizes of objects
@@ -199,10 +277,26 @@ type Config struct {
 	// DataDir (even if it's empty). In this case, names of
 	// the files will be "db.cxds" and "db.idxdb"
 	DataDir string
+	// DBEngine is engine of CXDS and IdxDB used on drive.
+	// It can be DBEngineBolt or DBEngineBadger. The same
+	// engine used for both stores. The Badger stores
+	// data in directories instead of files. The DBEngine
+	// ignored if DB field provided or InMemoryDB is true
+	DBEngine string
 
 	// DB is *data.DB you can provide. If the field is not nil
 	// nil, then DPPath and InMemoryDB fields ignored.
 	DB *data.DB
+
+	// Hash is content hash algorithm used to calculate keys
+	// of objects. By default it's data.SHA256. The Hash is
+	// recorded in DB. A Container can't open DB recorded
+	// with another Hash (see data.CheckHash). Nodes with
+	// different Hash can't connect to each other. Since
+	// a Registry is saved like any other object, use
+	// the RegistryRef method of the Container instead of
+	// the (*registry.Registry).Reference to get its key
+	Hash data.Hash
 }
 
 // NewConfig returns pointer to Config with default values
@@ -219,11 +313,22 @@ func NewConfig() (conf *Config) {
 	conf.CachePolicy = LRU
 	conf.CacheCleaning = CacheCleaning
 	conf.CacheMaxItemSize = CacheMaxItemSize
+	conf.CacheFlushInterval = CacheFlushInterval
+	conf.CacheMaxDirty = CacheMaxDirty
+	conf.CacheWeight = CacheWeight
+
+	// preview cache configs
+
+	conf.PreviewCacheMaxAmount = PreviewCacheMaxAmount
+	conf.PreviewCacheMaxVolume = PreviewCacheMaxVolume
 
 	conf.MaxObjectSize = MaxObjectSize
+	conf.MaxRootTimeSkew = MaxRootTimeSkew
 
 	// data dir
 	conf.DataDir = DataDir()
+	conf.DBEngine = DBEngineBolt
+	conf.Hash = data.SHA256
 
 	return
 }
@@ -243,6 +348,16 @@ func (c *Config) FromFlags() {
 		"db-path",
 		c.DBPath,
 		"path to database")
+	flag.StringVar(&c.DBEngine,
+		"db-engine",
+		c.DBEngine,
+		"database engine: bolt or badger")
+	flag.Var(&c.CachePolicy,
+		"cache-policy",
+		"cache policy: LRU, LFU, ARC or W-TinyLFU")
+	flag.Var(&c.Hash,
+		"hash",
+		"content hash algorithm: SHA256 or BLAKE2b-256")
 }
 
 // Validate the Config
@@ -266,10 +381,11 @@ func (c *Config) Validate() error {
 			c.CacheMaxVolume)
 	}
 
-	if c.CachePolicy != LRU && c.CachePolicy != LFU {
-		return fmt.Errorf(
-			"skyobject.Config.CachePolicy is unknown: %d (choose LRU or LFU)",
-			c.CachePolicy)
+	switch c.CachePolicy {
+	case LRU, LFU, ARC, WTinyLFU:
+	default:
+		return fmt.Errorf("skyobject.Config.CachePolicy is unknown: %d"+
+			" (choose LRU, LFU, ARC or WTinyLFU)", c.CachePolicy)
 	}
 
 	if c.CacheCleaning < 0.5 {
@@ -284,6 +400,22 @@ func (c *Config) Validate() error {
 			c.CacheCleaning)
 	}
 
+	if c.CacheWriteBack == true && c.CacheMaxDirty < 1 {
+		return fmt.Errorf("skyobject.Config.CacheMaxDirty is too small: %d",
+			c.CacheMaxDirty)
+	}
+
+	if c.SharedCache != nil && c.CacheWeight <= 0 {
+		return fmt.Errorf("skyobject.Config.CacheWeight is not positive: %f",
+			c.CacheWeight)
+	}
+
+	if c.CacheFlushInterval < 0 {
+		return fmt.Errorf(
+			"skyobject.Config.CacheFlushInterval is negative: %v",
+			c.CacheFlushInterval)
+	}
+
 	var cacheMaxItemSize = int(float64(c.CacheMaxVolume) *
 		(1.0 - c.CacheCleaning))
 
@@ -293,10 +425,38 @@ func (c *Config) Validate() error {
 			c.CacheMaxItemSize, cacheMaxItemSize)
 	}
 
+	if c.PreviewCacheMaxAmount < 0 {
+		return fmt.Errorf(
+			"skyobject.Config.PreviewCacheMaxAmount is negative: %d",
+			c.PreviewCacheMaxAmount)
+	}
+
+	if c.PreviewCacheMaxVolume < 0 {
+		return fmt.Errorf(
+			"skyobject.Config.PreviewCacheMaxVolume is negative: %d",
+			c.PreviewCacheMaxVolume)
+	}
+
 	if c.MaxObjectSize < MinObjectSize {
 		return fmt.Errorf("skyobject.Config.MAxObjectSize is too small: %d",
 			c.MaxObjectSize)
 	}
 
+	if c.MaxRootTimeSkew < 0 {
+		return fmt.Errorf("skyobject.Config.MaxRootTimeSkew is negative: %v",
+			c.MaxRootTimeSkew)
+	}
+
+	switch c.DBEngine {
+	case DBEngineBolt, DBEngineBadger:
+	default:
+		return fmt.Errorf("skyobject.Config.DBEngine is unknown: %q "+
+			"(choose %q or %q)", c.DBEngine, DBEngineBolt, DBEngineBadger)
+	}
+
+	if err := c.Hash.Validate(); err != nil {
+		return fmt.Errorf("skyobject.Config.Hash is invalid: %d", c.Hash)
+	}
+
 	return nil
 }
@@ -4,10 +4,14 @@ import (
 	"log"
 	"path/filepath"
 
+	"github.com/dgraph-io/badger"
+
 	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/data/cxds"
+	cxbadger "github.com/skycoin/cxo/data/cxds/badger"
+	idxbadger "github.com/skycoin/cxo/data/idx/badger"
 	"github.com/skycoin/cxo/data/idxdb"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
@@ -23,6 +27,8 @@ type Container struct {
 
 	db *data.DB // database
 
+	preview *previewCache // objects of feeds preview
+
 	conf *Config // configurations
 
 	// human readable (used by node for debugging)
@@ -67,6 +73,11 @@ func NewContainer(conf *Config) (c *Container, err error) {
 		}
 	}()
 
+	// check hash algorithm of the DB
+	if err = data.CheckHash(c.db, conf.Hash); err != nil {
+		return
+	}
+
 	// check size of objects
 	if err = c.checkSize(); err != nil {
 		return
@@ -75,6 +86,10 @@ func NewContainer(conf *Config) (c *Container, err error) {
 	// initialize cache
 	c.initCache()
 
+	// cache for feeds preview
+	c.preview = newPreviewCache(conf.PreviewCacheMaxAmount,
+		conf.PreviewCacheMaxVolume)
+
 	if err = c.Index.load(c); err != nil {
 		return
 	}
@@ -115,12 +130,13 @@ func (c *Container) createDB(conf *Config) (err error) {
 		var cx data.CXDS
 		var idx data.IdxDB
 
-		if cx, err = cxds.NewDriveCXDS(c.cxPath); err != nil {
-			return
+		if conf.DBEngine == DBEngineBadger {
+			cx, idx, err = c.createBadgerDB()
+		} else {
+			cx, idx, err = c.createBoltDB()
 		}
 
-		if idx, err = idxdb.NewDriveIdxDB(c.idxPath); err != nil {
-			cx.Close()
+		if err != nil {
 			return
 		}
 
@@ -133,6 +149,54 @@ func (c *Container) createDB(conf *Config) (err error) {
 	return
 }
 
+// create CXDS and IdxDB using BoltDB
+func (c *Container) createBoltDB() (
+	cx data.CXDS,
+	idx data.IdxDB,
+	err error,
+) {
+
+	if cx, err = cxds.NewDriveCXDS(c.cxPath); err != nil {
+		return
+	}
+
+	if idx, err = idxdb.NewDriveIdxDB(c.idxPath); err != nil {
+		cx.Close()
+		return nil, nil, err
+	}
+
+	return
+}
+
+// badger options with given directory
+func badgerOptions(dir string) (opts badger.Options) {
+	opts = badger.DefaultOptions
+	opts.Dir = dir
+	opts.ValueDir = dir
+	return
+}
+
+// create CXDS and IdxDB using Badger
+func (c *Container) createBadgerDB() (
+	cx data.CXDS,
+	idx data.IdxDB,
+	err error,
+) {
+
+	if cx, err = cxbadger.NewBadger(badgerOptions(c.cxPath), 0); err != nil {
+		return
+	}
+
+	idx, err = idxbadger.NewBadger(badgerOptions(c.idxPath), 0)
+
+	if err != nil {
+		cx.Close()
+		return nil, nil, err
+	}
+
+	return
+}
+
 type rcs struct {
 	rc uint32 // saved rc (DB)
 	cc uint32 // correct rc (determined by walking)
@@ -251,6 +315,28 @@ func (c *Container) DB() (db *data.DB) {
 	return c.db
 }
 
+// Hash returns content hash algorithm of the Container
+func (c *Container) Hash() data.Hash {
+	return c.conf.Hash
+}
+
+// Sum returns hash of given value using
+// hash algorithm of the Container
+func (c *Container) Sum(val []byte) (key cipher.SHA256) {
+	return c.conf.Hash.Sum(val)
+}
+
+// RegistryRef returns reference of given Registry in the
+// Container. The RegistryRef is key of encoded Registry in
+// DB. It's the same as the Reference of the Registry, if
+// hash algorithm of the Container is SHA256
+func (c *Container) RegistryRef(r *registry.Registry) registry.RegistryRef {
+	if c.conf.Hash == data.SHA256 {
+		return r.Reference()
+	}
+	return registry.RegistryRef(c.conf.Hash.Sum(r.Encode()))
+}
+
 // Walk walks throug given Root calling given
 // walkFunc for every object of the Root including
 // hash of the Root and Registry (depending on the
@@ -301,6 +387,45 @@ func (c *Container) walkRoot(
 	return r.Walk(pack, walkFunc)
 }
 
+// Query executes given query against given Root calling
+// given queryFunc for every value found. See registry.Query
+// for details about the query language. The Query loads
+// objects from DB lazily, by needs
+func (c *Container) Query(
+	r *registry.Root,
+	query string,
+	queryFunc registry.QueryFunc,
+) (
+	err error,
+) {
+	return c.QueryAfter(r, query, nil, queryFunc)
+}
+
+// QueryAfter is the same as the Query, but it continues
+// the query after result with given cursor. See
+// registry.Root.QueryAfter for details
+func (c *Container) QueryAfter(
+	r *registry.Root,
+	query string,
+	after registry.QueryCursor,
+	queryFunc registry.QueryFunc,
+) (
+	err error,
+) {
+
+	var q *registry.Query
+	if q, err = registry.ParseQuery(query); err != nil {
+		return
+	}
+
+	var reg *registry.Registry
+	if reg, err = c.Registry(r.Reg); err != nil {
+		return
+	}
+
+	return r.QueryAfter(c.getPack(reg), q, after, queryFunc)
+}
+
 // Config returns configs of the Container.
 // The Config must not be modified
 func (c *Container) Config() (conf *Config) {
@@ -318,7 +443,10 @@ func (c *Container) rootByHash(
 		return
 	}
 
-	r, err = registry.DecodeRoot(val)
+	if r, err = registry.DecodeRoot(val); err != nil {
+		return
+	}
+
 	r.Hash = hash
 	return
 }
@@ -345,6 +473,7 @@ func (c *Container) RootByHash(
 	}
 
 	r.Sig = dr.Sig
+	r.Sigs = dr.Sigs
 	r.IsFull = true
 	return
 }
@@ -0,0 +1,260 @@
+package skyobject
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// AddRevocation adds given Revocation. Root objects
+// signed by the revoked key with timestamp equal to
+// or greater then Since of the Revocation will be
+// rejected. Since the timestamp is set by signer,
+// after the Since by local time, Root objects of the
+// revoked key are rejected, whatever timestamp they
+// have. Usually, revocations are received with
+// Root objects (see Revocations field of registry.Root),
+// but the AddRevocation can be used to add a Revocation
+// obtained other ways. The feed must exist. The
+// revocations are saved in DB, if the IdxDB implements
+// data.FeedKeeper interface. Otherwise, they are
+// restored from last Root objects of a feed, when the
+// Container created
+func (i *Index) AddRevocation(rv registry.Revocation) (err error) {
+
+	if err = rv.Verify(); err != nil {
+		return
+	}
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[rv.Feed]; ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	if i.addRevocation(rv) == false {
+		return // already have
+	}
+
+	return i.saveFeedInfo(rv.Feed)
+}
+
+// Revocations returns list of known revocations
+// of given feed. A publisher should put them to
+// Root objects it publishes to spread them
+func (i *Index) Revocations(
+	feed cipher.PubKey,
+) (
+	rvs []registry.Revocation,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var rs = i.revoked[feed]
+
+	if len(rs) == 0 {
+		return
+	}
+
+	rvs = make([]registry.Revocation, 0, len(rs))
+
+	for _, rv := range rs {
+		rvs = append(rvs, rv)
+	}
+
+	return
+}
+
+// IsRevoked returns true if given key revoked
+// for given feed at given time (unix nano)
+func (i *Index) IsRevoked(feed, key cipher.PubKey, t int64) (yep bool) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	return i.isRevoked(feed, key, t)
+}
+
+// under lock
+func (i *Index) isRevoked(feed, key cipher.PubKey, t int64) (yep bool) {
+
+	var rv, ok = i.revoked[feed][key]
+
+	return ok == true && rv.Revokes(key, t) == true
+}
+
+// under lock, the Revocation must be verified;
+// it returns false if the Index already has the
+// same or earlier Revocation of the key
+func (i *Index) addRevocation(rv registry.Revocation) (added bool) {
+
+	var rs, ok = i.revoked[rv.Feed]
+
+	if ok == false {
+		rs = make(map[cipher.PubKey]registry.Revocation)
+		i.revoked[rv.Feed] = rs
+	}
+
+	// keep the earliest
+	if last, ok := rs[rv.Key]; ok == false || rv.Since < last.Since {
+		rs[rv.Key] = rv
+		return true
+	}
+
+	return
+}
+
+// under lock, apply revocations of given Root
+func (i *Index) applyRevocations(r *registry.Root) (err error) {
+
+	for k := range r.Revocations {
+
+		var rv = &r.Revocations[k]
+
+		if rv.Feed != r.Pub {
+			return registry.ErrInvalidRevocation
+		}
+
+		if err = rv.Verify(); err != nil {
+			return
+		}
+
+	}
+
+	var added bool
+
+	for _, rv := range r.Revocations {
+		if i.addRevocation(rv) == true {
+			added = true
+		}
+	}
+
+	if _, ok := i.feeds[r.Pub]; ok == false || added == false {
+		return // nothing to save (preview)
+	}
+
+	return i.saveFeedInfo(r.Pub)
+}
+
+// under lock, check signer of given Root, the signer
+// is owner of the feed, authorized writer or a key
+// with Delegation. If the strict is true, then a
+// revoked key is rejected if its Revocation is in
+// effect by local time, whatever Time of the Root
+// is; e.g. a revoked key can't get around the
+// Revocation using a Time before the Since. The
+// strict is false for evidences (see Equivocation)
+func (i *Index) checkSigner(
+	r *registry.Root, // : the Root
+	strict bool, //      : check revocations by local time
+) (
+	err error, //        : the error
+) {
+
+	var signer = r.Signer()
+
+	if signer == r.Pub {
+		return // the owner
+	}
+
+	if i.isRevoked(r.Pub, signer, r.Time) == true {
+		return ErrRevokedKey
+	}
+
+	if strict == true &&
+		i.isRevoked(r.Pub, signer, time.Now().UnixNano()) == true {
+
+		return ErrRevokedKey
+	}
+
+	if r.Delegation.IsBlank() == false {
+		return r.VerifyDelegation()
+	}
+
+	if i.isWriter(r.Pub, signer) == false {
+		return ErrUnauthorizedWriter
+	}
+
+	return
+}
+
+// under lock, check Time of given Root. Delegations and
+// revocations are checked using the Time, that is set by
+// signer of the Root. Thus, the Time can't be ahead of
+// local time more then MaxRootTimeSkew, and it must be
+// greater then Time of previous Root of the head, if
+// the Index has the previous Root
+func (i *Index) checkTime(r *registry.Root) (err error) {
+
+	var skew = i.c.conf.MaxRootTimeSkew
+
+	if skew > 0 && r.Time > time.Now().Add(skew).UnixNano() {
+		return ErrRootFromFuture
+	}
+
+	if r.Seq == 0 {
+		return // first Root of the head
+	}
+
+	var hs, ok = i.feeds[r.Pub]
+
+	if ok == false {
+		return // preview
+	}
+
+	var prev = hs.h[r.Nonce] // last Root of the head
+
+	if prev == nil || prev.Seq >= r.Seq {
+
+		switch prev, err = i.findRoot(r.Pub, r.Nonce, r.Seq-1); err {
+		case nil:
+		case data.ErrNoSuchHead, data.ErrNotFound:
+			return nil // can't check
+		default:
+			return
+		}
+
+	}
+
+	if r.Time <= prev.Time {
+		return ErrInvalidTime
+	}
+
+	return
+}
+
+// restore revocations from last Root objects of all
+// feeds, that is useful if IdxDB doesn't implement
+// data.FeedKeeper interface, or for Root objects
+// received before the revocations were saved
+func (i *Index) loadRevocations() (err error) {
+
+	var r *registry.Root
+
+	for _, hs := range i.feeds {
+
+		for _, dr := range hs.h {
+
+			if dr == nil {
+				continue // blank head
+			}
+
+			if r, err = i.c.rootByHash(dr.Hash); err != nil {
+				return
+			}
+
+			if err = i.applyRevocations(r); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
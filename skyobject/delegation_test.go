@@ -0,0 +1,213 @@
+package skyobject
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idx/memory"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestIndex_AddRevocation(t *testing.T) {
+	// AddRevocation(rv registry.Revocation) (err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		kp, ks = cipher.GenerateKeyPair()
+		now    = time.Now()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	// delegated publishing key
+
+	var up, err = c.Unpack(ks, testRegistry)
+	assertNil(t, err)
+
+	var r = &registry.Root{
+		Pub:        pk,
+		Nonce:      1,
+		Delegation: registry.NewDelegation(sk, kp, now, now.Add(time.Hour)),
+	}
+
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, r.Author == kp, "wrong Author")
+
+	var rr *registry.Root
+	rr, err = c.ReceivedRoot(pk, r.Sig, r.Encode())
+	assertNil(t, err)
+	assertTrue(t, rr.Signer() == kp, "wrong signer")
+
+	// revoked
+
+	assertNil(t, c.AddRevocation(registry.NewRevocation(sk, kp, now)))
+	assertTrue(t, len(c.Revocations(pk)) == 1, "wrong number of revocations")
+	assertTrue(t, c.IsRevoked(pk, kp, r.Time), "not revoked")
+
+	if _, err = c.ReceivedRoot(pk, r.Sig, r.Encode()); err != ErrRevokedKey {
+		t.Error("wrong error:", err)
+	}
+
+	r.Nonce = 2
+	if err = c.Save(up, r); err != ErrRevokedKey {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestIndex_Revocations(t *testing.T) {
+	// Revocations(feed cipher.PubKey) (rvs []registry.Revocation)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		kp, _  = cipher.GenerateKeyPair()
+		now    = time.Now()
+
+		conf = getTestConfig()
+	)
+
+	conf.DB = data.NewDB(cxds.NewMemoryCXDS(), memory.NewMemory(0))
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = &registry.Root{Pub: pk, Nonce: 1, Revocations: []registry.Revocation{
+		registry.NewRevocation(sk, kp, now),
+	}}
+	assertNil(t, c.Save(up, r))
+
+	// last Root of the head doesn't carry the Revocation
+
+	r.Revocations = nil
+	assertNil(t, c.Save(up, r))
+
+	var lc *Container
+	lc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer lc.Close()
+
+	assertTrue(t, len(lc.Revocations(pk)) == 1, "Revocation is not loaded")
+	assertTrue(t, lc.IsRevoked(pk, kp, now.UnixNano()), "not revoked")
+
+}
+
+func TestIndex_checkTime(t *testing.T) {
+	// checkTime(r *registry.Root) (err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	assertNil(t, c.Save(up, r))
+
+	var sign = func(x *registry.Root) (val []byte) {
+		val = x.Encode()
+		x.Sig = cipher.SignHash(c.Sum(val), sk)
+		return
+	}
+
+	// ahead of local time
+
+	var fr = &registry.Root{
+		Pub:   pk,
+		Nonce: 2,
+		Time:  time.Now().Add(2 * MaxRootTimeSkew).UnixNano(),
+	}
+
+	var val = sign(fr)
+	if _, err = c.ReceivedRoot(pk, fr.Sig, val); err != ErrRootFromFuture {
+		t.Error("wrong error:", err)
+	}
+
+	// not after previous Root of the head
+
+	var nr = &registry.Root{
+		Pub:   pk,
+		Nonce: 1,
+		Seq:   1,
+		Prev:  r.Hash,
+		Time:  r.Time,
+	}
+
+	val = sign(nr)
+	if _, err = c.ReceivedRoot(pk, nr.Sig, val); err != ErrInvalidTime {
+		t.Error("wrong error:", err)
+	}
+
+	nr.Time = r.Time + 1
+
+	val = sign(nr)
+	_, err = c.ReceivedRoot(pk, nr.Sig, val)
+	assertNil(t, err)
+
+}
+
+func TestIndex_checkSigner(t *testing.T) {
+	// checkSigner(r *registry.Root, strict bool) (err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		kp, ks = cipher.GenerateKeyPair()
+		now    = time.Now()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var d = registry.NewDelegation(sk, kp, now.Add(-2*time.Hour),
+		now.Add(time.Hour))
+
+	assertNil(t, c.AddRevocation(
+		registry.NewRevocation(sk, kp, now.Add(-time.Hour))))
+
+	// backdated first Root of a new head
+
+	var br = &registry.Root{
+		Pub:        pk,
+		Nonce:      2,
+		Time:       now.Add(-90 * time.Minute).UnixNano(),
+		Author:     kp,
+		Delegation: d,
+	}
+
+	var val = br.Encode()
+	br.Sig = cipher.SignHash(c.Sum(val), ks)
+
+	if _, err := c.ReceivedRoot(pk, br.Sig, val); err != ErrRevokedKey {
+		t.Error("wrong error:", err)
+	}
+
+	// an evidence is checked using the Time
+
+	assertNil(t, c.checkSigner(br, false))
+
+	if err := c.checkSigner(br, true); err != ErrRevokedKey {
+		t.Error("wrong error:", err)
+	}
+
+}
@@ -11,6 +11,24 @@ var (
 	ErrObjectIsTooLarge = errors.New("object is too large (see MaxObjectSize)")
 	ErrTerminated       = errors.New("terminated")
 	ErrBlankRegistryRef = errors.New("blank registry reference")
+
+	ErrFeedMismatch       = errors.New("feed of Root doesn't match")
+	ErrUnauthorizedWriter = errors.New("unauthorized writer of feed")
+	ErrInvalidParents     = errors.New("Parents are not Roots of the feed")
+	ErrRevokedKey         = errors.New("revoked key")
+	ErrRootFromFuture     = errors.New("Time of Root is ahead of local time")
+	ErrInvalidTime        = errors.New("Time of Root is not after previous")
+	ErrOutdatedRoot       = errors.New("Root is outdated, prepare it again")
+	ErrNotPrepared        = errors.New("Root is not prepared or changed")
+	ErrNotMultisig        = errors.New("not a multisig feed")
+	ErrMultisigMismatch   = errors.New("Multisig of Root doesn't match")
+
+	ErrInvalidPrev         = errors.New("Prev of Root doesn't match chain")
+	ErrInvalidEquivocation = errors.New("invalid equivocation evidence")
+
+	ErrLabelExists = errors.New("head with the label already exists")
+	ErrNoSuchLabel = errors.New("no such label")
+	ErrHeadExists  = errors.New("head already exists")
 )
 
 // ObjectIsTooLargeError represents error that
@@ -0,0 +1,115 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// a feedInfo represents meta information of a
+// feed, that is saved in IdxDB, if the IdxDB
+// implements data.FeedKeeper interface
+type feedInfo struct {
+	Revocations []registry.Revocation // revoked keys
+	Multisig    []byte                // encoded Multisig, if any
+	Denied      []cipher.PubKey       // writers removed by DelWriter
+	Forks       []Equivocation        // evidences of forks
+}
+
+// IsBlank returns true if the feedInfo is blank
+func (f *feedInfo) IsBlank() bool {
+	return len(f.Revocations) == 0 && len(f.Multisig) == 0 &&
+		len(f.Denied) == 0 && len(f.Forks) == 0
+}
+
+// loadFeedInfos loads infos of feeds from
+// IdxDB, if the IdxDB is data.FeedKeeper
+func (i *Index) loadFeedInfos() (err error) {
+
+	var fk, ok = i.c.db.IdxDB().(data.FeedKeeper)
+
+	if ok == false {
+		return
+	}
+
+	var val []byte
+
+	for pk := range i.feeds {
+
+		if val, err = fk.FeedInfo(pk); err != nil {
+			return
+		}
+
+		if len(val) == 0 {
+			continue
+		}
+
+		var info feedInfo
+		if err = encoder.DeserializeRaw(val, &info); err != nil {
+			return
+		}
+
+		for _, rv := range info.Revocations {
+			i.addRevocation(rv) // verified before saving
+		}
+
+		for _, writer := range info.Denied {
+			i.deny(pk, writer)
+		}
+
+		for k := range info.Forks {
+			i.addEquivocation(&info.Forks[k]) // verified before saving
+		}
+
+		if len(info.Multisig) == 0 {
+			continue
+		}
+
+		var ms *registry.Multisig
+		if ms, err = registry.DecodeMultisig(info.Multisig); err != nil {
+			return
+		}
+
+		i.multisigs[pk] = ms
+
+	}
+
+	return
+}
+
+// under lock, save info of given feed in
+// IdxDB, if the IdxDB is data.FeedKeeper
+func (i *Index) saveFeedInfo(pk cipher.PubKey) (err error) {
+
+	var fk, ok = i.c.db.IdxDB().(data.FeedKeeper)
+
+	if ok == false {
+		return
+	}
+
+	var info feedInfo
+
+	for _, rv := range i.revoked[pk] {
+		info.Revocations = append(info.Revocations, rv)
+	}
+
+	if ms, ok := i.multisigs[pk]; ok == true {
+		info.Multisig = ms.Encode()
+	}
+
+	for writer := range i.denied[pk] {
+		info.Denied = append(info.Denied, writer)
+	}
+
+	for _, e := range i.forks[pk] {
+		info.Forks = append(info.Forks, *e)
+	}
+
+	if info.IsBlank() == true {
+		return fk.SetFeedInfo(pk, nil)
+	}
+
+	return fk.SetFeedInfo(pk, encoder.Serialize(info))
+}
@@ -25,6 +25,7 @@ type Filler struct {
 	incs  map[cipher.SHA256]int
 	pincs map[cipher.SHA256]int      // features pre incs
 	pre   map[cipher.SHA256]struct{} // prerequested by RC
+	frs   []registry.FeedRef         // FeedRefs found
 
 	limit chan struct{} // max
 
@@ -138,6 +139,33 @@ func (f *Filler) Get(key cipher.SHA256) (val []byte, rc int, err error) {
 	return
 }
 
+// FeedRef implements registry.FeedRefSplitter
+// interface. The FeedRef collects FeedRefs of
+// the Root the Filler fills
+func (f *Filler) FeedRef(fr *registry.FeedRef) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	for _, x := range f.frs {
+		if x == *fr {
+			return // already have
+		}
+	}
+
+	f.frs = append(f.frs, *fr)
+}
+
+// FeedRefs returns list of unique FeedRefs of the Root
+// the Filler fills. The list is complete after successful
+// Run. FeedRefs of subtrees the DB already has (e.g. that
+// shared with another Root) are not listed
+func (f *Filler) FeedRefs() (frs []registry.FeedRef) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	return append(frs, f.frs...)
+}
+
 // Fail used to terminate the Filler with
 // provided error
 func (f *Filler) Fail(err error) {
@@ -371,7 +399,7 @@ func (f *Filler) features(co [][]byte, ch []cipher.SHA256) (err error) {
 
 		// save objects
 		for _, val := range co {
-			var key = cipher.SumSHA256(val)
+			var key = f.c.Sum(val)
 			f.pinc(key)
 
 			if _, err = f.c.Set(key, val, 1); err != nil {
@@ -438,8 +466,12 @@ func (f *Filler) Run(co [][]byte, ch []cipher.SHA256) (err error) {
 	select {
 	case err = <-f.errq:
 	case <-done:
-		f.r.IsFull = true // full!
-		_, err = f.c.AddRoot(f.r)
+		// objects of the Root should be saved
+		// before the Root (write-back mode)
+		if err = f.c.Flush(); err == nil {
+			f.r.IsFull = true // full!
+			_, err = f.c.AddRoot(f.r)
+		}
 	}
 
 	f.Close()
@@ -0,0 +1,284 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A SignedRoot represents encoded Root
+// with its signatures
+type SignedRoot struct {
+	Value []byte       // encoded Root
+	Sig   cipher.Sig   // signature
+	Sigs  []cipher.Sig // signatures of multisig Root, if any
+}
+
+// An Equivocation represents cryptographic evidence,
+// that a feed has two different signed Root objects
+// with the same feed, head and seq. E.g. the owner of
+// the feed (or someone, who stole its key) forks the
+// feed. The evidence can be verified by anyone, who
+// knows the feed (see VerifyEquivocation)
+type Equivocation struct {
+	Feed  cipher.PubKey // feed
+	Nonce uint64        // head
+	Seq   uint64        // seq
+
+	A SignedRoot // first Root (known)
+	B SignedRoot // second Root (conflicting)
+}
+
+// Short returns string like "1a2ef33/1234/2"
+// (pub_key/nonce/seq), where the pub_key is
+// hexadecimal encoded string trimmed to first
+// 7 characters
+func (e *Equivocation) Short() string {
+	var r = registry.Root{Pub: e.Feed, Nonce: e.Nonce, Seq: e.Seq}
+	return r.Short()
+}
+
+// An EquivocationError returned by ReceivedRoot if
+// a conflicting Root detected. The error contains
+// the evidence
+type EquivocationError struct {
+	e *Equivocation
+}
+
+// Equivocation returns the evidence
+func (e *EquivocationError) Equivocation() *Equivocation {
+	return e.e
+}
+
+// Error implements error interface
+func (e *EquivocationError) Error() string {
+	return "equivocation (conflicting Root objects): " + e.e.Short()
+}
+
+// VerifyEquivocation verifies given evidence. Both
+// Root objects of the evidence should be valid and
+// signed, they should belong to the same feed, head
+// and seq, and they should be different. The Feed,
+// Nonce and Seq fields of the Equivocation are
+// checked too. Signers and signatures of the Root
+// objects are checked the same way as signers and
+// signatures of received Root objects. E.g. a signer
+// should be owner of the feed, authorized writer or
+// a key with Delegation (a revoked key is checked
+// using Time of the Root), and Root objects of multisig
+// feed should have enough signatures. The method
+// returns ErrInvalidEquivocation, decoding error,
+// signer or signature verification error
+func (i *Index) VerifyEquivocation(e *Equivocation) (err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	return i.verifyEquivocation(e)
+}
+
+// under lock
+func (i *Index) verifyEquivocation(e *Equivocation) (err error) {
+
+	var a, b *registry.Root
+
+	if a, err = i.verifySignedRoot(&e.A); err != nil {
+		return
+	}
+
+	if b, err = i.verifySignedRoot(&e.B); err != nil {
+		return
+	}
+
+	if a.Pub != e.Feed || a.Nonce != e.Nonce || a.Seq != e.Seq ||
+		b.Pub != e.Feed || b.Nonce != e.Nonce || b.Seq != e.Seq ||
+		a.Hash == b.Hash {
+
+		return ErrInvalidEquivocation
+	}
+
+	return
+}
+
+// under lock, decode and verify signer and
+// signatures of given SignedRoot
+func (i *Index) verifySignedRoot(
+	sr *SignedRoot,
+) (
+	r *registry.Root,
+	err error,
+) {
+
+	if r, err = registry.DecodeRoot(sr.Value); err != nil {
+		return
+	}
+
+	// the same checks as for received Root, but a
+	// revoked key is checked using Time of the Root,
+	// since the evidence can be found after the
+	// Revocation
+
+	if err = i.checkSigner(r, false); err != nil {
+		return nil, err
+	}
+
+	r.Hash = i.c.Sum(sr.Value)
+
+	if err = cipher.VerifySignature(r.Signer(), sr.Sig, r.Hash); err != nil {
+		return nil, err
+	}
+
+	var ms *registry.Multisig
+
+	if ms, err = i.rootMultisig(r); err != nil {
+		return nil, err
+	}
+
+	if ms != nil {
+		if err = ms.Verify(r.Hash, sr.Sigs); err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// AddEquivocation verifies and adds given evidence,
+// received from a peer. The added is false if the
+// Index already has an evidence of the same feed,
+// head and seq. The feed must exist. The evidence
+// is saved in DB, if the IdxDB implements the
+// data.FeedKeeper interface
+func (i *Index) AddEquivocation(e *Equivocation) (added bool, err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if err = i.verifyEquivocation(e); err != nil {
+		return
+	}
+
+	if _, ok := i.feeds[e.Feed]; ok == false {
+		return false, data.ErrNoSuchFeed
+	}
+
+	if added = i.addEquivocation(e); added == true {
+		err = i.saveFeedInfo(e.Feed)
+	}
+
+	return
+}
+
+// Equivocations returns known evidences of given feed.
+// The evidences are saved in DB, if the IdxDB implements
+// the data.FeedKeeper interface. Otherwise, they are lost
+// when the Container closed. The evidences are removed
+// with the feed. A fork is detected comparing received
+// Root with stored one. Thus, a fork of a Root removed
+// by retention (see SetHeadKeep) or by the DelRoot can't
+// be detected
+func (i *Index) Equivocations(feed cipher.PubKey) (es []*Equivocation) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	return append(es, i.forks[feed]...)
+}
+
+// under lock
+func (i *Index) addEquivocation(e *Equivocation) (added bool) {
+
+	for _, x := range i.forks[e.Feed] {
+		if x.Nonce == e.Nonce && x.Seq == e.Seq {
+			return // already known
+		}
+	}
+
+	i.forks[e.Feed] = append(i.forks[e.Feed], e)
+	return true
+}
+
+// under lock, check Prev of given Root against
+// stored chain, if the Index has previous Root
+func (i *Index) checkPrev(r *registry.Root) (err error) {
+
+	if r.Seq == 0 {
+		if r.Prev != (cipher.SHA256{}) {
+			return ErrInvalidPrev
+		}
+		return
+	}
+
+	if r.Prev == (cipher.SHA256{}) {
+		return ErrInvalidPrev
+	}
+
+	var dr *data.Root
+
+	switch dr, err = i.findRoot(r.Pub, r.Nonce, r.Seq-1); err {
+	case nil:
+	case data.ErrNoSuchFeed, data.ErrNoSuchHead, data.ErrNotFound:
+		return nil // can't check
+	default:
+		return
+	}
+
+	if dr.Hash != r.Prev {
+		return ErrInvalidPrev
+	}
+
+	return
+}
+
+// under lock, check given Root against stored
+// Root with the same feed, head and seq; the
+// Root must be verified; the method returns
+// EquivocationError if they are different; a
+// removed Root can't be compared, and a fork
+// of such Root is not detected
+func (i *Index) checkFork(
+	r *registry.Root, //  : received Root
+	val []byte, //        : encoded Root
+	sig cipher.Sig, //    : signature
+	sigs []cipher.Sig, // : signatures of multisig Root
+) (
+	err error, //         : the error
+) {
+
+	var dr *data.Root
+
+	switch dr, err = i.findRoot(r.Pub, r.Nonce, r.Seq); err {
+	case nil:
+	case data.ErrNoSuchFeed, data.ErrNoSuchHead, data.ErrNotFound:
+		return nil // nothing to compare with
+	default:
+		return
+	}
+
+	if dr.Hash == r.Hash {
+		return // the same
+	}
+
+	var known []byte
+	if known, _, err = i.c.Get(dr.Hash, 0); err != nil {
+		return
+	}
+
+	var e = &Equivocation{
+		Feed:  r.Pub,
+		Nonce: r.Nonce,
+		Seq:   r.Seq,
+
+		A: SignedRoot{Value: known, Sig: dr.Sig, Sigs: dr.Sigs},
+		B: SignedRoot{Value: val, Sig: sig, Sigs: sigs},
+	}
+
+	if i.addEquivocation(e) == true {
+		if err = i.saveFeedInfo(r.Pub); err != nil {
+			return
+		}
+	}
+
+	return &EquivocationError{e}
+}
@@ -0,0 +1,181 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idx/memory"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestIndex_Equivocations(t *testing.T) {
+	// Equivocations(feed cipher.PubKey) (es []*Equivocation)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+
+		db   = data.NewDB(cxds.NewMemoryCXDS(), memory.NewMemory(0))
+		conf = getTestConfig()
+	)
+
+	conf.DB = db
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	assertNil(t, c.Save(up, r))
+
+	// conflicting Root, signed by the owner
+
+	var (
+		fork = &registry.Root{Pub: pk, Nonce: 1, Reg: r.Reg, Time: r.Time + 1}
+		val  = fork.Encode()
+		sig  = cipher.SignHash(c.Sum(val), sk)
+	)
+
+	_, err = c.ReceivedRoot(pk, sig, val)
+
+	var ee, ok = err.(*EquivocationError)
+
+	if ok == false {
+		t.Fatal("wrong error:", err)
+	}
+
+	var es = c.Equivocations(pk)
+	assertTrue(t, len(es) == 1, "wrong number of evidences")
+	assertTrue(t, es[0] == ee.Equivocation(), "wrong evidence")
+	assertNil(t, c.VerifyEquivocation(es[0]))
+
+	var added bool
+	added, err = c.AddEquivocation(es[0])
+	assertNil(t, err)
+	assertTrue(t, added == false, "added twice")
+
+	// the evidence is loaded
+
+	var lc *Container
+	lc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer lc.Close()
+
+	var ls = lc.Equivocations(pk)
+	assertTrue(t, len(ls) == 1, "evidence is not loaded")
+	assertNil(t, lc.VerifyEquivocation(ls[0]))
+	assertTrue(t, ls[0].B.Sig == sig, "wrong evidence loaded")
+
+	// invalid evidence
+
+	var invalid = *es[0]
+	invalid.B = invalid.A
+
+	if err = c.VerifyEquivocation(&invalid); err != ErrInvalidEquivocation {
+		t.Error("wrong error:", err)
+	}
+
+	// Prev linkage
+
+	var next = &registry.Root{Pub: pk, Nonce: 1, Seq: 1, Reg: r.Reg,
+		Time: r.Time + 1, Prev: cipher.SumSHA256([]byte("x"))}
+
+	val = next.Encode()
+	sig = cipher.SignHash(c.Sum(val), sk)
+
+	if _, err = c.ReceivedRoot(pk, sig, val); err != ErrInvalidPrev {
+		t.Error("wrong error:", err)
+	}
+
+	next.Prev = r.Hash
+
+	val = next.Encode()
+	sig = cipher.SignHash(c.Sum(val), sk)
+
+	_, err = c.ReceivedRoot(pk, sig, val)
+	assertNil(t, err)
+
+}
+
+func TestIndex_VerifyEquivocation(t *testing.T) {
+	// VerifyEquivocation(e *Equivocation) (err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		xp, xs = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var signed = func(r *registry.Root, key cipher.SecKey) (sr SignedRoot) {
+		sr.Value = r.Encode()
+		sr.Sig = cipher.SignHash(c.Sum(sr.Value), key)
+		return
+	}
+
+	var pair = func(a, b *registry.Root, key cipher.SecKey) *Equivocation {
+		return &Equivocation{
+			Feed:  a.Pub,
+			Nonce: a.Nonce,
+			Seq:   a.Seq,
+			A:     signed(a, key),
+			B:     signed(b, key),
+		}
+	}
+
+	// forged Author, the key is not authorized
+
+	var (
+		a = &registry.Root{Pub: pk, Nonce: 1, Author: xp, Time: 1}
+		b = &registry.Root{Pub: pk, Nonce: 1, Author: xp, Time: 2}
+	)
+
+	var err = c.VerifyEquivocation(pair(a, b, xs))
+	if err != ErrUnauthorizedWriter {
+		t.Error("wrong error:", err)
+	}
+
+	var added bool
+	if added, err = c.AddEquivocation(pair(a, b, xs)); err !=
+		ErrUnauthorizedWriter {
+
+		t.Error("wrong error:", err)
+	}
+	assertTrue(t, added == false, "forged evidence added")
+	assertTrue(t, len(c.Equivocations(pk)) == 0, "forged evidence added")
+
+	// signed by owner
+
+	a.Author, b.Author = cipher.PubKey{}, cipher.PubKey{}
+	assertNil(t, c.VerifyEquivocation(pair(a, b, sk)))
+
+	// multisig feed, not enough signatures
+
+	var ms *registry.Multisig
+	ms, err = registry.NewMultisig(1, xp)
+	assertNil(t, err)
+	assertNil(t, c.SetMultisig(pk, ms))
+
+	err = c.VerifyEquivocation(pair(a, b, sk))
+	if err != registry.ErrNotEnoughSignatures {
+		t.Error("wrong error:", err)
+	}
+
+	var e = pair(a, b, sk)
+	e.A.Sigs = []cipher.Sig{cipher.SignHash(c.Sum(e.A.Value), xs)}
+	e.B.Sigs = []cipher.Sig{cipher.SignHash(c.Sum(e.B.Value), xs)}
+
+	assertNil(t, c.VerifyEquivocation(e))
+
+}
@@ -0,0 +1,445 @@
+package skyobject
+
+import (
+	"math/rand"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// under lock, track timestamp of the newest Root
+// and the active head if it's not selected explicitly
+func (i *indexHeads) touch(nonce uint64, t int64) {
+
+	if i.activet < t {
+		i.activet = t
+
+		if i.pinned == false {
+			i.activen = nonce
+		}
+	}
+
+}
+
+// loadHeadInfos loads infos of heads from
+// IdxDB, if the IdxDB is data.HeadKeeper
+func (i *Index) loadHeadInfos() (err error) {
+
+	var hk, ok = i.c.db.IdxDB().(data.HeadKeeper)
+
+	if ok == false {
+		return
+	}
+
+	var info data.HeadInfo
+
+	for pk, hs := range i.feeds {
+
+		for nonce := range hs.h {
+
+			if info, err = hk.HeadInfo(pk, nonce); err != nil {
+				return
+			}
+
+			if info.IsBlank() == true {
+				continue
+			}
+
+			hs.infos[nonce] = info
+
+			if info.Active == true {
+				hs.pinned = true
+				hs.activen = nonce
+			}
+
+		}
+
+	}
+
+	return
+}
+
+// under lock
+func (i *Index) indexHead(
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	hs *indexHeads,
+	err error,
+) {
+
+	var ok bool
+
+	if hs, ok = i.feeds[pk]; ok == false {
+		return nil, data.ErrNoSuchFeed
+	}
+
+	if _, ok = hs.h[nonce]; ok == false {
+		return nil, data.ErrNoSuchHead
+	}
+
+	return
+}
+
+// under lock, save info in IdxDB (if the IdxDB
+// is data.HeadKeeper) and in the Index
+func (i *Index) setHeadInfo(
+	pk cipher.PubKey,
+	hs *indexHeads,
+	nonce uint64,
+	info data.HeadInfo,
+) (
+	err error,
+) {
+
+	if hk, ok := i.c.db.IdxDB().(data.HeadKeeper); ok == true {
+		if err = hk.SetHeadInfo(pk, nonce, info); err != nil {
+			return
+		}
+	}
+
+	if info.IsBlank() == true {
+		delete(hs.infos, nonce)
+	} else {
+		hs.infos[nonce] = info
+	}
+
+	return
+}
+
+// HeadInfo returns label, retention and explicit
+// activity of given head. The info is saved in DB
+// if the IdxDB implements data.HeadKeeper interface.
+// Otherwise, the info is not saved in DB; set it
+// every time the Container created
+func (i *Index) HeadInfo(
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	info data.HeadInfo,
+	err error,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs *indexHeads
+	if hs, err = i.indexHead(pk, nonce); err != nil {
+		return
+	}
+
+	info = hs.infos[nonce]
+	return
+}
+
+// SetHeadLabel sets human readable label of given
+// head. Labels of heads of a feed are unique. The
+// SetHeadLabel returns ErrLabelExists if another
+// head of the feed has the same label. Blank label
+// removes label of the head
+func (i *Index) SetHeadLabel(
+	pk cipher.PubKey,
+	nonce uint64,
+	label string,
+) (
+	err error,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs *indexHeads
+	if hs, err = i.indexHead(pk, nonce); err != nil {
+		return
+	}
+
+	if label != "" {
+		for n, info := range hs.infos {
+			if n != nonce && info.Label == label {
+				return ErrLabelExists
+			}
+		}
+	}
+
+	var info = hs.infos[nonce]
+	info.Label = label
+
+	return i.setHeadInfo(pk, hs, nonce, info)
+}
+
+// HeadByLabel returns nonce of head with given
+// label. It returns ErrNoSuchLabel if there is
+// not a head with the label
+func (i *Index) HeadByLabel(
+	pk cipher.PubKey,
+	label string,
+) (
+	nonce uint64,
+	err error,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs, ok = i.feeds[pk]
+
+	if ok == false {
+		return 0, data.ErrNoSuchFeed
+	}
+
+	if label != "" {
+		for n, info := range hs.infos {
+			if info.Label == label {
+				return n, nil
+			}
+		}
+	}
+
+	return 0, ErrNoSuchLabel
+}
+
+// SetActiveHead selects active head of given feed
+// explicitly. After that, the ActiveHead returns
+// the head, regardless timestamps of Root objects,
+// until the ResetActiveHead called or the head
+// removed
+func (i *Index) SetActiveHead(pk cipher.PubKey, nonce uint64) (err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs *indexHeads
+	if hs, err = i.indexHead(pk, nonce); err != nil {
+		return
+	}
+
+	if hs.pinned == true && hs.activen != nonce {
+
+		var prev = hs.infos[hs.activen]
+		prev.Active = false
+
+		if err = i.setHeadInfo(pk, hs, hs.activen, prev); err != nil {
+			return
+		}
+
+	}
+
+	var info = hs.infos[nonce]
+	info.Active = true
+
+	if err = i.setHeadInfo(pk, hs, nonce, info); err != nil {
+		return
+	}
+
+	hs.pinned, hs.activen = true, nonce
+	return
+}
+
+// ResetActiveHead resets explicitly selected active
+// head of given feed (see SetActiveHead). After that,
+// the active head is head with the newest Root
+func (i *Index) ResetActiveHead(pk cipher.PubKey) (err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs, ok = i.feeds[pk]
+
+	if ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	if hs.pinned == false {
+		return // not selected
+	}
+
+	var info = hs.infos[hs.activen]
+	info.Active = false
+
+	if err = i.setHeadInfo(pk, hs, hs.activen, info); err != nil {
+		return
+	}
+
+	hs.pinned = false
+	hs.setActive()
+	return
+}
+
+// under lock, a head removed
+func (i *Index) delHeadInfo(hs *indexHeads, nonce uint64) {
+
+	delete(hs.infos, nonce)
+
+	if hs.activen == nonce {
+		hs.pinned = false
+		hs.setActive()
+	}
+
+}
+
+// with lock
+func (i *Index) setHeadKeepLock(
+	pk cipher.PubKey,
+	nonce uint64,
+	keep uint32,
+) (
+	err error,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs *indexHeads
+	if hs, err = i.indexHead(pk, nonce); err != nil {
+		return
+	}
+
+	var info = hs.infos[nonce]
+	info.Keep = keep
+
+	return i.setHeadInfo(pk, hs, nonce, info)
+}
+
+// SetHeadKeep sets retention of given head. The head
+// keeps given number of last Root objects, older Root
+// objects removed. The SetHeadKeep removes outdated
+// Root objects immediately. Every new Root of the head
+// removes outdated Root objects too. Zero keep turns
+// the retention off. E.g. all Root objects are kept.
+// A removed Root is not kept in any form, and a fork
+// of the Root can't be detected (see Equivocations)
+func (i *Index) SetHeadKeep(
+	pk cipher.PubKey,
+	nonce uint64,
+	keep uint32,
+) (
+	err error,
+) {
+
+	// with lock
+	if err = i.setHeadKeepLock(pk, nonce, keep); err != nil {
+		return
+	}
+
+	// without lock
+	return i.retain(pk, nonce)
+}
+
+// with lock, seq numbers of Root objects of given
+// head to remove, regarding the Keep of the head
+func (i *Index) outdatedRoots(
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	seqs []uint64,
+	err error,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs *indexHeads
+	if hs, err = i.indexHead(pk, nonce); err != nil {
+		return nil, nil // removed
+	}
+
+	var keep = int(hs.infos[nonce].Keep)
+
+	if keep == 0 {
+		return // keep all
+	}
+
+	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+
+		var heads data.Heads
+		if heads, err = feeds.Heads(pk); err != nil {
+			return
+		}
+
+		var rs data.Roots
+		if rs, err = heads.Roots(nonce); err != nil {
+			return
+		}
+
+		var n int
+
+		return rs.Descend(func(dr *data.Root) (_ error) {
+			if n++; n > keep {
+				seqs = append(seqs, dr.Seq)
+			}
+			return
+		})
+
+	})
+
+	return
+}
+
+// retain removes outdated Root objects
+// of given head (see SetHeadKeep)
+func (i *Index) retain(pk cipher.PubKey, nonce uint64) (err error) {
+
+	// with lock
+	var seqs []uint64
+	if seqs, err = i.outdatedRoots(pk, nonce); err != nil {
+		return
+	}
+
+	// without lock
+	for _, seq := range seqs {
+		if err = i.DelRoot(pk, nonce, seq); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// ForkHead creates new head of feed of given Root.
+// The first Root of the new head has the same Refs,
+// Descriptor and Registry as given Root. Objects of
+// the Root are shared, not copied. If given nonce is
+// zero, then random nonce used. The ForkHead returns
+// ErrHeadExists if head with given nonce already
+// exists. Given Root is not changed.
+//
+// Secret key of the Unpack should be allowed to save
+// Root objects of the feed (see Save). Root objects
+// of M-of-N multisig feed can't be forked this way
+func (c *Container) ForkHead(
+	up *Unpack, //          : to save new Root
+	src *registry.Root, //  : Root to fork
+	nonce uint64, //        : head of new Root or zero
+) (
+	r *registry.Root, //    : the new Root
+	err error, //           : an error
+) {
+
+	if c.HasFeed(src.Pub) == false {
+		return nil, data.ErrNoSuchFeed
+	}
+
+	if nonce != 0 && c.HasHead(src.Pub, nonce) == true {
+		return nil, ErrHeadExists
+	}
+
+	for nonce == 0 || c.HasHead(src.Pub, nonce) == true {
+		nonce = rand.Uint64()
+	}
+
+	r = &registry.Root{
+		Refs:       append([]registry.Dynamic{}, src.Refs...),
+		Descriptor: append([]byte{}, src.Descriptor...),
+		Reg:        src.Reg,
+		Pub:        src.Pub,
+		Nonce:      nonce,
+	}
+
+	if err = c.Save(up, r); err != nil {
+		return nil, err
+	}
+
+	return
+}
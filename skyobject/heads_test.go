@@ -0,0 +1,194 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestIndex_SetHeadLabel(t *testing.T) {
+	// SetHeadLabel(pk cipher.PubKey, nonce uint64, label string) (err error)
+
+	var (
+		c     = getTestContainer()
+		pk, _ = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	if err := c.SetHeadLabel(pk, 1, "main"); err != data.ErrNoSuchFeed {
+		t.Error("wrong error:", err)
+	}
+
+	assertNil(t, c.AddFeed(pk))
+
+	if err := c.SetHeadLabel(pk, 1, "main"); err != data.ErrNoSuchHead {
+		t.Error("wrong error:", err)
+	}
+
+	assertNil(t, c.AddHead(pk, 1))
+	assertNil(t, c.AddHead(pk, 2))
+	assertNil(t, c.SetHeadLabel(pk, 1, "main"))
+
+	if err := c.SetHeadLabel(pk, 2, "main"); err != ErrLabelExists {
+		t.Error("wrong error:", err)
+	}
+
+	var nonce, err = c.HeadByLabel(pk, "main")
+	assertNil(t, err)
+	assertTrue(t, nonce == 1, "wrong head")
+
+	var info data.HeadInfo
+	info, err = c.HeadInfo(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, info.Label == "main", "wrong label")
+
+	// remove
+
+	assertNil(t, c.SetHeadLabel(pk, 1, ""))
+
+	if _, err = c.HeadByLabel(pk, "main"); err != ErrNoSuchLabel {
+		t.Error("wrong error:", err)
+	}
+
+	assertNil(t, c.SetHeadLabel(pk, 2, "main"))
+	assertNil(t, c.DelHead(pk, 2))
+
+	if _, err = c.HeadByLabel(pk, "main"); err != ErrNoSuchLabel {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestIndex_SetActiveHead(t *testing.T) {
+	// SetActiveHead(pk cipher.PubKey, nonce uint64) (err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	assertNil(t, c.Save(up, &registry.Root{Pub: pk, Nonce: 1}))
+	assertNil(t, c.Save(up, &registry.Root{Pub: pk, Nonce: 2}))
+	assertTrue(t, c.ActiveHead(pk) == 2, "wrong active head")
+
+	assertNil(t, c.SetActiveHead(pk, 1))
+	assertNil(t, c.Save(up, &registry.Root{Pub: pk, Nonce: 2}))
+	assertTrue(t, c.ActiveHead(pk) == 1, "active head changed")
+
+	assertNil(t, c.SetActiveHead(pk, 2))
+	var info data.HeadInfo
+	info, err = c.HeadInfo(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, info.Active == false, "two active heads")
+
+	assertNil(t, c.SetActiveHead(pk, 1))
+	assertNil(t, c.ResetActiveHead(pk))
+	assertTrue(t, c.ActiveHead(pk) == 2, "active head not reset")
+
+	assertNil(t, c.SetActiveHead(pk, 1))
+	assertNil(t, c.DelHead(pk, 1))
+	assertTrue(t, c.ActiveHead(pk) == 2, "active head not removed")
+
+}
+
+func TestIndex_SetHeadKeep(t *testing.T) {
+	// SetHeadKeep(pk cipher.PubKey, nonce uint64, keep uint32) (err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+
+	for i := 0; i < 3; i++ {
+		assertNil(t, c.Save(up, r))
+	}
+
+	assertNil(t, c.SetHeadKeep(pk, 1, 2))
+
+	if _, err = c.Root(pk, 1, 0); err == nil {
+		t.Error("outdated Root not removed")
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	if _, err = c.Root(pk, 1, 1); err == nil {
+		t.Error("outdated Root not removed")
+	}
+
+	for _, seq := range []uint64{2, 3} {
+		if _, err = c.Root(pk, 1, seq); err != nil {
+			t.Error(err)
+		}
+	}
+
+}
+
+func TestContainer_ForkHead(t *testing.T) {
+	// ForkHead(up *Unpack, src *registry.Root,
+	//     nonce uint64) (r *registry.Root, err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var src = &registry.Root{Pub: pk, Nonce: 1, Descriptor: []byte("src")}
+
+	var user registry.Dynamic
+	assertNil(t, user.SetValue(up, &User{Name: "Alice", Age: 21}))
+	src.Refs = append(src.Refs, user)
+
+	assertNil(t, c.Save(up, src))
+
+	if _, err = c.ForkHead(up, src, 1); err != ErrHeadExists {
+		t.Error("wrong error:", err)
+	}
+
+	var r *registry.Root
+	r, err = c.ForkHead(up, src, 0)
+	assertNil(t, err)
+
+	assertTrue(t, r.Nonce != 0 && r.Nonce != src.Nonce, "wrong nonce")
+	assertTrue(t, r.Seq == 0, "wrong seq")
+	assertTrue(t, string(r.Descriptor) == "src", "wrong descriptor")
+	assertTrue(t, len(r.Refs) == 1 && r.Refs[0].Hash == user.Hash,
+		"wrong refs")
+	assertTrue(t, c.HasHead(pk, r.Nonce), "missing head")
+
+	// the objects are shared
+
+	assertNil(t, c.DelHead(pk, src.Nonce))
+
+	var val []byte
+	val, _, err = c.Get(user.Hash, 0)
+	assertNil(t, err)
+	assertTrue(t, len(val) != 0, "object of forked Root removed")
+
+}
@@ -16,12 +16,16 @@ type indexHeads struct {
 	h       map[uint64]*data.Root // last Root
 	activen uint64                // head with latest root (nonce)
 	activet int64                 // timestamp of the last root
+	pinned  bool                  // the activen selected explicitly
+
+	infos map[uint64]data.HeadInfo // labels, retention, etc
 }
 
 // heads
 func newIndexHeads() (hs *indexHeads) {
 	hs = new(indexHeads)
 	hs.h = make(map[uint64]*data.Root)
+	hs.infos = make(map[uint64]data.HeadInfo)
 	return
 }
 
@@ -30,15 +34,19 @@ func (i *indexHeads) setActive() {
 
 	// reset first
 	i.activet = 0
-	i.activen = 0
+
+	if i.pinned == false {
+		i.activen = 0
+	}
 
 	for nonce, dr := range i.h {
 
-		if i.activet < dr.Time {
-			i.activet = dr.Time
-			i.activen = nonce
+		if dr == nil {
+			continue // blank head
 		}
 
+		i.touch(nonce, dr.Time)
+
 	}
 
 }
@@ -57,6 +65,21 @@ type Index struct {
 	feeds  map[cipher.PubKey]*indexHeads
 	feedsl []cipher.PubKey // change on write
 
+	// feed -> writer -> grant of the writer
+	writers map[cipher.PubKey]map[cipher.PubKey]registry.Grant
+
+	// feed -> writer removed by the DelWriter
+	denied map[cipher.PubKey]map[cipher.PubKey]struct{}
+
+	// feed -> revoked key -> revocation
+	revoked map[cipher.PubKey]map[cipher.PubKey]registry.Revocation
+
+	// M-of-N multisig feeds
+	multisigs map[cipher.PubKey]*registry.Multisig
+
+	// evidences of forks (see Equivocation)
+	forks map[cipher.PubKey][]*Equivocation
+
 	stat   *indexStat
 	closeo sync.Once // close once
 }
@@ -67,6 +90,12 @@ func (i *Index) load(c *Container) (err error) {
 	i.stat = newIndexStat(c.conf.RollAvgSamples)
 
 	i.feeds = make(map[cipher.PubKey]*indexHeads)
+	i.writers = make(map[cipher.PubKey]map[cipher.PubKey]registry.Grant)
+	i.denied = make(map[cipher.PubKey]map[cipher.PubKey]struct{})
+	i.revoked = make(
+		map[cipher.PubKey]map[cipher.PubKey]registry.Revocation)
+	i.multisigs = make(map[cipher.PubKey]*registry.Multisig)
+	i.forks = make(map[cipher.PubKey][]*Equivocation)
 	i.c = c
 
 	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
@@ -106,11 +135,8 @@ func (i *Index) load(c *Container) (err error) {
 
 				feedMap.h[nonce] = ir // head (or nil)
 
-				if ir != nil && feedMap.activet < ir.Time {
-
-					feedMap.activet = ir.Time // last timestamp of active feeds
-					feedMap.activen = nonce   // active head (nonce)
-
+				if ir != nil {
+					feedMap.touch(nonce, ir.Time) // active head (nonce)
 				}
 
 				return
@@ -131,6 +157,22 @@ func (i *Index) load(c *Container) (err error) {
 		return
 	}
 
+	if err = i.loadFeedInfos(); err != nil {
+		return
+	}
+
+	if err = i.loadRevocations(); err != nil {
+		return
+	}
+
+	if err = i.loadGrants(); err != nil {
+		return
+	}
+
+	if err = i.loadHeadInfos(); err != nil {
+		return
+	}
+
 	i.loadTime = time.Now().UnixNano()
 
 	return
@@ -190,6 +232,11 @@ func (i *Index) AddFeed(pk cipher.PubKey) (err error) {
 		i.feeds[pk] = newIndexHeads() // add to Index
 	}
 
+	// revocations received with previewed Root objects
+	if len(i.revoked[pk]) > 0 {
+		return i.saveFeedInfo(pk)
+	}
+
 	return
 }
 
@@ -246,22 +293,84 @@ func (i *Index) receivedRoot(
 	pk cipher.PubKey,
 	sig cipher.Sig,
 	val []byte,
+	sigs []cipher.Sig,
 ) (
 	r *registry.Root,
 	err error,
 ) {
 
-	var hash = cipher.SumSHA256(val)
-	if err = cipher.VerifySignature(pk, sig, hash); err != nil {
+	if r, err = registry.DecodeRoot(val); err != nil {
 		return
 	}
 
-	if r, err = registry.DecodeRoot(val); err != nil {
-		return
+	if r.Pub != pk {
+		return nil, ErrFeedMismatch
+	}
+
+	// the revocations and the grants are signed by owner of
+	// the feed, and they are applied even if the Root is
+	// rejected
+
+	if err = i.applyRevocations(r); err != nil {
+		return nil, err
+	}
+
+	if err = i.applyGrants(r); err != nil {
+		return nil, err
+	}
+
+	// the Root can be signed by a writer of a
+	// multi-writer feed or by a publishing key,
+	// that are checked using the Time
+
+	if err = i.checkTime(r); err != nil {
+		return nil, err
+	}
+
+	if err = i.checkSigner(r, true); err != nil {
+		return nil, err
+	}
+
+	var hash = i.c.Sum(val)
+	if err = cipher.VerifySignature(r.Signer(), sig, hash); err != nil {
+		return nil, err
+	}
+
+	// M-of-N signatures of multisig feed, the
+	// Multisig can be declared by the Root
+
+	var ms *registry.Multisig
+
+	if ms, err = i.rootMultisig(r); err != nil {
+		return nil, err
+	}
+
+	if ms != nil {
+		if err = ms.Verify(hash, sigs); err != nil {
+			return nil, err
+		}
 	}
 
 	r.Hash = hash // set the hash
 	r.Sig = sig   // set the signature
+	r.Sigs = sigs // set signatures of multisig Root
+
+	// the Parents should be Root objects of
+	// the feed, the Prev should match stored
+	// chain, and a signed Root that conflicts
+	// with stored one is evidence of fork
+
+	if err = i.checkParents(r, false); err != nil {
+		return nil, err
+	}
+
+	if err = i.checkPrev(r); err != nil {
+		return nil, err
+	}
+
+	if err = i.checkFork(r, val, sig, sigs); err != nil {
+		return nil, err
+	}
 
 	return
 }
@@ -272,11 +381,13 @@ func (i *Index) receivedRoot(
 // can returns data.ErrNoSuchFeed error. This method
 // never return this error. And this method never set
 // IsFull fields to true, if this Container already
-// have this Root
+// have this Root. The sigs are signatures of a
+// Root of multisig feed (see SetMultisig)
 func (i *Index) PreviewRoot(
 	pk cipher.PubKey,
 	sig cipher.Sig,
 	val []byte,
+	sigs ...cipher.Sig,
 ) (
 	r *registry.Root,
 	err error,
@@ -285,7 +396,7 @@ func (i *Index) PreviewRoot(
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
-	return i.receivedRoot(pk, sig, val)
+	return i.receivedRoot(pk, sig, val, sigs)
 }
 
 // ReceivedRoot called by the node package to
@@ -295,11 +406,13 @@ func (i *Index) PreviewRoot(
 // root. The method changes nothing in DB, it
 // only checks the Root. The method set IsFull
 // field of the Root to true if DB already have
-// this Root
+// this Root. The sigs are signatures of a Root
+// of multisig feed (see SetMultisig)
 func (i *Index) ReceivedRoot(
 	pk cipher.PubKey,
 	sig cipher.Sig,
 	val []byte,
+	sigs ...cipher.Sig,
 ) (
 	r *registry.Root,
 	err error,
@@ -308,7 +421,7 @@ func (i *Index) ReceivedRoot(
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
-	if r, err = i.receivedRoot(pk, sig, val); err != nil {
+	if r, err = i.receivedRoot(pk, sig, val, sigs); err != nil {
 		r = nil // GC
 		return
 	}
@@ -348,6 +461,10 @@ func (i *Index) addRoot(r *registry.Root) (alreadyHave bool, err error) {
 
 	switch ir, err = i.findRoot(r.Pub, r.Nonce, r.Seq); {
 	case err == nil:
+		if ir.Hash != r.Hash {
+			// conflicting Root received while filling
+			return false, i.checkFork(r, r.Encode(), r.Sig, r.Sigs)
+		}
 		ir.Access = time.Now().UnixNano()
 		alreadyHave = true
 		return
@@ -383,6 +500,7 @@ func (i *Index) addRoot(r *registry.Root) (alreadyHave bool, err error) {
 		dr.Prev = r.Prev
 		dr.Hash = r.Hash
 		dr.Sig = r.Sig
+		dr.Sigs = r.Sigs
 		dr.Time = r.Time
 
 		return rs.Set(dr)
@@ -405,11 +523,7 @@ func (i *Index) addRoot(r *registry.Root) (alreadyHave bool, err error) {
 	// replace the last
 
 	hs.h[r.Nonce] = dr
-
-	if hs.activet < r.Time {
-		hs.activet = r.Time
-		hs.activen = r.Nonce
-	}
+	hs.touch(r.Nonce, r.Time)
 
 	// add to stat
 	i.stat.addRoot()
@@ -427,11 +541,7 @@ func (i *Index) addSavedRoot(r *registry.Root, dr *data.Root) {
 	// replace the last
 
 	hs.h[r.Nonce] = dr
-
-	if hs.activet < r.Time {
-		hs.activet = r.Time
-		hs.activen = r.Nonce
-	}
+	hs.touch(r.Nonce, r.Time)
 
 	// add to stat
 	i.stat.addRoot()
@@ -445,9 +555,24 @@ func (i *Index) addSavedRoot(r *registry.Root, dr *data.Root) {
 // it returns alreadyHave reply instead. E.g. if the Container
 // already have this Root, then the alreadyHave reply will be
 // true. The method never save the Root inside CXDS. E.g. the
-// method adds the Root to index (that is necessary)
+// method adds the Root to index (that is necessary).
+// The AddRoot removes outdated Root objects of the head
+// of the Root, if the head has retention (see SetHeadKeep)
 func (i *Index) AddRoot(r *registry.Root) (alreadyHave bool, err error) {
 
+	// with lock
+	if alreadyHave, err = i.addRootLock(r); err != nil || alreadyHave == true {
+		return
+	}
+
+	// without lock
+	err = i.retain(r.Pub, r.Nonce)
+	return
+}
+
+// with lock
+func (i *Index) addRootLock(r *registry.Root) (alreadyHave bool, err error) {
+
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
@@ -466,9 +591,8 @@ func (i *Index) AddRoot(r *registry.Root) (alreadyHave bool, err error) {
 // then reply will be zero too.
 //
 // Every new Root object can change the ActiveHead
-// value if its head is different. And it's impossible
-// to change the ActiveHead value other ways neither
-// than inserting Root
+// value if its head is different. Use SetActiveHead
+// to select active head explicitly
 func (i *Index) ActiveHead(pk cipher.PubKey) (nonce uint64) {
 
 	i.mx.Lock()
@@ -562,6 +686,7 @@ func (i *Index) LastRoot(
 
 	r.IsFull = true
 	r.Sig = lr.Sig
+	r.Sigs = lr.Sigs
 	return
 }
 
@@ -618,6 +743,11 @@ func (i *Index) delFeed(
 	// delete from the Index
 
 	delete(i.feeds, pk)
+	delete(i.writers, pk)
+	delete(i.denied, pk)
+	delete(i.revoked, pk)
+	delete(i.multisigs, pk)
+	delete(i.forks, pk)
 	i.feedsl = nil // clear the list
 
 	return
@@ -708,6 +838,7 @@ func (i *Index) delHead(
 	// delete from the Index
 
 	delete(hs.h, nonce)
+	i.delHeadInfo(hs, nonce)
 
 	return
 
@@ -1035,7 +1166,7 @@ func (i *Index) AddHead(pk cipher.PubKey, nonce uint64) (err error) {
 
 	// add to the Index
 
-	i.feeds[pk] = newIndexHeads()
+	hs.h[nonce] = nil // blank head
 	return
 
 }
@@ -1128,6 +1259,39 @@ func (i *Index) Root(
 
 	r.IsFull = true
 	r.Sig = dr.Sig
+	r.Sigs = dr.Sigs
 
 	return
 }
+
+// FeedRefRoot returns Root the FeedRef points to. It's
+// latest Root of active head of the feed, if the FeedRef
+// has not head. Or latest Root of given head, if the
+// FeedRef has not seq. Or Root with given seq of given
+// head. The FeedRefRoot returns data.ErrNoSuchFeed,
+// data.ErrNoSuchHead or data.ErrNotFound if the Root
+// has not been received yet. Blank or invalid FeedRef
+// produces registry.ErrInvalidFeedRef error
+func (i *Index) FeedRefRoot(
+	fr *registry.FeedRef, // : the FeedRef
+) (
+	r *registry.Root, //     : the Root
+	err error, //            : an error
+) {
+
+	if fr.IsBlank() == true || fr.IsValid() == false {
+		return nil, registry.ErrInvalidFeedRef
+	}
+
+	if fr.HasSeq() == true {
+		return i.Root(fr.Pub, fr.Nonce, fr.Seq)
+	}
+
+	var nonce = fr.Nonce
+
+	if fr.HasHead() == false {
+		nonce = i.ActiveHead(fr.Pub)
+	}
+
+	return i.LastRoot(fr.Pub, nonce)
+}
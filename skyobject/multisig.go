@@ -0,0 +1,194 @@
+package skyobject
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// SetMultisig makes given feed M-of-N multisig feed.
+// Root objects of the feed should have enough valid
+// signatures of keys of given Multisig (see Sigs field
+// of the registry.Root). Other Root objects of the feed
+// are rejected with the registry.ErrNotEnoughSignatures
+// error. The Multisig replaces previous one.
+//
+// To publish a Root of multisig feed, prepare it using
+// the Prepare method, collect signatures of the Root
+// (see registry.PartialSig) and save it using the Save
+// method. The Prepare puts the Multisig to first Root
+// of a head.
+//
+// The Multisig is part of configuration of the feed,
+// and it's never taken from received Root objects,
+// since owner of the feed can't prove it. Thus, a
+// Container that follows a multisig feed should be
+// configured by the SetMultisig too. A received Root
+// that declares another Multisig (or declares it, if
+// the feed is not multisig) is rejected with the
+// ErrMultisigMismatch error. A multisig feed can't be
+// turned to ordinary feed, but the Multisig can be
+// replaced.
+//
+// The Multisig is saved in DB, if the IdxDB implements
+// data.FeedKeeper interface. The feed must exist
+func (i *Index) SetMultisig(
+	feed cipher.PubKey, //       : the feed
+	ms *registry.Multisig, //    : M-of-N
+) (
+	err error, //                : the error
+) {
+
+	if err = ms.Validate(); err != nil {
+		return
+	}
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[feed]; ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	i.multisigs[feed] = &registry.Multisig{
+		Keys:      append([]cipher.PubKey{}, ms.Keys...),
+		Threshold: ms.Threshold,
+	}
+
+	return i.saveFeedInfo(feed)
+}
+
+// under lock, returns Multisig of feed of given
+// Root, or nil if the feed is not multisig. The
+// Multisig can be declared by first Root of a head
+// signed by owner of the feed, and the declared
+// Multisig must match the configured one
+func (i *Index) rootMultisig(
+	r *registry.Root, //        : the Root
+) (
+	ms *registry.Multisig, //   : Multisig of the feed
+	err error, //               : the error
+) {
+
+	ms = i.multisigs[r.Pub] // configured
+
+	if r.Multisig.IsBlank() == true {
+		return // not declared
+	}
+
+	if r.Seq != 0 || r.Author != (cipher.PubKey{}) {
+		return nil, registry.ErrInvalidMultisig
+	}
+
+	if ms == nil || ms.Equal(&r.Multisig) == false {
+		return nil, ErrMultisigMismatch
+	}
+
+	return
+}
+
+// Multisig returns copy of Multisig of given
+// feed, or nil if the feed is not multisig
+func (i *Index) Multisig(feed cipher.PubKey) (ms *registry.Multisig) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var m, ok = i.multisigs[feed]
+
+	if ok == false {
+		return
+	}
+
+	return &registry.Multisig{
+		Keys:      append([]cipher.PubKey{}, m.Keys...),
+		Threshold: m.Threshold,
+	}
+}
+
+// Prepare given Root of multisig feed to be signed.
+// The Prepare sets Reg, Author, Seq, Prev, Time,
+// Multisig and Hash fields of the Root, and resets
+// its Sigs. The Multisig is set in first Root of a
+// head signed by owner of the feed only. Thus,
+// the Hash can be signed by keys of the Multisig (see
+// registry.NewPartialSig and AddSig method of the
+// registry.Multisig). The Root must not be changed
+// after the Prepare, and it should be saved using the
+// Save method with the same Unpack.
+//
+// If another Root is saved to the same head after the
+// Prepare, then the Save returns ErrOutdatedRoot, and
+// the Root should be prepared and signed again
+func (c *Container) Prepare(up *Unpack, r *registry.Root) (err error) {
+
+	if err = c.checkRoot(up, r); err != nil {
+		return
+	}
+
+	return c.Index.prepareRoot(up, r)
+}
+
+func (i *Index) prepareRoot(up *Unpack, r *registry.Root) (err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var hs, ok = i.feeds[r.Pub]
+
+	if ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	var ms *registry.Multisig
+	if ms, ok = i.multisigs[r.Pub]; ok == false {
+		return ErrNotMultisig
+	}
+
+	var writer = cipher.PubKeyFromSecKey(up.sk)
+
+	if writer == r.Pub {
+		r.Author = cipher.PubKey{} // the owner
+	} else {
+		r.Author = writer
+	}
+
+	if err = i.applyRevocations(r); err != nil {
+		return
+	}
+
+	// next after the last Root of the head
+
+	if dr := hs.h[r.Nonce]; dr != nil {
+		r.Seq = dr.Seq + 1
+		r.Prev = dr.Hash
+	} else {
+		r.Seq = 0
+		r.Prev = cipher.SHA256{}
+	}
+
+	r.Time = time.Now().UnixNano()
+
+	// the Multisig travels with first Root of a head
+
+	if r.Seq == 0 && r.Author == (cipher.PubKey{}) {
+		r.Multisig = registry.Multisig{
+			Keys:      append([]cipher.PubKey{}, ms.Keys...),
+			Threshold: ms.Threshold,
+		}
+	} else {
+		r.Multisig = registry.Multisig{}
+	}
+
+	if err = i.checkSigner(r, true); err != nil {
+		return
+	}
+
+	r.Sigs = nil
+	r.Hash = i.c.Sum(r.Encode())
+
+	return
+}
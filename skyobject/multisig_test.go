@@ -0,0 +1,194 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idx/memory"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_Prepare(t *testing.T) {
+	// Prepare(up *Unpack, r *registry.Root) (err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		ap, as = cipher.GenerateKeyPair()
+		bp, bs = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var ms, err = registry.NewMultisig(2, ap, bp)
+	assertNil(t, err)
+	assertNil(t, c.SetMultisig(pk, ms))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+
+	// not signed
+
+	assertNil(t, c.Prepare(up, r))
+
+	if err = c.Save(up, r); err != registry.ErrNotEnoughSignatures {
+		t.Error("wrong error:", err)
+	}
+
+	// signed
+
+	assertNil(t, c.Prepare(up, r))
+	assertNil(t, ms.AddSig(r, registry.NewPartialSig(r.Hash, as)))
+	assertNil(t, ms.AddSig(r, registry.NewPartialSig(r.Hash, bs)))
+
+	var hash = r.Hash
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, r.Hash == hash, "hash changed")
+
+	var rr *registry.Root
+	rr, err = c.ReceivedRoot(pk, r.Sig, r.Encode(), r.Sigs...)
+	assertNil(t, err)
+	assertTrue(t, len(rr.Sigs) == 2, "wrong number of signatures")
+
+	if _, err = c.ReceivedRoot(pk, r.Sig, r.Encode()); err !=
+		registry.ErrNotEnoughSignatures {
+
+		t.Error("wrong error:", err)
+	}
+
+	// outdated
+
+	if err = c.Save(up, r); err != ErrOutdatedRoot {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestIndex_rootMultisig(t *testing.T) {
+	// rootMultisig(r *registry.Root) (ms *registry.Multisig, err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		ap, as = cipher.GenerateKeyPair()
+		bp, bs = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var ms, err = registry.NewMultisig(2, ap, bp)
+	assertNil(t, err)
+	assertNil(t, c.SetMultisig(pk, ms))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var sign = func(r *registry.Root) {
+		assertNil(t, ms.AddSig(r, registry.NewPartialSig(r.Hash, as)))
+		assertNil(t, ms.AddSig(r, registry.NewPartialSig(r.Hash, bs)))
+	}
+
+	// the Multisig travels with first Root of a head
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	assertNil(t, c.Prepare(up, r))
+	assertTrue(t, r.Multisig.Equal(ms), "Multisig is not set in first Root")
+
+	sign(r)
+	assertNil(t, c.Save(up, r))
+
+	var nr = &registry.Root{Pub: pk, Nonce: 1}
+	assertNil(t, c.Prepare(up, nr))
+	assertTrue(t, nr.Multisig.IsBlank(), "Multisig is set in next Root")
+
+	sign(nr)
+	assertNil(t, c.Save(up, nr))
+
+	// not adopted by receiver, that is not configured
+
+	var conf = getTestConfig()
+	conf.DB = data.NewDB(cxds.NewMemoryCXDS(), memory.NewMemory(0))
+
+	var rc *Container
+	rc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer rc.Close()
+
+	assertNil(t, rc.AddFeed(pk))
+
+	if _, err = rc.ReceivedRoot(pk, r.Sig, r.Encode(), r.Sigs...); err !=
+		ErrMultisigMismatch {
+
+		t.Error("wrong error:", err)
+	}
+
+	assertTrue(t, rc.Multisig(pk) == nil, "Multisig is adopted")
+
+	// configured and saved in DB
+
+	assertNil(t, rc.SetMultisig(pk, ms))
+
+	_, err = rc.ReceivedRoot(pk, r.Sig, r.Encode(), r.Sigs...)
+	assertNil(t, err)
+
+	var lc *Container
+	lc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer lc.Close()
+
+	var got = lc.Multisig(pk)
+	assertTrue(t, got != nil && got.Equal(ms), "Multisig is not loaded")
+
+	if _, err = lc.ReceivedRoot(pk, nr.Sig, nr.Encode()); err !=
+		registry.ErrNotEnoughSignatures {
+
+		t.Error("wrong error:", err)
+	}
+
+	_, err = lc.ReceivedRoot(pk, nr.Sig, nr.Encode(), nr.Sigs...)
+	assertNil(t, err)
+
+	// owner-only Root of a new head
+
+	var or = &registry.Root{Pub: pk, Nonce: 3}
+
+	var ov = or.Encode()
+	or.Sig = cipher.SignHash(lc.Sum(ov), sk)
+
+	if _, err = lc.ReceivedRoot(pk, or.Sig, ov); err !=
+		registry.ErrNotEnoughSignatures {
+
+		t.Error("wrong error:", err)
+	}
+
+	// another Multisig can't replace configured one
+
+	var xm *registry.Multisig
+	xm, err = registry.NewMultisig(1, ap)
+	assertNil(t, err)
+
+	var xr = &registry.Root{Pub: pk, Nonce: 2, Multisig: *xm}
+
+	var val = xr.Encode()
+	xr.Hash = lc.Sum(val)
+	xr.Sig = cipher.SignHash(xr.Hash, sk)
+	assertNil(t, xm.AddSig(xr, registry.NewPartialSig(xr.Hash, as)))
+
+	if _, err = lc.ReceivedRoot(pk, xr.Sig, val, xr.Sigs...); err !=
+		ErrMultisigMismatch {
+
+		t.Error("wrong error:", err)
+	}
+
+}
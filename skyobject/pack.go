@@ -36,9 +36,16 @@ func (p *Pack) Set(key cipher.SHA256, val []byte) (err error) {
 	return
 }
 
+// Sum implements registry.Hasher interface
+// and returns hash of given value using hash
+// algorithm of the Container
+func (p *Pack) Sum(val []byte) (key cipher.SHA256) {
+	return p.c.Sum(val)
+}
+
 // Add is Set that calculates hash inside
 func (p *Pack) Add(val []byte) (key cipher.SHA256, err error) {
-	key = cipher.SumSHA256(val)
+	key = p.c.Sum(val)
 	err = p.Set(key, val)
 	return
 }
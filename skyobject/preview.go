@@ -1,6 +1,8 @@
 package skyobject
 
 import (
+	"errors"
+
 	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/cxo/data"
@@ -22,10 +24,17 @@ type Getter interface {
 // feeds preview. The Preview get object
 // of a Root from database if possible,
 // otherwise it request this objects using
-// provided getter. The Preview used by
+// provided getter. Received objects are
+// kept in bounded preview cache of the
+// Container (see PreviewCacheMaxAmount
+// and PreviewCacheMaxVolume of Config).
+// The cache is shared between all Preview
+// instances. If the cache is disabled, then
+// the Preview keeps received objects itself
+// until it's released. The Preview used by
 // the node package for feeds preview
 type Preview struct {
-	m map[cipher.SHA256][]byte // hash -> value (TODO: keep in memory?)
+	m map[cipher.SHA256][]byte // if the preview cache is disabled
 	g Getter                   // get from remote peer
 	r *registry.Root           // root for Preview
 	c *Container               // back reference to access DB and get Registry
@@ -41,25 +50,84 @@ func (p *Preview) Root() (r *registry.Root) {
 // Get from DB or from remote peer
 func (p *Preview) Get(key cipher.SHA256) (val []byte, err error) {
 
-	// check out map first
+	// check out preview cache (or the map) first
 	var ok bool
-	if val, ok = p.m[key]; ok {
+	if val, ok = p.getReceived(key); ok {
 		return // alrady received
 	}
 
-	if val, err = p.Pack.Get(key); err != nil && err != data.ErrNotFound {
-		return // db failure
+	if val, err = p.Pack.Get(key); err == nil || err != data.ErrNotFound {
+		return // found in DB or db failure
 	}
 
 	// not found
 
 	if val, err = p.g.Get(key); err == nil {
-		p.m[key] = val // save in the map
+		p.setReceived(key, val) // keep in the preview cache (or the map)
 	}
 
 	return
 }
 
+// received object from the preview cache or from
+// the map, if the preview cache is disabled
+func (p *Preview) getReceived(key cipher.SHA256) (val []byte, ok bool) {
+
+	if p.m != nil {
+		val, ok = p.m[key]
+		return
+	}
+
+	return p.c.preview.get(key)
+}
+
+// keep received object in the preview cache or in
+// the map, if the preview cache is disabled
+func (p *Preview) setReceived(key cipher.SHA256, val []byte) {
+
+	if p.m != nil {
+		p.m[key] = val
+		return
+	}
+
+	p.c.preview.set(key, val)
+}
+
+// Items of the Root of the Preview
+// (see (*registry.Root).Items for details)
+func (p *Preview) Items() (items []*registry.Item, err error) {
+	return p.r.Items(p)
+}
+
+// Object returns object with given hash and
+// schema (see registry.ObjectItem for details)
+func (p *Preview) Object(
+	sr registry.SchemaRef, // : schema of the object
+	hash cipher.SHA256, //    : hash of the object
+) (
+	it *registry.Item, //     : the object
+	err error, //             : error if any
+) {
+	return registry.ObjectItem(p, sr, hash)
+}
+
+// Refs returns page of elements of registry.Refs from
+// i (inclusive) to j (exclusive) and length of the Refs.
+// Only required branches of the Refs will be requested
+// (see registry.RefsItems for details)
+func (p *Preview) Refs(
+	el registry.SchemaRef, //  : schema of elements
+	hash cipher.SHA256, //     : hash of the Refs
+	i int, //                  : first element
+	j int, //                  : last element (exclusive)
+) (
+	length int, //             : length of the Refs
+	items []*registry.Item, // : page of the Refs
+	err error, //              : error if any
+) {
+	return registry.RefsItems(p, el, hash, i, j)
+}
+
 // Preview creates Preview using given
 // Getter and registry.Root. It returns
 // error if the Preview method can't
@@ -80,7 +148,11 @@ func (c *Container) Preview(
 
 	pack.r = r
 	pack.g = g
-	pack.m = make(map[cipher.SHA256][]byte)
+	pack.c = c
+
+	if c.preview.disabled() == true {
+		pack.m = make(map[cipher.SHA256][]byte)
+	}
 
 	var reg *registry.Registry
 	if reg, err = c.Registry(r.Reg); err != nil {
@@ -92,8 +164,15 @@ func (c *Container) Preview(
 		// not found, let's get it using the Getter
 
 		var val []byte
-		if val, err = g.Get(cipher.SHA256(r.Reg)); err != nil {
-			return // can't receive
+		var ok bool
+		if val, ok = pack.getReceived(cipher.SHA256(r.Reg)); ok == false {
+
+			if val, err = g.Get(cipher.SHA256(r.Reg)); err != nil {
+				return // can't receive
+			}
+
+			pack.setReceived(cipher.SHA256(r.Reg), val)
+
 		}
 
 		if reg, err = registry.DecodeRegistry(val); err != nil {
@@ -109,3 +188,53 @@ func (c *Container) Preview(
 	return
 
 }
+
+// PreviewRootByHash returns Root with given hash for
+// feed preview. The Root is taken from DB, from the
+// preview cache or requested using given Getter. The
+// Root is checked the same way as the PreviewRoot does.
+// Thus, the sig and the sigs are signature and M-of-N
+// signatures of the Root. The PreviewRootByHash is used
+// by the node package to preview the same Root page by
+// page, even if the feed has newer Root objects
+func (c *Container) PreviewRootByHash(
+	pk cipher.PubKey, //    : feed
+	hash cipher.SHA256, //  : hash of the Root
+	sig cipher.Sig, //      : signature of the Root
+	sigs []cipher.Sig, //   : M-of-N signatures of the Root
+	g Getter, //            : to get the Root from remote peer
+) (
+	r *registry.Root, //    : the Root
+	err error, //           : an error
+) {
+
+	var val, ok = c.preview.get(hash)
+
+	if ok == false {
+
+		if val, _, err = c.Get(hash, 0); err != nil {
+
+			if err != data.ErrNotFound {
+				return // DB failure
+			}
+
+			if val, err = g.Get(hash); err != nil {
+				return // can't receive
+			}
+
+			c.preview.set(hash, val)
+
+		}
+
+	}
+
+	if r, err = c.PreviewRoot(pk, sig, val, sigs...); err != nil {
+		return
+	}
+
+	if r.Hash != hash {
+		return nil, errors.New("wrong Root received (different hash)")
+	}
+
+	return
+}
@@ -0,0 +1,121 @@
+package skyobject
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A previewCache is bounded LRU cache of objects
+// received from remote peers for feeds preview.
+// The previewCache is shared between all Preview
+// instances of a Container. Thus, a feed can be
+// explored page by page without requesting the
+// same objects again and again. The previewCache
+// never exceeds its boundaries, removing least
+// recently used objects. Objects of the cache are
+// not stored in DB
+type previewCache struct {
+	mx sync.Mutex
+
+	maxAmount int // max number of items
+	maxVolume int // max total length of values
+
+	amount int // number of items
+	volume int // total length of values
+
+	l *list.List                      // front is most recently used
+	m map[cipher.SHA256]*list.Element // hash -> element
+}
+
+// element of the previewCache
+type previewItem struct {
+	key cipher.SHA256
+	val []byte
+}
+
+// create previewCache; if one of given limits
+// is zero, then the previewCache is disabled
+func newPreviewCache(maxAmount, maxVolume int) (p *previewCache) {
+
+	p = new(previewCache)
+
+	p.maxAmount = maxAmount
+	p.maxVolume = maxVolume
+
+	p.l = list.New()
+	p.m = make(map[cipher.SHA256]*list.Element)
+
+	return
+}
+
+// is the cache switched off
+func (p *previewCache) disabled() bool {
+	return p.maxAmount == 0 || p.maxVolume == 0
+}
+
+// get value from the cache
+func (p *previewCache) get(key cipher.SHA256) (val []byte, ok bool) {
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	var el *list.Element
+	if el, ok = p.m[key]; ok == false {
+		return
+	}
+
+	p.l.MoveToFront(el)
+	return el.Value.(*previewItem).val, true
+}
+
+// remove least recently used item
+func (p *previewCache) removeOldest() {
+
+	var el = p.l.Back()
+
+	if el == nil {
+		return
+	}
+
+	var pi = p.l.Remove(el).(*previewItem)
+	delete(p.m, pi.key)
+
+	p.amount--
+	p.volume -= len(pi.val)
+}
+
+// put value to the cache
+func (p *previewCache) set(key cipher.SHA256, val []byte) {
+
+	if p.disabled() == true || len(val) > p.maxVolume {
+		return // can't fit
+	}
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if el, ok := p.m[key]; ok == true {
+		p.l.MoveToFront(el)
+		return // already have
+	}
+
+	for p.amount+1 > p.maxAmount || p.volume+len(val) > p.maxVolume {
+		p.removeOldest()
+	}
+
+	p.m[key] = p.l.PushFront(&previewItem{key, val})
+
+	p.amount++
+	p.volume += len(val)
+}
+
+// amount and volume of the cache
+func (p *previewCache) amountVolume() (amount, volume int) {
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	return p.amount, p.volume
+}
@@ -0,0 +1,111 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func Test_previewCache(t *testing.T) {
+
+	var (
+		pc = newPreviewCache(2, 10)
+
+		one, two, three = []byte("one"), []byte("two"), []byte("three")
+
+		oneKey   = cipher.SumSHA256(one)
+		twoKey   = cipher.SumSHA256(two)
+		threeKey = cipher.SumSHA256(three)
+	)
+
+	pc.set(oneKey, one)
+	pc.set(twoKey, two)
+
+	if _, ok := pc.get(oneKey); ok == false {
+		t.Error("missing 'one'")
+	}
+
+	// the 'two' is least recently used
+	pc.set(threeKey, three)
+
+	if _, ok := pc.get(twoKey); ok == true {
+		t.Error("'two' has not been removed")
+	}
+
+	if _, ok := pc.get(oneKey); ok == false {
+		t.Error("missing 'one'")
+	}
+
+	if amount, volume := pc.amountVolume(); amount != 2 || volume != 8 {
+		t.Error("wrong amount or volume:", amount, volume)
+	}
+
+	// too big
+	pc.set(cipher.SHA256{1}, make([]byte, 11))
+
+	if amount, _ := pc.amountVolume(); amount != 2 {
+		t.Error("wrong amount:", amount)
+	}
+
+	// disabled
+	pc = newPreviewCache(0, 0)
+	pc.set(oneKey, one)
+
+	if _, ok := pc.get(oneKey); ok == true {
+		t.Error("disabled cache keeps an item")
+	}
+
+}
+
+// counts requests
+type countingGetter struct {
+	m  map[cipher.SHA256][]byte
+	rq int
+}
+
+func (c *countingGetter) Get(key cipher.SHA256) (val []byte, err error) {
+	c.rq++
+	return c.m[key], nil
+}
+
+func TestPreview_Get(t *testing.T) {
+	// Get(key cipher.SHA256) (val []byte, err error)
+
+	var conf = getTestConfig()
+	conf.PreviewCacheMaxAmount = 0 // disable
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var (
+		val = []byte("value")
+		key = cipher.SumSHA256(val)
+		reg = testRegistry.Encode()
+		rr  = cipher.SHA256(c.RegistryRef(testRegistry))
+
+		g = &countingGetter{m: map[cipher.SHA256][]byte{
+			key: val,
+			rr:  reg,
+		}}
+	)
+
+	var r = &registry.Root{Reg: registry.RegistryRef(rr)}
+
+	var p *Preview
+	p, err = c.Preview(r, g)
+	assertNil(t, err)
+
+	for i := 0; i < 3; i++ {
+		var got []byte
+		got, err = p.Get(key)
+		assertNil(t, err)
+		assertTrue(t, string(got) == "value", "wrong value")
+	}
+
+	// the registry and the value
+	assertTrue(t, g.rq == 2, "objects requested again")
+
+}
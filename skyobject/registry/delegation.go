@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A Delegation represents certificate, that authorizes
+// a publishing key to sign Root objects of a feed
+// during a validity window. The Delegation is signed
+// by owner of the feed. Thus, the owner can keep its
+// secret key offline, and publishers can use short-lived
+// keys, rotating them without changing the feed.
+//
+// A Root signed by a publishing key carries the
+// Delegation (see Delegation field of the Root) and
+// the publishing key is Author of the Root. Use
+// Revocation to revoke a leaked key before its
+// Delegation expires
+type Delegation struct {
+	Feed      cipher.PubKey // feed (owner)
+	Key       cipher.PubKey // publishing key
+	NotBefore int64         // start of the window (unix nano)
+	NotAfter  int64         // end of the window (unix nano, exclusive)
+
+	Sig cipher.Sig // signature of the owner
+}
+
+// NewDelegation creates Delegation of given key
+// signed by given secret key of a feed
+func NewDelegation(
+	sk cipher.SecKey, //      : secret key of the feed
+	key cipher.PubKey, //     : publishing key
+	notBefore time.Time, //   : start of the window
+	notAfter time.Time, //    : end of the window
+) (
+	d Delegation, //          : the Delegation
+) {
+
+	d.Feed = cipher.PubKeyFromSecKey(sk)
+	d.Key = key
+	d.NotBefore = notBefore.UnixNano()
+	d.NotAfter = notAfter.UnixNano()
+	d.Sig = cipher.SignHash(d.Hash(), sk)
+
+	return
+}
+
+// IsBlank returns true if the Delegation is blank
+func (d *Delegation) IsBlank() bool {
+	return *d == Delegation{}
+}
+
+// Hash of the Delegation that signed (e.g.
+// hash of the Delegation without signature)
+func (d *Delegation) Hash() cipher.SHA256 {
+	var x = *d
+	x.Sig = cipher.Sig{}
+	return cipher.SumSHA256(encoder.Serialize(x))
+}
+
+// Verify the Delegation. The Verify checks the window
+// and signature of the Delegation. It returns the
+// ErrInvalidDelegation or signature verification error
+func (d *Delegation) Verify() (err error) {
+
+	if d.Feed == (cipher.PubKey{}) || d.Key == (cipher.PubKey{}) ||
+		d.Feed == d.Key || d.NotAfter <= d.NotBefore {
+
+		return ErrInvalidDelegation
+	}
+
+	return cipher.VerifySignature(d.Feed, d.Sig, d.Hash())
+}
+
+// IsValidAt returns true if given
+// timestamp is inside the window
+func (d *Delegation) IsValidAt(t int64) bool {
+	return d.NotBefore <= t && t < d.NotAfter
+}
+
+// A Revocation represents record, that revokes a
+// publishing key of a feed. Root objects signed by
+// the key with timestamp equal to or greater then
+// Since of the Revocation are rejected, even if
+// Delegation of the key is valid. The Revocation
+// is signed by owner of the feed.
+//
+// Root objects carry revocations (see Revocations
+// field of the Root). The Revocations are applied
+// by receiver, even if the Root is rejected
+type Revocation struct {
+	Feed  cipher.PubKey // feed (owner)
+	Key   cipher.PubKey // revoked key
+	Since int64         // revoked since (unix nano)
+
+	Sig cipher.Sig // signature of the owner
+}
+
+// NewRevocation creates Revocation of given key
+// signed by given secret key of a feed
+func NewRevocation(
+	sk cipher.SecKey, //  : secret key of the feed
+	key cipher.PubKey, // : the key to revoke
+	since time.Time, //   : revoked since
+) (
+	r Revocation, //      : the Revocation
+) {
+
+	r.Feed = cipher.PubKeyFromSecKey(sk)
+	r.Key = key
+	r.Since = since.UnixNano()
+	r.Sig = cipher.SignHash(r.Hash(), sk)
+
+	return
+}
+
+// Hash of the Revocation that signed (e.g.
+// hash of the Revocation without signature)
+func (r *Revocation) Hash() cipher.SHA256 {
+	var x = *r
+	x.Sig = cipher.Sig{}
+	return cipher.SumSHA256(encoder.Serialize(x))
+}
+
+// Verify signature of the Revocation. It returns
+// the ErrInvalidRevocation or signature
+// verification error
+func (r *Revocation) Verify() (err error) {
+
+	if r.Feed == (cipher.PubKey{}) || r.Key == (cipher.PubKey{}) ||
+		r.Feed == r.Key {
+
+		return ErrInvalidRevocation
+	}
+
+	return cipher.VerifySignature(r.Feed, r.Sig, r.Hash())
+}
+
+// Revokes returns true if the Revocation revokes
+// given key at given time
+func (r *Revocation) Revokes(key cipher.PubKey, t int64) bool {
+	return r.Key == key && r.Since <= t
+}
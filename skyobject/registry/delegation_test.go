@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestNewDelegation(t *testing.T) {
+	// NewDelegation(sk, key, notBefore, notAfter) (d Delegation)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		kp, _  = cipher.GenerateKeyPair()
+		now    = time.Now()
+		d      = NewDelegation(sk, kp, now, now.Add(time.Hour))
+	)
+
+	if d.Feed != pk || d.Key != kp {
+		t.Error("wrong Feed or Key")
+	}
+
+	if err := d.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	if d.IsValidAt(now.UnixNano()) == false {
+		t.Error("invalid at start of the window")
+	}
+
+	if d.IsValidAt(now.Add(time.Hour).UnixNano()) == true {
+		t.Error("valid at end of the window")
+	}
+
+	if d.IsValidAt(now.Add(-time.Second).UnixNano()) == true {
+		t.Error("valid before the window")
+	}
+
+	// forged
+
+	var fd = d
+	fd.NotAfter = now.Add(2 * time.Hour).UnixNano()
+
+	if err := fd.Verify(); err == nil {
+		t.Error("missing error")
+	}
+
+	// invalid window
+
+	var id = NewDelegation(sk, kp, now, now)
+
+	if err := id.Verify(); err != ErrInvalidDelegation {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestNewRevocation(t *testing.T) {
+	// NewRevocation(sk, key, since) (r Revocation)
+
+	var (
+		_, sk = cipher.GenerateKeyPair()
+		kp, _ = cipher.GenerateKeyPair()
+		xp, _ = cipher.GenerateKeyPair()
+		now   = time.Now()
+		rv    = NewRevocation(sk, kp, now)
+	)
+
+	if err := rv.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	if rv.Revokes(kp, now.UnixNano()) == false {
+		t.Error("not revoked")
+	}
+
+	if rv.Revokes(kp, now.Add(-time.Second).UnixNano()) == true {
+		t.Error("revoked before Since")
+	}
+
+	if rv.Revokes(xp, now.UnixNano()) == true {
+		t.Error("revokes another key")
+	}
+
+	var fr = rv
+	fr.Key = xp
+
+	if err := fr.Verify(); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestRoot_VerifyDelegation(t *testing.T) {
+	// VerifyDelegation() (err error)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		kp, _  = cipher.GenerateKeyPair()
+		xp, _  = cipher.GenerateKeyPair()
+		now    = time.Now()
+	)
+
+	var r = &Root{
+		Pub:        pk,
+		Author:     kp,
+		Time:       now.UnixNano(),
+		Delegation: NewDelegation(sk, kp, now, now.Add(time.Hour)),
+		Revocations: []Revocation{
+			NewRevocation(sk, xp, now),
+		},
+	}
+
+	if err := r.VerifyDelegation(); err != nil {
+		t.Error(err)
+	}
+
+	// encode / decode
+
+	var dr, err = DecodeRoot(r.Encode())
+
+	if err != nil {
+		t.Fatal(err)
+	} else if dr.Delegation != r.Delegation {
+		t.Error("wrong decoded Delegation")
+	} else if len(dr.Revocations) != 1 ||
+		dr.Revocations[0] != r.Revocations[0] {
+
+		t.Error("wrong decoded Revocations")
+	} else if err = dr.VerifyDelegation(); err != nil {
+		t.Error(err)
+	}
+
+	// expired
+
+	r.Time = now.Add(time.Hour).UnixNano()
+
+	if err = r.VerifyDelegation(); err != ErrDelegationExpired {
+		t.Error("wrong error:", err)
+	}
+
+	// another Author
+
+	r.Time, r.Author = now.UnixNano(), xp
+
+	if err = r.VerifyDelegation(); err != ErrInvalidDelegation {
+		t.Error("wrong error:", err)
+	}
+
+	// blank
+
+	r.Author, r.Delegation = kp, Delegation{}
+
+	if err = r.VerifyDelegation(); err != ErrInvalidDelegation {
+		t.Error("wrong error:", err)
+	}
+
+}
@@ -0,0 +1,296 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A StructDescription describes a struct type to
+// register. The StructDescription used to create
+// a Registry without Go types. For example, if
+// the Registry received through RPC or created
+// from JSON. See NewRegistryFromDescriptions
+type StructDescription struct {
+	Name   string             // registered name, e.g. "pkg.User"
+	Fields []FieldDescription // fields of the struct
+}
+
+// A FieldDescription describes a field of a struct.
+// The Schema is string representation of schema of
+// the field, the same as Schema.String() returns.
+// Possible values are
+//
+//     bool, int8, int16, int32, int64,
+//     uint8, uint16, uint32, uint64,
+//     float32, float64, string        - basic types
+//     byte                            - alias for uint8
+//     pkg.Name                        - registered struct
+//     []T                             - slice of T
+//     [N]T                            - array of T
+//     *pkg.Name                       - Ref to pkg.Name
+//     []*pkg.Name                     - Refs of pkg.Name
+//     *(dynamic)                      - Dynamic reference
+//     *(feed)                         - FeedRef
+//     map[]*pkg.Name                  - Map of pkg.Name
+//
+// The Tag is optional. For the Ref, the Refs and the Map
+// the skyobject tag is added if it's missing.
+// Thus, a Registry created from descriptions has
+// the same RegistryRef as the same Registry
+// created from Go types
+type FieldDescription struct {
+	Name   string // name of the field
+	Schema string // schema of the field
+	Tag    string // optional tag
+}
+
+// NewRegistryFromDescriptions creates Registry using
+// given descriptions of struct types. See also
+// StructDescription and FieldDescription
+func NewRegistryFromDescriptions(
+	ds []StructDescription,
+) (
+	r *Registry,
+	err error,
+) {
+
+	var names = make(map[string]struct{}, len(ds))
+
+	for _, d := range ds {
+
+		if d.Name == "" {
+			return nil, fmt.Errorf("%v: empty name", ErrInvalidType)
+		}
+
+		if _, ok := names[d.Name]; ok == true {
+			return nil, fmt.Errorf("%v: name %q registered twice",
+				ErrInvalidType, d.Name)
+		}
+
+		names[d.Name] = struct{}{}
+	}
+
+	r = newRegistry()
+
+	for _, d := range ds {
+
+		var ss = new(structSchema)
+		ss.kind, ss.name = reflect.Struct, []byte(d.Name)
+
+		var fnames = make(map[string]struct{}, len(d.Fields))
+
+		for _, fd := range d.Fields {
+
+			if _, ok := fnames[fd.Name]; ok == true || fd.Name == "" {
+				return nil, fmt.Errorf("%v: invalid or duplicate field "+
+					"name %q of %q", ErrInvalidType, fd.Name, d.Name)
+			}
+
+			fnames[fd.Name] = struct{}{}
+
+			var f *field
+			if f, err = describedField(&fd, names); err != nil {
+				return nil, fmt.Errorf("%v: field %q of %q: %v",
+					ErrInvalidType, fd.Name, d.Name, err)
+			}
+
+			ss.fields = append(ss.fields, f)
+		}
+
+		r.reg[d.Name] = ss
+	}
+
+	r.finialize()
+	return
+}
+
+// create field by description
+func describedField(
+	fd *FieldDescription, //    : the description
+	names map[string]struct{}, // : registered types
+) (
+	f *field, //                : the field
+	err error, //               : error
+) {
+
+	f = new(field)
+	f.name = []byte(fd.Name)
+
+	var (
+		s   = fd.Schema
+		ref string // name of element of Ref, Refs or Map
+	)
+
+	switch {
+
+	case s == "*(dynamic)":
+
+		f.schema = &referenceSchema{
+			schema: schema{kind: reflect.Interface},
+			typ:    ReferenceTypeDynamic,
+		}
+
+	case s == "*(feed)":
+
+		f.schema = feedRefSchema()
+
+	case strings.HasPrefix(s, "map[]*"):
+
+		ref = s[len("map[]*"):]
+		f.schema = &referenceSchema{
+			schema: schema{kind: reflect.Ptr},
+			typ:    ReferenceTypeMap,
+			elem:   &schema{kind: reflect.Struct, name: []byte(ref)},
+		}
+
+	case strings.HasPrefix(s, "[]*") && s != "[]*(feed)":
+
+		ref = s[len("[]*"):]
+		f.schema = &referenceSchema{
+			schema: schema{kind: reflect.Ptr},
+			typ:    ReferenceTypeSlice,
+			elem:   &schema{kind: reflect.Struct, name: []byte(ref)},
+		}
+
+	case strings.HasPrefix(s, "*"):
+
+		ref = s[len("*"):]
+		f.schema = &referenceSchema{
+			schema: schema{kind: reflect.Ptr},
+			typ:    ReferenceTypeSingle,
+			elem:   &schema{kind: reflect.Struct, name: []byte(ref)},
+		}
+
+	default:
+
+		if f.schema, err = describedSchema(s, names); err != nil {
+			return
+		}
+
+	}
+
+	f.tag = []byte(fd.Tag)
+
+	if ref == "" {
+		return
+	}
+
+	if _, ok := names[ref]; ok == false {
+		return nil, fmt.Errorf("missing schema %q", ref)
+	}
+
+	// add skyobject tag if missing
+	if _, err = TagSchemaName(reflect.StructTag(fd.Tag)); err != nil {
+
+		var st = Tag + `:"schema=` + ref + `"`
+
+		if fd.Tag != "" {
+			st += " " + fd.Tag
+		}
+
+		f.tag, err = []byte(st), nil
+	}
+
+	return
+}
+
+// create non-reference schema by string
+func describedSchema(
+	s string, //                : string representation
+	names map[string]struct{}, // : registered types
+) (
+	sch Schema, //              : the schema
+	err error, //               : error
+) {
+
+	if s == "byte" {
+		s = "uint8"
+	}
+
+	switch s {
+	case "bool", "int8", "int16", "int32", "int64",
+		"uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "string":
+
+		return &schema{kind: basicKind(s), name: []byte(s)}, nil
+	}
+
+	if s == "*(feed)" {
+		return feedRefSchema(), nil // FeedRef is allowed in arrays and slices
+	}
+
+	if strings.HasPrefix(s, "*") == true {
+		return nil, fmt.Errorf("reference %q is allowed for fields only", s)
+	}
+
+	if strings.HasPrefix(s, "[") == false {
+
+		if _, ok := names[s]; ok == false {
+			return nil, fmt.Errorf("missing schema %q", s)
+		}
+
+		// will be replaced with registered schema by finialize
+		return &schema{kind: reflect.Struct, name: []byte(s)}, nil
+	}
+
+	var i = strings.IndexByte(s, ']')
+
+	if i < 0 {
+		return nil, fmt.Errorf("invalid schema %q", s)
+	}
+
+	var el Schema
+	if el, err = describedSchema(s[i+1:], names); err != nil {
+		return
+	}
+
+	if i == 1 {
+
+		var ss = new(sliceSchema)
+		ss.kind, ss.elem = reflect.Slice, el
+		return ss, nil
+
+	}
+
+	var length int
+	if length, err = strconv.Atoi(s[1:i]); err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid length of array %q", s)
+	}
+
+	var as = new(arraySchema)
+	as.kind, as.elem, as.length = reflect.Array, el, length
+	return as, nil
+}
+
+// kind by name of a basic type
+func basicKind(s string) reflect.Kind {
+	switch s {
+	case "bool":
+		return reflect.Bool
+	case "int8":
+		return reflect.Int8
+	case "int16":
+		return reflect.Int16
+	case "int32":
+		return reflect.Int32
+	case "int64":
+		return reflect.Int64
+	case "uint8":
+		return reflect.Uint8
+	case "uint16":
+		return reflect.Uint16
+	case "uint32":
+		return reflect.Uint32
+	case "uint64":
+		return reflect.Uint64
+	case "float32":
+		return reflect.Float32
+	case "float64":
+		return reflect.Float64
+	case "string":
+		return reflect.String
+	}
+	return reflect.Invalid
+}
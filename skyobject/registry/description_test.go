@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"testing"
+)
+
+func testDescriptions() []StructDescription {
+	return []StructDescription{
+		{"test.User", []FieldDescription{
+			{"Name", "string", ""},
+			{"Age", "uint32", ""},
+		}},
+		{"test.Group", []FieldDescription{
+			{"Name", "string", ""},
+			{"Members", "[]*test.User", ""},
+			{"Curator", "*test.User", ""},
+			{"Developer", "*(dynamic)", ""},
+		}},
+		{"test.Man", []FieldDescription{
+			{"Name", "string", ""},
+			{"GitHub", "string", ""},
+		}},
+	}
+}
+
+func TestNewRegistryFromDescriptions(t *testing.T) {
+
+	var reg, err = NewRegistryFromDescriptions(testDescriptions())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var goreg = NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Register("test.Group", TestGroup{})
+		r.Register("test.Man", TestMan{})
+	})
+
+	if reg.Reference() != goreg.Reference() {
+		t.Error("different registries")
+	}
+
+	t.Run("arrays and slices", func(t *testing.T) {
+
+		var reg, err = NewRegistryFromDescriptions([]StructDescription{
+			{"test.Arrays", []FieldDescription{
+				{"ZeroInt8", "[0]int8", ""},
+				{"OneInt16", "[1]int16", ""},
+				{"TwoStrings", "[2]string", ""},
+				{"ThreeEmptyStruct", "[3]test.Empty", ""},
+			}},
+			{"test.Slices", []FieldDescription{
+				{"Bytes", "[]byte", ""},
+				{"Users", "[][]test.Empty", ""},
+			}},
+			{"test.Empty", nil},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sch Schema
+		if sch, err = reg.SchemaByName("test.Slices"); err != nil {
+			t.Fatal(err)
+		}
+
+		if s := sch.Fields()[1].Schema().String(); s != "[][]test.Empty" {
+			t.Error("wrong schema:", s)
+		}
+
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+
+		for _, ds := range [][]StructDescription{
+			{{"", nil}},
+			{{"test.A", nil}, {"test.A", nil}},
+			{{"test.A", []FieldDescription{{"X", "test.B", ""}}}},
+			{{"test.A", []FieldDescription{{"X", "*test.B", ""}}}},
+			{{"test.A", []FieldDescription{{"X", "[]*int8", ""}}}},
+			{{"test.A", []FieldDescription{{"X", "[x]int8", ""}}}},
+			{{"test.A", []FieldDescription{{"X", "[]*test.A", ""},
+				{"X", "int8", ""}}}},
+		} {
+			if _, err := NewRegistryFromDescriptions(ds); err == nil {
+				t.Error("missing error:", ds)
+			}
+		}
+
+	})
+
+}
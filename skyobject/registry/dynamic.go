@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/skycoin/skycoin/src/cipher"
-	"github.com/skycoin/skycoin/src/cipher/encoder"
 )
 
 // A Dynamic represents reference to object
@@ -79,7 +78,7 @@ func (d *Dynamic) SetValue(
 	}
 
 	var hash cipher.SHA256
-	if hash, err = pack.Add(encoder.Serialize(obj)); err != nil {
+	if hash, err = addValue(pack, obj); err != nil {
 		return
 	}
 
@@ -29,6 +29,29 @@ var (
 	ErrRefsIterating      = errors.New("Refs is iterating")
 	ErrInvalidDegree      = errors.New("invalid degree")
 
+	ErrInvalidEncodedMap = errors.New("invalid encoded Map")
+	ErrInvalidMap        = errors.New("invalid Map")
+	ErrMapIterating      = errors.New("Map is iterating")
+	ErrMapIsNotBlank     = errors.New("Map is not blank")
+
+	ErrInvalidFieldIndex = errors.New("invalid field index")
+	ErrNoSuchFieldIndex  = errors.New("no such field index")
+	ErrInvalidIndexValue = errors.New("invalid type of value of field index")
+
+	ErrInvalidFeedRef = errors.New("invalid FeedRef")
+
+	ErrInvalidQueryCursor = errors.New("invalid QueryCursor")
+
+	ErrInvalidEncodedRoot = errors.New("invalid encoded Root")
+	ErrInvalidGrant       = errors.New("invalid Grant")
+	ErrInvalidDelegation  = errors.New("invalid Delegation")
+	ErrDelegationExpired  = errors.New("Delegation is expired or not valid yet")
+	ErrInvalidRevocation  = errors.New("invalid Revocation")
+
+	ErrInvalidMultisig     = errors.New("invalid Multisig")
+	ErrNotMultisigKey      = errors.New("not a key of Multisig")
+	ErrNotEnoughSignatures = errors.New("not enough signatures")
+
 	ErrNotFound        = errors.New("not found")
 	ErrStopIteration   = errors.New("stop iteration")
 	ErrMissingRegistry = errors.New("missing registry")
@@ -0,0 +1,193 @@
+package registry
+
+import (
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A FeedRefFlags represents flags of a FeedRef,
+// that describes which fields of the FeedRef are set
+type FeedRefFlags uint8
+
+// flags of FeedRef
+const (
+	FeedRefHead FeedRefFlags = 1 << iota // the Nonce is set
+	FeedRefSeq                           // the Seq is set
+)
+
+// A FeedRef represents reference to a Root of another
+// feed. Unlike the Ref, Refs, Dynamic and the Map, the
+// FeedRef doesn't point to an object by hash. It points
+// to latest Root of a feed, or to latest Root of a head
+// of the feed (if the FeedRefHead flag is set), or to
+// Root with given seq of given head (if both FeedRefHead
+// and FeedRefSeq flags are set).
+//
+// The FeedRef is never followed by Walk, Split and
+// other methods that work with objects of a Root,
+// because the referenced Root belongs to another feed
+// and can be missing in DB. A Splitter that implements
+// the FeedRefSplitter interface receives every FeedRef
+// it meets. Thus, the node package can subscribe to
+// the feeds or prefetch them.
+//
+// The FeedRef has no schema of the Root it points to.
+// Use it as field of a struct, or as element of
+// array or slice
+//
+//     type Post struct {
+//         Text  string
+//         About registry.FeedRef
+//     }
+type FeedRef struct {
+	Pub   cipher.PubKey // feed
+	Nonce uint64        // head (if FeedRefHead flag is set)
+	Seq   uint64        // seq (if FeedRefSeq flag is set)
+	Flags FeedRefFlags  // which fields are set
+}
+
+// IsBlank returns true if the FeedRef is blank
+func (f *FeedRef) IsBlank() bool {
+	return *f == FeedRef{}
+}
+
+// IsValid returns true if the FeedRef is blank or
+// points to a feed. A FeedRef is invalid if it has
+// unknown flags, the FeedRefSeq flag without the
+// FeedRefHead flag, or Nonce and Seq without
+// appropriate flags
+func (f *FeedRef) IsValid() bool {
+
+	if f.IsBlank() == true {
+		return true
+	}
+
+	if f.Pub == (cipher.PubKey{}) {
+		return false
+	}
+
+	if f.Flags&^(FeedRefHead|FeedRefSeq) != 0 {
+		return false // unknown flags
+	}
+
+	if f.HasSeq() == true && f.HasHead() == false {
+		return false // seq of what head?
+	}
+
+	if f.HasHead() == false && f.Nonce != 0 {
+		return false
+	}
+
+	if f.HasSeq() == false && f.Seq != 0 {
+		return false
+	}
+
+	return true
+}
+
+// HasHead returns true if the FeedRef points
+// to particular head of the feed
+func (f *FeedRef) HasHead() bool {
+	return f.Flags&FeedRefHead != 0
+}
+
+// HasSeq returns true if the FeedRef points
+// to particular Root of the head
+func (f *FeedRef) HasSeq() bool {
+	return f.Flags&FeedRefSeq != 0
+}
+
+// SetFeed sets feed of the FeedRef clearing
+// head and seq
+func (f *FeedRef) SetFeed(pk cipher.PubKey) {
+	*f = FeedRef{Pub: pk}
+}
+
+// SetHead sets head of the FeedRef
+// clearing seq
+func (f *FeedRef) SetHead(nonce uint64) {
+	f.Nonce, f.Seq = nonce, 0
+	f.Flags = FeedRefHead
+}
+
+// SetSeq sets seq of the FeedRef. The FeedRef
+// must have head. The SetSeq panics otherwise
+func (f *FeedRef) SetSeq(seq uint64) {
+	if f.HasHead() == false {
+		panic("SetSeq of FeedRef without head")
+	}
+	f.Seq = seq
+	f.Flags |= FeedRefSeq
+}
+
+// Clear makes the FeedRef blank
+func (f *FeedRef) Clear() {
+	*f = FeedRef{}
+}
+
+// Short string
+func (f *FeedRef) Short() string {
+	return f.string(f.Pub.Hex()[:7])
+}
+
+// String implements fmt.Stringer interface
+func (f *FeedRef) String() string {
+	return f.string(f.Pub.Hex())
+}
+
+func (f *FeedRef) string(pk string) (s string) {
+
+	s = "{" + pk
+
+	if f.HasHead() == true {
+		s += "/" + strconv.FormatUint(f.Nonce, 10)
+	} else {
+		s += "/*"
+	}
+
+	if f.HasSeq() == true {
+		s += "/" + strconv.FormatUint(f.Seq, 10)
+	} else {
+		s += "/*"
+	}
+
+	return s + "}"
+}
+
+// A FeedRefSplitter is optional interface of a
+// Splitter. If a Splitter implements the interface,
+// then the Split calls the FeedRef method for every
+// non-blank FeedRef of a Root. The FeedRef never
+// follows the reference. The fr argument must not be
+// modified and must not be kept after the call (use
+// a copy instead)
+type FeedRefSplitter interface {
+	Splitter
+
+	// FeedRef called for every non-blank
+	// FeedRef of a Root that splitted
+	FeedRef(fr *FeedRef)
+}
+
+// Split used by the node package to fill a Root.
+// The Split never follows the FeedRef. It calls
+// the FeedRef method of given Splitter if the
+// Splitter implements the FeedRefSplitter
+// interface and the FeedRef is not blank
+func (f *FeedRef) Split(s Splitter) {
+
+	if f.IsValid() == false {
+		s.Fail(ErrInvalidFeedRef)
+		return
+	}
+
+	if f.IsBlank() == true {
+		return // nothing to split
+	}
+
+	if fs, ok := s.(FeedRefSplitter); ok == true {
+		fs.FeedRef(f)
+	}
+
+}
@@ -0,0 +1,359 @@
+package registry
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+type TestFeedPost struct {
+	Text  string
+	About FeedRef
+	Also  []FeedRef
+}
+
+func testFeedRefRegistry() *Registry {
+	return NewRegistry(func(r *Reg) {
+		r.Register("test.FeedPost", TestFeedPost{})
+	})
+}
+
+func testFeedRef(seed string, head bool, seq bool) (fr FeedRef) {
+	var pk, _ = cipher.GenerateDeterministicKeyPair([]byte(seed))
+	fr.SetFeed(pk)
+	if head == true {
+		fr.SetHead(1)
+	}
+	if seq == true {
+		fr.SetSeq(2)
+	}
+	return
+}
+
+// a Splitter that collects FeedRefs
+type feedRefSplitter struct {
+	pack *dummyPack
+
+	mx   sync.Mutex
+	frs  []FeedRef
+	gets int
+	err  error
+}
+
+func (f *feedRefSplitter) Registry() *Registry {
+	return f.pack.Registry()
+}
+
+func (f *feedRefSplitter) Pre(cipher.SHA256) (_ int, _ error) {
+	return
+}
+
+func (f *feedRefSplitter) Get(key cipher.SHA256) (val []byte, rc int,
+	err error) {
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.gets++
+	val, err = f.pack.Get(key)
+	return
+}
+
+func (f *feedRefSplitter) Fail(err error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+func (f *feedRefSplitter) Go(fn func()) {
+	fn()
+}
+
+func (f *feedRefSplitter) FeedRef(fr *FeedRef) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.frs = append(f.frs, *fr)
+}
+
+func TestFeedRef_IsValid(t *testing.T) {
+	// IsValid() bool
+
+	for i, fr := range []FeedRef{
+		{},
+		testFeedRef("x", false, false),
+		testFeedRef("x", true, false),
+		testFeedRef("x", true, true),
+	} {
+		if fr.IsValid() == false {
+			t.Errorf("%d: invalid %s", i, fr.String())
+		}
+	}
+
+	var fr = testFeedRef("x", true, true)
+
+	for i, invalid := range []FeedRef{
+		{Nonce: 1, Flags: FeedRefHead},
+		{Pub: fr.Pub, Seq: 1, Flags: FeedRefSeq},
+		{Pub: fr.Pub, Nonce: 1},
+		{Pub: fr.Pub, Nonce: 1, Seq: 1, Flags: FeedRefHead},
+		{Pub: fr.Pub, Flags: 1 << 7},
+	} {
+		if invalid.IsValid() == true {
+			t.Errorf("%d: valid %s", i, invalid.String())
+		}
+	}
+
+}
+
+func TestFeedRef_SetSeq(t *testing.T) {
+	// SetSeq(seq uint64)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("missing panic")
+		}
+	}()
+
+	var fr = testFeedRef("x", false, false)
+	fr.SetSeq(1)
+}
+
+func TestFeedRef_String(t *testing.T) {
+	// String() string
+
+	var fr = testFeedRef("x", true, false)
+
+	if want := "{" + fr.Pub.Hex() + "/1/*}"; fr.String() != want {
+		t.Errorf("wrong String %q, want %q", fr.String(), want)
+	}
+
+	fr.SetSeq(2)
+
+	if want := "{" + fr.Pub.Hex()[:7] + "/1/2}"; fr.Short() != want {
+		t.Errorf("wrong Short %q, want %q", fr.Short(), want)
+	}
+
+}
+
+func TestRegistry_feedRef(t *testing.T) {
+
+	var reg = testFeedRefRegistry()
+
+	var sch, err = reg.SchemaByName("test.FeedPost")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fs := sch.Fields(); len(fs) != 3 {
+		t.Fatal("wrong number of fields", len(fs))
+	} else if s := fs[1].Schema(); s.ReferenceType() != ReferenceTypeFeed {
+		t.Error("wrong reference type", s.ReferenceType())
+	} else if s.String() != "*(feed)" {
+		t.Error("wrong String", s.String())
+	} else if s.Elem() != nil {
+		t.Error("unexpected Elem")
+	} else if s = fs[2].Schema().Elem(); s.ReferenceType() !=
+		ReferenceTypeFeed {
+
+		t.Error("wrong reference type of element", s.ReferenceType())
+	}
+
+	var dr *Registry
+	if dr, err = DecodeRegistry(reg.Encode()); err != nil {
+		t.Fatal(err)
+	} else if dr.Reference() != reg.Reference() {
+		t.Error("different decoded reference")
+	}
+
+	// described
+
+	var ds *Registry
+	ds, err = NewRegistryFromDescriptions([]StructDescription{
+		{Name: "test.FeedPost", Fields: []FieldDescription{
+			{Name: "Text", Schema: "string"},
+			{Name: "About", Schema: "*(feed)"},
+			{Name: "Also", Schema: "[]*(feed)"},
+		}},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	} else if ds.Reference() != reg.Reference() {
+		t.Error("different described reference")
+	}
+
+	t.Run("register", func(t *testing.T) {
+
+		defer func() {
+			if recover() == nil {
+				t.Error("missing panic")
+			}
+		}()
+
+		NewRegistry(func(r *Reg) {
+			r.Register("FeedRef", FeedRef{})
+		})
+
+	})
+
+}
+
+func testFeedRefPost(t *testing.T) (pack *dummyPack, dr Dynamic,
+	post *TestFeedPost) {
+
+	pack = testPackReg(testFeedRefRegistry())
+
+	post = &TestFeedPost{
+		Text:  "hello",
+		About: testFeedRef("about", true, true),
+		Also: []FeedRef{
+			testFeedRef("also", false, false),
+			{}, // blank
+			testFeedRef("also", true, false),
+		},
+	}
+
+	dr = dynamicByValue(pack, post)
+	return
+}
+
+func TestFeedRef_Split(t *testing.T) {
+	// Split(s Splitter)
+
+	var (
+		pack, dr, post = testFeedRefPost(t)
+		fs             = &feedRefSplitter{pack: pack}
+	)
+
+	dr.Split(fs)
+
+	if fs.err != nil {
+		t.Fatal(fs.err)
+	}
+
+	if fs.gets != 1 {
+		t.Error("FeedRef followed:", fs.gets)
+	}
+
+	var want = []FeedRef{post.About, post.Also[0], post.Also[2]}
+
+	if len(fs.frs) != len(want) {
+		t.Fatalf("wrong number of FeedRefs %d, want %d", len(fs.frs),
+			len(want))
+	}
+
+	for i, fr := range fs.frs {
+		if fr != want[i] {
+			t.Errorf("wrong FeedRef %s, want %s", fr.Short(), want[i].Short())
+		}
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+
+		var (
+			fs = &feedRefSplitter{pack: pack}
+			fr = FeedRef{Nonce: 1}
+		)
+
+		fr.Split(fs)
+
+		if fs.err != ErrInvalidFeedRef {
+			t.Error("wrong error:", fs.err)
+		}
+
+	})
+
+}
+
+func TestFeedRef_walk(t *testing.T) {
+
+	var (
+		pack, dr, _ = testFeedRefPost(t)
+		r           = &Root{Refs: []Dynamic{dr}}
+		n           int
+	)
+
+	var err = r.Walk(pack, func(cipher.SHA256, int) (bool, error) {
+		n++
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 1 {
+		t.Error("wrong number of objects walked:", n)
+	}
+
+	var items []*Item
+	if items, err = r.Items(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	var it *Item
+	if it, err = ObjectItem(pack, items[0].Elem, items[0].Hash); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(it.Items) != 3 {
+		t.Fatal("wrong number of fields:", len(it.Items))
+	}
+
+	if about := it.Items[1]; about.Ref != ReferenceTypeFeed {
+		t.Error("wrong reference type", about.Ref)
+	} else if about.IsBlank() == true {
+		t.Error("blank FeedRef item")
+	}
+
+	if blank := it.Items[2].Items[1]; blank.IsBlank() == false {
+		t.Error("not blank FeedRef item")
+	}
+
+}
+
+func TestFeedRefJSON(t *testing.T) {
+	// FeedRefJSON(val interface{}) (fr FeedRef, err error)
+
+	var (
+		want = testFeedRef("x", true, true)
+		fr   FeedRef
+		err  error
+	)
+
+	fr, err = FeedRefJSON(map[string]interface{}{
+		"Pub":   want.Pub.Hex(),
+		"Nonce": float64(1),
+		"Seq":   float64(2),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	} else if fr != want {
+		t.Errorf("wrong FeedRef %s, want %s", fr.Short(), want.Short())
+	}
+
+	if fr, err = FeedRefJSON(nil); err != nil {
+		t.Error(err)
+	} else if fr.IsBlank() == false {
+		t.Error("not blank")
+	}
+
+	for _, invalid := range []interface{}{
+		"x",
+		map[string]interface{}{"Nonce": float64(1)},
+		map[string]interface{}{"Pub": want.Pub.Hex(), "Seq": float64(1)},
+		map[string]interface{}{"Pub": want.Pub.Hex(), "Hash": "x"},
+	} {
+		if _, err = FeedRefJSON(invalid); err == nil {
+			t.Errorf("missing error: %v", invalid)
+		}
+	}
+
+}
@@ -0,0 +1,230 @@
+package registry
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// isIndexableSchema returns true if field of given
+// schema can be used as key of a field index
+func isIndexableSchema(sch Schema) bool {
+
+	if sch.IsReference() == true {
+		return false
+	}
+
+	switch sch.Kind() {
+	case reflect.Bool,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.String:
+		return true
+	case reflect.Slice, reflect.Array:
+		var el = sch.Elem()
+		return el != nil && el.Kind() == reflect.Uint8 // []byte, [N]byte
+	}
+
+	return false
+}
+
+// schemaField returns field of given struct schema by name
+func schemaField(sch Schema, name string) (fl Field, err error) {
+
+	if sch.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema %s is not a struct", sch)
+	}
+
+	for _, fl = range sch.Fields() {
+		if fl.Name() == name {
+			return
+		}
+	}
+
+	return nil, fmt.Errorf("schema %s doesn't have field %q", sch, name)
+}
+
+// fieldOfEncoded returns encoded field
+// with given name of given object
+func fieldOfEncoded(
+	sch Schema, // : schema of the object
+	val []byte, // : encoded object
+	name string, // : name of the field
+) (
+	fl Field, //    : the field
+	p []byte, //    : the encoded field
+	err error, //   : error if any
+) {
+
+	if sch.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("schema %s is not a struct", sch)
+	}
+
+	var n, m int
+
+	for _, fl = range sch.Fields() {
+
+		if m, err = fl.Schema().Size(val[n:]); err != nil {
+			return
+		}
+
+		if fl.Name() == name {
+			return fl, val[n : n+m], nil
+		}
+
+		n += m
+	}
+
+	return nil, nil, fmt.Errorf("schema %s doesn't have field %q", sch, name)
+}
+
+// fieldKeyEncoded converts encoded field to key of field
+// index; the key keeps order of values of the field
+func fieldKeyEncoded(sch Schema, p []byte) (key []byte, err error) {
+
+	switch sch.Kind() {
+
+	case reflect.Bool:
+		if len(p) != 1 {
+			return nil, ErrInvalidSchemaOrData
+		}
+		return []byte{p[0]}, nil
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var x int64
+		switch len(p) {
+		case 1:
+			x = int64(int8(p[0]))
+		case 2:
+			x = int64(int16(binary.LittleEndian.Uint16(p)))
+		case 4:
+			x = int64(int32(binary.LittleEndian.Uint32(p)))
+		case 8:
+			x = int64(binary.LittleEndian.Uint64(p))
+		default:
+			return nil, ErrInvalidSchemaOrData
+		}
+		return fieldKeyUint(uint64(x) ^ (1 << 63)), nil
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var x uint64
+		switch len(p) {
+		case 1:
+			x = uint64(p[0])
+		case 2:
+			x = uint64(binary.LittleEndian.Uint16(p))
+		case 4:
+			x = uint64(binary.LittleEndian.Uint32(p))
+		case 8:
+			x = binary.LittleEndian.Uint64(p)
+		default:
+			return nil, ErrInvalidSchemaOrData
+		}
+		return fieldKeyUint(x), nil
+
+	case reflect.String, reflect.Slice:
+		if len(p) < 4 {
+			return nil, ErrInvalidSchemaOrData
+		}
+		return fieldKeyBytes(p[4:]), nil
+
+	case reflect.Array:
+		return append([]byte{}, p...), nil
+
+	}
+
+	return nil, fmt.Errorf("schema %s can't be used as index key", sch)
+}
+
+// fieldKeyValue converts given Go value to
+// key of field index of given schema; any
+// integer can be used for integer fields
+// if it fits the sign of the field
+func fieldKeyValue(sch Schema, val interface{}) (key []byte, err error) {
+
+	var v = reflect.Indirect(reflect.ValueOf(val))
+
+	if v.IsValid() == false {
+		return nil, ErrInvalidIndexValue
+	}
+
+	switch sch.Kind() {
+
+	case reflect.Bool:
+		if v.Kind() != reflect.Bool {
+			return nil, ErrInvalidIndexValue
+		}
+		if v.Bool() == true {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			return fieldKeyUint(uint64(v.Int()) ^ (1 << 63)), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64:
+			if v.Uint() <= math.MaxInt64 {
+				return fieldKeyUint(v.Uint() ^ (1 << 63)), nil
+			}
+		}
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64:
+			return fieldKeyUint(v.Uint()), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			if v.Int() >= 0 {
+				return fieldKeyUint(uint64(v.Int())), nil
+			}
+		}
+
+	case reflect.String, reflect.Slice:
+		switch {
+		case v.Kind() == reflect.String:
+			return fieldKeyBytes([]byte(v.String())), nil
+		case v.Kind() == reflect.Slice &&
+			v.Type().Elem().Kind() == reflect.Uint8:
+			return fieldKeyBytes(v.Bytes()), nil
+		}
+
+	case reflect.Array:
+		if v.Kind() == reflect.Array && v.Len() == sch.Len() &&
+			v.Type().Elem().Kind() == reflect.Uint8 {
+
+			key = make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(key), v)
+			return
+		}
+
+	}
+
+	return nil, ErrInvalidIndexValue
+}
+
+func fieldKeyUint(x uint64) (key []byte) {
+	key = make([]byte, 8)
+	binary.BigEndian.PutUint64(key, x)
+	return
+}
+
+// fieldKeyBytes escapes zeroes (0x00 -> 0x00 0xff) and
+// appends terminator (0x00 0x01) to keep order of
+// values of different length
+func fieldKeyBytes(p []byte) (key []byte) {
+
+	key = make([]byte, 0, len(p)+2)
+
+	for _, b := range p {
+		if key = append(key, b); b == 0x00 {
+			key = append(key, 0xff)
+		}
+	}
+
+	return append(key, 0x00, 0x01)
+}
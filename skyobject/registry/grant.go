@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A Grant represents record, that authorizes a writer
+// of multi-writer feed. The writer publishes Root
+// objects using its own heads of the feed (see Author
+// and Parents fields of the Root). The Grant is
+// signed by owner of the feed.
+//
+// Root objects carry grants (see Grants field of the
+// Root). The Grants are applied by receiver, even if
+// the Root is rejected
+type Grant struct {
+	Feed   cipher.PubKey // feed (owner)
+	Writer cipher.PubKey // authorized writer
+
+	Sig cipher.Sig // signature of the owner
+}
+
+// NewGrant creates Grant of given writer
+// signed by given secret key of a feed
+func NewGrant(
+	sk cipher.SecKey, //     : secret key of the feed
+	writer cipher.PubKey, // : the writer to authorize
+) (
+	g Grant, //              : the Grant
+) {
+
+	g.Feed = cipher.PubKeyFromSecKey(sk)
+	g.Writer = writer
+	g.Sig = cipher.SignHash(g.Hash(), sk)
+
+	return
+}
+
+// Hash of the Grant that signed (e.g.
+// hash of the Grant without signature)
+func (g *Grant) Hash() cipher.SHA256 {
+	var x = *g
+	x.Sig = cipher.Sig{}
+	return cipher.SumSHA256(encoder.Serialize(x))
+}
+
+// Verify signature of the Grant. It returns
+// the ErrInvalidGrant or signature
+// verification error
+func (g *Grant) Verify() (err error) {
+
+	if g.Feed == (cipher.PubKey{}) || g.Writer == (cipher.PubKey{}) ||
+		g.Feed == g.Writer {
+
+		return ErrInvalidGrant
+	}
+
+	return cipher.VerifySignature(g.Feed, g.Sig, g.Hash())
+}
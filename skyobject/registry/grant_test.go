@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestNewGrant(t *testing.T) {
+	// NewGrant(sk, writer) (g Grant)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		wp, _  = cipher.GenerateKeyPair()
+		xp, _  = cipher.GenerateKeyPair()
+		g      = NewGrant(sk, wp)
+	)
+
+	if err := g.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	if g.Feed != pk || g.Writer != wp {
+		t.Error("wrong Grant")
+	}
+
+	var fg = g
+	fg.Writer = xp
+
+	if err := fg.Verify(); err == nil {
+		t.Error("missing error")
+	}
+
+	// encode / decode
+
+	var r = &Root{Pub: pk, Grants: []Grant{g}}
+
+	var dr, err = DecodeRoot(r.Encode())
+
+	if err != nil {
+		t.Fatal(err)
+	} else if len(dr.Grants) != 1 || dr.Grants[0] != g {
+		t.Error("wrong decoded Grants")
+	}
+
+}
@@ -0,0 +1,563 @@
+package registry
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// EncodeJSON encodes given value using given Schema. The
+// value is a value decoded by encoding/json package (it's
+// recommended to use json.Decoder with UseNumber). The
+// EncodeJSON is generic encoder that doesn't need Go types.
+// Representation of values is
+//
+//     bool                 - JSON boolean
+//     intX, uintX, floatX  - JSON number
+//     string               - JSON string
+//     []byte, [N]byte      - hex-encoded JSON string
+//     slice, array         - JSON array
+//     struct               - JSON object (field name -> value),
+//                            missing fields are zero
+//     Ref                  - hex-encoded hash of existing object,
+//                            or JSON object to create
+//     Refs                 - hex-encoded hash of existing Refs,
+//                            or JSON array of elements, where
+//                            every element is hash or object (the
+//                            same as Ref)
+//     Map                  - hex-encoded hash of existing Map,
+//                            or JSON object (key -> element), where
+//                            every element is the same as Ref
+//     Dynamic              - {"Schema": "pkg.Name", "Hash": hash}
+//                            or {"Schema": "pkg.Name", "Object": {}}
+//     FeedRef              - {"Pub": pk, "Nonce": n, "Seq": n}, where
+//                            Nonce and Seq are optional, the Seq
+//                            requires the Nonce
+//
+// JSON null is zero value for all types, and blank
+// reference for references. Objects to create are
+// saved using given Pack. The Pack must have Registry
+// to create Dynamic references
+func EncodeJSON(
+	pack Pack, //          : pack to save referenced objects
+	sch Schema, //         : schema of the value
+	val interface{}, //    : the value
+) (
+	b []byte, //           : encoded value
+	err error, //          : error
+) {
+	return appendJSON(nil, pack, sch, val)
+}
+
+// DynamicJSON creates Dynamic reference from given
+// value. See EncodeJSON for details
+func DynamicJSON(pack Pack, val interface{}) (dr Dynamic, err error) {
+
+	if val == nil {
+		return // blank
+	}
+
+	var m, ok = val.(map[string]interface{})
+
+	if ok == false {
+		return dr, fmt.Errorf("invalid Dynamic %T, expected object", val)
+	}
+
+	for k := range m {
+		if k != "Schema" && k != "Hash" && k != "Object" {
+			return dr, fmt.Errorf("unexpected field %q of Dynamic", k)
+		}
+	}
+
+	var name string
+	if name, ok = m["Schema"].(string); ok == false {
+		return dr, fmt.Errorf("missing or invalid Schema of Dynamic")
+	}
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		return dr, ErrMissingRegistry
+	}
+
+	var sch Schema
+	if sch, err = reg.SchemaByName(name); err != nil {
+		return
+	}
+
+	if dr.Hash, err = refJSON(pack, sch, m["Object"], m["Hash"]); err != nil {
+		return
+	}
+
+	dr.Schema = sch.Reference()
+	return
+}
+
+// FeedRefJSON creates FeedRef from given
+// value. See EncodeJSON for details
+func FeedRefJSON(val interface{}) (fr FeedRef, err error) {
+
+	if val == nil {
+		return // blank
+	}
+
+	var m, ok = val.(map[string]interface{})
+
+	if ok == false {
+		return fr, fmt.Errorf("invalid FeedRef %T, expected object", val)
+	}
+
+	for k := range m {
+		if k != "Pub" && k != "Nonce" && k != "Seq" {
+			return fr, fmt.Errorf("unexpected field %q of FeedRef", k)
+		}
+	}
+
+	var pk string
+	if pk, ok = m["Pub"].(string); ok == false {
+		return fr, fmt.Errorf("missing or invalid Pub of FeedRef")
+	}
+
+	if fr.Pub, err = cipher.PubKeyFromHex(pk); err != nil {
+		return
+	}
+
+	var s string
+
+	if nonce, ok := m["Nonce"]; ok == true {
+		if s, err = numberJSON(nonce); err != nil {
+			return
+		}
+		if fr.Nonce, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return
+		}
+		fr.Flags |= FeedRefHead
+	}
+
+	if seq, ok := m["Seq"]; ok == true {
+		if s, err = numberJSON(seq); err != nil {
+			return
+		}
+		if fr.Seq, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return
+		}
+		fr.Flags |= FeedRefSeq
+	}
+
+	if fr.IsValid() == false {
+		err = ErrInvalidFeedRef
+	}
+
+	return
+}
+
+// hash of existing object or of created object
+func refJSON(
+	pack Pack, //           : pack to save
+	sch Schema, //          : schema of the object
+	obj interface{}, //     : object to create or nil
+	hash interface{}, //    : hex-encoded hash or nil
+) (
+	key cipher.SHA256, //   : result
+	err error, //           : error
+) {
+
+	if obj != nil && hash != nil {
+		return key, fmt.Errorf("both object and hash provided")
+	}
+
+	if hash != nil {
+		var s, ok = hash.(string)
+		if ok == false {
+			return key, fmt.Errorf("invalid hash %T", hash)
+		}
+		return cipher.SHA256FromHex(s)
+	}
+
+	if obj == nil {
+		return // blank
+	}
+
+	var val []byte
+	if val, err = EncodeJSON(pack, sch, obj); err != nil {
+		return
+	}
+
+	return pack.Add(val)
+}
+
+// hash or object
+func refElemJSON(
+	pack Pack, //         : pack to save
+	sch Schema, //        : schema of the object
+	val interface{}, //   : value
+) (
+	key cipher.SHA256, // : result
+	err error, //         : error
+) {
+
+	if s, ok := val.(string); ok == true {
+		return refJSON(pack, sch, nil, s)
+	}
+
+	return refJSON(pack, sch, val, nil)
+}
+
+func appendJSON(
+	b []byte, //        : buffer
+	pack Pack, //       : pack to save referenced objects
+	sch Schema, //      : schema of the value
+	val interface{}, // : the value
+) (
+	_ []byte, //        : encoded value
+	err error, //       : error
+) {
+
+	if sch.IsReference() == true {
+		return appendReferenceJSON(b, pack, sch, val)
+	}
+
+	switch sch.Kind() {
+	case reflect.Slice, reflect.Array:
+		return appendSliceJSON(b, pack, sch, val)
+	case reflect.Struct:
+		return appendStructJSON(b, pack, sch, val)
+	}
+
+	var x interface{}
+	if x, err = basicJSON(sch, val); err != nil {
+		return
+	}
+
+	return append(b, encoder.Serialize(x)...), nil
+}
+
+func appendReferenceJSON(
+	b []byte, //        : buffer
+	pack Pack, //       : pack to save referenced objects
+	sch Schema, //      : schema of the value
+	val interface{}, // : the value
+) (
+	_ []byte, //        : encoded value
+	err error, //       : error
+) {
+
+	switch sch.ReferenceType() {
+
+	case ReferenceTypeSingle:
+
+		var ref Ref
+		if ref.Hash, err = refElemJSON(pack, sch.Elem(), val); err != nil {
+			return
+		}
+
+		return append(b, encoder.Serialize(ref)...), nil
+
+	case ReferenceTypeSlice:
+
+		var refs Refs
+
+		switch x := val.(type) {
+		case nil:
+		case string:
+			if refs.Hash, err = cipher.SHA256FromHex(x); err != nil {
+				return
+			}
+		case []interface{}:
+			var hashes = make([]cipher.SHA256, 0, len(x))
+			for i, el := range x {
+				var key cipher.SHA256
+				if key, err = refElemJSON(pack, sch.Elem(), el); err != nil {
+					return nil, fmt.Errorf("element %d: %v", i, err)
+				}
+				hashes = append(hashes, key)
+			}
+			if err = refs.AppendHashes(pack, hashes...); err != nil {
+				return
+			}
+		default:
+			return nil, fmt.Errorf("invalid Refs %T", val)
+		}
+
+		return append(b, encoder.Serialize(refs)...), nil
+
+	case ReferenceTypeMap:
+
+		var m Map
+
+		switch x := val.(type) {
+		case nil:
+		case string:
+			if m.Hash, err = cipher.SHA256FromHex(x); err != nil {
+				return
+			}
+		case map[string]interface{}:
+			var keys = make([]string, 0, len(x))
+			for k := range x {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys) // the same Map every time
+			for _, k := range keys {
+				var key cipher.SHA256
+				if key, err = refElemJSON(pack, sch.Elem(), x[k]); err != nil {
+					return nil, fmt.Errorf("element %q: %v", k, err)
+				}
+				if err = m.Put(pack, []byte(k), key); err != nil {
+					return
+				}
+			}
+			if err = m.Rebuild(pack); err != nil {
+				return
+			}
+		default:
+			return nil, fmt.Errorf("invalid Map %T", val)
+		}
+
+		return append(b, encoder.Serialize(m)...), nil
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if dr, err = DynamicJSON(pack, val); err != nil {
+			return
+		}
+
+		return append(b, encoder.Serialize(dr)...), nil
+
+	case ReferenceTypeFeed:
+
+		var fr FeedRef
+		if fr, err = FeedRefJSON(val); err != nil {
+			return
+		}
+
+		return append(b, encoder.Serialize(fr)...), nil
+
+	}
+
+	return nil, ErrInvalidSchema
+}
+
+func appendSliceJSON(
+	b []byte, //        : buffer
+	pack Pack, //       : pack to save referenced objects
+	sch Schema, //      : schema of the value
+	val interface{}, // : the value
+) (
+	_ []byte, //        : encoded value
+	err error, //       : error
+) {
+
+	var el = sch.Elem()
+
+	if el == nil {
+		return nil, ErrInvalidSchema
+	}
+
+	var elems []interface{}
+
+	switch x := val.(type) {
+	case nil:
+	case []interface{}:
+		elems = x
+	case string:
+
+		if el.Kind() != reflect.Uint8 {
+			return nil, fmt.Errorf("invalid %s: %T", sch.String(), val)
+		}
+
+		var p []byte
+		if p, err = hex.DecodeString(x); err != nil {
+			return
+		}
+
+		elems = make([]interface{}, 0, len(p))
+		for _, c := range p {
+			elems = append(elems, json.Number(strconv.Itoa(int(c))))
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid %s: %T", sch.String(), val)
+	}
+
+	var ln = len(elems)
+
+	if sch.Kind() == reflect.Array {
+
+		if val != nil && ln != sch.Len() {
+			return nil, fmt.Errorf("invalid length of %s: %d",
+				sch.String(), ln)
+		}
+
+		ln = sch.Len()
+
+	} else {
+
+		b = append(b, encoder.Serialize(uint32(ln))...)
+
+	}
+
+	for i := 0; i < ln; i++ {
+
+		var x interface{}
+
+		if i < len(elems) {
+			x = elems[i]
+		}
+
+		if b, err = appendJSON(b, pack, el, x); err != nil {
+			return nil, fmt.Errorf("element %d: %v", i, err)
+		}
+
+	}
+
+	return b, nil
+}
+
+func appendStructJSON(
+	b []byte, //        : buffer
+	pack Pack, //       : pack to save referenced objects
+	sch Schema, //      : schema of the value
+	val interface{}, // : the value
+) (
+	_ []byte, //        : encoded value
+	err error, //       : error
+) {
+
+	var m, ok = val.(map[string]interface{})
+
+	if ok == false && val != nil {
+		return nil, fmt.Errorf("invalid %s: %T", sch.String(), val)
+	}
+
+	var fields = sch.Fields()
+
+	for k := range m {
+		if hasField(fields, k) == false {
+			return nil, fmt.Errorf("%v: %q of %s", ErrNoSuchField, k,
+				sch.String())
+		}
+	}
+
+	for _, f := range fields {
+		if b, err = appendJSON(b, pack, f.Schema(), m[f.Name()]); err != nil {
+			return nil, fmt.Errorf("field %q of %s: %v", f.Name(),
+				sch.String(), err)
+		}
+	}
+
+	return b, nil
+}
+
+func hasField(fields []Field, name string) bool {
+	for _, f := range fields {
+		if f.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// string representation of a JSON number
+func numberJSON(val interface{}) (s string, err error) {
+
+	switch x := val.(type) {
+	case nil:
+		return "0", nil
+	case json.Number:
+		return x.String(), nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	}
+
+	return "", fmt.Errorf("invalid number %T", val)
+}
+
+// decoded value to bool, intX, uintX, floatX or string
+func basicJSON(sch Schema, val interface{}) (x interface{}, err error) {
+
+	var kind = sch.Kind()
+
+	switch kind {
+
+	case reflect.Bool:
+
+		if val == nil {
+			return false, nil
+		}
+
+		var ok bool
+		if x, ok = val.(bool); ok == false {
+			err = fmt.Errorf("invalid bool %T", val)
+		}
+		return
+
+	case reflect.String:
+
+		if val == nil {
+			return "", nil
+		}
+
+		var ok bool
+		if x, ok = val.(string); ok == false {
+			err = fmt.Errorf("invalid string %T", val)
+		}
+		return
+
+	}
+
+	var s string
+	if s, err = numberJSON(val); err != nil {
+		return
+	}
+
+	var (
+		i int64
+		u uint64
+		f float64
+	)
+
+	switch kind {
+	case reflect.Int8:
+		i, err = strconv.ParseInt(s, 10, 8)
+		x = int8(i)
+	case reflect.Int16:
+		i, err = strconv.ParseInt(s, 10, 16)
+		x = int16(i)
+	case reflect.Int32:
+		i, err = strconv.ParseInt(s, 10, 32)
+		x = int32(i)
+	case reflect.Int64:
+		i, err = strconv.ParseInt(s, 10, 64)
+		x = i
+	case reflect.Uint8:
+		u, err = strconv.ParseUint(s, 10, 8)
+		x = uint8(u)
+	case reflect.Uint16:
+		u, err = strconv.ParseUint(s, 10, 16)
+		x = uint16(u)
+	case reflect.Uint32:
+		u, err = strconv.ParseUint(s, 10, 32)
+		x = uint32(u)
+	case reflect.Uint64:
+		u, err = strconv.ParseUint(s, 10, 64)
+		x = u
+	case reflect.Float32:
+		f, err = strconv.ParseFloat(s, 32)
+		x = float32(f)
+	case reflect.Float64:
+		f, err = strconv.ParseFloat(s, 64)
+		x = f
+	default:
+		err = fmt.Errorf("invalid Kind <%s> of Schema %q",
+			kind.String(), sch.String())
+	}
+
+	return
+}
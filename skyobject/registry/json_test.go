@@ -0,0 +1,180 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+func decodeTestJSON(t *testing.T, s string) (val interface{}) {
+	t.Helper()
+
+	var dec = json.NewDecoder(bytes.NewBufferString(s))
+	dec.UseNumber()
+
+	if err := dec.Decode(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestEncodeJSON(t *testing.T) {
+
+	var (
+		pack = getTestPack()
+		reg  = pack.Registry()
+
+		sch Schema
+		val []byte
+		err error
+	)
+
+	t.Run("basic", func(t *testing.T) {
+
+		if sch, err = reg.SchemaByName("test.Ints"); err != nil {
+			t.Fatal(err)
+		}
+
+		val, err = EncodeJSON(pack, sch, decodeTestJSON(t,
+			`{"Int8": -8, "Int16": 16, "Int64": 64}`))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bytes.Equal(val,
+			encoder.Serialize(TestIntsStruct{-8, 16, 0, 64})) == false {
+
+			t.Error("wrong encoded value")
+		}
+
+		for _, s := range []string{
+			`{"Int8": 128}`,   // overflow
+			`{"Int8": "8"}`,   // type
+			`{"Unknown": 1}`,  // field
+			`{"Int16": 1.5}`,  // float
+			`[]`,              // not a struct
+			`{"Int32": true}`, // type
+		} {
+			if _, err = EncodeJSON(pack, sch, decodeTestJSON(t, s)); err == nil {
+				t.Error("missing error:", s)
+			}
+		}
+
+	})
+
+	t.Run("arrays and slices", func(t *testing.T) {
+
+		if sch, err = reg.SchemaByName("test.Slices"); err != nil {
+			t.Fatal(err)
+		}
+
+		val, err = EncodeJSON(pack, sch, decodeTestJSON(t, `{
+			"Int8": [8],
+			"String": ["string", "string"],
+			"StringStruct": [{"String": "string"}]
+		}`))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var x TestSliceStruct
+		if err = encoder.DeserializeRaw(val, &x); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(x.Int8) != 1 || x.Int8[0] != 8 || len(x.String) != 2 ||
+			len(x.StringStruct) != 1 || x.StringStruct[0].String != "string" {
+
+			t.Error("wrong decoded value:", x)
+		}
+
+	})
+
+	t.Run("references", func(t *testing.T) {
+
+		var (
+			man  = TestMan{"kostyarin", "logrusorgru"}
+			mank = getHash(man)
+			usr  = TestUser{Name: "Alice", Age: 21}
+			usrk = getHash(usr)
+		)
+
+		if sch, err = reg.SchemaByName("test.Group"); err != nil {
+			t.Fatal(err)
+		}
+
+		val, err = EncodeJSON(pack, sch, decodeTestJSON(t, `{
+			"Name": "the CXO",
+			"Members": [
+				{"Name": "Alice", "Age": 21},
+				"`+usrk.Hex()+`"
+			],
+			"Curator": {"Name": "Alice", "Age": 21},
+			"Developer": {
+				"Schema": "test.Man",
+				"Object": {"Name": "kostyarin", "GitHub": "logrusorgru"}
+			}
+		}`))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var grp TestGroup
+		if err = encoder.DeserializeRaw(val, &grp); err != nil {
+			t.Fatal(err)
+		}
+
+		if grp.Curator.Hash != usrk {
+			t.Error("wrong Curator")
+		}
+
+		if grp.Developer.Hash != mank {
+			t.Error("wrong Developer")
+		}
+
+		if _, err = pack.Get(mank); err != nil {
+			t.Error("Developer not saved:", err)
+		}
+
+		var manSch Schema
+		if manSch, err = reg.SchemaByName("test.Man"); err != nil {
+			t.Fatal(err)
+		}
+
+		if grp.Developer.Schema != manSch.Reference() {
+			t.Error("wrong schema of Developer")
+		}
+
+		var ln int
+		if ln, err = grp.Members.Len(pack); err != nil {
+			t.Fatal(err)
+		} else if ln != 2 {
+			t.Error("wrong length of Members:", ln)
+		}
+
+		var hash cipher.SHA256
+		if hash, err = grp.Members.HashByIndex(pack, 1); err != nil {
+			t.Fatal(err)
+		} else if hash != usrk {
+			t.Error("wrong element of Members")
+		}
+
+		// blank
+		if val, err = EncodeJSON(pack, sch, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if bytes.Equal(val, encoder.Serialize(TestGroup{})) == false {
+			t.Error("wrong blank value")
+		}
+
+	})
+
+}
@@ -0,0 +1,573 @@
+package registry
+
+import (
+	"bytes"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A Map represents persistent sorted map, where keys
+// are []byte (or strings converted to []byte) and values
+// are references to objects of schema of the Map. The
+// Map represented as Merkle B-tree. Every node of the
+// tree is object in DB, and the Hash of the Map is hash
+// of root node. Since, nodes never changed in place,
+// but saved as new objects, then unchanged subtrees are
+// shared between Root objects. Thus, changing a big Map
+// costs O(depth) new objects.
+//
+// The degree of the Map is minimum degree of the B-tree.
+// E.g. every node, except root, contains from degree-1
+// to 2*degree-1 keys. Default degree of the Map is
+// default degree of the Pack.
+//
+// Use tag to set schema of values of the Map
+//
+//     type Group struct {
+//         Name    string
+//         Members registry.Map `skyobject:"schema=pkg.User"`
+//     }
+//
+// The Map uses lazy-loading strategy by default. E.g.
+// nodes of the tree are loaded by needs. Use EntireRefs
+// flag of the Pack to load entire Map. The LazyUpdating
+// flag turns off saving nodes after every change. Use
+// the Rebuild in this case to save changes. The
+// HashTableIndex flag doesn't affect the Map
+//
+// The Map is not thread safe
+type Map struct {
+	// Hash of root node of the Map. It's blank
+	// if the Map is blank
+	Hash cipher.SHA256
+
+	depth  int    `enc:"-"` // depth of the tree - 1
+	degree Degree `enc:"-"` // degree
+	length int    `enc:"-"` // number of entries
+
+	root *mapNode `enc:"-"` // root node (nil if not initialized)
+	mods refsMod  `enc:"-"` // modifications of the Map
+
+	flags Flags `enc:"-"` // first use (load) flags
+
+	iterating int `enc:"-"` // number of iterators
+}
+
+// A MapIterateFunc used to iterate over a Map. The
+// key must not be modified. Use ErrStopIteration to
+// break an iteration. The ErrStopIteration will be
+// hidden, but any other error will be returned. The
+// Map can't be changed inside the MapIterateFunc
+type MapIterateFunc func(key []byte, hash cipher.SHA256) (err error)
+
+// String implements fmt.Stringer interface and
+// returns hexadecimal encoded hash of the Map
+func (m *Map) String() string {
+	return m.Hash.Hex()
+}
+
+// Short string
+func (m *Map) Short() string {
+	return m.Hash.Hex()[:7]
+}
+
+// an encodedMap represents encoded root
+// node of the Map, with degree, depth and
+// length of the Map
+type encodedMap struct {
+	Depth  uint32
+	Degree uint32
+	Length uint32
+
+	Keys     [][]byte
+	Values   []cipher.SHA256
+	Branches []cipher.SHA256
+}
+
+func (m *Map) initialize(pack Pack) (err error) {
+
+	if m.root != nil {
+		return // already initialized
+	}
+
+	var root = &mapNode{mods: loadedMod}
+
+	m.flags = pack.Flags()   // keep current flags
+	m.degree = pack.Degree() // use default degree
+
+	if err = m.degree.Validate(); err != nil {
+		panic("invalid Degree of the Pack") // test the Pack
+	}
+
+	if m.Hash == (cipher.SHA256{}) {
+		m.root = root
+		return // blank Map, don't need to load
+	}
+
+	var em encodedMap
+	if err = get(pack, m.Hash, &em); err != nil {
+		return // get or decoding error
+	}
+
+	var en = encodedMapNode{
+		Keys:     em.Keys,
+		Values:   em.Values,
+		Branches: em.Branches,
+	}
+
+	if err = root.load(&en, int(em.Depth)); err != nil {
+		return
+	}
+
+	m.depth = int(em.Depth)
+	m.degree = Degree(em.Degree) // overwrite from saved
+	m.length = int(em.Length)
+
+	if m.length == 0 || m.degree.Validate() != nil {
+		return ErrInvalidEncodedMap // invalid state
+	}
+
+	if m.flags&EntireRefs != 0 {
+		if err = m.loadEntire(pack, root, m.depth); err != nil {
+			return
+		}
+	}
+
+	m.root = root
+	return
+}
+
+// Init does nothing, but if the Map is not
+// initialized, then the Init method initializes
+// it saving current flags of given pack. See
+// also Refs.Init
+func (m *Map) Init(pack Pack) (err error) {
+	return m.initialize(pack)
+}
+
+// Len returns number of entries of the Map
+func (m *Map) Len(pack Pack) (ln int, err error) {
+	if err = m.initialize(pack); err != nil {
+		return
+	}
+	ln = m.length
+	return
+}
+
+// Depth return real depth of the Map
+func (m *Map) Depth(pack Pack) (depth int, err error) {
+	if err = m.initialize(pack); err != nil {
+		return
+	}
+	depth = m.depth + 1
+	return
+}
+
+// Degree returns degree of the Map
+func (m *Map) Degree(pack Pack) (degree Degree, err error) {
+	if err = m.initialize(pack); err != nil {
+		return
+	}
+	degree = m.degree
+	return
+}
+
+// SetDegree sets degree of blank Map. Degree of
+// a Map can be changed only if the Map is blank.
+// Like the Refs, blank Map can't keep its degree
+func (m *Map) SetDegree(pack Pack, degree Degree) (err error) {
+
+	if err = degree.Validate(); err != nil {
+		return
+	}
+
+	if err = m.initialize(pack); err != nil {
+		return
+	}
+
+	if m.length != 0 {
+		return ErrMapIsNotBlank
+	}
+
+	m.degree = degree
+	return
+}
+
+// Flags returns current flags of the Map.
+// See also Refs.Flags
+func (m *Map) Flags() (flags Flags) {
+	return m.flags
+}
+
+// Reset the Map. The Reset method doesn't reloads
+// the Map, but allows reloading for other methods.
+// It's impossible to reset during an iteration.
+// The Reset resets flags of the Map too
+func (m *Map) Reset() (err error) {
+
+	if m.iterating > 0 {
+		return ErrMapIterating
+	}
+
+	*m = Map{Hash: m.Hash}
+	return
+}
+
+// Clear the Map making it blank
+func (m *Map) Clear() {
+	*m = Map{}
+}
+
+//
+// get
+//
+
+// Get returns hash of value by given key. It returns
+// ErrNotFound if the Map doesn't contain the key
+//
+// The big O of the call is O(depth * log(degree))
+func (m *Map) Get(
+	pack Pack, //          : pack to load
+	key []byte, //         : the key
+) (
+	hash cipher.SHA256, // : hash of value
+	err error, //          : error if any
+) {
+
+	if err = m.initialize(pack); err != nil {
+		return
+	}
+
+	var (
+		mn, depth = m.root, m.depth
+
+		i  int
+		ok bool
+	)
+
+	for {
+
+		if i, ok = mn.search(key); ok == true {
+			return mn.values[i], nil
+		}
+
+		if depth == 0 {
+			return hash, ErrNotFound
+		}
+
+		if mn, err = m.branch(pack, mn, i, depth); err != nil {
+			return
+		}
+
+		depth--
+	}
+
+}
+
+// Has returns true if the Map contains given key
+func (m *Map) Has(pack Pack, key []byte) (ok bool, err error) {
+
+	if _, err = m.Get(pack, key); err == nil {
+		ok = true
+	} else if err == ErrNotFound {
+		err = nil
+	}
+
+	return
+}
+
+// Value decodes value by given key. It returns
+// ErrNotFound if the Map doesn't contain the key,
+// and ErrReferenceRepresentsNil if value is nil
+func (m *Map) Value(
+	pack Pack, //       : pack to load
+	key []byte, //      : the key
+	obj interface{}, // : pointer to object to decode to
+) (
+	err error, //       : error if any
+) {
+
+	var hash cipher.SHA256
+	if hash, err = m.Get(pack, key); err != nil {
+		return
+	}
+
+	if hash == (cipher.SHA256{}) {
+		return ErrReferenceRepresentsNil
+	}
+
+	return get(pack, hash, obj)
+}
+
+//
+// put
+//
+
+// Put sets hash of value by given key. If the
+// Map already contains the key, then the Put
+// replaces hash of the value. The hash must
+// point to object of schema of the Map. Use
+// blank hash for nil
+//
+// The big O of the call is O(depth * degree)
+func (m *Map) Put(
+	pack Pack, //          : pack to load and save
+	key []byte, //         : the key
+	hash cipher.SHA256, // : hash of value
+) (
+	err error, //          : error if any
+) {
+
+	if m.iterating > 0 {
+		return ErrMapIterating
+	}
+
+	if err = m.initialize(pack); err != nil {
+		return
+	}
+
+	var ok bool
+	if ok, err = m.replace(pack, key, hash); err != nil || ok == true {
+		return m.updateHashIfNeed(pack, err)
+	}
+
+	// so, the Map doesn't contain the key
+
+	key = append([]byte{}, key...) // copy
+
+	if len(m.root.keys) == m.maxKeys() {
+		m.growRoot()
+	}
+
+	err = m.insertNonFull(pack, m.root, m.depth, key, hash)
+
+	if err == nil {
+		m.length++
+	}
+
+	return m.updateHashIfNeed(pack, err)
+}
+
+// PutValue saves given value and puts its hash by
+// given key. Use nil to put nil. See also Put
+func (m *Map) PutValue(
+	pack Pack, //       : pack to load and save
+	key []byte, //      : the key
+	obj interface{}, // : the value
+) (
+	err error, //       : error if any
+) {
+
+	var hash cipher.SHA256
+
+	if isNil(obj) == false {
+		if hash, err = addValue(pack, obj); err != nil {
+			return
+		}
+	}
+
+	return m.Put(pack, key, hash)
+}
+
+//
+// delete
+//
+
+// Delete deletes entry by given key. It returns
+// ErrNotFound if the Map doesn't contain the key
+//
+// The big O of the call is O(depth * degree)
+func (m *Map) Delete(
+	pack Pack, //  : pack to load and save
+	key []byte, // : the key
+) (
+	err error, //  : error if any
+) {
+
+	if m.iterating > 0 {
+		return ErrMapIterating
+	}
+
+	// the Get initializes the Map and loads all nodes
+	// required, thus the delete never fails because of
+	// missing key
+
+	if _, err = m.Get(pack, key); err != nil {
+		return
+	}
+
+	err = m.delete(pack, m.root, m.depth, key)
+
+	if err == nil {
+		m.length--
+		m.shrinkRoot()
+	}
+
+	return m.updateHashIfNeed(pack, err)
+}
+
+//
+// iterate
+//
+
+// Ascend iterates over all entries of the Map
+// ascending order of keys
+func (m *Map) Ascend(
+	pack Pack, //                : pack to load
+	iterateFunc MapIterateFunc, // : the function
+) (
+	err error, //                : error if any
+) {
+	return m.AscendRange(pack, nil, nil, iterateFunc)
+}
+
+// AscendRange iterates over entries of the Map from
+// given key (inclusive) to given key (exclusive)
+// ascending order. Use nil to iterate from first
+// or to last key
+func (m *Map) AscendRange(
+	pack Pack, //                : pack to load
+	from []byte, //              : from this key (inclusive) or nil
+	to []byte, //                : to this key (exclusive) or nil
+	iterateFunc MapIterateFunc, // : the function
+) (
+	err error, //                : error if any
+) {
+
+	if err = m.initialize(pack); err != nil {
+		return
+	}
+
+	m.iterating++
+	defer func() { m.iterating-- }()
+
+	err = m.ascendNode(pack, m.root, m.depth, from, to, iterateFunc)
+
+	if err == ErrStopIteration {
+		err = nil
+	}
+
+	return
+}
+
+// Descend iterates over all entries of the Map
+// descending order of keys
+func (m *Map) Descend(
+	pack Pack, //                : pack to load
+	iterateFunc MapIterateFunc, // : the function
+) (
+	err error, //                : error if any
+) {
+
+	if err = m.initialize(pack); err != nil {
+		return
+	}
+
+	m.iterating++
+	defer func() { m.iterating-- }()
+
+	err = m.descendNode(pack, m.root, m.depth, iterateFunc)
+
+	if err == ErrStopIteration {
+		err = nil
+	}
+
+	return
+}
+
+//
+// save
+//
+
+// Rebuild saves all unsaved changes of the Map.
+// If LazyUpdating flag is set, then you have to
+// call the Rebuild to make the Hash actual. The
+// Rebuild can't be called inside an iterator
+func (m *Map) Rebuild(pack Pack) (err error) {
+
+	if m.iterating > 0 {
+		return ErrMapIterating
+	}
+
+	if err = m.initialize(pack); err != nil {
+		return
+	}
+
+	return m.updateHash(pack)
+}
+
+// updateHashIfNeed saves changes if the LazyUpdating
+// flag is not set; it does nothing if given error is
+// not nil, and returns the error
+func (m *Map) updateHashIfNeed(pack Pack, err error) error {
+
+	if err != nil {
+		return err
+	}
+
+	if m.flags&LazyUpdating != 0 {
+		m.mods |= contentMod // but not saved
+		return nil
+	}
+
+	return m.updateHash(pack)
+}
+
+// updateHash saves all modified nodes
+func (m *Map) updateHash(pack Pack) (err error) {
+
+	if m.root.mods&contentMod == 0 {
+		m.mods &^= contentMod
+		return // actual state
+	}
+
+	if m.length == 0 {
+		m.Hash = cipher.SHA256{} // blank hash is blank Map
+		m.depth = 0
+		m.root = &mapNode{mods: loadedMod}
+		m.mods &^= contentMod
+		m.mods |= originMod
+		return
+	}
+
+	if err = m.saveBranches(pack, m.root, m.depth); err != nil {
+		return
+	}
+
+	var em = encodedMap{
+		Depth:  uint32(m.depth),
+		Degree: uint32(m.degree),
+		Length: uint32(m.length),
+
+		Keys:     m.root.keys,
+		Values:   m.root.values,
+		Branches: m.root.branchHashes(),
+	}
+
+	var (
+		val  = encoder.Serialize(em)
+		hash = sum(pack, val)
+	)
+
+	if hash != m.Hash {
+
+		if err = pack.Set(hash, val); err != nil {
+			return
+		}
+
+		m.Hash = hash
+
+	}
+
+	m.root.mods &^= contentMod
+	m.mods &^= contentMod
+	m.mods |= originMod
+
+	return
+}
+
+// compare keys
+func compareKeys(a, b []byte) int {
+	return bytes.Compare(a, b)
+}
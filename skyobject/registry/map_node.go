@@ -0,0 +1,777 @@
+package registry
+
+import (
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// a node of the Map
+type mapNode struct {
+	hash cipher.SHA256 // hash of the node (blank for root)
+	mods refsMod       // loadedMod and contentMod
+
+	keys     [][]byte        // sorted keys
+	values   []cipher.SHA256 // hashes of values
+	branches []*mapNode      // len(keys)+1 branches or nil for leafs
+}
+
+// DB representation of the mapNode
+type encodedMapNode struct {
+	Keys     [][]byte
+	Values   []cipher.SHA256
+	Branches []cipher.SHA256 // empty for leafs
+}
+
+//
+// load
+//
+
+// load the node from given encoded node
+// that has given depth
+func (n *mapNode) load(en *encodedMapNode, depth int) (err error) {
+
+	if len(en.Keys) != len(en.Values) || len(en.Keys) == 0 {
+		return ErrInvalidEncodedMap
+	}
+
+	if depth == 0 {
+		if len(en.Branches) != 0 {
+			return ErrInvalidEncodedMap
+		}
+	} else if len(en.Branches) != len(en.Keys)+1 {
+		return ErrInvalidEncodedMap
+	}
+
+	for i := 1; i < len(en.Keys); i++ {
+		if compareKeys(en.Keys[i-1], en.Keys[i]) >= 0 {
+			return ErrInvalidEncodedMap // not sorted
+		}
+	}
+
+	n.keys, n.values = en.Keys, en.Values
+
+	if depth > 0 {
+		n.branches = make([]*mapNode, 0, len(en.Branches))
+		for _, hash := range en.Branches {
+			n.branches = append(n.branches, &mapNode{hash: hash})
+		}
+	}
+
+	n.mods |= loadedMod
+	return
+}
+
+// loadNodeIfNeed loads given node of given depth
+func (m *Map) loadNodeIfNeed(
+	pack Pack, //   : pack to load
+	n *mapNode, //  : the node
+	depth int, //   : depth of the node
+) (
+	err error, //   : error if any
+) {
+
+	if n.mods&loadedMod != 0 {
+		return // already loaded
+	}
+
+	var en encodedMapNode
+	if err = get(pack, n.hash, &en); err != nil {
+		return
+	}
+
+	return n.load(&en, depth)
+}
+
+// branch returns loaded i-th branch of
+// given node of given depth
+func (m *Map) branch(
+	pack Pack, //     : pack to load
+	n *mapNode, //    : the node
+	i int, //         : index of the branch
+	depth int, //     : depth of the n (> 0)
+) (
+	br *mapNode, //   : the branch
+	err error, //     : error if any
+) {
+
+	br = n.branches[i]
+
+	if err = m.loadNodeIfNeed(pack, br, depth-1); err != nil {
+		br = nil
+	}
+
+	return
+}
+
+// loadEntire loads entire subtree
+func (m *Map) loadEntire(
+	pack Pack, //  : pack to load
+	n *mapNode, // : the loaded node
+	depth int, //  : depth of the node
+) (
+	err error, //  : error if any
+) {
+
+	if depth == 0 {
+		return
+	}
+
+	var br *mapNode
+
+	for i := range n.branches {
+
+		if br, err = m.branch(pack, n, i, depth); err != nil {
+			return
+		}
+
+		if err = m.loadEntire(pack, br, depth-1); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+//
+// search
+//
+
+// search returns index of first key that is
+// greater or equal to given one, and true if
+// the key is equal
+func (n *mapNode) search(key []byte) (i int, ok bool) {
+
+	i = sort.Search(len(n.keys), func(i int) bool {
+		return compareKeys(n.keys[i], key) >= 0
+	})
+
+	ok = i < len(n.keys) && compareKeys(n.keys[i], key) == 0
+	return
+}
+
+//
+// insert
+//
+
+// max keys per node
+func (m *Map) maxKeys() int {
+	return 2*int(m.degree) - 1
+}
+
+// replace hash of value by existing key, it returns
+// false if the Map doesn't contain the key
+func (m *Map) replace(
+	pack Pack, //          : pack to load
+	key []byte, //         : the key
+	hash cipher.SHA256, // : new hash
+) (
+	ok bool, //            : replaced
+	err error, //          : error if any
+) {
+
+	var (
+		n, depth = m.root, m.depth
+		path     []*mapNode
+		i        int
+	)
+
+	for {
+
+		path = append(path, n)
+
+		if i, ok = n.search(key); ok == true {
+			break
+		}
+
+		if depth == 0 {
+			return // not found
+		}
+
+		if n, err = m.branch(pack, n, i, depth); err != nil {
+			return
+		}
+
+		depth--
+	}
+
+	if n.values[i] == hash {
+		return // nothing to change
+	}
+
+	n.values[i] = hash
+
+	for _, up := range path {
+		up.mods |= contentMod
+	}
+
+	return
+}
+
+// growRoot splits full root increasing depth of the Map
+func (m *Map) growRoot() {
+
+	var root = &mapNode{
+		mods:     loadedMod | contentMod,
+		branches: []*mapNode{m.root},
+	}
+
+	m.splitChild(root, 0)
+
+	m.root = root
+	m.depth++
+}
+
+// splitChild splits full i-th branch of given node,
+// the node should not be full, and the branch should
+// be loaded
+func (m *Map) splitChild(n *mapNode, i int) {
+
+	var (
+		t = int(m.degree)
+
+		y = n.branches[i]
+		z = &mapNode{mods: loadedMod | contentMod}
+	)
+
+	z.keys = append(make([][]byte, 0, m.maxKeys()), y.keys[t:]...)
+	z.values = append(make([]cipher.SHA256, 0, m.maxKeys()), y.values[t:]...)
+
+	if y.branches != nil {
+		z.branches = append(make([]*mapNode, 0, m.maxKeys()+1),
+			y.branches[t:]...)
+		y.branches = y.branches[:t:t]
+	}
+
+	// median goes up
+
+	n.keys = insertKey(n.keys, i, y.keys[t-1])
+	n.values = insertValue(n.values, i, y.values[t-1])
+	n.branches = insertBranch(n.branches, i+1, z)
+
+	y.keys = y.keys[: t-1 : t-1]
+	y.values = y.values[: t-1 : t-1]
+
+	y.mods |= contentMod
+	n.mods |= contentMod
+}
+
+// insertNonFull inserts given key to given non-full node
+func (m *Map) insertNonFull(
+	pack Pack, //          : pack to load
+	n *mapNode, //         : the node
+	depth int, //          : depth of the node
+	key []byte, //         : key to insert
+	hash cipher.SHA256, // : hash of value
+) (
+	err error, //          : error if any
+) {
+
+	for {
+
+		n.mods |= contentMod
+
+		var i, _ = n.search(key) // the key doesn't exist
+
+		if depth == 0 {
+			n.keys = insertKey(n.keys, i, key)
+			n.values = insertValue(n.values, i, hash)
+			return
+		}
+
+		var br *mapNode
+		if br, err = m.branch(pack, n, i, depth); err != nil {
+			return
+		}
+
+		if len(br.keys) == m.maxKeys() {
+
+			m.splitChild(n, i)
+
+			if compareKeys(key, n.keys[i]) > 0 {
+				i++
+			}
+
+			br = n.branches[i] // loaded
+
+		}
+
+		n, depth = br, depth-1
+	}
+
+}
+
+//
+// delete
+//
+
+// delete given key from subtree of given node that
+// contains the key; the node should contain at least
+// degree keys or should be root
+func (m *Map) delete(
+	pack Pack, //  : pack to load
+	n *mapNode, // : the node
+	depth int, //  : depth of the node
+	key []byte, // : key to delete
+) (
+	err error, //  : error if any
+) {
+
+	var t = int(m.degree)
+
+	for {
+
+		n.mods |= contentMod
+
+		var i, ok = n.search(key)
+
+		if depth == 0 {
+			if ok == false {
+				return ErrInvalidMap // the key must exist
+			}
+			n.keys = deleteKey(n.keys, i)
+			n.values = deleteValue(n.values, i)
+			return
+		}
+
+		var y, z *mapNode
+
+		if ok == true { // internal node contains the key
+
+			if y, err = m.branch(pack, n, i, depth); err != nil {
+				return
+			}
+
+			if z, err = m.branch(pack, n, i+1, depth); err != nil {
+				return
+			}
+
+			if len(y.keys) >= t {
+
+				// replace with predecessor and delete it from the y
+				var pk []byte
+				pk, n.values[i], err = m.last(pack, y, depth-1)
+				if err != nil {
+					return
+				}
+				n.keys[i], key = pk, pk
+
+			} else if len(z.keys) >= t {
+
+				// replace with successor and delete it from the z
+				var sk []byte
+				sk, n.values[i], err = m.first(pack, z, depth-1)
+				if err != nil {
+					return
+				}
+				n.keys[i], key = sk, sk
+				y = z
+
+			} else {
+
+				m.merge(n, i) // the key goes down to the y
+
+			}
+
+			n, depth = y, depth-1
+			continue
+		}
+
+		// the subtree of i-th branch contains the key
+
+		if y, err = m.branch(pack, n, i, depth); err != nil {
+			return
+		}
+
+		if len(y.keys) < t {
+			if y, err = m.fill(pack, n, i, depth); err != nil {
+				return
+			}
+		}
+
+		n, depth = y, depth-1
+	}
+
+}
+
+// fill i-th branch of given node, that has degree-1
+// keys, borrowing a key from a sibling or merging it
+// with a sibling; the method returns the branch
+func (m *Map) fill(
+	pack Pack, //   : pack to load
+	n *mapNode, //  : the node
+	i int, //       : index of the branch
+	depth int, //   : depth of the node
+) (
+	br *mapNode, // : the filled branch
+	err error, //   : error if any
+) {
+
+	var (
+		t = int(m.degree)
+
+		y    = n.branches[i] // loaded
+		l, r *mapNode        // siblings
+	)
+
+	if i > 0 {
+		if l, err = m.branch(pack, n, i-1, depth); err != nil {
+			return
+		}
+		if len(l.keys) >= t {
+			m.rotateRight(n, i-1)
+			return y, nil
+		}
+	}
+
+	if i < len(n.keys) {
+		if r, err = m.branch(pack, n, i+1, depth); err != nil {
+			return
+		}
+		if len(r.keys) >= t {
+			m.rotateLeft(n, i)
+			return y, nil
+		}
+		m.merge(n, i)
+		return y, nil
+	}
+
+	m.merge(n, i-1)
+	return l, nil
+}
+
+// rotateRight moves last key of i-th branch to the
+// node, and i-th key of the node to (i+1)-th branch
+func (m *Map) rotateRight(n *mapNode, i int) {
+
+	var (
+		l = n.branches[i]
+		r = n.branches[i+1]
+
+		last = len(l.keys) - 1
+	)
+
+	r.keys = insertKey(r.keys, 0, n.keys[i])
+	r.values = insertValue(r.values, 0, n.values[i])
+
+	n.keys[i], n.values[i] = l.keys[last], l.values[last]
+
+	l.keys = deleteKey(l.keys, last)
+	l.values = deleteValue(l.values, last)
+
+	if l.branches != nil {
+		r.branches = insertBranch(r.branches, 0, l.branches[last+1])
+		l.branches = deleteBranch(l.branches, last+1)
+	}
+
+	l.mods |= contentMod
+	r.mods |= contentMod
+}
+
+// rotateLeft moves first key of (i+1)-th branch to
+// the node, and i-th key of the node to i-th branch
+func (m *Map) rotateLeft(n *mapNode, i int) {
+
+	var (
+		l = n.branches[i]
+		r = n.branches[i+1]
+	)
+
+	l.keys = append(l.keys, n.keys[i])
+	l.values = append(l.values, n.values[i])
+
+	n.keys[i], n.values[i] = r.keys[0], r.values[0]
+
+	r.keys = deleteKey(r.keys, 0)
+	r.values = deleteValue(r.values, 0)
+
+	if r.branches != nil {
+		l.branches = append(l.branches, r.branches[0])
+		r.branches = deleteBranch(r.branches, 0)
+	}
+
+	l.mods |= contentMod
+	r.mods |= contentMod
+}
+
+// merge i-th key of given node and (i+1)-th branch
+// to i-th branch; the branches should be loaded
+func (m *Map) merge(n *mapNode, i int) {
+
+	var (
+		y = n.branches[i]
+		z = n.branches[i+1]
+	)
+
+	y.keys = append(append(y.keys, n.keys[i]), z.keys...)
+	y.values = append(append(y.values, n.values[i]), z.values...)
+
+	if y.branches != nil {
+		y.branches = append(y.branches, z.branches...)
+	}
+
+	n.keys = deleteKey(n.keys, i)
+	n.values = deleteValue(n.values, i)
+	n.branches = deleteBranch(n.branches, i+1)
+
+	y.mods |= contentMod
+	n.mods |= contentMod
+}
+
+// shrinkRoot reduces depth of the Map
+// if root node has no keys
+func (m *Map) shrinkRoot() {
+
+	if len(m.root.keys) > 0 || m.depth == 0 {
+		return
+	}
+
+	m.root = m.root.branches[0] // loaded
+	m.root.hash = cipher.SHA256{}
+	m.root.mods |= contentMod // root has another encoding
+	m.depth--
+}
+
+// first returns first entry of given subtree
+func (m *Map) first(
+	pack Pack, //          : pack to load
+	n *mapNode, //         : the node
+	depth int, //          : depth of the node
+) (
+	key []byte, //         : first key
+	hash cipher.SHA256, // : hash of value
+	err error, //          : error if any
+) {
+
+	for ; depth > 0; depth-- {
+		if n, err = m.branch(pack, n, 0, depth); err != nil {
+			return
+		}
+	}
+
+	return n.keys[0], n.values[0], nil
+}
+
+// last returns last entry of given subtree
+func (m *Map) last(
+	pack Pack, //          : pack to load
+	n *mapNode, //         : the node
+	depth int, //          : depth of the node
+) (
+	key []byte, //         : last key
+	hash cipher.SHA256, // : hash of value
+	err error, //          : error if any
+) {
+
+	for ; depth > 0; depth-- {
+		if n, err = m.branch(pack, n, len(n.branches)-1, depth); err != nil {
+			return
+		}
+	}
+
+	return n.keys[len(n.keys)-1], n.values[len(n.values)-1], nil
+}
+
+//
+// iterate
+//
+
+func (m *Map) ascendNode(
+	pack Pack, //                : pack to load
+	n *mapNode, //               : the node
+	depth int, //                : depth of the node
+	from, to []byte, //          : range
+	iterateFunc MapIterateFunc, // : the function
+) (
+	err error, //                : error if any
+) {
+
+	var i int
+	if from != nil {
+		i, _ = n.search(from)
+	}
+
+	for ; i <= len(n.keys); i++ {
+
+		if depth > 0 {
+
+			var br *mapNode
+			if br, err = m.branch(pack, n, i, depth); err != nil {
+				return
+			}
+
+			err = m.ascendNode(pack, br, depth-1, from, to, iterateFunc)
+			if err != nil {
+				return
+			}
+
+			from = nil // all next keys are greater
+
+		}
+
+		if i == len(n.keys) {
+			break
+		}
+
+		if to != nil && compareKeys(n.keys[i], to) >= 0 {
+			return ErrStopIteration // done
+		}
+
+		if err = iterateFunc(n.keys[i], n.values[i]); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+func (m *Map) descendNode(
+	pack Pack, //                : pack to load
+	n *mapNode, //               : the node
+	depth int, //                : depth of the node
+	iterateFunc MapIterateFunc, // : the function
+) (
+	err error, //                : error if any
+) {
+
+	for i := len(n.keys); i >= 0; i-- {
+
+		if i < len(n.keys) {
+			if err = iterateFunc(n.keys[i], n.values[i]); err != nil {
+				return
+			}
+		}
+
+		if depth > 0 {
+
+			var br *mapNode
+			if br, err = m.branch(pack, n, i, depth); err != nil {
+				return
+			}
+
+			if err = m.descendNode(pack, br, depth-1, iterateFunc); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
+
+//
+// save
+//
+
+// hashes of branches of the node
+func (n *mapNode) branchHashes() (hashes []cipher.SHA256) {
+
+	if len(n.branches) == 0 {
+		return
+	}
+
+	hashes = make([]cipher.SHA256, 0, len(n.branches))
+
+	for _, br := range n.branches {
+		hashes = append(hashes, br.hash)
+	}
+
+	return
+}
+
+// saveBranches saves modified branches of
+// given node, and their modified branches
+func (m *Map) saveBranches(
+	pack Pack, //  : pack to save
+	n *mapNode, // : the node
+	depth int, //  : depth of the node
+) (
+	err error, //  : error if any
+) {
+
+	if depth == 0 {
+		return
+	}
+
+	for _, br := range n.branches {
+
+		if br.mods&contentMod == 0 {
+			continue // not modified (or not loaded)
+		}
+
+		if err = m.saveBranches(pack, br, depth-1); err != nil {
+			return
+		}
+
+		var en = encodedMapNode{
+			Keys:     br.keys,
+			Values:   br.values,
+			Branches: br.branchHashes(),
+		}
+
+		var val = encoder.Serialize(en)
+
+		if br.hash = sum(pack, val); br.hash == (cipher.SHA256{}) {
+			return ErrInvalidMap
+		}
+
+		if err = pack.Set(br.hash, val); err != nil {
+			return
+		}
+
+		br.mods &^= contentMod
+	}
+
+	return
+}
+
+//
+// slices
+//
+
+func insertKey(keys [][]byte, i int, key []byte) [][]byte {
+	keys = append(keys, nil)
+	copy(keys[i+1:], keys[i:])
+	keys[i] = key
+	return keys
+}
+
+func deleteKey(keys [][]byte, i int) [][]byte {
+	copy(keys[i:], keys[i+1:])
+	keys[len(keys)-1] = nil
+	return keys[:len(keys)-1]
+}
+
+func insertValue(
+	values []cipher.SHA256,
+	i int,
+	hash cipher.SHA256,
+) []cipher.SHA256 {
+
+	values = append(values, cipher.SHA256{})
+	copy(values[i+1:], values[i:])
+	values[i] = hash
+	return values
+}
+
+func deleteValue(values []cipher.SHA256, i int) []cipher.SHA256 {
+	copy(values[i:], values[i+1:])
+	return values[:len(values)-1]
+}
+
+func insertBranch(branches []*mapNode, i int, br *mapNode) []*mapNode {
+	branches = append(branches, nil)
+	copy(branches[i+1:], branches[i:])
+	branches[i] = br
+	return branches
+}
+
+func deleteBranch(branches []*mapNode, i int) []*mapNode {
+	copy(branches[i:], branches[i+1:])
+	branches[len(branches)-1] = nil
+	return branches[:len(branches)-1]
+}
@@ -0,0 +1,562 @@
+package registry
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+type TestMapGroup struct {
+	Name  string
+	Users Map `skyobject:"schema=test.User"`
+}
+
+func testMapFlags() []Flags {
+	return []Flags{
+		0,
+		EntireRefs,
+		LazyUpdating,
+	}
+}
+
+func testMapKey(i int) []byte {
+	return []byte(fmt.Sprintf("key-%05d", i))
+}
+
+// sorted keys of the model
+func testMapKeys(want map[string]cipher.SHA256) (keys []string) {
+	keys = make([]string, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
+
+// check content of the Map, then reload
+// the Map and check it again
+func testMapCheck(
+	t *testing.T, //                 : the testing
+	m *Map, //                       : the Map
+	pack Pack, //                    : the Pack
+	want map[string]cipher.SHA256, // : expected content
+) {
+
+	var check = func() {
+
+		var (
+			ln   int
+			hash cipher.SHA256
+			err  error
+		)
+
+		if ln, err = m.Len(pack); err != nil {
+			t.Fatal(err)
+		} else if ln != len(want) {
+			t.Fatalf("wrong length %d, want %d", ln, len(want))
+		}
+
+		for k, w := range want {
+			if hash, err = m.Get(pack, []byte(k)); err != nil {
+				t.Fatalf("%q: %v", k, err)
+			} else if hash != w {
+				t.Fatalf("wrong hash of %q", k)
+			}
+		}
+
+		var keys = testMapKeys(want)
+
+		var i int
+		err = m.Ascend(pack, func(key []byte, hash cipher.SHA256) (_ error) {
+			if i >= len(keys) || string(key) != keys[i] {
+				t.Fatalf("wrong key %q at %d", key, i)
+			}
+			i++
+			return
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if i != len(keys) {
+			t.Fatalf("wrong number of keys %d, want %d", i, len(keys))
+		}
+
+		err = m.Descend(pack, func(key []byte, hash cipher.SHA256) (_ error) {
+			i--
+			if i < 0 || string(key) != keys[i] {
+				t.Fatalf("wrong key %q at %d", key, i)
+			}
+			return
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if i != 0 {
+			t.Fatalf("wrong number of keys %d", len(keys)-i)
+		}
+
+		testMapNodeCheck(t, m, m.root, m.depth, true)
+	}
+
+	check()
+
+	if err := m.Rebuild(pack); err != nil { // LazyUpdating
+		t.Fatal(err)
+	}
+
+	if len(want) == 0 && m.Hash != (cipher.SHA256{}) {
+		t.Error("blank Map with non-blank hash")
+	}
+
+	if err := m.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	check()
+}
+
+// check number of keys of loaded nodes
+func testMapNodeCheck(
+	t *testing.T,
+	m *Map,
+	n *mapNode,
+	depth int,
+	isRoot bool,
+) {
+
+	if n.mods&loadedMod == 0 {
+		return
+	}
+
+	if len(n.keys) > m.maxKeys() {
+		t.Fatalf("overflowed node: %d > %d", len(n.keys), m.maxKeys())
+	}
+
+	if isRoot == false && len(n.keys) < int(m.degree)-1 {
+		t.Fatalf("underflowed node: %d < %d", len(n.keys), m.degree-1)
+	}
+
+	if len(n.keys) != len(n.values) {
+		t.Fatal("wrong number of values")
+	}
+
+	if depth == 0 {
+		if len(n.branches) != 0 {
+			t.Fatal("leaf with branches")
+		}
+		return
+	}
+
+	if len(n.branches) != len(n.keys)+1 {
+		t.Fatalf("wrong number of branches %d, keys %d", len(n.branches),
+			len(n.keys))
+	}
+
+	for _, br := range n.branches {
+		testMapNodeCheck(t, m, br, depth-1, false)
+	}
+}
+
+func TestMap_Put(t *testing.T) {
+	// Put(pack Pack, key []byte, hash cipher.SHA256) (err error)
+
+	var pack = getTestPack()
+
+	for _, flags := range testMapFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		t.Logf("flags %08b", flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			t.Log("degree", degree)
+
+			var (
+				m    Map
+				want = make(map[string]cipher.SHA256)
+				rnd  = rand.New(rand.NewSource(int64(degree)))
+			)
+
+			if err := m.SetDegree(pack, degree); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, n := range []int{1, int(degree), 100} {
+
+				for _, i := range rnd.Perm(n * 2)[:n] {
+
+					var hash = hashByNumber(uint64(rnd.Int63()))
+
+					if err := m.Put(pack, testMapKey(i), hash); err != nil {
+						t.Fatal(err)
+					}
+
+					want[string(testMapKey(i))] = hash
+				}
+
+				testMapCheck(t, &m, pack, want)
+
+				if d, err := m.Degree(pack); err != nil {
+					t.Fatal(err)
+				} else if d != degree {
+					t.Errorf("wrong degree %d, want %d", d, degree)
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
+func TestMap_Delete(t *testing.T) {
+	// Delete(pack Pack, key []byte) (err error)
+
+	var pack = getTestPack()
+
+	for _, flags := range testMapFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		t.Logf("flags %08b", flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			t.Log("degree", degree)
+
+			var (
+				m    Map
+				want = make(map[string]cipher.SHA256)
+				rnd  = rand.New(rand.NewSource(int64(degree)))
+			)
+
+			if err := m.SetDegree(pack, degree); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 200; i++ {
+				var hash = hashByNumber(uint64(i + 1))
+				if err := m.Put(pack, testMapKey(i), hash); err != nil {
+					t.Fatal(err)
+				}
+				want[string(testMapKey(i))] = hash
+			}
+
+			if err := m.Delete(pack, testMapKey(1000)); err != ErrNotFound {
+				t.Error("wrong error:", err)
+			}
+
+			for _, n := range []int{1, int(degree), 50, 200} {
+
+				var keys = testMapKeys(want)
+
+				if n > len(keys) {
+					n = len(keys) // delete all
+				}
+
+				for _, i := range rnd.Perm(len(keys))[:n] {
+
+					if err := m.Delete(pack, []byte(keys[i])); err != nil {
+						t.Fatal(err)
+					}
+
+					delete(want, keys[i])
+				}
+
+				testMapCheck(t, &m, pack, want)
+
+			}
+
+			if len(want) != 0 {
+				t.Fatal("test is broken")
+			}
+
+			// put again after all deleted
+			if err := m.Put(pack, testMapKey(1), hashByNumber(1)); err != nil {
+				t.Fatal(err)
+			}
+
+			want[string(testMapKey(1))] = hashByNumber(1)
+			testMapCheck(t, &m, pack, want)
+
+		}
+
+	}
+
+}
+
+func TestMap_AscendRange(t *testing.T) {
+	// AscendRange(pack Pack, from, to []byte,
+	//     iterateFunc MapIterateFunc) (err error)
+
+	var (
+		pack = getTestPack()
+
+		m   Map
+		err error
+	)
+
+	for i := 0; i < 100; i++ {
+		if err = m.Put(pack, testMapKey(i), hashByNumber(1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		from, to []byte
+		first    int
+		n        int
+	}{
+		{nil, nil, 0, 100},
+		{testMapKey(10), nil, 10, 90},
+		{nil, testMapKey(10), 0, 10},
+		{testMapKey(10), testMapKey(20), 10, 10},
+		{[]byte("key-00010.5"), testMapKey(20), 11, 9},
+		{testMapKey(20), testMapKey(10), 20, 0},
+		{[]byte("z"), nil, 100, 0},
+	} {
+
+		var i = tc.first
+
+		err = m.AscendRange(pack, tc.from, tc.to,
+			func(key []byte, _ cipher.SHA256) (_ error) {
+				if string(key) != string(testMapKey(i)) {
+					t.Errorf("wrong key %q, want %q", key, testMapKey(i))
+				}
+				i++
+				return
+			})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if i-tc.first != tc.n {
+			t.Errorf("wrong number of keys %d, want %d (%q - %q)",
+				i-tc.first, tc.n, tc.from, tc.to)
+		}
+
+	}
+
+	// stop iteration and change inside
+
+	var n int
+	err = m.Ascend(pack, func([]byte, cipher.SHA256) (err error) {
+		if err = m.Put(pack, testMapKey(1), hashByNumber(2)); err == nil {
+			t.Error("missing ErrMapIterating")
+		}
+		n++
+		return ErrStopIteration
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Error("ErrStopIteration doesn't stop iteration")
+	}
+
+}
+
+func TestMap_sharing(t *testing.T) {
+
+	var (
+		pack = getTestPack()
+
+		m   Map
+		err error
+	)
+
+	for i := 0; i < 200; i++ {
+		if err = m.Put(pack, testMapKey(i), hashByNumber(1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		old    = m.Hash
+		before = len(pack.vals)
+	)
+
+	if err = m.Put(pack, testMapKey(1000), hashByNumber(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	var depth int
+	if depth, err = m.Depth(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	// a node and its new sibling (split) per level
+	if created := len(pack.vals) - before; created > 2*depth {
+		t.Errorf("too many new objects %d, depth %d", created, depth)
+	}
+
+	// the old Map is still there
+
+	var om = Map{Hash: old}
+
+	if _, err = om.Get(pack, testMapKey(1000)); err != ErrNotFound {
+		t.Error("wrong error:", err)
+	}
+
+	if ln, err := om.Len(pack); err != nil {
+		t.Fatal(err)
+	} else if ln != 200 {
+		t.Errorf("wrong length %d, want 200", ln)
+	}
+
+	// the same content is the same hash
+
+	if err = m.Delete(pack, testMapKey(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	var nm Map
+
+	for i := 0; i < 200; i++ {
+		if err = nm.Put(pack, testMapKey(i), hashByNumber(1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if nm.Hash != old {
+		t.Error("different hashes of the same Maps created the same way")
+	}
+
+}
+
+func TestMap_Value(t *testing.T) {
+	// Value(pack Pack, key []byte, obj interface{}) (err error)
+
+	var (
+		pack  = getTestPack()
+		users = getTestUsers(10)
+
+		m   Map
+		err error
+	)
+
+	for i, usr := range users {
+		if err = m.PutValue(pack, testMapKey(i), usr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = m.PutValue(pack, []byte("nil"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var usr TestUser
+
+	for i, u := range users {
+		if err = m.Value(pack, testMapKey(i), &usr); err != nil {
+			t.Fatal(err)
+		}
+		var want = u.(TestUser)
+		if usr.Name != want.Name || usr.Age != want.Age {
+			t.Errorf("wrong value of %d: %v", i, usr)
+		}
+	}
+
+	err = m.Value(pack, []byte("nil"), &usr)
+	if err != ErrReferenceRepresentsNil {
+		t.Error("wrong error:", err)
+	}
+
+	if err = m.Value(pack, []byte("x"), &usr); err != ErrNotFound {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestMap_Walk(t *testing.T) {
+	// Walk(pack Pack, sch Schema, walkFunc WalkFunc) (err error)
+
+	var (
+		reg = NewRegistry(func(r *Reg) {
+			r.Register("test.User", TestUser{})
+			r.Register("test.MapGroup", TestMapGroup{})
+		})
+		pack  = testPackReg(reg)
+		users = getTestUsers(50)
+
+		grp TestMapGroup
+		sch Schema
+		err error
+	)
+
+	if sch, err = reg.SchemaByName("test.MapGroup"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := sch.Fields()[1].Schema().String(); s != "map[]*test.User" {
+		t.Errorf("wrong schema of the Map %q", s)
+	}
+
+	// encode and decode the schema
+
+	var ds Schema
+	if ds, err = decodeSchema(sch.Fields()[1].Schema().Encode()); err != nil {
+		t.Fatal(err)
+	} else if ds.ReferenceType() != ReferenceTypeMap {
+		t.Error("wrong reference type", ds.ReferenceType())
+	}
+
+	for i, usr := range users {
+		if err = grp.Users.PutValue(pack, testMapKey(i), usr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		hash    cipher.SHA256
+		visited = make(map[cipher.SHA256]int)
+	)
+
+	if hash, err = pack.Add(encoder.Serialize(grp)); err != nil {
+		t.Fatal(err)
+	}
+
+	err = walkSchemaHash(pack, sch, hash, func(
+		hash cipher.SHA256,
+		depth int,
+	) (bool, error) {
+		visited[hash]++
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, usr := range users {
+		if visited[getHash(usr)] != 1 {
+			t.Error("user not visited")
+		}
+	}
+
+	if visited[grp.Users.Hash] != 1 {
+		t.Error("root of the Map not visited")
+	}
+
+	// all nodes and values are in the pack
+
+	for hash := range visited {
+		if _, ok := pack.vals[hash]; ok == false {
+			t.Errorf("visited object %s is not in pack", hash.Hex()[:7])
+		}
+	}
+
+	if len(visited) <= len(users)+1 {
+		t.Error("nodes of the Map not visited")
+	}
+
+}
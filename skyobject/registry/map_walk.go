@@ -0,0 +1,216 @@
+package registry
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// Walk is service method and used for filling, updating and
+// similar. The Walk walks through the Map tree invoking given
+// function for every node and every value starting from root
+// of the tree. Like the Refs.Walk the Walk requires schema of
+// values of the Map (not schema of the Map)
+//
+// The first hash is Map.Hash with depth of the Map + 1. Then
+// the Walk calls the WalkFunc for values of the root with zero
+// depth, and for branches of the root with depth of the root,
+// and so on. If the WalkFunc returns deepper == false for a
+// branch, then subtree of the branch will be skipped. Values
+// can be blank (nil), in this case the deepper reply ignored
+//
+// If the Map is not initialized, then it will be reset after.
+// Feel free to use ErrStopIteration to break the Walk. See
+// also Refs.Walk
+func (m *Map) Walk(
+	pack Pack, //         : pack to load
+	sch Schema, //        : schema of values of the Map
+	walkFunc WalkFunc, // : the function
+) (
+	err error, //         : an error
+) {
+
+	if walkFunc == nil {
+		panic("walkFunc is nil") // for developers
+	}
+
+	var resetRequired bool
+
+	defer func() {
+		if resetRequired == true {
+			if err != nil {
+				m.Reset() // ignore the error (can't happen)
+			} else {
+				err = m.Reset()
+			}
+		}
+	}()
+
+	if m.root == nil {
+
+		if m.Hash == (cipher.SHA256{}) {
+			return m.walkBlank(walkFunc) // don't initialize
+		}
+
+		resetRequired = true // reset it after
+
+		if err = m.initialize(pack); err != nil {
+			return
+		}
+
+	} else if err = m.updateHash(pack); err != nil {
+		return // update hashes if the Map is not actual
+	}
+
+	if m.Hash == (cipher.SHA256{}) {
+		return m.walkBlank(walkFunc)
+	}
+
+	var deepper bool
+
+	// starting from root (depth + 1 for the root)
+	if deepper, err = walkFunc(m.Hash, m.depth+1); err != nil {
+		if err == ErrStopIteration {
+			err = nil
+		}
+		return
+	} else if deepper == false {
+		return // done
+	}
+
+	err = m.walkNode(pack, sch, m.root, m.depth, walkFunc)
+
+	if err == ErrStopIteration {
+		err = nil
+	}
+
+	return
+}
+
+// walk if the Map is blank
+func (m *Map) walkBlank(walkFunc WalkFunc) (err error) {
+	if _, err = walkFunc(m.Hash, 1); err == ErrStopIteration {
+		err = nil
+	}
+	return
+}
+
+func (m *Map) walkNode(
+	pack Pack, //         : pack to load
+	sch Schema, //        : schema of values
+	n *mapNode, //        : the node
+	depth int, //         : depth of the node
+	walkFunc WalkFunc, // : the function
+) (
+	err error, //         : an error
+) {
+
+	var deepper bool
+
+	for _, hash := range n.values {
+
+		if deepper, err = walkFunc(hash, 0); err != nil {
+			return
+		}
+
+		if deepper == false || hash == (cipher.SHA256{}) {
+			continue
+		}
+
+		if err = walkSchemaHash(pack, sch, hash, walkFunc); err != nil {
+			return
+		}
+
+	}
+
+	for _, br := range n.branches {
+
+		if deepper, err = walkFunc(br.hash, depth); err != nil {
+			return
+		} else if deepper == false {
+			continue
+		}
+
+		if err = m.loadNodeIfNeed(pack, br, depth-1); err != nil {
+			return
+		}
+
+		if err = m.walkNode(pack, sch, br, depth-1, walkFunc); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+// Split used by the node package to fill the Map. Like
+// the Refs.Split, the Split never resets the Map and
+// never updates hashes of the Map
+func (m *Map) Split(s Splitter, el Schema) {
+
+	var fp = fakePack{s} // fake Pack
+
+	if m.Hash == (cipher.SHA256{}) {
+		return // done
+	}
+
+	if m.splitHash(&fp, m.Hash) == false {
+		return
+	}
+
+	if err := m.initialize(&fp); err != nil {
+		s.Fail(err)
+		return
+	}
+
+	m.splitNode(&fp, el, m.root, m.depth)
+}
+
+// get and cache value, and return true
+// if hard rc of value is zero
+func (m *Map) splitHash(fp *fakePack, hash cipher.SHA256) (load bool) {
+
+	var rc, err = fp.s.Pre(hash)
+
+	if err != nil {
+		fp.s.Fail(err)
+		return
+	}
+
+	return (rc == 0) // load if hard rc == 0
+}
+
+func (m *Map) splitNode(
+	fp *fakePack, // : fake pack to load
+	sch Schema, //   : schema of values
+	n *mapNode, //   : the node
+	depth int, //    : depth of the node
+) {
+
+	for _, hash := range n.values {
+		splitSchemaHashAsync(fp.s, sch, hash)
+	}
+
+	var toSplit []*mapNode // data-race protection
+
+	for _, br := range n.branches {
+
+		if m.splitHash(fp, br.hash) == false {
+			continue
+		}
+
+		if err := m.loadNodeIfNeed(fp, br, depth-1); err != nil {
+			fp.s.Fail(err)
+			return
+		}
+
+		toSplit = append(toSplit, br)
+	}
+
+	// data-race protection: load first, then split
+
+	for _, br := range toSplit {
+		var br = br
+		fp.s.Go(func() { m.splitNode(fp, sch, br, depth-1) })
+	}
+
+}
@@ -0,0 +1,235 @@
+package registry
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A Multisig represents M-of-N signature scheme of
+// a feed. The Multisig lists N public keys and the
+// threshold M. A Root of such feed should have at
+// least M valid signatures of the keys to be
+// accepted (see Sigs field of the Root).
+//
+// The first Root of a head of the feed, signed by
+// owner of the feed, carries the Multisig (see
+// Multisig field of the Root). A node adopts the
+// Multisig receiving the Root, if the node doesn't
+// know Multisig of the feed yet. The signatures
+// are collected offline using PartialSig. Since,
+// every signature signs hash of a Root, the Root
+// can't be changed after the first signature
+type Multisig struct {
+	Keys      []cipher.PubKey // N public keys
+	Threshold int             // M, required signatures
+}
+
+// NewMultisig creates and validates Multisig
+// with given threshold and keys
+func NewMultisig(
+	threshold int, //           : M
+	keys ...cipher.PubKey, //   : N public keys
+) (
+	ms *Multisig, //            : the Multisig
+	err error, //               : ErrInvalidMultisig
+) {
+
+	ms = &Multisig{
+		Keys:      append([]cipher.PubKey{}, keys...),
+		Threshold: threshold,
+	}
+
+	if err = ms.Validate(); err != nil {
+		ms = nil
+	}
+
+	return
+}
+
+// Validate the Multisig. The Multisig should have
+// at least one key, keys should be unique and not
+// blank, and the threshold should be in [1, N]
+func (m *Multisig) Validate() (err error) {
+
+	if m.Threshold < 1 || m.Threshold > len(m.Keys) {
+		return ErrInvalidMultisig
+	}
+
+	var seen = make(map[cipher.PubKey]struct{}, len(m.Keys))
+
+	for _, pk := range m.Keys {
+
+		if pk == (cipher.PubKey{}) {
+			return ErrInvalidMultisig
+		}
+
+		if _, ok := seen[pk]; ok == true {
+			return ErrInvalidMultisig
+		}
+
+		seen[pk] = struct{}{}
+	}
+
+	return
+}
+
+// IsBlank returns true if the Multisig is blank
+func (m *Multisig) IsBlank() bool {
+	return len(m.Keys) == 0 && m.Threshold == 0
+}
+
+// Equal returns true if given Multisig has
+// the same keys (in the same order) and the
+// same threshold
+func (m *Multisig) Equal(o *Multisig) bool {
+
+	if m.Threshold != o.Threshold || len(m.Keys) != len(o.Keys) {
+		return false
+	}
+
+	for i, pk := range m.Keys {
+		if o.Keys[i] != pk {
+			return false
+		}
+	}
+
+	return true
+}
+
+// an encodedMultisig represents Multisig
+// that can be encoded (the int can't)
+type encodedMultisig struct {
+	Keys      []cipher.PubKey
+	Threshold uint32
+}
+
+func (m *Multisig) encoded() encodedMultisig {
+	return encodedMultisig{Keys: m.Keys, Threshold: uint32(m.Threshold)}
+}
+
+func (e *encodedMultisig) multisig() Multisig {
+	return Multisig{Keys: e.Keys, Threshold: int(e.Threshold)}
+}
+
+// Encode the Multisig
+func (m *Multisig) Encode() (p []byte) {
+	return encoder.Serialize(m.encoded())
+}
+
+// DecodeMultisig decodes and validates
+// given encoded Multisig
+func DecodeMultisig(p []byte) (ms *Multisig, err error) {
+
+	var em encodedMultisig
+
+	if err = encoder.DeserializeRaw(p, &em); err != nil {
+		return
+	}
+
+	var m = em.multisig()
+
+	if err = m.Validate(); err != nil {
+		return
+	}
+
+	return &m, nil
+}
+
+// Index returns index of given public key in
+// the Keys, or -1 if the Multisig doesn't
+// have the key
+func (m *Multisig) Index(pk cipher.PubKey) (i int) {
+
+	for k, key := range m.Keys {
+		if key == pk {
+			return k
+		}
+	}
+
+	return -1
+}
+
+// AddSig adds given PartialSig to Sigs of given
+// Root. The Root must have its final Hash. The
+// AddSig verifies the PartialSig and returns
+// ErrNotMultisigKey if the Multisig doesn't have
+// key of the PartialSig
+func (m *Multisig) AddSig(r *Root, ps PartialSig) (err error) {
+
+	var i = m.Index(ps.Key)
+
+	if i < 0 {
+		return ErrNotMultisigKey
+	}
+
+	if err = cipher.VerifySignature(ps.Key, ps.Sig, r.Hash); err != nil {
+		return
+	}
+
+	if len(r.Sigs) != len(m.Keys) {
+		var sigs = make([]cipher.Sig, len(m.Keys))
+		copy(sigs, r.Sigs)
+		r.Sigs = sigs
+	}
+
+	r.Sigs[i] = ps.Sig
+	return
+}
+
+// Verify given signatures of given hash of a Root.
+// A signature should be signature of a key with
+// the same index, or blank. All signatures should
+// be valid, and the number of signatures should
+// be equal to or greater then the threshold. The
+// Verify returns ErrInvalidMultisig, signature
+// verification error or ErrNotEnoughSignatures
+func (m *Multisig) Verify(
+	hash cipher.SHA256, // : hash of a Root
+	sigs []cipher.Sig, //  : signatures
+) (
+	err error, //          : the error
+) {
+
+	if len(sigs) > len(m.Keys) {
+		return ErrInvalidMultisig
+	}
+
+	var n int
+
+	for i, sig := range sigs {
+
+		if sig == (cipher.Sig{}) {
+			continue // not signed
+		}
+
+		if err = cipher.VerifySignature(m.Keys[i], sig, hash); err != nil {
+			return
+		}
+
+		n++
+	}
+
+	if n < m.Threshold {
+		return ErrNotEnoughSignatures
+	}
+
+	return
+}
+
+// A PartialSig represents signature of a key of
+// Multisig. Signers of a Root of a multisig feed
+// create PartialSig objects offline and send them
+// back to publisher of the Root, that collects
+// the signatures (see AddSig method of the Multisig)
+type PartialSig struct {
+	Key cipher.PubKey // public key of the signer
+	Sig cipher.Sig    // signature of hash of a Root
+}
+
+// NewPartialSig signs given hash of a Root
+// using given secret key
+func NewPartialSig(hash cipher.SHA256, sk cipher.SecKey) (ps PartialSig) {
+	ps.Key = cipher.PubKeyFromSecKey(sk)
+	ps.Sig = cipher.SignHash(hash, sk)
+	return
+}
@@ -0,0 +1,148 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func testMultisigKeys(n int) (pks []cipher.PubKey, sks []cipher.SecKey) {
+	for i := 0; i < n; i++ {
+		var pk, sk = cipher.GenerateKeyPair()
+		pks, sks = append(pks, pk), append(sks, sk)
+	}
+	return
+}
+
+func TestNewMultisig(t *testing.T) {
+	// NewMultisig(threshold int, keys ...cipher.PubKey) (*Multisig, error)
+
+	var pks, _ = testMultisigKeys(3)
+
+	if ms, err := NewMultisig(2, pks...); err != nil {
+		t.Error(err)
+	} else if ms.Index(pks[2]) != 2 {
+		t.Error("wrong index")
+	} else if ms.Index(cipher.PubKey{}) != -1 {
+		t.Error("wrong index of unknown key")
+	}
+
+	for i, invalid := range []struct {
+		threshold int
+		keys      []cipher.PubKey
+	}{
+		{0, pks},
+		{4, pks},
+		{1, nil},
+		{1, []cipher.PubKey{pks[0], pks[0]}},
+		{1, []cipher.PubKey{pks[0], {}}},
+	} {
+		if _, err := NewMultisig(invalid.threshold,
+			invalid.keys...); err != ErrInvalidMultisig {
+
+			t.Errorf("%d: wrong error: %v", i, err)
+		}
+	}
+
+}
+
+func TestDecodeMultisig(t *testing.T) {
+	// DecodeMultisig(p []byte) (ms *Multisig, err error)
+
+	var (
+		pks, _ = testMultisigKeys(3)
+		pk, _  = cipher.GenerateKeyPair()
+
+		ms, err = NewMultisig(2, pks...)
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dm *Multisig
+	if dm, err = DecodeMultisig(ms.Encode()); err != nil {
+		t.Fatal(err)
+	} else if dm.Equal(ms) == false {
+		t.Error("wrong decoded Multisig")
+	}
+
+	var invalid = Multisig{Keys: pks, Threshold: 4}
+	if _, err = DecodeMultisig(invalid.Encode()); err != ErrInvalidMultisig {
+		t.Error("wrong error:", err)
+	}
+
+	// Root
+
+	var r = &Root{Pub: pk, Multisig: *ms}
+
+	var dr *Root
+	if dr, err = DecodeRoot(r.Encode()); err != nil {
+		t.Fatal(err)
+	} else if dr.Multisig.Equal(ms) == false {
+		t.Error("wrong decoded Multisig of Root")
+	}
+
+}
+
+func TestMultisig_AddSig(t *testing.T) {
+	// AddSig(r *Root, ps PartialSig) (err error)
+
+	var (
+		pks, sks = testMultisigKeys(3)
+		_, xs    = cipher.GenerateKeyPair()
+		ms, err  = NewMultisig(2, pks...)
+		r        = &Root{Hash: cipher.SumSHA256([]byte("root"))}
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ms.AddSig(r, NewPartialSig(r.Hash, xs)); err != ErrNotMultisigKey {
+		t.Error("wrong error:", err)
+	}
+
+	var forged = NewPartialSig(cipher.SumSHA256([]byte("x")), sks[0])
+
+	if err = ms.AddSig(r, forged); err == nil {
+		t.Error("missing error")
+	}
+
+	if err = ms.AddSig(r, NewPartialSig(r.Hash, sks[2])); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Sigs) != 3 || r.Sigs[2] == (cipher.Sig{}) {
+		t.Fatal("signature not added")
+	}
+
+	if err = ms.Verify(r.Hash, r.Sigs); err != ErrNotEnoughSignatures {
+		t.Error("wrong error:", err)
+	}
+
+	if err = ms.AddSig(r, NewPartialSig(r.Hash, sks[0])); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ms.Verify(r.Hash, r.Sigs); err != nil {
+		t.Error(err)
+	}
+
+	// wrong position
+
+	var sigs = []cipher.Sig{r.Sigs[2], {}, r.Sigs[0]}
+
+	if err = ms.Verify(r.Hash, sigs); err == nil {
+		t.Error("missing error")
+	}
+
+	// too many
+
+	if err = ms.Verify(r.Hash, append(r.Sigs, r.Sigs[0])); err !=
+		ErrInvalidMultisig {
+
+		t.Error("wrong error:", err)
+	}
+
+}
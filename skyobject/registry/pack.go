@@ -114,6 +114,23 @@ type Pack interface {
 	ClearFlags(Flags) // clear given Flags from internal (AND NOT)
 }
 
+// A Hasher is optional interface of a Pack. If a Pack
+// implements the Hasher, then Refs use it to calculate
+// hashes of its nodes. Otherwise, the SHA256 is used.
+// The Sum must return the same hash the Add method of
+// the Pack returns
+type Hasher interface {
+	Sum(val []byte) (key cipher.SHA256) // hash of given value
+}
+
+// hash of given value using the Pack
+func sum(pack Pack, val []byte) cipher.SHA256 {
+	if h, ok := pack.(Hasher); ok == true {
+		return h.Sum(val)
+	}
+	return cipher.SumSHA256(val)
+}
+
 // get by hash from the Pack and deocde to given pointer (obj)
 func get(
 	pack Pack, //          : pack to get from
@@ -1,8 +1,9 @@
 package registry
 
 import (
-	//"testing"
 	"errors"
+	"fmt"
+	"testing"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/encoder"
@@ -103,6 +104,59 @@ func (e *errorPack) Add([]byte) (_ cipher.SHA256, err error) {
 	return
 }
 
+// pack with another hash function
+
+type hasherPack struct {
+	*dummyPack
+}
+
+func (h *hasherPack) Sum(val []byte) cipher.SHA256 {
+	return cipher.SumSHA256(append([]byte("another "), val...))
+}
+
+func (h *hasherPack) Add(val []byte) (key cipher.SHA256, err error) {
+	key = h.Sum(val)
+	err = h.Set(key, val)
+	return
+}
+
+func TestHasher(t *testing.T) {
+
+	var (
+		pack = &hasherPack{getTestPack()}
+		refs Refs
+
+		hashes []cipher.SHA256
+	)
+
+	for i := 0; i < 10; i++ {
+		var hash, err = addToPack(pack, TestUser{Name: fmt.Sprint(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := refs.AppendHashes(pack, hashes...); err != nil {
+		t.Fatal(err)
+	}
+
+	if refs.Hash == (cipher.SHA256{}) {
+		t.Fatal("blank hash of the Refs")
+	}
+
+	for key, val := range pack.vals {
+		if pack.Sum(val) != key {
+			t.Error("object saved with hash of another algorithm")
+		}
+	}
+
+	if _, ok := pack.vals[refs.Hash]; ok == false {
+		t.Error("the Refs is not saved by its hash")
+	}
+
+}
+
 //
 // helper method
 //
@@ -0,0 +1,589 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A Query is parsed query over a Root. The query language
+// is a path to values of the Root with optional condition
+//
+//     Refs[0].Members[*].Name where Age > 30
+//
+// A path starts from the Refs of the Root and consists of
+//
+//     .Name       - field of a struct
+//     [5]         - element of an array, slice or Refs
+//     [*]         - all elements of an array, slice, Refs or Map
+//     [2:5]       - elements from 2 to 5 (exclusive), any
+//                   bound can be omitted: [2:], [:5]
+//     ["key"]     - value of a Map by key
+//
+// References (Ref, Dynamic, elements of Refs and values of a
+// Map) followed transparently. Nil references skipped. The
+// condition is evaluated against the last struct of the path.
+// E.g. against a result if the result is a struct, or against
+// the struct that contains the result. The condition is
+//
+//     Field op value
+//
+// where the Field is name of field (or dot separated path
+// of fields, for example Author.Name), op is one of =, ==,
+// !=, <, <=, >, >=, and value is number, "string", true or
+// false. The conditions can be combined using and, or, not
+// and parentheses. If a field of a condition can't be
+// reached (nil reference or object without the field),
+// then the condition is false
+type Query struct {
+	query string      // source
+	path  []queryStep // path
+	where queryExpr   // condition or nil
+}
+
+// ParseQuery parses given query. See Query for details
+func ParseQuery(query string) (q *Query, err error) {
+
+	var p = queryParser{lexer: queryLexer{src: query}}
+
+	if err = p.next(); err != nil {
+		return
+	}
+
+	q = &Query{query: strings.TrimSpace(query)}
+
+	if q.path, err = p.parsePath(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == queryTokenWhere {
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+		if q.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.tok.kind != queryTokenEOF {
+		return nil, p.unexpected()
+	}
+
+	return
+}
+
+// String returns the query
+func (q *Query) String() string {
+	return q.query
+}
+
+//
+// steps
+//
+
+type queryStepKind int
+
+const (
+	queryStepField queryStepKind = iota // .Name
+	queryStepIndex                      // [i]
+	queryStepAll                        // [*]
+	queryStepRange                      // [i:j]
+	queryStepKey                        // ["key"]
+)
+
+// a queryStep is part of path
+type queryStep struct {
+	kind     queryStepKind
+	name     string // field name or key
+	from, to int    // index or range, the to < 0 means the end
+}
+
+//
+// conditions
+//
+
+// a queryExpr is condition of a Query
+type queryExpr interface {
+	eval(qe *queryEval, ctx *queryNode) (ok bool, err error)
+}
+
+type queryOr struct{ a, b queryExpr }
+
+type queryAnd struct{ a, b queryExpr }
+
+type queryNot struct{ x queryExpr }
+
+// a queryCmp is comparison of a field with a literal
+type queryCmp struct {
+	field []string    // path to the field
+	op    string      // the operator
+	value interface{} // bool, string, int64, uint64 or float64
+}
+
+//
+// lexer
+//
+
+type queryTokenKind int
+
+const (
+	queryTokenEOF    queryTokenKind = iota // end of the query
+	queryTokenIdent                        // identifier
+	queryTokenNumber                       // number
+	queryTokenString                       // "quoted string"
+	queryTokenPunct                        // . [ ] * : ( )
+	queryTokenOp                           // = == != < <= > >=
+	queryTokenWhere                        // where
+	queryTokenAnd                          // and
+	queryTokenOr                           // or
+	queryTokenNot                          // not
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	val  string // value
+	pos  int    // position in the query
+}
+
+func (q *queryToken) String() string {
+	if q.kind == queryTokenEOF {
+		return "end of query"
+	}
+	return strconv.Quote(q.val)
+}
+
+type queryLexer struct {
+	src string
+	pos int
+}
+
+func (q *queryLexer) next() (tok queryToken, err error) {
+
+	for q.pos < len(q.src) && unicode.IsSpace(rune(q.src[q.pos])) {
+		q.pos++
+	}
+
+	tok.pos = q.pos
+
+	if q.pos == len(q.src) {
+		return // EOF
+	}
+
+	var (
+		c     = q.src[q.pos]
+		start = q.pos
+	)
+
+	switch {
+
+	case c == '_' || unicode.IsLetter(rune(c)):
+
+		for q.pos < len(q.src) && (q.src[q.pos] == '_' ||
+			unicode.IsLetter(rune(q.src[q.pos])) ||
+			unicode.IsDigit(rune(q.src[q.pos]))) {
+
+			q.pos++
+		}
+
+		tok.val = q.src[start:q.pos]
+
+		switch tok.val {
+		case "where":
+			tok.kind = queryTokenWhere
+		case "and":
+			tok.kind = queryTokenAnd
+		case "or":
+			tok.kind = queryTokenOr
+		case "not":
+			tok.kind = queryTokenNot
+		default:
+			tok.kind = queryTokenIdent
+		}
+
+	case c == '-' || unicode.IsDigit(rune(c)):
+
+		q.pos++
+
+		for q.pos < len(q.src) && (q.src[q.pos] == '.' ||
+			unicode.IsDigit(rune(q.src[q.pos]))) {
+
+			q.pos++
+		}
+
+		tok.kind, tok.val = queryTokenNumber, q.src[start:q.pos]
+
+	case c == '"':
+
+		for q.pos++; q.pos < len(q.src) && q.src[q.pos] != '"'; q.pos++ {
+			if q.src[q.pos] == '\\' {
+				q.pos++ // skip escaped
+			}
+		}
+
+		if q.pos >= len(q.src) {
+			return tok, fmt.Errorf("query: unterminated string at %d", start)
+		}
+
+		q.pos++
+
+		tok.kind = queryTokenString
+
+		if tok.val, err = strconv.Unquote(q.src[start:q.pos]); err != nil {
+			return tok, fmt.Errorf("query: invalid string at %d: %v",
+				start, err)
+		}
+
+	case strings.IndexByte(".[]*:()", c) >= 0:
+
+		q.pos++
+		tok.kind, tok.val = queryTokenPunct, string(c)
+
+	case strings.IndexByte("=!<>", c) >= 0:
+
+		q.pos++
+
+		if q.pos < len(q.src) && q.src[q.pos] == '=' {
+			q.pos++
+		}
+
+		tok.kind, tok.val = queryTokenOp, q.src[start:q.pos]
+
+		if tok.val == "!" {
+			return tok, fmt.Errorf("query: unexpected \"!\" at %d", start)
+		}
+
+	default:
+		return tok, fmt.Errorf("query: unexpected %q at %d", c, start)
+
+	}
+
+	return
+}
+
+//
+// parser
+//
+
+type queryParser struct {
+	lexer queryLexer
+	tok   queryToken // current token
+}
+
+func (q *queryParser) next() (err error) {
+	q.tok, err = q.lexer.next()
+	return
+}
+
+func (q *queryParser) unexpected() error {
+	return fmt.Errorf("query: unexpected %s at %d", q.tok.String(),
+		q.tok.pos)
+}
+
+func (q *queryParser) isPunct(p string) bool {
+	return q.tok.kind == queryTokenPunct && q.tok.val == p
+}
+
+func (q *queryParser) expectPunct(p string) (err error) {
+	if q.isPunct(p) == false {
+		return q.unexpected()
+	}
+	return q.next()
+}
+
+func (q *queryParser) parseIndex() (i int, err error) {
+
+	if q.tok.kind != queryTokenNumber {
+		return 0, q.unexpected()
+	}
+
+	var u uint64
+	if u, err = strconv.ParseUint(q.tok.val, 10, 31); err != nil {
+		return 0, fmt.Errorf("query: invalid index %s at %d",
+			q.tok.String(), q.tok.pos)
+	}
+
+	return int(u), q.next()
+}
+
+// path = Name { "." Name | "[" selector "]" }
+func (q *queryParser) parsePath() (path []queryStep, err error) {
+
+	if q.tok.kind != queryTokenIdent {
+		return nil, q.unexpected()
+	}
+
+	path = append(path, queryStep{kind: queryStepField, name: q.tok.val})
+
+	if err = q.next(); err != nil {
+		return
+	}
+
+	for {
+
+		switch {
+
+		case q.isPunct("."):
+
+			if err = q.next(); err != nil {
+				return
+			}
+
+			if q.tok.kind != queryTokenIdent {
+				return nil, q.unexpected()
+			}
+
+			path = append(path, queryStep{
+				kind: queryStepField,
+				name: q.tok.val,
+			})
+
+			if err = q.next(); err != nil {
+				return
+			}
+
+		case q.isPunct("["):
+
+			if err = q.next(); err != nil {
+				return
+			}
+
+			var step queryStep
+			if step, err = q.parseSelector(); err != nil {
+				return
+			}
+
+			if err = q.expectPunct("]"); err != nil {
+				return
+			}
+
+			path = append(path, step)
+
+		default:
+			return // end of the path
+
+		}
+
+	}
+
+}
+
+// selector = "*" | index | [index] ":" [index] | string
+func (q *queryParser) parseSelector() (step queryStep, err error) {
+
+	switch {
+
+	case q.isPunct("*"):
+		step.kind = queryStepAll
+		return step, q.next()
+
+	case q.tok.kind == queryTokenString:
+		step.kind, step.name = queryStepKey, q.tok.val
+		return step, q.next()
+
+	case q.isPunct(":"):
+		step.kind, step.from = queryStepRange, 0
+
+	default:
+
+		if step.from, err = q.parseIndex(); err != nil {
+			return
+		}
+
+		if q.isPunct(":") == false {
+			step.kind = queryStepIndex
+			return
+		}
+
+		step.kind = queryStepRange
+
+	}
+
+	// range, skip the ":"
+
+	if err = q.next(); err != nil {
+		return
+	}
+
+	if q.isPunct("]") == true {
+		step.to = -1 // the end
+		return
+	}
+
+	if step.to, err = q.parseIndex(); err != nil {
+		return
+	}
+
+	if step.to < step.from {
+		err = fmt.Errorf("query: invalid range [%d:%d]", step.from, step.to)
+	}
+
+	return
+}
+
+// or = and { "or" and }
+func (q *queryParser) parseOr() (x queryExpr, err error) {
+
+	if x, err = q.parseAnd(); err != nil {
+		return
+	}
+
+	for q.tok.kind == queryTokenOr {
+
+		if err = q.next(); err != nil {
+			return
+		}
+
+		var y queryExpr
+		if y, err = q.parseAnd(); err != nil {
+			return
+		}
+
+		x = &queryOr{x, y}
+	}
+
+	return
+}
+
+// and = unary { "and" unary }
+func (q *queryParser) parseAnd() (x queryExpr, err error) {
+
+	if x, err = q.parseUnary(); err != nil {
+		return
+	}
+
+	for q.tok.kind == queryTokenAnd {
+
+		if err = q.next(); err != nil {
+			return
+		}
+
+		var y queryExpr
+		if y, err = q.parseUnary(); err != nil {
+			return
+		}
+
+		x = &queryAnd{x, y}
+	}
+
+	return
+}
+
+// unary = "not" unary | "(" or ")" | comparison
+func (q *queryParser) parseUnary() (x queryExpr, err error) {
+
+	switch {
+
+	case q.tok.kind == queryTokenNot:
+
+		if err = q.next(); err != nil {
+			return
+		}
+
+		if x, err = q.parseUnary(); err != nil {
+			return
+		}
+
+		return &queryNot{x}, nil
+
+	case q.isPunct("("):
+
+		if err = q.next(); err != nil {
+			return
+		}
+
+		if x, err = q.parseOr(); err != nil {
+			return
+		}
+
+		return x, q.expectPunct(")")
+
+	}
+
+	return q.parseCmp()
+}
+
+// comparison = Name { "." Name } op literal
+func (q *queryParser) parseCmp() (x queryExpr, err error) {
+
+	var cmp queryCmp
+
+	for {
+
+		if q.tok.kind != queryTokenIdent {
+			return nil, q.unexpected()
+		}
+
+		cmp.field = append(cmp.field, q.tok.val)
+
+		if err = q.next(); err != nil {
+			return
+		}
+
+		if q.isPunct(".") == false {
+			break
+		}
+
+		if err = q.next(); err != nil {
+			return
+		}
+
+	}
+
+	if q.tok.kind != queryTokenOp {
+		return nil, q.unexpected()
+	}
+
+	if cmp.op = q.tok.val; cmp.op == "==" {
+		cmp.op = "="
+	}
+
+	if err = q.next(); err != nil {
+		return
+	}
+
+	if cmp.value, err = q.parseLiteral(); err != nil {
+		return
+	}
+
+	return &cmp, nil
+}
+
+// literal = number | string | true | false
+func (q *queryParser) parseLiteral() (val interface{}, err error) {
+
+	var tok = q.tok
+
+	switch {
+
+	case tok.kind == queryTokenString:
+		val = tok.val
+
+	case tok.kind == queryTokenIdent && tok.val == "true":
+		val = true
+
+	case tok.kind == queryTokenIdent && tok.val == "false":
+		val = false
+
+	case tok.kind == queryTokenNumber:
+
+		if val, err = strconv.ParseInt(tok.val, 10, 64); err == nil {
+			break
+		}
+
+		if val, err = strconv.ParseUint(tok.val, 10, 64); err == nil {
+			break
+		}
+
+		if val, err = strconv.ParseFloat(tok.val, 64); err != nil {
+			return nil, fmt.Errorf("query: invalid number %s at %d",
+				tok.String(), tok.pos)
+		}
+
+	default:
+		return nil, q.unexpected()
+
+	}
+
+	return val, q.next()
+}
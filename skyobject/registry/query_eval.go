@@ -0,0 +1,878 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A QueryResult represents value found by a Query
+type QueryResult struct {
+	Path   string        // path to the value, e.g. Refs[0].Members[1].Name
+	Schema Schema        // schema of the value
+	Hash   cipher.SHA256 // hash of the value, if the value is an object
+	Val    []byte        // encoded value
+	Cursor QueryCursor   // position of the result (see QueryAfter)
+}
+
+// A QueryCursor is opaque position of a result of a
+// Query. It's used to continue the query after the
+// result (see QueryAfter). A QueryCursor is valid
+// for the same query and the same Root only
+type QueryCursor []byte
+
+// positions of a QueryCursor; a position is
+// index (8 bytes big-endian, to keep order)
+// or key of a Map, for every iterated level
+func (q QueryCursor) positions() (ps [][]byte, err error) {
+
+	for p := []byte(q); len(p) > 0; {
+
+		var ln, n = binary.Uvarint(p)
+
+		if n <= 0 || uint64(len(p)-n) < ln {
+			return nil, ErrInvalidQueryCursor
+		}
+
+		ps, p = append(ps, p[n:n+int(ln)]), p[n+int(ln):]
+	}
+
+	return
+}
+
+// encode positions to QueryCursor
+func queryCursor(ps [][]byte) (q QueryCursor) {
+
+	var buf [binary.MaxVarintLen64]byte
+
+	for _, pos := range ps {
+		q = append(q, buf[:binary.PutUvarint(buf[:], uint64(len(pos)))]...)
+		q = append(q, pos...)
+	}
+
+	return
+}
+
+// position of element of an array, slice or Refs
+func queryIndex(i int) (pos []byte) {
+	pos = make([]byte, 8)
+	binary.BigEndian.PutUint64(pos, uint64(i))
+	return
+}
+
+// Item returns Item that represents the result. Name
+// of the Item is path of the result. The Item can be
+// transmitted through RPC
+func (q *QueryResult) Item() (it *Item) {
+	return itemData(q.Path, q.Schema, q.Val)
+}
+
+// A QueryFunc used to receive results of a Query.
+// Use ErrStopIteration to stop the query
+type QueryFunc func(res *QueryResult) (err error)
+
+// used to stop a query through nested iterations,
+// since Refs and Map clear the ErrStopIteration
+var errQueryStop = errors.New("stop query")
+
+// Query executes given query calling given queryFunc for
+// every value found. The values are streamed, e.g. they
+// are found and passed to the queryFunc one by one. The
+// Query loads only objects required by the query. Thus,
+// the Refs and Map are loaded lazily, and references not
+// used by the query are never loaded. The Pack should have
+// related Registry. See Query for details
+func (r *Root) Query(
+	pack Pack, //            : pack to load objects
+	q *Query, //             : the query
+	queryFunc QueryFunc, //  : the function
+) (
+	err error, //            : error if any
+) {
+	return r.QueryAfter(pack, q, nil, queryFunc)
+}
+
+// QueryAfter is the same as the Query, but it continues
+// the query after result with given cursor (see Cursor
+// field of the QueryResult). Elements of the Root tree
+// before the cursor are not loaded and not evaluated.
+// Thus, a query can be executed page by page. Use nil
+// cursor to start from the beginning
+func (r *Root) QueryAfter(
+	pack Pack, //            : pack to load objects
+	q *Query, //             : the query
+	after QueryCursor, //    : continue after this result
+	queryFunc QueryFunc, //  : the function
+) (
+	err error, //            : error if any
+) {
+
+	var first = q.path[0]
+
+	if first.name != "Refs" {
+		return fmt.Errorf("query: Root doesn't have field %q", first.name)
+	}
+
+	var (
+		steps = q.path[1:]
+		sel   = queryStep{kind: queryStepAll, to: -1}
+	)
+
+	// Refs means Refs[*]
+	if len(steps) > 0 && steps[0].kind != queryStepField {
+		sel, steps = steps[0], steps[1:]
+	}
+
+	if len(r.Refs) == 0 {
+		return // nothing to find
+	}
+
+	var qe = queryEval{pack: pack, reg: pack.Registry(), q: q, fn: queryFunc}
+
+	if qe.reg == nil {
+		return ErrMissingRegistry
+	}
+
+	if qe.after, err = after.positions(); err != nil {
+		return
+	}
+
+	var root = &queryNode{resume: len(qe.after) > 0}
+
+	err = qe.indexes(root, sel, "Refs", len(r.Refs), func(i int) (err error) {
+
+		var n *queryNode
+		n, err = qe.dynamic("Refs["+strconv.Itoa(i)+"]", &r.Refs[i], nil)
+
+		if err != nil || n == nil {
+			return
+		}
+
+		return qe.walk(qe.child(root, n, queryIndex(i)), steps)
+	})
+
+	if err == errQueryStop {
+		err = nil
+	}
+
+	return
+}
+
+// a queryNode is value of a Root tree
+type queryNode struct {
+	path string        // path to the value
+	sch  Schema        // schema of the value
+	val  []byte        // encoded value
+	hash cipher.SHA256 // hash of the object, if the value is an object
+	ctx  *queryNode    // the last struct of the path
+
+	pos    [][]byte // positions of iterated levels
+	resume bool     // the pos is prefix of cursor
+}
+
+// inherit position of given node
+func (n *queryNode) inherit(from *queryNode) *queryNode {
+	n.pos, n.resume = from.pos, from.resume
+	return n
+}
+
+// newQueryNode creates queryNode and sets its ctx
+func newQueryNode(
+	path string, //        : path to the value
+	sch Schema, //         : schema of the value
+	val []byte, //         : encoded value
+	hash cipher.SHA256, // : hash of the value or blank
+	ctx *queryNode, //     : the last struct
+) (
+	n *queryNode, //       : the node
+) {
+
+	n = &queryNode{path: path, sch: sch, val: val, hash: hash, ctx: ctx}
+
+	if sch.IsReference() == false && sch.Kind() == reflect.Struct {
+		n.ctx = n
+	}
+
+	return
+}
+
+// a queryEval executes a Query
+type queryEval struct {
+	pack Pack
+	reg  *Registry
+	q    *Query
+	fn   QueryFunc
+
+	after [][]byte // positions of cursor
+}
+
+// cursor position on level of children of given
+// node, if the node is on the path of the cursor
+func (q *queryEval) cursorAt(n *queryNode) (pos []byte, ok bool) {
+	if n.resume == true && len(n.pos) < len(q.after) {
+		return q.after[len(n.pos)], true
+	}
+	return
+}
+
+// skip returns true if child of given node at
+// given position is before the cursor or is the
+// result of the cursor
+func (q *queryEval) skip(n *queryNode, pos []byte) bool {
+
+	var cur, ok = q.cursorAt(n)
+
+	if ok == false {
+		return false
+	}
+
+	var c = bytes.Compare(pos, cur)
+
+	return c < 0 || (c == 0 && len(n.pos)+1 == len(q.after))
+}
+
+// child sets position of given child of given node
+func (q *queryEval) child(
+	n *queryNode, //  : parent
+	ch *queryNode, // : child
+	pos []byte, //    : position of the child
+) *queryNode {
+
+	ch.pos = append(append(make([][]byte, 0, len(n.pos)+1), n.pos...), pos)
+
+	if cur, ok := q.cursorAt(n); ok == true {
+		ch.resume = bytes.Equal(pos, cur)
+	}
+
+	return ch
+}
+
+// walk the node by given steps
+func (q *queryEval) walk(n *queryNode, steps []queryStep) (err error) {
+
+	// follow references
+
+	if n, err = q.deref(n); err != nil || n == nil {
+		return
+	}
+
+	if len(steps) == 0 {
+		return q.yield(n)
+	}
+
+	var step = steps[0]
+
+	if steps = steps[1:]; step.kind == queryStepField {
+		return q.field(n, step.name, steps)
+	}
+
+	switch n.sch.ReferenceType() {
+	case ReferenceTypeSlice:
+		return q.refs(n, step, steps)
+	case ReferenceTypeMap:
+		return q.mapValues(n, step, steps)
+	}
+
+	switch n.sch.Kind() {
+	case reflect.Array, reflect.Slice:
+		return q.slice(n, step, steps)
+	}
+
+	return fmt.Errorf("query: %s (%s) is not an array, slice, Refs or Map",
+		n.path, n.sch)
+}
+
+// yield the node if it matches the condition
+func (q *queryEval) yield(n *queryNode) (err error) {
+
+	if q.q.where != nil {
+
+		if n.ctx == nil {
+			return fmt.Errorf("query: %s (%s) is not a struct and not a "+
+				"field of a struct to check condition", n.path, n.sch)
+		}
+
+		var ok bool
+		if ok, err = q.q.where.eval(q, n.ctx); err != nil || ok == false {
+			return
+		}
+
+	}
+
+	err = q.fn(&QueryResult{
+		Path:   n.path,
+		Schema: n.sch,
+		Hash:   n.hash,
+		Val:    n.val,
+		Cursor: queryCursor(n.pos),
+	})
+
+	if err == ErrStopIteration {
+		err = errQueryStop
+	}
+
+	return
+}
+
+// deref follows Ref and Dynamic, it returns nil
+// if the reference represents nil, and returns
+// given node if it's not a Ref or Dynamic
+func (q *queryEval) deref(n *queryNode) (d *queryNode, err error) {
+
+	switch n.sch.ReferenceType() {
+
+	case ReferenceTypeSingle:
+
+		var ref Ref
+		if err = encoder.DeserializeRaw(n.val, &ref); err != nil {
+			return
+		}
+
+		if ref.IsBlank() == true {
+			return // nil
+		}
+
+		var el Schema
+		if el = n.sch.Elem(); el == nil {
+			return nil, fmt.Errorf("query: missing schema of element of %s",
+				n.path)
+		}
+
+		if d, err = q.object(n.path, el, ref.Hash, n.ctx); err != nil {
+			return
+		}
+
+		return d.inherit(n), nil
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if err = encoder.DeserializeRaw(n.val, &dr); err != nil {
+			return
+		}
+
+		if d, err = q.dynamic(n.path, &dr, n.ctx); err != nil || d == nil {
+			return
+		}
+
+		return d.inherit(n), nil
+
+	}
+
+	return n, nil // not a Ref and not a Dynamic
+}
+
+// dynamic follows given Dynamic
+func (q *queryEval) dynamic(
+	path string, //     : path to the Dynamic
+	dr *Dynamic, //     : the Dynamic
+	ctx *queryNode, //  : the last struct
+) (
+	n *queryNode, //    : the object or nil
+	err error, //       : error if any
+) {
+
+	if dr.IsValid() == false {
+		return nil, ErrInvalidDynamicReference
+	}
+
+	if dr.IsBlank() == true {
+		return // nil
+	}
+
+	var sch Schema
+	if sch, err = q.reg.SchemaByReference(dr.Schema); err != nil {
+		return
+	}
+
+	return q.object(path, sch, dr.Hash, ctx)
+}
+
+// object loads object by hash
+func (q *queryEval) object(
+	path string, //        : path to the object
+	sch Schema, //         : schema of the object
+	hash cipher.SHA256, // : hash of the object
+	ctx *queryNode, //     : the last struct
+) (
+	n *queryNode, //       : the object
+	err error, //          : error if any
+) {
+
+	var val []byte
+	if val, err = q.pack.Get(hash); err != nil {
+		return
+	}
+
+	return newQueryNode(path, sch, val, hash, ctx), nil
+}
+
+// field of struct
+func (q *queryEval) field(
+	n *queryNode, //        : the struct
+	name string, //         : name of the field
+	steps []queryStep, //   : next steps
+) (
+	err error, //           : error if any
+) {
+
+	var (
+		fl Field
+		p  []byte
+	)
+
+	if fl, p, err = fieldOfEncoded(n.sch, n.val, name); err != nil {
+		return fmt.Errorf("query: %s: %v", n.path, err)
+	}
+
+	return q.walk(
+		newQueryNode(n.path+"."+name, fl.Schema(), p, cipher.SHA256{},
+			n.ctx).inherit(n),
+		steps,
+	)
+}
+
+// indexes calls given function for indices
+// selected by given step, skipping indices
+// before the cursor
+func (q *queryEval) indexes(
+	n *queryNode, //          : the array
+	step queryStep, //        : the selector
+	path string, //           : path to the array
+	ln int, //                : length of the array
+	fn func(i int) error, //  : the function
+) (
+	err error, //             : error if any
+) {
+
+	var from, to = step.from, step.to
+
+	switch step.kind {
+	case queryStepIndex:
+		to = from + 1
+	case queryStepAll:
+		from, to = 0, ln
+	case queryStepRange:
+		if to < 0 || to > ln {
+			to = ln
+		}
+	default:
+		return fmt.Errorf("query: %s is not a Map", path)
+	}
+
+	for i := from; i < to && i < ln; i++ {
+		if q.skip(n, queryIndex(i)) == true {
+			continue
+		}
+		if err = fn(i); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// elements of array or slice
+func (q *queryEval) slice(
+	n *queryNode, //        : the array or slice
+	step queryStep, //      : the selector
+	steps []queryStep, //   : next steps
+) (
+	err error, //           : error if any
+) {
+
+	var (
+		el = n.sch.Elem()
+		p  = n.val
+		ln int
+	)
+
+	if el == nil {
+		return fmt.Errorf("query: missing schema of element of %s", n.path)
+	}
+
+	if n.sch.Kind() == reflect.Array {
+		ln = n.sch.Len()
+	} else {
+		if len(p) < 4 {
+			return ErrInvalidSchemaOrData
+		}
+		ln, p = int(binary.LittleEndian.Uint32(p)), p[4:]
+	}
+
+	// sizes of elements can be different,
+	// thus, we have to skip all previous
+
+	var (
+		shift int
+		next  = 0
+	)
+
+	return q.indexes(n, step, n.path, ln, func(i int) (err error) {
+
+		for ; next <= i; next++ {
+
+			var m int
+			if m, err = el.Size(p[shift:]); err != nil {
+				return
+			}
+
+			if next == i {
+				var en = newQueryNode(n.path+"["+strconv.Itoa(i)+"]", el,
+					p[shift:shift+m], cipher.SHA256{}, n.ctx)
+
+				en = q.child(n, en, queryIndex(i))
+
+				if err = q.walk(en, steps); err != nil {
+					return
+				}
+			}
+
+			shift += m
+		}
+
+		return
+	})
+
+}
+
+// elements of Refs
+func (q *queryEval) refs(
+	n *queryNode, //        : the Refs
+	step queryStep, //      : the selector
+	steps []queryStep, //   : next steps
+) (
+	err error, //           : error if any
+) {
+
+	var (
+		refs Refs
+		el   Schema
+		ln   int
+	)
+
+	if el = n.sch.Elem(); el == nil {
+		return fmt.Errorf("query: missing schema of element of %s", n.path)
+	}
+
+	if err = encoder.DeserializeRaw(n.val, &refs); err != nil {
+		return
+	}
+
+	if ln, err = refs.Len(q.pack); err != nil {
+		return
+	}
+
+	var element = func(i int, hash cipher.SHA256) (err error) {
+
+		if hash == (cipher.SHA256{}) || q.skip(n, queryIndex(i)) == true {
+			return // nil or before the cursor
+		}
+
+		var en *queryNode
+		en, err = q.object(n.path+"["+strconv.Itoa(i)+"]", el, hash, n.ctx)
+		if err != nil {
+			return
+		}
+
+		return q.walk(q.child(n, en, queryIndex(i)), steps)
+	}
+
+	switch step.kind {
+
+	case queryStepIndex:
+
+		if step.from >= ln {
+			return // out of range
+		}
+
+		var hash cipher.SHA256
+		if hash, err = refs.HashByIndex(q.pack, step.from); err != nil {
+			return
+		}
+
+		return element(step.from, hash)
+
+	case queryStepAll, queryStepRange:
+
+		var from, to = step.from, step.to
+
+		if step.kind == queryStepAll {
+			from, to = 0, -1
+		}
+
+		// fast forward to the cursor
+		if cur, ok := q.cursorAt(n); ok == true && len(cur) == 8 {
+			if i := int(binary.BigEndian.Uint64(cur)); i > from {
+				from = i
+			}
+		}
+
+		if from >= ln {
+			return // out of range
+		}
+
+		// the Refs loads branches lazily
+		return refs.AscendFrom(q.pack, from,
+			func(i int, hash cipher.SHA256) (err error) {
+				if to >= 0 && i >= to {
+					return ErrStopIteration
+				}
+				return element(i, hash)
+			})
+
+	}
+
+	return fmt.Errorf("query: %s is not a Map", n.path)
+}
+
+// values of Map
+func (q *queryEval) mapValues(
+	n *queryNode, //        : the Map
+	step queryStep, //      : the selector
+	steps []queryStep, //   : next steps
+) (
+	err error, //           : error if any
+) {
+
+	var (
+		m  Map
+		el Schema
+	)
+
+	if el = n.sch.Elem(); el == nil {
+		return fmt.Errorf("query: missing schema of element of %s", n.path)
+	}
+
+	if err = encoder.DeserializeRaw(n.val, &m); err != nil {
+		return
+	}
+
+	var value = func(key []byte, hash cipher.SHA256) (err error) {
+
+		if q.skip(n, key) == true {
+			return // before the cursor
+		}
+
+		var vn *queryNode
+		vn, err = q.object(n.path+"["+strconv.Quote(string(key))+"]", el,
+			hash, n.ctx)
+		if err != nil {
+			return
+		}
+
+		return q.walk(q.child(n, vn, key), steps)
+	}
+
+	switch step.kind {
+
+	case queryStepKey:
+
+		var hash cipher.SHA256
+		if hash, err = m.Get(q.pack, []byte(step.name)); err != nil {
+			if err == ErrNotFound {
+				err = nil
+			}
+			return
+		}
+
+		return value([]byte(step.name), hash)
+
+	case queryStepAll:
+
+		// fast forward to the cursor
+		var from, _ = q.cursorAt(n)
+
+		// the Map loads nodes lazily
+		return m.AscendRange(q.pack, from, nil, value)
+
+	}
+
+	return fmt.Errorf("query: index or range can't be used with Map %s",
+		n.path)
+}
+
+//
+// conditions
+//
+
+func (q *queryOr) eval(qe *queryEval, ctx *queryNode) (ok bool, err error) {
+	if ok, err = q.a.eval(qe, ctx); err != nil || ok == true {
+		return
+	}
+	return q.b.eval(qe, ctx)
+}
+
+func (q *queryAnd) eval(qe *queryEval, ctx *queryNode) (ok bool, err error) {
+	if ok, err = q.a.eval(qe, ctx); err != nil || ok == false {
+		return
+	}
+	return q.b.eval(qe, ctx)
+}
+
+func (q *queryNot) eval(qe *queryEval, ctx *queryNode) (ok bool, err error) {
+	if ok, err = q.x.eval(qe, ctx); err != nil {
+		return
+	}
+	return !ok, nil
+}
+
+func (q *queryCmp) eval(qe *queryEval, ctx *queryNode) (ok bool, err error) {
+
+	var (
+		n  = ctx
+		fl Field
+		p  []byte
+	)
+
+	for _, name := range q.field {
+
+		if n, err = qe.deref(n); err != nil || n == nil {
+			return // nil reference
+		}
+
+		if _, err = schemaField(n.sch, name); err != nil {
+			return false, nil // missing field
+		}
+
+		if fl, p, err = fieldOfEncoded(n.sch, n.val, name); err != nil {
+			return false, fmt.Errorf("query: %s: %v", n.path, err)
+		}
+
+		n = newQueryNode(n.path+"."+name, fl.Schema(), p, cipher.SHA256{},
+			n.ctx)
+	}
+
+	if n, err = qe.deref(n); err != nil || n == nil {
+		return // nil reference
+	}
+
+	var x interface{}
+	if x, err = decodeItemValue(n.sch, n.val); err != nil {
+		return false, fmt.Errorf("query: can't compare %s (%s)",
+			n.path, n.sch)
+	}
+
+	return queryCompare(n, x, q.op, q.value)
+}
+
+// queryCompare compares value of given node with a literal
+func queryCompare(
+	n *queryNode, //     : the node
+	x interface{}, //    : value of the node
+	op string, //        : the operator
+	y interface{}, //    : the literal
+) (
+	ok bool, //          : result
+	err error, //        : error if any
+) {
+
+	var c int
+
+	switch xv := x.(type) {
+
+	case bool:
+
+		var yv, is = y.(bool)
+
+		if is == false || (op != "=" && op != "!=") {
+			return false, fmt.Errorf("query: can't compare %s (%s) %s %v",
+				n.path, n.sch, op, y)
+		}
+
+		if xv != yv {
+			c = 1
+		}
+
+	case string:
+
+		var yv, is = y.(string)
+
+		if is == false {
+			return false, fmt.Errorf("query: can't compare %s (%s) with %v",
+				n.path, n.sch, y)
+		}
+
+		c = strings.Compare(xv, yv)
+
+	default:
+
+		var xf, yf *big.Float
+
+		if xf = queryNumber(x); xf == nil {
+			return // NaN
+		}
+
+		if yf = queryNumber(y); yf == nil {
+			return false, fmt.Errorf("query: can't compare %s (%s) with %v",
+				n.path, n.sch, y)
+		}
+
+		c = xf.Cmp(yf)
+
+	}
+
+	switch op {
+	case "=":
+		ok = c == 0
+	case "!=":
+		ok = c != 0
+	case "<":
+		ok = c < 0
+	case "<=":
+		ok = c <= 0
+	case ">":
+		ok = c > 0
+	case ">=":
+		ok = c >= 0
+	}
+
+	return
+}
+
+// queryNumber converts given number to big.Float
+// that can hold any int64, uint64 or float64; it
+// returns nil if given value is not a number or NaN
+func queryNumber(x interface{}) (f *big.Float) {
+
+	f = new(big.Float).SetPrec(128)
+
+	switch xv := x.(type) {
+	case int8:
+		f.SetInt64(int64(xv))
+	case int16:
+		f.SetInt64(int64(xv))
+	case int32:
+		f.SetInt64(int64(xv))
+	case int64:
+		f.SetInt64(xv)
+	case uint8:
+		f.SetUint64(uint64(xv))
+	case uint16:
+		f.SetUint64(uint64(xv))
+	case uint32:
+		f.SetUint64(uint64(xv))
+	case uint64:
+		f.SetUint64(xv)
+	case float32:
+		return queryNumber(float64(xv))
+	case float64:
+		if math.IsNaN(xv) == true {
+			return nil
+		}
+		f.SetFloat64(xv)
+	default:
+		return nil
+	}
+
+	return
+}
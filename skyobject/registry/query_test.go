@@ -0,0 +1,446 @@
+package registry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestParseQuery(t *testing.T) {
+	// ParseQuery(query string) (q *Query, err error)
+
+	for _, valid := range []string{
+		"Refs",
+		"Refs[0]",
+		"Refs[*].Name",
+		" Refs[1:] . Members [ :5 ] ",
+		`Refs[0].Users["alice"].Age`,
+		"Refs where Name = \"x\"",
+		"Refs[0].Members[*].Name where Age > 30",
+		"Refs where Age >= -1 and Age <= 1.5 or not (Name != \"\\\"\")",
+		"Refs where (A == true or B < 10) and C.D > 18446744073709551615",
+	} {
+		if q, err := ParseQuery(valid); err != nil {
+			t.Errorf("%q: %v", valid, err)
+		} else if q.String() == "" {
+			t.Errorf("%q: blank String", valid)
+		}
+	}
+
+	for _, invalid := range []string{
+		"",
+		"[0]",
+		"Refs.",
+		"Refs[",
+		"Refs[-1]",
+		"Refs[5:2]",
+		"Refs[x]",
+		"Refs where",
+		"Refs where Name",
+		"Refs where Name = ",
+		"Refs where Name ! 1",
+		"Refs where Name = x",
+		"Refs where (Name = 1",
+		"Refs where Name = \"x",
+		"Refs Name",
+		"Refs # 1",
+	} {
+		if _, err := ParseQuery(invalid); err == nil {
+			t.Errorf("%q: missing error", invalid)
+		}
+	}
+
+}
+
+// a pack that counts Get calls
+type countPack struct {
+	*dummyPack
+	gets int
+}
+
+func (c *countPack) Get(key cipher.SHA256) (val []byte, err error) {
+	c.gets++
+	return c.dummyPack.Get(key)
+}
+
+func testQueryRoot(t *testing.T) (pack *countPack, r *Root) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		for _, tt := range testTypes() {
+			r.Register(tt.Name, tt.Val)
+		}
+		r.Register("test.MapGroup", TestMapGroup{})
+	})
+
+	pack = &countPack{dummyPack: testPackReg(reg)}
+
+	var (
+		grp  TestGroup
+		mgrp TestMapGroup
+		err  error
+	)
+
+	grp.Name = "the CXO"
+
+	if err = grp.Members.AppendValues(pack, getTestUsers(40)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = grp.Curator.SetValue(pack, &TestUser{Name: "Eva"}); err != nil {
+		t.Fatal(err)
+	}
+
+	grp.Developer = dynamicByValue(pack, &TestMan{"kostyarin", "logrusorgru"})
+
+	mgrp.Name = "the Map"
+
+	for _, name := range []string{"alice", "bob", "eve"} {
+		var usr = TestUser{Name: name, Age: uint32(len(name))}
+		if err = mgrp.Users.PutValue(pack, []byte(name), usr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r = new(Root)
+	r.Refs = []Dynamic{
+		dynamicByValue(pack, &grp),
+		{}, // nil
+		dynamicByValue(pack, &TestUser{Name: "Alice", Age: 21}),
+		dynamicByValue(pack, &mgrp),
+		dynamicByValue(pack, &TestSliceStruct{
+			String:       []string{"zero", "one", "two"},
+			StringStruct: []TestStringStruct{{"a"}, {"bb"}, {"ccc"}},
+		}),
+	}
+
+	return
+}
+
+func testQuery(
+	t *testing.T,
+	pack Pack,
+	r *Root,
+	query string,
+) (
+	rs []*QueryResult,
+) {
+
+	var (
+		q   *Query
+		err error
+	)
+
+	if q, err = ParseQuery(query); err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Query(pack, q, func(res *QueryResult) (_ error) {
+		rs = append(rs, res)
+		return
+	})
+
+	if err != nil {
+		t.Fatalf("%q: %v", query, err)
+	}
+
+	return
+}
+
+func testQueryPaths(t *testing.T, query string, rs []*QueryResult,
+	paths ...string) {
+
+	if len(rs) != len(paths) {
+		t.Errorf("%q: wrong number of results %d, want %d", query, len(rs),
+			len(paths))
+		return
+	}
+
+	for i, res := range rs {
+		if res.Path != paths[i] {
+			t.Errorf("%q: wrong path %q, want %q", query, res.Path, paths[i])
+		}
+	}
+
+}
+
+func TestRoot_Query(t *testing.T) {
+	// Query(pack Pack, q *Query, queryFunc QueryFunc) (err error)
+
+	var pack, r = testQueryRoot(t)
+
+	t.Run("where", func(t *testing.T) {
+
+		var (
+			query = "Refs[0].Members[*].Name where Age > 30"
+			rs    = testQuery(t, pack, r, query)
+			paths []string
+		)
+
+		for i := 31; i < 40; i++ {
+			paths = append(paths, fmt.Sprintf("Refs[0].Members[%d].Name", i))
+		}
+
+		testQueryPaths(t, query, rs, paths...)
+
+		for i, res := range rs {
+			var want = fmt.Sprintf("Alice #%d", 31+i+15)
+			if it := res.Item(); it.Value != want {
+				t.Errorf("wrong value %q, want %q", it.Value, want)
+			}
+		}
+
+	})
+
+	for _, tc := range []struct {
+		query string
+		paths []string
+	}{
+		{"Refs[0].Members[2:5]", []string{
+			"Refs[0].Members[2]",
+			"Refs[0].Members[3]",
+			"Refs[0].Members[4]",
+		}},
+		{"Refs[0].Members[38:]", []string{
+			"Refs[0].Members[38]",
+			"Refs[0].Members[39]",
+		}},
+		{"Refs[0].Members[40]", nil},
+		{"Refs[0].Curator.Name", []string{"Refs[0].Curator.Name"}},
+		{"Refs[0].Developer.GitHub", []string{"Refs[0].Developer.GitHub"}},
+		{"Refs[5:]", nil},
+		{"Refs", []string{"Refs[0]", "Refs[2]", "Refs[3]", "Refs[4]"}},
+		{"Refs[*] where Name = \"Alice\"", []string{"Refs[2]"}},
+		{"Refs where Age >= 21 and not (Name != \"Alice\")",
+			[]string{"Refs[2]"}},
+		{"Refs where Curator.Name = \"Eva\" or Age = 21",
+			[]string{"Refs[0]", "Refs[2]"}},
+		{"Refs[0].Members", []string{"Refs[0].Members"}},
+		{"Refs[0].Members[*] where Age < 2 or Age = 39.0", []string{
+			"Refs[0].Members[0]",
+			"Refs[0].Members[1]",
+			"Refs[0].Members[39]",
+		}},
+		{`Refs[3].Users["bob"].Age`, []string{`Refs[3].Users["bob"].Age`}},
+		{`Refs[3].Users["joe"]`, nil},
+		{"Refs[3].Users[*] where Age = 5", []string{
+			`Refs[3].Users["alice"]`,
+		}},
+		{"Refs[4].String[1:]", []string{
+			"Refs[4].String[1]",
+			"Refs[4].String[2]",
+		}},
+		{"Refs[4].StringStruct[*].String where String >= \"bb\"", []string{
+			"Refs[4].StringStruct[1].String",
+			"Refs[4].StringStruct[2].String",
+		}},
+	} {
+		testQueryPaths(t, tc.query, testQuery(t, pack, r, tc.query),
+			tc.paths...)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+
+		for _, query := range []string{
+			"Root",
+			"Refs[0].Nothing",
+			"Refs[0].Name[0]",
+			"Refs[0].Members[\"x\"]",
+			"Refs[3].Users[0]",
+			"Refs[4].String[*] where String = 1",
+			"Refs[2] where Age = \"x\"",
+			"Refs[2] where Name > true",
+		} {
+
+			var q, err = ParseQuery(query)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = r.Query(pack, q, func(*QueryResult) (_ error) { return })
+
+			if err == nil {
+				t.Errorf("%q: missing error", query)
+			}
+
+		}
+
+	})
+
+	t.Run("stop", func(t *testing.T) {
+
+		var (
+			q, _ = ParseQuery("Refs[*].Members[*]")
+			n    int
+		)
+
+		var err = r.Query(pack, q, func(*QueryResult) (err error) {
+			if n++; n == 2 {
+				err = ErrStopIteration
+			}
+			return
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != 2 {
+			t.Error("wrong number of results:", n)
+		}
+
+	})
+
+	t.Run("lazy", func(t *testing.T) {
+
+		pack.gets = 0
+
+		var (
+			query = "Refs[0].Members[0].Name"
+			rs    = testQuery(t, pack, r, query)
+		)
+
+		testQueryPaths(t, query, rs, "Refs[0].Members[0].Name")
+
+		// the group, the Refs, nodes of the Refs and the element;
+		// the depth of the Refs is 3 (degree 3, 40 elements)
+		if pack.gets > 6 {
+			t.Error("too many objects loaded:", pack.gets)
+		}
+
+	})
+
+	t.Run("missing registry", func(t *testing.T) {
+
+		var q, _ = ParseQuery("Refs")
+
+		if err := r.Query(testPackReg(nil), q, nil); err != ErrMissingRegistry {
+			t.Error("wrong error:", err)
+		}
+
+	})
+
+}
+
+func TestRoot_QueryAfter(t *testing.T) {
+	// QueryAfter(pack Pack, q *Query, after QueryCursor,
+	//     queryFunc QueryFunc) (err error)
+
+	var pack, r = testQueryRoot(t)
+
+	// page by page, using cursor of the last result
+	var paged = func(q *Query, limit int) (paths []string) {
+
+		var after QueryCursor
+
+		for {
+
+			var n int
+
+			var err = r.QueryAfter(pack, q, after,
+				func(res *QueryResult) (err error) {
+					paths = append(paths, res.Path)
+					after = res.Cursor
+					if n++; n == limit {
+						err = ErrStopIteration
+					}
+					return
+				})
+
+			if err != nil {
+				t.Fatalf("%q: %v", q, err)
+			}
+
+			if n < limit {
+				return // the end
+			}
+
+		}
+
+	}
+
+	for _, query := range []string{
+		"Refs",
+		"Refs[0].Members[*].Name where Age > 30",
+		"Refs[0].Members[3:7]",
+		"Refs[0].Curator.Name",
+		"Refs[3].Users[*]",
+		`Refs[3].Users["bob"].Age`,
+		"Refs[4].StringStruct[*].String",
+	} {
+
+		var (
+			q, _ = ParseQuery(query)
+			rs   = testQuery(t, pack, r, query)
+		)
+
+		for _, limit := range []int{1, 2, 3} {
+			testQueryPaths(t, query, rs, paged(q, limit)...)
+		}
+
+		if len(rs) == 0 {
+			continue
+		}
+
+		// after the last result
+		var err = r.QueryAfter(pack, q, rs[len(rs)-1].Cursor,
+			func(res *QueryResult) (_ error) {
+				t.Errorf("%q: unexpected result %q", query, res.Path)
+				return
+			})
+
+		if err != nil {
+			t.Error(err)
+		}
+
+	}
+
+	t.Run("lazy", func(t *testing.T) {
+
+		pack.gets = 0
+
+		var (
+			q, _   = ParseQuery("Refs[0].Members[*].Name")
+			rs     = testQuery(t, pack, r, "Refs[0].Members[*].Name")
+			paths  []string
+			cursor = rs[37].Cursor
+			full   = pack.gets
+		)
+
+		pack.gets = 0
+
+		var err = r.QueryAfter(pack, q, cursor,
+			func(res *QueryResult) (_ error) {
+				paths = append(paths, res.Path)
+				return
+			})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testQueryPaths(t, "after 37", rs[38:], paths...)
+
+		// the group, the Refs, nodes of the Refs and two elements;
+		// skipped elements are not loaded
+		if pack.gets > 10 || pack.gets*4 > full {
+			t.Error("too many objects loaded:", pack.gets, full)
+		}
+
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+
+		var q, _ = ParseQuery("Refs")
+
+		var err = r.QueryAfter(pack, q, QueryCursor{0xff},
+			func(*QueryResult) (_ error) { return })
+
+		if err != ErrInvalidQueryCursor {
+			t.Error("wrong error:", err)
+		}
+
+	})
+
+}
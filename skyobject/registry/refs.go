@@ -56,6 +56,9 @@ type Refs struct {
 
 	flags Flags `enc:"-"` // first use (load) flags
 
+	fieldIndexes   []*fieldIndex         `enc:"-"` // field indexes
+	fieldIndexMods map[cipher.SHA256]int `enc:"-"` // not indexed changes
+
 	// stack of iterators, if element is true, then length of the Refs
 	// has been changed and the iterator have to find next element
 	// from the Root (and set next element of the iterators slice to true
@@ -102,9 +105,14 @@ func (r *Refs) initialize(pack Pack) (err error) {
 		return // blank Refs, don't need to load
 	}
 
+	var val []byte
+	if val, err = pack.Get(r.Hash); err != nil {
+		return // get error
+	}
+
 	var er encodedRefs
-	if err = get(pack, r.Hash, &er); err != nil {
-		return // get or decoding error
+	if er, r.fieldIndexes, err = decodeRefs(val); err != nil {
+		return // decoding error
 	}
 
 	r.depth = int(er.Depth)
@@ -186,8 +194,15 @@ func (r *Refs) SetDegree(pack Pack, degree Degree) (err error) {
 
 	nr.iterators = r.iterators // copy iterators
 
+	// the same elements, the same indexes
+	nr.fieldIndexes, nr.fieldIndexMods = r.fieldIndexes, r.fieldIndexMods
+
 	*r = *nr
 
+	if len(r.fieldIndexes) > 0 {
+		return r.updateHashIfNeed(pack, r.flags&LazyUpdating == 0)
+	}
+
 	return
 }
 
@@ -557,6 +572,10 @@ func (r *Refs) encode() []byte {
 
 	}
 
+	if len(r.fieldIndexes) > 0 {
+		return r.encodeIndexed(&er)
+	}
+
 	return encoder.Serialize(er)
 }
 
@@ -585,11 +604,19 @@ func (r *Refs) updateHash(pack Pack) (err error) {
 		r.mods &^= contentMod          // clear the flag
 		r.mods |= originMod            // the Refs has been changed
 		r.leafs, r.branches = nil, nil // clear
+		r.fieldIndexMods = nil         // blank indexes
+		for _, fi := range r.fieldIndexes {
+			fi.keys.Clear()
+		}
+		return
+	}
+
+	if err = r.updateFieldIndexes(pack); err != nil {
 		return
 	}
 
 	val := r.encode()
-	hash := cipher.SumSHA256(val)
+	hash := sum(pack, val)
 
 	if r.Hash != hash {
 
@@ -652,7 +679,7 @@ func (r *Refs) SetValueByIndex(
 	var hash cipher.SHA256
 
 	if isNil(obj) == false {
-		if hash, err = pack.Add(encoder.Serialize(obj)); err != nil {
+		if hash, err = addValue(pack, obj); err != nil {
 			return
 		}
 	}
@@ -1504,7 +1531,7 @@ func (r *Refs) AppendValues(
 
 		} else {
 
-			if hash, err = pack.Add(encoder.Serialize(val)); err != nil {
+			if hash, err = addValue(pack, val); err != nil {
 				return
 			}
 
@@ -1664,7 +1691,13 @@ func (r *Refs) Rebuild(
 		if slice, err = r.Slice(pack, 0, r.length); err != nil {
 			return
 		}
+		// the same elements, the same indexes
+		slice.fieldIndexes = r.fieldIndexes
+		slice.fieldIndexMods = r.fieldIndexMods
 		*r = *slice // replace
+		if len(r.fieldIndexes) > 0 {
+			err = r.updateHash(pack)
+		}
 	} else {
 		err = r.walkUpdating(pack)
 	}
@@ -0,0 +1,724 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A FieldIndexer is optional interface of a Pack. If a
+// Pack implements the FieldIndexer, then field indexes
+// returned by the FieldIndexes added to Refs of values
+// saved using the Pack, in addition to indexes defined
+// by tags. See Refs.AddFieldIndex for details
+type FieldIndexer interface {
+	// FieldIndexes returns names of fields of given
+	// schema of elements of Refs, that should be indexed
+	FieldIndexes(el Schema) (fields []string)
+}
+
+// A LookupFunc used to iterate over elements
+// found by field index. Use ErrStopIteration
+// to stop the iteration
+type LookupFunc func(hash cipher.SHA256) (err error)
+
+// flag of the Depth of encoded Refs with field indexes
+const fieldIndexedRefs uint32 = 1 << 31
+
+// length of hash and seq number in end of
+// key of a field index
+const fieldIndexSuffix = len(cipher.SHA256{}) + 4
+
+// a fieldIndex is persistent index of values of a field
+// of elements of Refs; keys of the Map are encoded field
+// value + hash of element + seq number of copy of the
+// element, and values of the Map are blank
+type fieldIndex struct {
+	field  string    // name of the field
+	schema SchemaRef // schema of elements
+	keys   Map       // the index
+}
+
+// an encodedFieldIndex represents fieldIndex in DB
+type encodedFieldIndex struct {
+	Field  string
+	Schema SchemaRef
+	Map    cipher.SHA256
+}
+
+// an encodedIndexedRefs represents encoded Refs that
+// has field indexes; the Depth has fieldIndexedRefs
+// flag to distinguish it from the encodedRefs
+type encodedIndexedRefs struct {
+	Depth    uint32
+	Degree   uint32
+	Length   uint32
+	Elements []cipher.SHA256
+	Indexes  []encodedFieldIndex
+}
+
+// decodeRefs decodes root of Refs that
+// can have field indexes
+func decodeRefs(val []byte) (
+	er encodedRefs, //          : the Refs
+	fis []*fieldIndex, //       : field indexes
+	err error, //               : error if any
+) {
+
+	if len(val) < 4 ||
+		binary.LittleEndian.Uint32(val)&fieldIndexedRefs == 0 {
+
+		err = encoder.DeserializeRaw(val, &er)
+		return
+	}
+
+	var eir encodedIndexedRefs
+	if err = encoder.DeserializeRaw(val, &eir); err != nil {
+		return
+	}
+
+	er = encodedRefs{
+		Depth:    eir.Depth &^ fieldIndexedRefs,
+		Degree:   eir.Degree,
+		Length:   eir.Length,
+		Elements: eir.Elements,
+	}
+
+	fis = make([]*fieldIndex, 0, len(eir.Indexes))
+
+	for _, efi := range eir.Indexes {
+		fis = append(fis, &fieldIndex{
+			field:  efi.Field,
+			schema: efi.Schema,
+			keys:   Map{Hash: efi.Map},
+		})
+	}
+
+	return
+}
+
+// encodeIndexed encodes given encodedRefs with
+// field indexes of the Refs
+func (r *Refs) encodeIndexed(er *encodedRefs) []byte {
+
+	var eir = encodedIndexedRefs{
+		Depth:    er.Depth | fieldIndexedRefs,
+		Degree:   er.Degree,
+		Length:   er.Length,
+		Elements: er.Elements,
+		Indexes:  make([]encodedFieldIndex, 0, len(r.fieldIndexes)),
+	}
+
+	for _, fi := range r.fieldIndexes {
+		eir.Indexes = append(eir.Indexes, encodedFieldIndex{
+			Field:  fi.field,
+			Schema: fi.schema,
+			Map:    fi.keys.Hash,
+		})
+	}
+
+	return encoder.Serialize(eir)
+}
+
+// fieldIndexByName returns field index or nil
+func (r *Refs) fieldIndexByName(field string) *fieldIndex {
+	for _, fi := range r.fieldIndexes {
+		if fi.field == field {
+			return fi
+		}
+	}
+	return nil
+}
+
+// fieldIndexChange records added (n > 0) or
+// removed (n < 0) element for field indexes
+func (r *Refs) fieldIndexChange(hash cipher.SHA256, n int) {
+
+	if len(r.fieldIndexes) == 0 || hash == (cipher.SHA256{}) {
+		return
+	}
+
+	if r.fieldIndexMods == nil {
+		r.fieldIndexMods = make(map[cipher.SHA256]int)
+	}
+
+	r.fieldIndexMods[hash] += n
+}
+
+//
+// public API
+//
+
+// AddFieldIndex creates persistent index of values of field
+// with given name of elements of the Refs. The el is schema
+// of elements of the Refs. The field must be bool, integer,
+// string, []byte or [N]byte. The index is part of the Refs
+// and it's updated by the Refs every time elements of the
+// Refs change. Thus, elements can be found by the field
+// using the Lookup and LookupRange methods without full scan
+//
+// The AddFieldIndex does nothing if the Refs already has index
+// of the field. Like the degree, blank Refs can't keep its
+// indexes in DB. Slice of the Refs doesn't have indexes
+//
+// Usually, field indexes defined using tags, and added
+// automatically
+//
+//     type Feed struct {
+//         Users registry.Refs `skyobject:"schema=pkg.User,index=Name"`
+//     }
+//
+// The big O of the call is O(n), where n is length of the Refs
+func (r *Refs) AddFieldIndex(
+	pack Pack, //     : pack to load and save
+	el Schema, //     : schema of elements
+	field string, //  : name of the field
+) (
+	err error, //     : error if any
+) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if fi := r.fieldIndexByName(field); fi != nil {
+		if fi.schema != el.Reference() {
+			return ErrInvalidFieldIndex
+		}
+		return // already have
+	}
+
+	var fl Field
+	if fl, err = schemaField(el, field); err != nil {
+		return
+	}
+
+	if isIndexableSchema(fl.Schema()) == false {
+		return ErrInvalidFieldIndex
+	}
+
+	// apply changes to other indexes first
+
+	if err = r.updateFieldIndexes(pack); err != nil {
+		return
+	}
+
+	var fi = &fieldIndex{
+		field:  field,
+		schema: el.Reference(),
+	}
+
+	// index existing elements
+
+	var counts = make(map[cipher.SHA256]int)
+
+	err = r.Ascend(pack, func(_ int, hash cipher.SHA256) (_ error) {
+		if hash != (cipher.SHA256{}) {
+			counts[hash]++
+		}
+		return
+	})
+
+	if err != nil {
+		return
+	}
+
+	if err = fi.update(pack, el, counts); err != nil {
+		return
+	}
+
+	r.fieldIndexes = append(r.fieldIndexes, fi)
+
+	r.mods |= contentMod
+	return r.updateHashIfNeed(pack, r.flags&LazyUpdating == 0)
+}
+
+// DelFieldIndex deletes field index of given field. It
+// returns ErrNotFound if the Refs doesn't have the index
+func (r *Refs) DelFieldIndex(pack Pack, field string) (err error) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	for i, fi := range r.fieldIndexes {
+
+		if fi.field != field {
+			continue
+		}
+
+		copy(r.fieldIndexes[i:], r.fieldIndexes[i+1:])
+		r.fieldIndexes[len(r.fieldIndexes)-1] = nil
+		r.fieldIndexes = r.fieldIndexes[:len(r.fieldIndexes)-1]
+
+		if len(r.fieldIndexes) == 0 {
+			r.fieldIndexMods = nil
+		}
+
+		r.mods |= contentMod
+		return r.updateHashIfNeed(pack, r.flags&LazyUpdating == 0)
+	}
+
+	return ErrNotFound
+}
+
+// FieldIndexes returns names of indexed fields
+func (r *Refs) FieldIndexes(pack Pack) (fields []string, err error) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	for _, fi := range r.fieldIndexes {
+		fields = append(fields, fi.field)
+	}
+
+	return
+}
+
+// Lookup returns hashes of elements that have given value
+// of given field. The Refs must have index of the field.
+// Type of the value must be compatible with type of the
+// field. E.g. any integer type can be used to find an
+// integer field. Every hash returned once, even if the
+// Refs contains many elements with the hash
+//
+// The big O of the call is O(log(n) + m), where n is length
+// of the Refs and m is number of elements found
+func (r *Refs) Lookup(
+	pack Pack, //              : pack to load
+	field string, //           : name of the field
+	value interface{}, //      : value of the field
+) (
+	hashes []cipher.SHA256, // : hashes of elements found
+	err error, //              : error if any
+) {
+
+	var fi *fieldIndex
+	var fl Field
+
+	if fi, fl, err = r.lookupFieldIndex(pack, field); err != nil {
+		return
+	}
+
+	var key []byte
+	if key, err = fieldKeyValue(fl.Schema(), value); err != nil {
+		return
+	}
+
+	// all keys are key + hash + seq
+
+	var to = append(append([]byte{}, key...),
+		bytes.Repeat([]byte{0xff}, fieldIndexSuffix+1)...)
+
+	err = fi.ascend(pack, key, to, func(hash cipher.SHA256) (_ error) {
+		hashes = append(hashes, hash)
+		return
+	})
+
+	return
+}
+
+// LookupRange calls given LookupFunc for elements with value
+// of given field from given value (inclusive) to given value
+// (exclusive), ordered by the value. Use nil for first or
+// last value. The Refs must have index of the field. See
+// also Lookup
+func (r *Refs) LookupRange(
+	pack Pack, //                : pack to load
+	field string, //             : name of the field
+	from interface{}, //         : from this value (or nil)
+	to interface{}, //           : to this value (or nil)
+	lookupFunc LookupFunc, //    : the function
+) (
+	err error, //                : error if any
+) {
+
+	var fi *fieldIndex
+	var fl Field
+
+	if fi, fl, err = r.lookupFieldIndex(pack, field); err != nil {
+		return
+	}
+
+	var fromKey, toKey []byte
+
+	if from != nil {
+		if fromKey, err = fieldKeyValue(fl.Schema(), from); err != nil {
+			return
+		}
+	}
+
+	if to != nil {
+		if toKey, err = fieldKeyValue(fl.Schema(), to); err != nil {
+			return
+		}
+	}
+
+	return fi.ascend(pack, fromKey, toKey, lookupFunc)
+}
+
+// lookupFieldIndex returns field index and its field
+func (r *Refs) lookupFieldIndex(
+	pack Pack, //       : pack to load
+	field string, //    : name of the field
+) (
+	fi *fieldIndex, //  : the index
+	fl Field, //        : the field
+	err error, //       : error if any
+) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if fi = r.fieldIndexByName(field); fi == nil {
+		return nil, nil, ErrNoSuchFieldIndex
+	}
+
+	// apply pending changes (LazyUpdating)
+	if err = r.updateFieldIndexes(pack); err != nil {
+		return
+	}
+
+	var sch Schema
+	if sch, err = fi.elemSchema(pack); err != nil {
+		return
+	}
+
+	fl, err = schemaField(sch, field)
+	return
+}
+
+//
+// updating
+//
+
+// updateFieldIndexes applies all changes of elements
+// of the Refs to field indexes
+func (r *Refs) updateFieldIndexes(pack Pack) (err error) {
+
+	if len(r.fieldIndexMods) == 0 {
+		return
+	}
+
+	for _, fi := range r.fieldIndexes {
+
+		var sch Schema
+		if sch, err = fi.elemSchema(pack); err != nil {
+			return
+		}
+
+		if err = fi.update(pack, sch, r.fieldIndexMods); err != nil {
+			return
+		}
+
+	}
+
+	r.fieldIndexMods = nil
+	return
+}
+
+// schema of elements
+func (f *fieldIndex) elemSchema(pack Pack) (sch Schema, err error) {
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		return nil, ErrMissingRegistry
+	}
+
+	return reg.SchemaByReference(f.schema)
+}
+
+// update the index by given changes (hash -> number of
+// added elements, negative for deleted elements)
+func (f *fieldIndex) update(
+	pack Pack, //                        : pack to load and save
+	sch Schema, //                       : schema of elements
+	mods map[cipher.SHA256]int, //       : changes
+) (
+	err error, //                        : error if any
+) {
+
+	// sorted to get the same index every time
+
+	var hashes = make([]cipher.SHA256, 0, len(mods))
+
+	for hash, n := range mods {
+		if n != 0 {
+			hashes = append(hashes, hash)
+		}
+	}
+
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+
+	for _, hash := range hashes {
+
+		var (
+			val []byte
+			fl  Field
+			p   []byte
+			key []byte
+		)
+
+		if val, err = pack.Get(hash); err != nil {
+			return
+		}
+
+		if fl, p, err = fieldOfEncoded(sch, val, f.field); err != nil {
+			return
+		}
+
+		if key, err = fieldKeyEncoded(fl.Schema(), p); err != nil {
+			return
+		}
+
+		if err = f.change(pack, key, hash, mods[hash]); err != nil {
+			return
+		}
+
+	}
+
+	return f.keys.Rebuild(pack) // LazyUpdating
+}
+
+// change number of copies of given element
+func (f *fieldIndex) change(
+	pack Pack, //          : pack to load and save
+	key []byte, //         : encoded value of the field
+	hash cipher.SHA256, // : hash of the element
+	n int, //              : number of copies to add or delete
+) (
+	err error, //          : error if any
+) {
+
+	var prefix = append(append([]byte{}, key...), hash[:]...)
+
+	// count existing copies
+
+	var count uint32
+
+	err = f.keys.AscendRange(pack, prefix,
+		append(append([]byte{}, prefix...), 0xff, 0xff, 0xff, 0xff),
+		func([]byte, cipher.SHA256) (_ error) {
+			count++
+			return
+		})
+
+	if err != nil {
+		return
+	}
+
+	for ; n > 0; n-- {
+		err = f.keys.Put(pack, fieldIndexKey(prefix, count), cipher.SHA256{})
+		if err != nil {
+			return
+		}
+		count++
+	}
+
+	for ; n < 0 && count > 0; n++ {
+		count--
+		if err = f.keys.Delete(pack, fieldIndexKey(prefix, count)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// key + hash + seq
+func fieldIndexKey(prefix []byte, seq uint32) (key []byte) {
+	key = make([]byte, len(prefix)+4)
+	copy(key, prefix)
+	binary.BigEndian.PutUint32(key[len(prefix):], seq)
+	return
+}
+
+// ascend calls given function for first copy
+// of every element with key in given range
+func (f *fieldIndex) ascend(
+	pack Pack, //             : pack to load
+	from, to []byte, //       : range of keys
+	lookupFunc LookupFunc, // : the function
+) (
+	err error, //             : error if any
+) {
+
+	return f.keys.AscendRange(pack, from, to,
+		func(key []byte, _ cipher.SHA256) (err error) {
+
+			var ln = len(key)
+
+			if ln < fieldIndexSuffix {
+				return ErrInvalidFieldIndex
+			}
+
+			if binary.BigEndian.Uint32(key[ln-4:]) != 0 {
+				return // not first copy
+			}
+
+			var hash cipher.SHA256
+			copy(hash[:], key[ln-fieldIndexSuffix:ln-4])
+
+			return lookupFunc(hash)
+		})
+}
+
+//
+// walk and split
+//
+
+// walkFieldIndexes walks through nodes of the field
+// indexes; values of the indexes are blank and the
+// walkFunc is not called for them
+func (r *Refs) walkFieldIndexes(
+	pack Pack, //         : pack to load
+	walkFunc WalkFunc, // : the function
+) (
+	err error, //         : error if any
+) {
+
+	var indexFunc = func(
+		hash cipher.SHA256,
+		depth int,
+	) (
+		deepper bool,
+		err error,
+	) {
+		if depth == 0 {
+			return // blank value
+		}
+		return walkFunc(hash, depth)
+	}
+
+	for _, fi := range r.fieldIndexes {
+		if fi.keys.Hash == (cipher.SHA256{}) {
+			continue
+		}
+		if err = fi.keys.Walk(pack, nil, indexFunc); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// splitFieldIndexes is Split for field indexes
+func (r *Refs) splitFieldIndexes(s Splitter) {
+	for _, fi := range r.fieldIndexes {
+		fi.keys.Split(s, nil) // values are blank
+	}
+}
+
+//
+// tags
+//
+
+// addValue adds field indexes to Refs of given value and
+// saves the value returning its hash
+func addValue(pack Pack, obj interface{}) (hash cipher.SHA256, err error) {
+
+	var val interface{}
+	if val, err = addFieldIndexes(pack, obj); err != nil {
+		return
+	}
+
+	return pack.Add(encoder.Serialize(val))
+}
+
+// addFieldIndexes adds field indexes defined by tags and
+// by FieldIndexer to Refs fields of given struct. It returns
+// given value, or pointer to its copy if the value is not
+// a pointer
+func addFieldIndexes(
+	pack Pack, //        : pack to load and save
+	obj interface{}, //  : the object
+) (
+	val interface{}, //  : the object or its modified copy
+	err error, //        : error if any
+) {
+
+	val = obj
+
+	var (
+		v  = reflect.Indirect(reflect.ValueOf(obj))
+		fi FieldIndexer
+		ok bool
+	)
+
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	fi, _ = pack.(FieldIndexer)
+
+	var t = v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+
+		var sf = t.Field(i)
+
+		if sf.Type != typeOfRefs || sf.PkgPath != "" {
+			continue // not a Refs or unexported
+		}
+
+		var fields []string
+		if fields, err = TagIndexes(sf.Tag); err != nil {
+			return
+		}
+
+		if fi == nil && len(fields) == 0 {
+			continue // no indexes
+		}
+
+		var reg = pack.Registry()
+
+		if reg == nil {
+			if len(fields) == 0 {
+				continue // can't get the schema
+			}
+			return nil, ErrMissingRegistry
+		}
+
+		var el Schema
+		if el, err = reg.SchemaByName(mustTagSchemaName(sf.Tag)); err != nil {
+			return
+		}
+
+		if fi != nil {
+			fields = append(fields, fi.FieldIndexes(el)...)
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		if v.CanAddr() == false {
+			var cp = reflect.New(t).Elem()
+			cp.Set(v)
+			v, val = cp, cp.Addr().Interface()
+		}
+
+		var refs *Refs
+		if refs, ok = v.Field(i).Addr().Interface().(*Refs); ok == false {
+			continue // never happens
+		}
+
+		for _, field := range fields {
+			if err = refs.AddFieldIndex(pack, el, field); err != nil {
+				return
+			}
+		}
+
+	}
+
+	return
+}
@@ -0,0 +1,458 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+type TestIndexedFeed struct {
+	Users Refs `skyobject:"schema=test.User,index=Name,index=Age"`
+}
+
+// pack that indexes Name field of test.User
+type indexerPack struct {
+	*dummyPack
+}
+
+func (*indexerPack) FieldIndexes(el Schema) (fields []string) {
+	if el.Name() == "test.User" {
+		fields = []string{"Name"}
+	}
+	return
+}
+
+func testUserSchema(t *testing.T, pack Pack) (sch Schema) {
+	var err error
+	if sch, err = pack.Registry().SchemaByName("test.User"); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+// save n users with given name prefix
+// and return hashes of the users
+func testFieldIndexUsers(
+	t *testing.T,
+	pack Pack,
+	prefix string,
+	n int,
+) (hashes []cipher.SHA256) {
+
+	for i := 0; i < n; i++ {
+
+		var (
+			usr = TestUser{
+				Name: fmt.Sprintf("%s #%d", prefix, i%7),
+				Age:  uint32(i % 11),
+			}
+			hash cipher.SHA256
+			err  error
+		)
+
+		if hash, err = pack.Add(encoder.Serialize(usr)); err != nil {
+			t.Fatal(err)
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	return
+}
+
+// check the Name and the Age indexes
+// of the Refs by content of the Refs
+func testFieldIndexCheck(t *testing.T, r *Refs, pack Pack) {
+
+	var (
+		names = make(map[string]map[cipher.SHA256]struct{})
+		ages  []uint32
+
+		usr TestUser
+		err error
+	)
+
+	err = r.Ascend(pack, func(i int, hash cipher.SHA256) (err error) {
+		if err = get(pack, hash, &usr); err != nil {
+			return
+		}
+		if names[usr.Name] == nil {
+			names[usr.Name] = make(map[cipher.SHA256]struct{})
+		}
+		names[usr.Name][hash] = struct{}{}
+		ages = append(ages, usr.Age)
+		return
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range names {
+
+		var hashes []cipher.SHA256
+		if hashes, err = r.Lookup(pack, "Name", name); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(hashes) != len(want) {
+			t.Fatalf("wrong number of elements found by %q: %d, want %d",
+				name, len(hashes), len(want))
+		}
+
+		for _, hash := range hashes {
+			if _, ok := want[hash]; ok == false {
+				t.Fatalf("wrong element found by %q", name)
+			}
+		}
+
+	}
+
+	if hashes, err := r.Lookup(pack, "Name", "Bob"); err != nil {
+		t.Fatal(err)
+	} else if len(hashes) != 0 {
+		t.Fatal("found missing element")
+	}
+
+	// the Age, ordered
+
+	sort.Slice(ages, func(i, j int) bool { return ages[i] < ages[j] })
+
+	var found []uint32
+
+	err = r.LookupRange(pack, "Age", nil, nil,
+		func(hash cipher.SHA256) (err error) {
+			if err = get(pack, hash, &usr); err == nil {
+				found = append(found, usr.Age)
+			}
+			return
+		})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the ages contains duplicates, but the found doesn't
+
+	var uniq = make(map[uint32]int)
+	for _, age := range ages {
+		uniq[age]++
+	}
+
+	if len(found) > len(ages) || len(found) < len(uniq) {
+		t.Fatalf("wrong number of elements found %d, want %d-%d",
+			len(found), len(uniq), len(ages))
+	}
+
+	for i := 1; i < len(found); i++ {
+		if found[i-1] > found[i] {
+			t.Fatal("wrong order of elements")
+		}
+	}
+
+}
+
+func TestRefs_AddFieldIndex(t *testing.T) {
+	// AddFieldIndex(pack Pack, el Schema, field string) (err error)
+
+	var (
+		pack = getTestPack()
+		sch  = testUserSchema(t, pack)
+	)
+
+	for _, flags := range testRefsFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		t.Logf("flags %08b", flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			t.Log("degree", degree)
+
+			var (
+				r      Refs
+				n      = int(degree)*int(degree) + 1
+				users  = testFieldIndexUsers(t, pack, "Alice", n)
+				others = testFieldIndexUsers(t, pack, "Eve", int(degree)*3+5)
+				err    error
+			)
+
+			clearRefs(t, &r, pack, degree)
+
+			// duplicates
+			if err = r.AppendHashes(pack, users[0], users[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = r.AppendHashes(pack, users...); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = r.AddFieldIndex(pack, sch, "Hidden"); err == nil {
+				t.Error("missing error") // not encoded field
+			}
+
+			for _, field := range []string{"Name", "Age", "Name"} {
+				if err = r.AddFieldIndex(pack, sch, field); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if fields, err := r.FieldIndexes(pack); err != nil {
+				t.Fatal(err)
+			} else if len(fields) != 2 {
+				t.Fatal("wrong field indexes", fields)
+			}
+
+			var check = func() {
+				testFieldIndexCheck(t, &r, pack)
+				if err := r.Rebuild(pack); err != nil {
+					t.Fatal(err)
+				}
+				if err := r.Reset(); err != nil {
+					t.Fatal(err)
+				}
+				testFieldIndexCheck(t, &r, pack)
+			}
+
+			check()
+
+			// change elements
+
+			if err = r.SetHashByIndex(pack, 1, others[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = r.DeleteByIndex(pack, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = r.DeleteByHash(pack, users[1]); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = r.InsertAt(pack, 2, others[1:]...); err != nil {
+				t.Fatal(err)
+			}
+
+			check()
+
+			if err = r.Splice(pack, 1, 5, others[0], others[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = r.SetDegree(pack, degree+1); err != nil {
+				t.Fatal(err)
+			}
+
+			check()
+
+			if err = r.DelFieldIndex(pack, "Age"); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = r.DelFieldIndex(pack, "Age"); err != ErrNotFound {
+				t.Error("wrong error:", err)
+			}
+
+			if _, err = r.Lookup(pack, "Age", 1); err != ErrNoSuchFieldIndex {
+				t.Error("wrong error:", err)
+			}
+
+			if _, err = r.Lookup(pack, "Name", 1); err != ErrInvalidIndexValue {
+				t.Error("wrong error:", err)
+			}
+
+		}
+
+	}
+
+}
+
+func TestRefs_LookupRange(t *testing.T) {
+	// LookupRange(pack Pack, field string, from, to interface{},
+	//     lookupFunc LookupFunc) (err error)
+
+	var (
+		pack  = getTestPack()
+		sch   = testUserSchema(t, pack)
+		users = getTestUsers(20)
+
+		r   Refs
+		err error
+	)
+
+	if err = r.AppendValues(pack, users...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = r.AddFieldIndex(pack, sch, "Age"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		from, to interface{}
+		first, n uint32
+	}{
+		{nil, nil, 0, 20},
+		{5, nil, 5, 15},
+		{nil, uint8(5), 0, 5},
+		{uint64(5), 10, 5, 5},
+		{10, 5, 10, 0},
+	} {
+
+		var (
+			age = tc.first
+			usr TestUser
+		)
+
+		err = r.LookupRange(pack, "Age", tc.from, tc.to,
+			func(hash cipher.SHA256) (err error) {
+				if err = get(pack, hash, &usr); err != nil {
+					return
+				}
+				if usr.Age != age {
+					t.Errorf("wrong age %d, want %d", usr.Age, age)
+				}
+				age++
+				return
+			})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if age-tc.first != tc.n {
+			t.Errorf("wrong number of elements %d, want %d (%v - %v)",
+				age-tc.first, tc.n, tc.from, tc.to)
+		}
+
+	}
+
+	for _, from := range []interface{}{"5", -1} {
+		if err = r.LookupRange(pack, "Age", from, nil, nil); err == nil {
+			t.Error("missing error")
+		}
+	}
+
+}
+
+func TestRefs_fieldIndexTag(t *testing.T) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Register("test.IndexedFeed", TestIndexedFeed{})
+	})
+
+	t.Run("tag", func(t *testing.T) {
+
+		var (
+			pack  = testPackReg(reg)
+			users = getTestUsers(30)
+
+			feed TestIndexedFeed
+			ref  Ref
+			err  error
+		)
+
+		if err = feed.Users.AppendValues(pack, users...); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = ref.SetValue(pack, feed); err != nil {
+			t.Fatal(err)
+		}
+
+		var got TestIndexedFeed
+		if err = ref.Value(pack, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		if fields, err := got.Users.FieldIndexes(pack); err != nil {
+			t.Fatal(err)
+		} else if len(fields) != 2 {
+			t.Fatal("wrong field indexes", fields)
+		}
+
+		testFieldIndexCheck(t, &got.Users, pack)
+
+		// walk through all objects
+
+		var sch Schema
+		if sch, err = reg.SchemaByName("test.IndexedFeed"); err != nil {
+			t.Fatal(err)
+		}
+
+		err = walkSchemaHash(pack, sch, ref.Hash, func(
+			hash cipher.SHA256,
+			depth int,
+		) (bool, error) {
+			if _, ok := pack.vals[hash]; ok == false {
+				t.Errorf("walk to missing object %s", hash.Hex()[:7])
+			}
+			return true, nil
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	})
+
+	t.Run("pack", func(t *testing.T) {
+
+		var (
+			pack  = &indexerPack{testPackReg(reg)}
+			users = getTestUsers(10)
+
+			r   Refs
+			ref Ref
+			err error
+		)
+
+		type Feed struct {
+			Users Refs `skyobject:"schema=test.User"`
+		}
+
+		if err = r.AppendValues(pack, users...); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = ref.SetValue(pack, &Feed{Users: r}); err != nil {
+			t.Fatal(err)
+		}
+
+		var got Feed
+		if err = ref.Value(pack, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		if fields, err := got.Users.FieldIndexes(pack); err != nil {
+			t.Fatal(err)
+		} else if len(fields) != 1 || fields[0] != "Name" {
+			t.Fatal("wrong field indexes", fields)
+		}
+
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+
+		type Feed struct {
+			Users Refs `skyobject:"schema=test.User,index=Hidden"`
+		}
+
+		defer shouldPanic(t)
+
+		NewRegistry(func(r *Reg) {
+			r.Register("test.User", TestUser{})
+			r.Register("test.Feed", Feed{})
+		})
+
+	})
+
+}
@@ -0,0 +1,200 @@
+package registry
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A LWWFunc used by MergeLWW to get key and
+// timestamp of an element of Refs. Elements
+// with the same key are versions of the same
+// register, and the latest one wins
+type LWWFunc func(hash cipher.SHA256) (key []byte, time int64, err error)
+
+// MergeSet merges given Refs to this one as
+// append-only sets (G-Set CRDT). E.g. after the
+// merge this Refs contains all unique elements of
+// this Refs and of the others. Nil elements and
+// duplicates are removed. The result is ordered by
+// hash. Thus, writers of a multi-writer feed that
+// merge the same sets in any order get the same
+// Refs with the same hash (if the Refs have the same
+// degree and flags). Field indexes of this Refs are
+// rebuilt after the merge, and the pack must have
+// registry in this case.
+//
+// All the Refs should have the same schema. There
+// are no internal checks for the schema
+func (r *Refs) MergeSet(
+	pack Pack, //       : pack to load and save
+	others ...*Refs, // : Refs to merge
+) (
+	err error, //       : error if any
+) {
+
+	var set = make(map[cipher.SHA256]struct{})
+
+	for _, refs := range append([]*Refs{r}, others...) {
+
+		err = refs.Ascend(pack, func(_ int, hash cipher.SHA256) (_ error) {
+			if hash != (cipher.SHA256{}) {
+				set[hash] = struct{}{}
+			}
+			return
+		})
+
+		if err != nil {
+			return
+		}
+
+	}
+
+	var hashes = make([]cipher.SHA256, 0, len(set))
+
+	for hash := range set {
+		hashes = append(hashes, hash)
+	}
+
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+
+	return r.replace(pack, hashes)
+}
+
+// MergeLWW merges given Refs to this one as
+// last-writer-wins registers (LWW-Element-Set CRDT).
+// Every element of the Refs is a version of a register.
+// Given LWWFunc returns key of the register and time of
+// the version. After the merge, this Refs contains
+// latest version of every register. If two versions
+// of a register have the same time, then version with
+// greater hash wins. Nil elements are removed. The
+// result is ordered by key. Thus, writers of a
+// multi-writer feed that merge the same Refs in any
+// order get the same Refs with the same hash (if the
+// Refs have the same degree and flags). Field indexes
+// of this Refs are rebuilt after the merge, and the
+// pack must have registry in this case.
+//
+// All the Refs should have the same schema. There
+// are no internal checks for the schema
+func (r *Refs) MergeLWW(
+	pack Pack, //       : pack to load and save
+	lwwFunc LWWFunc, // : key and time of an element
+	others ...*Refs, // : Refs to merge
+) (
+	err error, //       : error if any
+) {
+
+	type version struct {
+		key  []byte
+		time int64
+		hash cipher.SHA256
+	}
+
+	var (
+		regs = make(map[string]*version)
+		seen = make(map[cipher.SHA256]struct{})
+	)
+
+	for _, refs := range append([]*Refs{r}, others...) {
+
+		err = refs.Ascend(pack, func(_ int, hash cipher.SHA256) (err error) {
+
+			if hash == (cipher.SHA256{}) {
+				return // nil
+			}
+
+			if _, ok := seen[hash]; ok == true {
+				return // already processed
+			}
+
+			seen[hash] = struct{}{}
+
+			var v = &version{hash: hash}
+			if v.key, v.time, err = lwwFunc(hash); err != nil {
+				return
+			}
+
+			var last, ok = regs[string(v.key)]
+
+			if ok == false || last.time < v.time || (last.time == v.time &&
+				bytes.Compare(last.hash[:], v.hash[:]) < 0) {
+
+				regs[string(v.key)] = v
+			}
+
+			return
+		})
+
+		if err != nil {
+			return
+		}
+
+	}
+
+	var vs = make([]*version, 0, len(regs))
+
+	for _, v := range regs {
+		vs = append(vs, v)
+	}
+
+	sort.Slice(vs, func(i, j int) bool {
+		return bytes.Compare(vs[i].key, vs[j].key) < 0
+	})
+
+	var hashes = make([]cipher.SHA256, 0, len(vs))
+
+	for _, v := range vs {
+		hashes = append(hashes, v.hash)
+	}
+
+	return r.replace(pack, hashes)
+}
+
+// replace elements of the Refs with given
+// hashes, keeping degree, flags and field
+// indexes; the result doesn't depend on
+// history of the Refs
+func (r *Refs) replace(pack Pack, hashes []cipher.SHA256) (err error) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if len(r.iterators) > 0 {
+		return ErrRefsIterating
+	}
+
+	var (
+		nr  = newRefs(r.degree, r.flags, len(hashes))
+		fis = r.fieldIndexes
+		sch Schema
+	)
+
+	if err = nr.AppendHashes(pack, hashes...); err != nil {
+		return
+	}
+
+	for _, fi := range fis {
+
+		if pack.Registry() == nil {
+			return ErrMissingRegistry
+		}
+
+		if sch, err = pack.Registry().SchemaByReference(fi.schema); err != nil {
+			return
+		}
+
+		if err = nr.AddFieldIndex(pack, sch, fi.field); err != nil {
+			return
+		}
+
+	}
+
+	*r = *nr
+	return
+}
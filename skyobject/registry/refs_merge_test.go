@@ -0,0 +1,165 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+func testMergeRefs(t *testing.T, pack Pack, users ...TestUser) (r *Refs) {
+
+	r = new(Refs)
+
+	for _, usr := range users {
+		if err := r.AppendValues(pack, usr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return
+}
+
+func testMergeUsers(t *testing.T, pack Pack, r *Refs) (users []TestUser) {
+
+	var err = r.Ascend(pack, func(_ int, hash cipher.SHA256) (err error) {
+		var usr TestUser
+		if err = get(pack, hash, &usr); err != nil {
+			return
+		}
+		users = append(users, usr)
+		return
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestRefs_MergeSet(t *testing.T) {
+	// MergeSet(pack Pack, others ...*Refs) (err error)
+
+	var (
+		pack = testPackReg(testRegistry())
+
+		alice = TestUser{Name: "alice"}
+		bob   = TestUser{Name: "bob"}
+		eve   = TestUser{Name: "eve"}
+
+		a = testMergeRefs(t, pack, alice, bob)
+		b = testMergeRefs(t, pack, eve, bob, eve)
+
+		ac, bc = *a, *b
+	)
+
+	if err := a.MergeSet(pack, &bc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.MergeSet(pack, &ac); err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Hash != b.Hash {
+		t.Error("merged Refs are different")
+	}
+
+	if users := testMergeUsers(t, pack, a); len(users) != 3 {
+		t.Error("wrong number of users:", len(users))
+	}
+
+	t.Run("field index", func(t *testing.T) {
+
+		var (
+			c      = testMergeRefs(t, pack, alice)
+			sch, _ = pack.Registry().SchemaByName("test.User")
+		)
+
+		if err := c.AddFieldIndex(pack, sch, "Name"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.MergeSet(pack, &bc); err != nil {
+			t.Fatal(err)
+		}
+
+		var hashes, err = c.Lookup(pack, "Name", "eve")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(hashes) != 1 {
+			t.Error("wrong number of elements found:", len(hashes))
+		}
+
+	})
+
+}
+
+func TestRefs_MergeLWW(t *testing.T) {
+	// MergeLWW(pack Pack, lwwFunc LWWFunc, others ...*Refs) (err error)
+
+	var (
+		pack = testPackReg(testRegistry())
+
+		a = testMergeRefs(t, pack,
+			TestUser{Name: "alice", Age: 1},
+			TestUser{Name: "bob", Age: 2},
+		)
+		b = testMergeRefs(t, pack,
+			TestUser{Name: "bob", Age: 1},
+			TestUser{Name: "alice", Age: 3},
+			TestUser{Name: "eve", Age: 1},
+		)
+
+		ac, bc = *a, *b
+	)
+
+	var lwwFunc = func(hash cipher.SHA256) (key []byte, time int64,
+		err error) {
+
+		var val []byte
+		if val, err = pack.Get(hash); err != nil {
+			return
+		}
+
+		var usr TestUser
+		if err = encoder.DeserializeRaw(val, &usr); err != nil {
+			return
+		}
+
+		return []byte(usr.Name), int64(usr.Age), nil
+	}
+
+	if err := a.MergeLWW(pack, lwwFunc, &bc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.MergeLWW(pack, lwwFunc, &ac); err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Hash != b.Hash {
+		t.Error("merged Refs are different")
+	}
+
+	var users = testMergeUsers(t, pack, a)
+
+	if len(users) != 3 {
+		t.Fatal("wrong number of users:", len(users))
+	}
+
+	for i, want := range []TestUser{
+		{Name: "alice", Age: 3},
+		{Name: "bob", Age: 2},
+		{Name: "eve", Age: 1},
+	} {
+		if users[i].Name != want.Name || users[i].Age != want.Age {
+			t.Errorf("wrong user %d: %v, want %v", i, users[i], want)
+		}
+	}
+
+}
@@ -352,7 +352,7 @@ func (r *refsNode) updateHash(
 	// encode
 	var val = r.encode(depth)
 	// get hash
-	var hash = cipher.SumSHA256(val)
+	var hash = sum(pack, val)
 
 	if hash == r.hash {
 		return // the hash is the same
@@ -436,6 +436,9 @@ func (r *Refs) setElementHash(
 		return // nothing to change
 	}
 
+	r.fieldIndexChange(el.Hash, -1) // delete old
+	r.fieldIndexChange(hash, 1)     // add new
+
 	if r.flags&HashTableIndex != 0 {
 		r.delElementFromIndex(el) // delete old
 		el.Hash = hash
@@ -491,6 +494,8 @@ func (r *Refs) deleteElementByIndex(
 					r.delElementFromIndex(el) // remove from hash-table index
 				}
 
+				r.fieldIndexChange(el.Hash, -1)
+
 				rn.deleteElementByIndex(j) // remove from leafs
 				rn.length--                // decrement length
 
@@ -591,6 +596,7 @@ func (r *Refs) deleteElement(
 	}
 
 	up.deleteElementByIndex(i)
+	r.fieldIndexChange(el.Hash, -1)
 
 	for ; up != nil; up, depth = up.upper, depth+1 {
 
@@ -1181,6 +1187,8 @@ func (r *Refs) appendNode(
 			r.addElementToIndex(el)
 		}
 
+		r.fieldIndexChange(hash, 1)
+
 		ap.rn.leafs = append(ap.rn.leafs, el)
 		ap.rn.length++ // add
 
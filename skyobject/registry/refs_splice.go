@@ -0,0 +1,457 @@
+package registry
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// InsertAt inserts given hashes to the Refs before element
+// with given index. The i can be equal to length of the
+// Refs, in this case the hashes will be appended. The hashes
+// must point to objects of schema of the Refs. There are no
+// internal checks for the Schema
+//
+// The InsertAt changes only branches that contain the i-th
+// element. If a node can't fit new elements, then it will
+// be split, and upper node gets new branches. If the upper
+// node can't fit them too, then it will be split too, and so
+// on. If the Refs can't fit new branches, then its depth will
+// be increased. Thus, elements of other branches are not moved
+//
+// The big O of the call is O(depth * degree + m), where m is
+// number of the hashes
+func (r *Refs) InsertAt(
+	pack Pack, //               : pack to load and save
+	i int, //                   : insert before this index
+	hashes ...cipher.SHA256, // : hashes to insert
+) (
+	err error, //               : error if any
+) {
+
+	return r.Splice(pack, i, i, hashes...)
+}
+
+// Splice replaces elements from given i (inclusive) to given
+// j (exclusive) with given hashes. The i and j are like golang
+// slice indices [i:j]. The number of hashes can be less or
+// greater then j - i. Thus, the Splice can be used to delete
+// or insert a range of elements. The hashes must point to
+// objects of schema of the Refs. There are no internal checks
+// for the Schema
+//
+// The Splice changes only branches that contain replaced
+// elements. Deleted elements doesn't reduce depth of the
+// Refs. Use Rebuild to reduce depth if it's possible. See
+// also InsertAt
+func (r *Refs) Splice(
+	pack Pack, //               : pack to load and save
+	i int, //                   : start of the range (inclusive)
+	j int, //                   : end of the range (exclusive)
+	hashes ...cipher.SHA256, // : hashes to place instead of the range
+) (
+	err error, //               : error if any
+) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if err = validateSliceIndices(i, j, r.length); err != nil {
+		return
+	}
+
+	if j == i && len(hashes) == 0 {
+		return // nothing to change
+	}
+
+	// (1) replace, (2) delete or insert
+
+	var n = j - i // number of elements to replace
+
+	if len(hashes) < n {
+		n = len(hashes)
+	}
+
+	var el *refsElement
+
+	for k := 0; k < n; k++ {
+
+		el, err = r.elementByIndex(pack, r.refsNode, i+k, r.depth)
+		if err != nil {
+			return
+		}
+
+		r.replaceElementHash(el, hashes[k])
+
+	}
+
+	if j-i > n {
+		err = r.deleteRangeNode(pack, r.refsNode, i+n, j, r.depth)
+	} else if len(hashes) > n {
+		err = r.insertHashes(pack, i+n, hashes[n:])
+	}
+
+	if err != nil {
+		return
+	}
+
+	if r.flags&LazyUpdating == 0 {
+		if err = r.walkUpdating(pack); err != nil {
+			return
+		}
+	} else {
+		r.mods |= contentMod // but not saved
+	}
+
+	r.rewindIterators() // for iterators
+	return
+}
+
+// mark given node and all upper nodes as modified
+func (r *refsNode) markContentChanges() {
+	for up := r; up != nil; up = up.upper {
+		up.mods |= contentMod
+	}
+}
+
+// replaceElementHash replaces hash of given element
+// marking upper nodes as modified; the method doesn't
+// update hashes of the nodes
+func (r *Refs) replaceElementHash(el *refsElement, hash cipher.SHA256) {
+
+	if el.Hash == hash {
+		return // nothing to change
+	}
+
+	r.fieldIndexChange(el.Hash, -1) // delete old
+	r.fieldIndexChange(hash, 1)     // add new
+
+	if r.flags&HashTableIndex != 0 {
+		r.delElementFromIndex(el) // delete old
+		el.Hash = hash
+		r.addElementToIndex(el) // add new
+	}
+
+	el.Hash = hash
+	el.upper.markContentChanges()
+}
+
+//
+// delete range
+//
+
+// deleteRangeNode deletes elements [i:j] of given
+// node; the method marks changed nodes as modified
+// but doesn't update their hashes; empty branches
+// removed
+func (r *Refs) deleteRangeNode(
+	pack Pack, //    : pack to load
+	rn *refsNode, // : the node (should be loaded)
+	i int, //        : start of the range (inclusive)
+	j int, //        : end of the range (exclusive)
+	depth int, //    : depth of the rn
+) (
+	err error, //    : error if any
+) {
+
+	if depth == 0 { // leafs
+
+		for _, el := range rn.leafs[i:j] {
+			if r.flags&HashTableIndex != 0 {
+				r.delElementFromIndex(el)
+			}
+			r.fieldIndexChange(el.Hash, -1)
+		}
+
+		var ln = copy(rn.leafs[i:], rn.leafs[j:]) + i
+
+		for k := ln; k < len(rn.leafs); k++ {
+			rn.leafs[k] = nil // GC
+		}
+
+		rn.leafs = rn.leafs[:ln]
+
+		rn.length -= j - i
+		rn.mods |= contentMod
+		return
+	}
+
+	// else, take a look at branches
+
+	var (
+		shift    int // index of first element of the br
+		from, to int // range inside the br
+	)
+
+	for k := 0; k < len(rn.branches) && shift < j; k++ {
+
+		var br = rn.branches[k]
+
+		if err = r.loadNodeIfNeed(pack, br, depth-1); err != nil {
+			return
+		}
+
+		if shift+br.length <= i {
+			shift += br.length // subtract length of the skipped branch
+			continue           // and skip the branch
+		}
+
+		if from = i - shift; from < 0 {
+			from = 0
+		}
+
+		if to = j - shift; to > br.length {
+			to = br.length
+		}
+
+		shift += br.length // before deleting
+
+		err = r.deleteRangeNode(pack, br, from, to, depth-1)
+		if err != nil {
+			return
+		}
+
+		if br.length == 0 {
+			rn.deleteNodeByIndex(k) // delete node, because it's empty
+			k--
+		}
+
+	}
+
+	rn.length -= j - i
+	rn.mods |= contentMod
+	return
+}
+
+//
+// insert
+//
+
+// insertHashes inserts given hashes before i-th element,
+// splitting nodes that can't fit new elements; the method
+// marks changed nodes as modified, but doesn't update
+// their hashes
+func (r *Refs) insertHashes(
+	pack Pack, //               : pack to load
+	i int, //                   : index to insert before
+	hashes []cipher.SHA256, //  : hashes to insert
+) (
+	err error, //               : error if any
+) {
+
+	// (1) find node with leafs that contains the i
+
+	var rn, depth = r.refsNode, r.depth
+
+	for ; depth > 0; depth-- {
+
+		if len(rn.branches) == 0 {
+			// blank Refs with depth > 0 (all elements deleted)
+			rn.branches = append(rn.branches, &refsNode{
+				upper: rn,
+				mods:  loadedMod | contentMod,
+			})
+		}
+
+		var br *refsNode
+		for _, br = range rn.branches {
+
+			if err = r.loadNodeIfNeed(pack, br, depth-1); err != nil {
+				return
+			}
+
+			if i <= br.length {
+				break // the branch can fit the i
+			}
+
+			i -= br.length // subtract length of the skipped branch
+		}
+
+		rn = br // the branch or the last one
+
+	}
+
+	// (2) insert the hashes to the leafs
+
+	var els = make([]*refsElement, 0, len(rn.leafs)+len(hashes))
+
+	els = append(els, rn.leafs[:i]...)
+
+	for _, hash := range hashes {
+
+		var el = &refsElement{
+			Hash:  hash,
+			upper: rn,
+		}
+
+		if r.flags&HashTableIndex != 0 {
+			r.addElementToIndex(el)
+		}
+
+		r.fieldIndexChange(hash, 1)
+
+		els = append(els, el)
+	}
+
+	rn.leafs = append(els, rn.leafs[i:]...)
+
+	for up := rn; up != nil; up = up.upper {
+		up.length += len(hashes)
+		up.mods |= contentMod
+	}
+
+	// (3) split full nodes going up
+
+	for ; rn.upper != nil; rn, depth = rn.upper, depth+1 {
+
+		if rn.children(depth) <= int(r.degree) {
+			return // the rn and all upper nodes are not overflowed
+		}
+
+		if err = r.splitOverflowed(pack, rn, depth); err != nil {
+			return
+		}
+
+	}
+
+	// (4) increase depth of the Refs if need
+
+	for r.children(r.depth) > int(r.degree) {
+
+		if err = r.increaseDepth(pack); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+// children returns number of leafs or branches of the node
+func (r *refsNode) children(depth int) int {
+	if depth == 0 {
+		return len(r.leafs)
+	}
+	return len(r.branches)
+}
+
+// splitOverflowed splits given overflowed node to nodes
+// that contain degree or less elements; new nodes
+// placed after the rn in upper node; thus the upper
+// node can be overflowed after
+func (r *Refs) splitOverflowed(
+	pack Pack, //    : pack to load
+	rn *refsNode, // : the node to split
+	depth int, //    : depth of the node
+) (
+	err error, //    : error if any
+) {
+
+	var (
+		degree = int(r.degree)
+		count  = rn.children(depth)
+		parts  = (count + degree - 1) / degree // > 1
+
+		leafs    = rn.leafs
+		branches = rn.branches
+
+		nodes = make([]*refsNode, 0, parts)
+	)
+
+	// spread the elements between parts uniformly
+
+	for p, from := 0, 0; p < parts; p++ {
+
+		var to = from + count/parts
+		if p < count%parts {
+			to++
+		}
+
+		var nd = rn // the first part is the rn itself
+		if p > 0 {
+			nd = &refsNode{
+				upper: rn.upper,
+				mods:  loadedMod | contentMod,
+			}
+		}
+
+		if depth == 0 {
+
+			nd.leafs = make([]*refsElement, 0, degree)
+			for _, el := range leafs[from:to] {
+				el.upper = nd
+				nd.leafs = append(nd.leafs, el)
+			}
+			nd.length = len(nd.leafs)
+
+		} else {
+
+			nd.branches = make([]*refsNode, 0, degree)
+			nd.length = 0
+			for _, br := range branches[from:to] {
+				if err = r.loadNodeIfNeed(pack, br, depth-1); err != nil {
+					return
+				}
+				br.upper = nd
+				nd.branches = append(nd.branches, br)
+				nd.length += br.length
+			}
+
+		}
+
+		nodes = append(nodes, nd)
+		from = to
+	}
+
+	// place new nodes after the rn
+
+	var up = rn.upper
+
+	for k, br := range up.branches {
+
+		if br != rn {
+			continue
+		}
+
+		var brs = make([]*refsNode, 0, len(up.branches)+parts-1)
+
+		brs = append(brs, up.branches[:k]...)
+		brs = append(brs, nodes...)
+		brs = append(brs, up.branches[k+1:]...)
+
+		up.branches = brs
+		up.mods |= contentMod
+
+		return
+	}
+
+	return ErrInvalidRefs // can't find the rn in upper branches
+}
+
+// increaseDepth moves all elements of the Refs to new
+// branch increasing depth, and splits the branch
+func (r *Refs) increaseDepth(pack Pack) (err error) {
+
+	var br = &refsNode{
+		length: r.length,
+		mods:   loadedMod | contentMod,
+
+		upper: r.refsNode,
+	}
+
+	if r.depth == 0 {
+		br.leafs = r.leafs
+		for _, el := range br.leafs {
+			el.upper = br
+		}
+	} else {
+		br.branches = r.branches
+		for _, nd := range br.branches {
+			nd.upper = br
+		}
+	}
+
+	r.leafs, r.branches = nil, []*refsNode{br}
+	r.depth++
+
+	return r.splitOverflowed(pack, br, r.depth-1)
+}
@@ -0,0 +1,319 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// check length, hashes, hash-table index and bookkeeping of
+// loaded nodes of the Refs, then reload the Refs and check
+// length and hashes again
+func testRefsSpliceCheck(
+	t *testing.T, //         : the testing
+	r *Refs, //              : the Refs
+	pack Pack, //            : the Pack
+	want []cipher.SHA256, // : expected content
+) {
+
+	var check = func() {
+
+		var (
+			ln   int
+			hash cipher.SHA256
+			err  error
+		)
+
+		if ln, err = r.Len(pack); err != nil {
+			t.Fatal(err)
+		} else if ln != len(want) {
+			t.Fatalf("wrong length %d, want %d", ln, len(want))
+		}
+
+		for i, w := range want {
+			if hash, err = r.HashByIndex(pack, i); err != nil {
+				t.Fatal(err)
+			} else if hash != w {
+				t.Fatalf("wrong hash of %d: %s, want %s", i, hash.Hex()[:7],
+					w.Hex()[:7])
+			}
+		}
+
+		if r.flags&HashTableIndex != 0 {
+			testRefsHashTableIndex(t, r, want)
+		}
+
+		testRefsSpliceNodeCheck(t, r, r.refsNode, r.depth)
+
+	}
+
+	check()
+
+	if err := r.Rebuild(pack); err != nil { // LazyUpdating
+		t.Fatal(err)
+	}
+
+	if len(want) == 0 && r.Hash != (cipher.SHA256{}) {
+		t.Error("blank Refs with non-blank hash")
+	}
+
+	r.Reset() // reload from the pack
+
+	check()
+}
+
+// check degree and length of loaded nodes
+func testRefsSpliceNodeCheck(
+	t *testing.T,
+	r *Refs,
+	rn *refsNode,
+	depth int,
+) {
+
+	if rn.isLoaded() == false {
+		return
+	}
+
+	if rn.children(depth) > int(r.degree) {
+		t.Fatalf("overflowed node: %d > %d", rn.children(depth), r.degree)
+	}
+
+	if depth == 0 {
+		if rn.length != len(rn.leafs) {
+			t.Fatalf("wrong length of leafs %d, want %d", rn.length,
+				len(rn.leafs))
+		}
+		for _, el := range rn.leafs {
+			if el.upper != rn {
+				t.Fatal("wrong upper of element")
+			}
+		}
+		return
+	}
+
+	var length int
+
+	for _, br := range rn.branches {
+		if br.upper != rn {
+			t.Fatal("wrong upper of branch")
+		}
+		if br.isLoaded() == false {
+			return // can't check the length
+		}
+		testRefsSpliceNodeCheck(t, r, br, depth-1)
+		length += br.length
+	}
+
+	if length != rn.length {
+		t.Fatalf("wrong length of node %d, want %d", rn.length, length)
+	}
+
+}
+
+// new hashes to insert
+func testRefsSpliceHashes(from, n int) (hashes []cipher.SHA256) {
+	hashes = make([]cipher.SHA256, 0, n)
+	for k := 0; k < n; k++ {
+		hashes = append(hashes, hashByNumber(uint64(1000+from+k)))
+	}
+	return
+}
+
+// splice the want
+func testSplice(
+	want []cipher.SHA256,
+	i, j int,
+	hashes []cipher.SHA256,
+) []cipher.SHA256 {
+
+	var res = make([]cipher.SHA256, 0, len(want)-(j-i)+len(hashes))
+
+	res = append(res, want[:i]...)
+	res = append(res, hashes...)
+	return append(res, want[j:]...)
+}
+
+func TestRefs_InsertAt(t *testing.T) {
+	// InsertAt(pack Pack, i int, hashes ...cipher.SHA256) (err error)
+
+	var (
+		pack = getTestPack()
+
+		users, want []cipher.SHA256
+
+		r   Refs
+		err error
+	)
+
+	for _, flags := range testRefsFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		t.Logf("flags %08b", flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			t.Log("degree", degree)
+
+			var lengths = append([]int{0}, testRefsLengths(degree)...)
+
+			for _, length := range lengths {
+
+				t.Log("length", length)
+
+				clearRefs(t, &r, pack, degree)
+				users = getHashList(getTestUsers(length))
+
+				if err = r.AppendHashes(pack, users...); err != nil {
+					t.Fatal(err)
+				}
+
+				if err = r.InsertAt(pack, -1, hashByNumber(1)); err == nil {
+					t.Error("missing error")
+				}
+
+				err = r.InsertAt(pack, length+1, hashByNumber(1))
+				if err == nil {
+					t.Error("missing error")
+				}
+
+				want = append([]cipher.SHA256{}, users...)
+
+				for _, n := range []int{
+					1,                           // single
+					int(degree) + 1,             // split
+					int(degree)*int(degree) + 1, // increase depth
+				} {
+
+					for _, i := range []int{
+						0,             // head
+						len(want) / 2, // middle
+						len(want),     // tail
+					} {
+
+						var hashes = testRefsSpliceHashes(len(want), n)
+
+						if err = r.InsertAt(pack, i, hashes...); err != nil {
+							t.Fatal(err)
+						}
+
+						want = testSplice(want, i, i, hashes)
+						testRefsSpliceCheck(t, &r, pack, want)
+
+						if t.Failed() {
+							logRefsTree(t, &r, pack, false)
+							t.FailNow()
+						}
+
+					}
+
+				}
+
+			}
+
+		}
+
+	}
+
+}
+
+func TestRefs_Splice(t *testing.T) {
+	// Splice(pack Pack, i, j int, hashes ...cipher.SHA256) (err error)
+
+	var (
+		pack = getTestPack()
+
+		users, want []cipher.SHA256
+
+		r   Refs
+		err error
+	)
+
+	for _, flags := range testRefsFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		t.Logf("flags %08b", flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			t.Log("degree", degree)
+
+			for _, length := range testRefsLengths(degree) {
+
+				t.Log("length", length)
+
+				clearRefs(t, &r, pack, degree)
+				users = getHashList(getTestUsers(length))
+
+				if err = r.AppendHashes(pack, users...); err != nil {
+					t.Fatal(err)
+				}
+
+				t.Run("out of range", func(t *testing.T) {
+					if err = r.Splice(pack, 1, 0); err == nil {
+						t.Error("missing error")
+					}
+					if err = r.Splice(pack, 0, length+1); err == nil {
+						t.Error("missing error")
+					}
+				})
+
+				want = append([]cipher.SHA256{}, users...)
+
+				for _, tc := range []struct {
+					i, j, n int
+				}{
+					{0, 1, 1},                       // replace
+					{0, len(want) / 2, 1},           // delete
+					{1, 1, int(degree) + 1},         // insert
+					{1, 2, int(degree) * 2},         // replace and insert
+					{0, len(want) / 2, 0},           // delete
+					{1, len(want) / 2, int(degree)}, // replace and delete
+				} {
+
+					if tc.j > len(want) {
+						tc.j = len(want)
+					}
+					if tc.i > tc.j {
+						tc.i = tc.j
+					}
+
+					var hashes = testRefsSpliceHashes(len(want), tc.n)
+
+					if err = r.Splice(pack, tc.i, tc.j, hashes...); err != nil {
+						t.Fatal(err)
+					}
+
+					want = testSplice(want, tc.i, tc.j, hashes)
+					testRefsSpliceCheck(t, &r, pack, want)
+
+					if t.Failed() {
+						logRefsTree(t, &r, pack, false)
+						t.FailNow()
+					}
+
+				}
+
+				// delete all and insert again
+				if err = r.Splice(pack, 0, len(want)); err != nil {
+					t.Fatal(err)
+				}
+
+				testRefsSpliceCheck(t, &r, pack, nil)
+
+				if err = r.InsertAt(pack, 0, users...); err != nil {
+					t.Fatal(err)
+				}
+
+				testRefsSpliceCheck(t, &r, pack, users)
+
+			}
+
+		}
+
+	}
+
+}
@@ -166,6 +166,11 @@ func (r *Refs) Walk(
 	// walk from nodes
 	err = r.walkNode(pack, sch, r.refsNode, r.depth, walkFunc)
 
+	// and through field indexes
+	if err == nil {
+		err = r.walkFieldIndexes(pack, walkFunc)
+	}
+
 	if err == ErrStopIteration {
 		err = nil
 	}
@@ -25,7 +25,7 @@ func (r *Reg) Register(name string, val interface{}) {
 	}
 	typ := typeOf(val)
 	switch typ {
-	case typeOfRef, typeOfRefs, typeOfDynamic:
+	case typeOfRef, typeOfRefs, typeOfDynamic, typeOfMap, typeOfFeedRef:
 		panic("can't register reference type")
 	default:
 	}
@@ -63,8 +63,12 @@ func (r *Reg) getSchema(typ reflect.Type) Schema {
 		}
 	}
 
-	if typ == typeOfRef || typ == typeOfRefs {
-		panic("Ref or Refs are not allowed in arrays and slices")
+	if typ == typeOfFeedRef { // reference to feed
+		return feedRefSchema()
+	}
+
+	if typ == typeOfRef || typ == typeOfRefs || typ == typeOfMap {
+		panic("Ref, Refs or Map are not allowed in arrays and slices")
 	}
 
 	switch typ.Kind() {
@@ -140,6 +144,17 @@ func (r *Reg) getSchema(typ reflect.Type) Schema {
 
 }
 
+// schema of FeedRef
+func feedRefSchema() Schema {
+	return &referenceSchema{
+		schema: schema{
+			ref:  SchemaRef{},
+			kind: reflect.Ptr, // FeedRef is pointer to Root of a feed
+		},
+		typ: ReferenceTypeFeed,
+	}
+}
+
 func (r *Reg) getField(sf reflect.StructField) Field {
 
 	f := new(field)
@@ -172,6 +187,17 @@ func (r *Reg) getField(sf reflect.StructField) Field {
 			elem: &schema{kind: reflect.Struct, name: []byte(tagRef)},
 		}
 		return f
+	case typeOfMap: // map of references
+		tagRef := mustTagSchemaName(sf.Tag)
+		f.schema = &referenceSchema{
+			schema: schema{
+				ref:  SchemaRef{},
+				kind: reflect.Ptr, // Map is pointer (actually map[]*T)
+			},
+			typ:  ReferenceTypeMap,
+			elem: &schema{kind: reflect.Struct, name: []byte(tagRef)},
+		}
+		return f
 	case typeOfDynamic: // dynamic reference
 		f.schema = &referenceSchema{
 			schema: schema{
@@ -181,6 +207,9 @@ func (r *Reg) getField(sf reflect.StructField) Field {
 			typ: ReferenceTypeDynamic,
 		}
 		return f
+	case typeOfFeedRef: // reference to feed
+		f.schema = feedRefSchema()
+		return f
 	default:
 	}
 
@@ -158,14 +158,14 @@ func (r *Registry) fillSchema(s Schema, filled map[Schema]struct{}) {
 	var err error
 	if s.IsReference() {
 		switch s.ReferenceType() {
-		case ReferenceTypeSingle, ReferenceTypeSlice:
+		case ReferenceTypeSingle, ReferenceTypeSlice, ReferenceTypeMap:
 			x := s.(*referenceSchema)
 			x.elem, err = r.schemaByName(x.elem.Name())
 			if err != nil {
 				panic(err)
 			}
 			r.fillSchema(x.elem, filled)
-		case ReferenceTypeDynamic:
+		case ReferenceTypeDynamic, ReferenceTypeFeed:
 			// do nothing
 		default:
 			panic("invalid reference: " + s.String())
@@ -225,6 +225,13 @@ func (r *Registry) finialize() {
 		r.srf[sch.Reference()] = sch
 	}
 
+	// check out field indexes
+	for _, sch := range r.reg {
+		if err := validateTagIndexes(sch); err != nil {
+			panic(err)
+		}
+	}
+
 	encoded := r.Encode()
 	r.ref = RegistryRef(cipher.SumSHA256(encoded))
 }
@@ -258,6 +265,64 @@ func TagSchemaName(tag reflect.StructTag) (sch string, err error) {
 	return
 }
 
+// TagIndexes returns names of fields of elements of
+// a Refs to index. E.g. it returns ["Name", "Age"] if
+// tag is `skyobject:"schema=User,index=Name,index=Age"`.
+// See Refs.AddFieldIndex for details
+func TagIndexes(tag reflect.StructTag) (fields []string, err error) {
+	for _, part := range strings.Split(tag.Get(Tag), ",") {
+		if !strings.HasPrefix(part, "index=") {
+			continue
+		}
+		if part = part[len("index="):]; part == "" {
+			err = fmt.Errorf("empty tag index field: %q", tag.Get(Tag))
+			return
+		}
+		fields = append(fields, part)
+	}
+	return
+}
+
+// validateTagIndexes checks index tags of
+// Refs fields of given struct schema
+func validateTagIndexes(sch Schema) (err error) {
+
+	if sch.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, fl := range sch.Fields() {
+
+		var fs = fl.Schema()
+
+		if fs.ReferenceType() != ReferenceTypeSlice {
+			continue
+		}
+
+		var fields []string
+		if fields, err = TagIndexes(fl.Tag()); err != nil {
+			return
+		}
+
+		for _, name := range fields {
+
+			var ifl Field
+			if ifl, err = schemaField(fs.Elem(), name); err != nil {
+				return
+			}
+
+			if isIndexableSchema(ifl.Schema()) == false {
+				return fmt.Errorf("field %q of %s can't be indexed", name,
+					fs.Elem())
+			}
+
+		}
+
+	}
+
+	return
+}
+
 func mustTagSchemaName(tag reflect.StructTag) string {
 	sch, err := TagSchemaName(tag)
 	if err != nil {
@@ -294,12 +359,13 @@ func decodeSchema(b []byte) (s Schema, err error) {
 	}
 	// is reference
 	switch ReferenceType(x.ReferenceType) {
-	case ReferenceTypeSingle, ReferenceTypeSlice, ReferenceTypeDynamic:
+	case ReferenceTypeSingle, ReferenceTypeSlice, ReferenceTypeDynamic,
+		ReferenceTypeMap, ReferenceTypeFeed:
 		// kind, typ, elem
 		rs := referenceSchema{}
 		rs.kind = reflect.Kind(x.Kind)
 		rs.typ = ReferenceType(x.ReferenceType)
-		if rs.typ != ReferenceTypeDynamic {
+		if rs.typ != ReferenceTypeDynamic && rs.typ != ReferenceTypeFeed {
 			if rs.elem, err = decodeSchema(x.Elem); err != nil {
 				return
 			}
@@ -28,11 +28,52 @@ type Root struct {
 	Sig  cipher.Sig    `enc:"-"` // signature
 	Hash cipher.SHA256 `enc:"-"` // hash of this encoded Root
 
+	// Sigs are signatures of a Root of M-of-N multisig
+	// feed, where Sigs[i] is signature of Keys[i] of the
+	// Multisig of the feed, or blank. Like the Sig, the
+	// Sigs are not part of the Root. See Multisig
+	Sigs []cipher.Sig `enc:"-"`
+
 	// Prev is hash of previous Root, the Prev can
 	// be blank is Seq of the Root is zero, that
 	// means the Root is first in chain
 	Prev cipher.SHA256
 
+	// Parents are hashes of Root objects of other
+	// heads of the feed, merged into this Root by
+	// its writer. The Prev is not included. See
+	// also Author field. The Parents, Author,
+	// Delegation, Revocations, Grants and Multisig
+	// are encoded after all other fields and only
+	// if they are not blank
+	Parents []cipher.SHA256 `enc:"-"`
+
+	// Author is public key of writer, that signs the
+	// Root, if the writer is not owner of the feed.
+	// E.g. if the Root belongs to a multi-writer feed.
+	// A Root signed by owner of the feed has blank
+	// Author
+	Author cipher.PubKey `enc:"-"`
+
+	// Delegation is certificate of the Author, if
+	// the Author is a publishing key authorized by
+	// owner of the feed. See Delegation for details
+	Delegation Delegation `enc:"-"`
+
+	// Revocations the Root carries. See Revocation
+	// for details
+	Revocations []Revocation `enc:"-"`
+
+	// Grants the Root carries. The Grants authorize
+	// writers of multi-writer feed. See Grant for
+	// details
+	Grants []Grant `enc:"-"`
+
+	// Multisig of M-of-N multisig feed. It's set
+	// in first Root (Seq is zero) of a head signed
+	// by owner of the feed. See Multisig for details
+	Multisig Multisig `enc:"-"`
+
 	// IsFull means that this Root object
 	// has been successfully colelcted by this
 	// machine. E.g. this field is not part
@@ -41,9 +82,85 @@ type Root struct {
 	IsFull bool `enc:"-"`
 }
 
+// a rootExtension represents encoded fields
+// of multi-writer Root, that are encoded after
+// all other fields to keep hashes of Root
+// objects that don't use them
+type rootExtension struct {
+	Parents     []cipher.SHA256
+	Author      cipher.PubKey
+	Delegation  Delegation
+	Revocations []Revocation
+	Grants      []Grant
+	Multisig    encodedMultisig
+}
+
 // Encode the Root
-func (r *Root) Encode() []byte {
-	return encoder.Serialize(r)
+func (r *Root) Encode() (b []byte) {
+
+	b = encoder.Serialize(r)
+
+	if r.isExtended() == true {
+		b = append(b, encoder.Serialize(rootExtension{
+			Parents:     r.Parents,
+			Author:      r.Author,
+			Delegation:  r.Delegation,
+			Revocations: r.Revocations,
+			Grants:      r.Grants,
+			Multisig:    r.Multisig.encoded(),
+		})...)
+	}
+
+	return
+}
+
+// IsMultiWriter returns true if the Root has
+// Parents or Author
+func (r *Root) IsMultiWriter() bool {
+	return len(r.Parents) > 0 || r.Author != (cipher.PubKey{})
+}
+
+// has fields of the rootExtension
+func (r *Root) isExtended() bool {
+	return r.IsMultiWriter() == true || r.Delegation.IsBlank() == false ||
+		len(r.Revocations) > 0 || len(r.Grants) > 0 ||
+		r.Multisig.IsBlank() == false
+}
+
+// VerifyDelegation verifies Delegation of the Root.
+// The Delegation should be valid, it should be signed
+// by the feed of the Root, it should delegate the
+// Author, and timestamp of the Root should be inside
+// the window of the Delegation. It returns the
+// ErrInvalidDelegation, ErrDelegationExpired or
+// signature verification error
+func (r *Root) VerifyDelegation() (err error) {
+
+	var d = &r.Delegation
+
+	if d.IsBlank() == true || d.Feed != r.Pub || d.Key != r.Author {
+		return ErrInvalidDelegation
+	}
+
+	if err = d.Verify(); err != nil {
+		return
+	}
+
+	if d.IsValidAt(r.Time) == false {
+		return ErrDelegationExpired
+	}
+
+	return
+}
+
+// Signer returns public key the Root signed by.
+// It's the Author, if the Author is not blank.
+// Otherwise, it's the Pub
+func (r *Root) Signer() (pk cipher.PubKey) {
+	if r.Author != (cipher.PubKey{}) {
+		return r.Author
+	}
+	return r.Pub
 }
 
 // Short return string like "1a2ef33/1234/2" (pub_key/nonce/seq),
@@ -80,10 +197,38 @@ func (r *Root) String() string {
 
 // DecodeRoot decodes and encoded Root object
 func DecodeRoot(val []byte) (r *Root, err error) {
+
 	r = new(Root)
+
 	if err = encoder.DeserializeRaw(val, r); err != nil {
-		r = nil
+		return nil, err
 	}
+
+	// multi-writer fields
+
+	var n = len(encoder.Serialize(r)) // fields above
+
+	if n == len(val) {
+		return // no extension
+	}
+
+	var ext rootExtension
+	if err = encoder.DeserializeRaw(val[n:], &ext); err != nil {
+		return nil, err
+	}
+
+	r.Parents, r.Author = ext.Parents, ext.Author
+	r.Delegation, r.Revocations = ext.Delegation, ext.Revocations
+	r.Grants, r.Multisig = ext.Grants, ext.Multisig.multisig()
+
+	// the extension can't be blank, and there are
+	// no other encodings of the same Root
+	if r.isExtended() == false || r.Author == r.Pub ||
+		len(encoder.Serialize(ext)) != len(val)-n {
+
+		return nil, ErrInvalidEncodedRoot
+	}
+
 	return
 }
 
@@ -0,0 +1,451 @@
+package registry
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// An Item represents a node of a Root tree. Unlike the
+// Tree method of the Root, the Items used to explore a
+// Root tree step by step. An Item contains decoded data
+// of an object, but references of the object are not
+// followed. Use Hash and Elem fields of an Item to get
+// next level of the tree (see ObjectItem and RefsItems).
+// The Item designed to be transmitted through RPC. Thus
+// it doesn't contain interfaces, and errors are strings
+type Item struct {
+	Name   string // name of field or index of element, can be blank
+	Schema string // schema of the item (string representation)
+	Value  string // value of basic item or FeedRef (blank if nil)
+	Err    string // error, if any
+
+	// references
+
+	Ref  ReferenceType // type of reference or ReferenceTypeNone
+	Hash cipher.SHA256 // hash of referenced object or hash of Refs
+	Elem SchemaRef     // schema of referenced object (or of element of Refs)
+
+	Items []*Item // fields of struct, elements of array or slice
+}
+
+// IsBlank returns true if the Item is a reference
+// that represents nil
+func (i *Item) IsBlank() bool {
+	if i.Ref == ReferenceTypeFeed {
+		return i.Value == ""
+	}
+	return i.Ref != ReferenceTypeNone && i.Hash == (cipher.SHA256{})
+}
+
+// Items returns list of Items of the Root.Refs. The
+// Items are not followed and represent dynamic references
+// only. The Pack should have related Registry
+func (r *Root) Items(pack Pack) (items []*Item, err error) {
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		if len(r.Refs) == 0 {
+			return // blank Root
+		}
+		return nil, ErrMissingRegistry
+	}
+
+	items = make([]*Item, 0, len(r.Refs))
+
+	for k, dr := range r.Refs {
+		items = append(items, itemDynamic(reg, strconv.Itoa(k), &dr))
+	}
+
+	return
+}
+
+// ObjectItem loads object with given hash and returns it
+// as Item. The Schema is schema of the object. Fields and
+// elements of the object are decoded, but references are
+// not followed. The Pack should have related Registry
+func ObjectItem(
+	pack Pack, //          : pack to get object and Registry
+	sr SchemaRef, //       : schema of the object
+	hash cipher.SHA256, // : hash of the object
+) (
+	it *Item, //           : the object
+	err error, //          : loading error
+) {
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		return nil, ErrMissingRegistry
+	}
+
+	var sch Schema
+	if sch, err = reg.SchemaByReference(sr); err != nil {
+		return
+	}
+
+	var val []byte
+	if val, err = pack.Get(hash); err != nil {
+		return
+	}
+
+	it = itemData("", sch, val)
+	return
+}
+
+// RefsItems returns page of elements of Refs with given
+// hash. The page is elements from i (inclusive) to j
+// (exclusive). If the j is greater then length of the Refs,
+// then it will be truncated to the length. The RefsItems
+// returns length of the Refs too. The Schema is schema of
+// elements of the Refs. Only required branches of the Refs
+// will be loaded. The Pack should have related Registry
+func RefsItems(
+	pack Pack, //          : pack to load the Refs
+	el SchemaRef, //       : schema of element
+	hash cipher.SHA256, // : hash of the Refs
+	i int, //              : first element
+	j int, //              : last element (exclusive)
+) (
+	length int, //         : length of the Refs
+	items []*Item, //      : elements
+	err error, //          : loading error
+) {
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		return 0, nil, ErrMissingRegistry
+	}
+
+	var sch Schema
+	if sch, err = reg.SchemaByReference(el); err != nil {
+		return
+	}
+
+	var refs = Refs{Hash: hash}
+
+	if length, err = refs.Len(pack); err != nil {
+		return
+	}
+
+	if j > length {
+		j = length
+	}
+
+	if i < 0 || i > j {
+		return 0, nil, ErrInvalidSliceIndex
+	}
+
+	if i == j {
+		return // empty page
+	}
+
+	items = make([]*Item, 0, j-i)
+
+	err = refs.AscendFrom(pack, i, func(k int, hash cipher.SHA256) (_ error) {
+
+		if k >= j {
+			return ErrStopIteration
+		}
+
+		items = append(items, &Item{
+			Name:   strconv.Itoa(k),
+			Schema: "*" + sch.String(),
+			Ref:    ReferenceTypeSingle,
+			Hash:   hash,
+			Elem:   el,
+		})
+
+		return
+	})
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return
+}
+
+func itemErr(name, schema string, err error) (it *Item) {
+	return &Item{Name: name, Schema: schema, Err: err.Error()}
+}
+
+func itemDynamic(reg *Registry, name string, dr *Dynamic) (it *Item) {
+
+	it = &Item{Name: name, Schema: "*(dynamic)", Ref: ReferenceTypeDynamic}
+
+	if dr.IsValid() == false {
+		it.Err = ErrInvalidDynamicReference.Error()
+		return
+	}
+
+	if dr.IsBlank() == true {
+		return
+	}
+
+	var sch, err = reg.SchemaByReference(dr.Schema)
+
+	if err != nil {
+		it.Err = err.Error()
+		return
+	}
+
+	it.Schema = "*" + sch.String()
+	it.Hash = dr.Hash
+	it.Elem = dr.Schema
+	return
+}
+
+func itemData(name string, sch Schema, val []byte) (it *Item) {
+
+	if sch.IsReference() == true {
+		return itemReference(name, sch, val)
+	}
+
+	switch sch.Kind() {
+	case reflect.Array, reflect.Slice:
+		return itemSlice(name, sch, val)
+	case reflect.Struct:
+		return itemStruct(name, sch, val)
+	}
+
+	var x, err = decodeItemValue(sch, val)
+
+	if err != nil {
+		return itemErr(name, sch.String(), err)
+	}
+
+	return &Item{Name: name, Schema: sch.String(), Value: fmt.Sprint(x)}
+}
+
+// decode bool, intX, uintX, floatX or string
+func decodeItemValue(sch Schema, val []byte) (x interface{}, err error) {
+
+	var ptr interface{} // pointer to value
+
+	switch sch.Kind() {
+	case reflect.Bool:
+		ptr = new(bool)
+	case reflect.Int8:
+		ptr = new(int8)
+	case reflect.Int16:
+		ptr = new(int16)
+	case reflect.Int32:
+		ptr = new(int32)
+	case reflect.Int64:
+		ptr = new(int64)
+	case reflect.Uint8:
+		ptr = new(uint8)
+	case reflect.Uint16:
+		ptr = new(uint16)
+	case reflect.Uint32:
+		ptr = new(uint32)
+	case reflect.Uint64:
+		ptr = new(uint64)
+	case reflect.Float32:
+		ptr = new(float32)
+	case reflect.Float64:
+		ptr = new(float64)
+	case reflect.String:
+		ptr = new(string)
+	default:
+		return nil, fmt.Errorf("invalid Kind <%s> of Schema %q",
+			sch.Kind().String(), sch.String())
+	}
+
+	if err = encoder.DeserializeRaw(val, ptr); err != nil {
+		return
+	}
+
+	return reflect.ValueOf(ptr).Elem().Interface(), nil
+}
+
+func itemReference(name string, sch Schema, val []byte) (it *Item) {
+
+	var err error
+
+	switch rt := sch.ReferenceType(); rt {
+
+	case ReferenceTypeSingle, ReferenceTypeSlice, ReferenceTypeMap:
+
+		var el Schema
+		if el = sch.Elem(); el == nil {
+			return itemErr(name, sch.String(),
+				fmt.Errorf("missing schema of element: %s", sch))
+		}
+
+		it = &Item{Name: name, Ref: rt, Elem: el.Reference()}
+
+		switch rt {
+		case ReferenceTypeSingle:
+			var ref Ref
+			err = encoder.DeserializeRaw(val, &ref)
+			it.Schema, it.Hash = "*"+el.String(), ref.Hash
+		case ReferenceTypeSlice:
+			var refs Refs
+			err = encoder.DeserializeRaw(val, &refs)
+			it.Schema, it.Hash = "[]*"+el.String(), refs.Hash
+		default:
+			var m Map
+			err = encoder.DeserializeRaw(val, &m)
+			it.Schema, it.Hash = "map[]*"+el.String(), m.Hash
+		}
+
+		if err != nil {
+			it.Err = err.Error()
+		}
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if err = encoder.DeserializeRaw(val, &dr); err != nil {
+			return itemErr(name, "*(dynamic)", err)
+		}
+
+		it = &Item{
+			Name:   name,
+			Schema: "*(dynamic)",
+			Ref:    ReferenceTypeDynamic,
+			Hash:   dr.Hash,
+			Elem:   dr.Schema,
+		}
+
+		if dr.IsValid() == false {
+			it.Err = ErrInvalidDynamicReference.Error()
+		}
+
+	case ReferenceTypeFeed:
+
+		var fr FeedRef
+		if err = encoder.DeserializeRaw(val, &fr); err != nil {
+			return itemErr(name, "*(feed)", err)
+		}
+
+		it = &Item{Name: name, Schema: "*(feed)", Ref: ReferenceTypeFeed}
+
+		if fr.IsBlank() == false {
+			it.Value = fr.String()
+		}
+
+		if fr.IsValid() == false {
+			it.Err = ErrInvalidFeedRef.Error()
+		}
+
+	default:
+		return itemErr(name, sch.String(),
+			fmt.Errorf("invalid schema (%s): reference with invalid type %d",
+				sch.String(), rt))
+
+	}
+
+	return
+}
+
+// slice or array
+func itemSlice(name string, sch Schema, val []byte) (it *Item) {
+
+	var (
+		el  Schema
+		err error
+	)
+
+	if el = sch.Elem(); el == nil {
+		return itemErr(name, sch.String(),
+			fmt.Errorf("invalid schema %q: nil-element", sch.String()))
+	}
+
+	it = &Item{Name: name, Schema: sch.String()}
+
+	// special case for []byte
+	if sch.Kind() == reflect.Slice && el.Kind() == reflect.Uint8 {
+
+		var x []byte
+		if err = encoder.DeserializeRaw(val, &x); err != nil {
+			it.Err = err.Error()
+			return
+		}
+
+		it.Value = hex.EncodeToString(x)
+		return
+	}
+
+	var (
+		ln    int // length
+		shift int // shift
+		m     int // size of element
+	)
+
+	if sch.Kind() == reflect.Array {
+		ln = sch.Len()
+	} else {
+		if ln, err = getLength(val); err != nil {
+			it.Err = err.Error()
+			return
+		}
+		shift = 4
+	}
+
+	it.Items = make([]*Item, 0, ln)
+
+	for k := 0; k < ln; k++ {
+
+		if shift > len(val) {
+			it.Err = fmt.Sprintf("unexpected end of %s at %d element",
+				sch.Kind().String(), k)
+			return
+		}
+
+		if m, err = el.Size(val[shift:]); err != nil {
+			it.Err = fmt.Sprintf("invalid object size at %d (%s): %v",
+				k, sch.Kind().String(), err)
+			return
+		}
+
+		it.Items = append(it.Items,
+			itemData(strconv.Itoa(k), el, val[shift:shift+m]))
+		shift += m
+
+	}
+
+	return
+}
+
+func itemStruct(name string, sch Schema, val []byte) (it *Item) {
+
+	var (
+		shift int
+		s     int
+		err   error
+	)
+
+	it = &Item{Name: name, Schema: sch.String()}
+	it.Items = make([]*Item, 0, len(sch.Fields()))
+
+	for _, f := range sch.Fields() {
+
+		if shift > len(val) {
+			it.Err = fmt.Sprintf("unexpected end of encoded struct '%s' "+
+				"at field '%s', schema of field: '%s'",
+				sch.String(), f.Name(), f.Schema().String())
+			return
+		}
+
+		if s, err = f.Schema().Size(val[shift:]); err != nil {
+			it.Err = err.Error()
+			return
+		}
+
+		it.Items = append(it.Items,
+			itemData(f.Name(), f.Schema(), val[shift:shift+s]))
+		shift += s
+
+	}
+
+	return
+}
@@ -0,0 +1,259 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestRoot_Items(t *testing.T) {
+	// (*Root) Items(pack Pack) (items []*Item, err error)
+
+	var (
+		pack = testPackReg(nil) // no Registry
+		r    = new(Root)
+
+		items []*Item
+		err   error
+	)
+
+	// blank
+
+	if items, err = r.Items(pack); err != nil {
+		t.Error(err)
+	} else if len(items) != 0 {
+		t.Error("unexpected items length:", len(items))
+	}
+
+	// missing registry
+
+	r.Refs = []Dynamic{{}}
+
+	if _, err = r.Items(pack); err != ErrMissingRegistry {
+		t.Error("unexpected error:", err)
+	}
+
+	// nil and Alice
+
+	pack = getTestPack()
+
+	var (
+		alice        = &TestUser{Name: "Alice", Age: 19}
+		aliceDynamic = dynamicByValue(pack, alice)
+	)
+
+	r.Refs = []Dynamic{{}, aliceDynamic}
+
+	if items, err = r.Items(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 {
+		t.Fatal("unexpected items length:", len(items))
+	}
+
+	if items[0].IsBlank() == false {
+		t.Error("not blank")
+	}
+
+	if items[1].Ref != ReferenceTypeDynamic {
+		t.Error("wrong reference type:", items[1].Ref)
+	}
+
+	if items[1].Hash != aliceDynamic.Hash {
+		t.Error("wrong hash")
+	}
+
+	if items[1].Elem != aliceDynamic.Schema {
+		t.Error("wrong schema")
+	}
+
+	if items[1].Name != "1" {
+		t.Error("wrong name:", items[1].Name)
+	}
+
+}
+
+func TestObjectItem(t *testing.T) {
+	// ObjectItem(pack Pack, sr SchemaRef, hash cipher.SHA256) (*Item, error)
+
+	var (
+		pack = getTestPack()
+		reg  = pack.Registry()
+
+		group = TestGroup{Name: "the Group"}
+		users = getTestUsers(10)
+
+		it  *Item
+		err error
+	)
+
+	if err = group.Members.AppendValues(pack, users...); err != nil {
+		t.Fatal(err)
+	}
+
+	if group.Curator.Hash, err = addToPack(pack, users[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	var hash cipher.SHA256
+	if hash, err = addToPack(pack, group); err != nil {
+		t.Fatal(err)
+	}
+
+	var groupSch, userSch Schema
+
+	if groupSch, err = reg.SchemaByName("test.Group"); err != nil {
+		t.Fatal(err)
+	}
+
+	if userSch, err = reg.SchemaByName("test.User"); err != nil {
+		t.Fatal(err)
+	}
+
+	if it, err = ObjectItem(pack, groupSch.Reference(), hash); err != nil {
+		t.Fatal(err)
+	}
+
+	if it.Err != "" {
+		t.Fatal(it.Err)
+	}
+
+	if len(it.Items) != 4 {
+		t.Fatal("wrong number of fields:", len(it.Items))
+	}
+
+	var name, members, curator, developer = it.Items[0], it.Items[1],
+		it.Items[2], it.Items[3]
+
+	if name.Name != "Name" || name.Value != group.Name {
+		t.Errorf("wrong Name field: %q: %q", name.Name, name.Value)
+	}
+
+	if members.Ref != ReferenceTypeSlice {
+		t.Error("wrong reference type of Members:", members.Ref)
+	}
+
+	if members.Hash != group.Members.Hash {
+		t.Error("wrong hash of Members")
+	}
+
+	if members.Elem != userSch.Reference() {
+		t.Error("wrong schema of Members")
+	}
+
+	if curator.Ref != ReferenceTypeSingle {
+		t.Error("wrong reference type of Curator:", curator.Ref)
+	}
+
+	if curator.Hash != group.Curator.Hash {
+		t.Error("wrong hash of Curator")
+	}
+
+	if developer.IsBlank() == false {
+		t.Error("Developer is not blank")
+	}
+
+	// follow the Curator
+
+	if it, err = ObjectItem(pack, curator.Elem, curator.Hash); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(it.Items) != 2 {
+		t.Fatal("wrong number of fields:", len(it.Items))
+	}
+
+	if it.Items[0].Value != users[0].(TestUser).Name {
+		t.Error("wrong name:", it.Items[0].Value)
+	}
+
+	// not found
+
+	if _, err = ObjectItem(pack, userSch.Reference(), cipher.SHA256{1}); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestRefsItems(t *testing.T) {
+	// RefsItems(pack Pack, el SchemaRef, hash cipher.SHA256,
+	//     i, j int) (length int, items []*Item, err error)
+
+	var (
+		pack  = getTestPack()
+		users = getTestUsers(17)
+		refs  Refs
+
+		userSch Schema
+		err     error
+	)
+
+	if userSch, err = pack.Registry().SchemaByName("test.User"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = refs.AppendValues(pack, users...); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		length int
+		items  []*Item
+	)
+
+	for _, tc := range []struct {
+		i, j   int
+		expect int
+	}{
+		{0, 0, 0},
+		{0, 5, 5},
+		{5, 10, 5},
+		{15, 20, 2},
+		{17, 100, 0},
+	} {
+
+		length, items, err = RefsItems(pack, userSch.Reference(), refs.Hash,
+			tc.i, tc.j)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if length != len(users) {
+			t.Error("wrong length:", length)
+		}
+
+		if len(items) != tc.expect {
+			t.Errorf("wrong page length [%d:%d]: %d", tc.i, tc.j, len(items))
+			continue
+		}
+
+		for k, it := range items {
+			if it.Hash != getHash(users[tc.i+k]) {
+				t.Errorf("wrong hash of %d element", tc.i+k)
+			}
+		}
+
+	}
+
+	// invalid indices
+
+	_, _, err = RefsItems(pack, userSch.Reference(), refs.Hash, 10, 5)
+
+	if err != ErrInvalidSliceIndex {
+		t.Error("unexpected error:", err)
+	}
+
+	// blank Refs
+
+	length, items, err = RefsItems(pack, userSch.Reference(),
+		cipher.SHA256{}, 0, 10)
+
+	if err != nil {
+		t.Error(err)
+	} else if length != 0 || len(items) != 0 {
+		t.Error("blank Refs is not empty")
+	}
+
+}
@@ -0,0 +1,82 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+func TestDecodeRoot(t *testing.T) {
+	// DecodeRoot(val []byte) (r *Root, err error)
+
+	var (
+		pk, _ = cipher.GenerateDeterministicKeyPair([]byte("owner"))
+		wp, _ = cipher.GenerateDeterministicKeyPair([]byte("writer"))
+		r     = &Root{Pub: pk, Nonce: 1, Seq: 2, Time: 3}
+		dr    *Root
+		err   error
+	)
+
+	// single-writer Root is encoded as before
+
+	type legacyRoot struct {
+		Refs       []Dynamic
+		Descriptor []byte
+		Reg        RegistryRef
+		Pub        cipher.PubKey
+		Nonce      uint64
+		Seq        uint64
+		Time       int64
+		Prev       cipher.SHA256
+	}
+
+	var legacy = encoder.Serialize(legacyRoot{Pub: pk, Nonce: 1, Seq: 2,
+		Time: 3})
+
+	if string(r.Encode()) != string(legacy) {
+		t.Error("encoding of single-writer Root changed")
+	}
+
+	if dr, err = DecodeRoot(legacy); err != nil {
+		t.Fatal(err)
+	} else if dr.IsMultiWriter() == true || dr.Signer() != pk {
+		t.Error("wrong decoded Root")
+	}
+
+	// multi-writer
+
+	r.Parents = []cipher.SHA256{cipher.SumSHA256([]byte("parent"))}
+	r.Author = wp
+
+	var val = r.Encode()
+
+	if dr, err = DecodeRoot(val); err != nil {
+		t.Fatal(err)
+	} else if dr.Signer() != wp {
+		t.Error("wrong Signer")
+	} else if len(dr.Parents) != 1 || dr.Parents[0] != r.Parents[0] {
+		t.Error("wrong Parents")
+	}
+
+	// invalid
+
+	var blankExt = append(legacy, encoder.Serialize(rootExtension{})...)
+
+	for i, invalid := range [][]byte{
+		blankExt,
+		append(append([]byte{}, val...), 0),
+		val[:len(val)-1],
+	} {
+		if _, err = DecodeRoot(invalid); err == nil {
+			t.Errorf("%d: missing error", i)
+		}
+	}
+
+	r.Author = pk
+
+	if _, err = DecodeRoot(r.Encode()); err == nil {
+		t.Error("missing error")
+	}
+
+}
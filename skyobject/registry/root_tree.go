@@ -86,6 +86,22 @@ func rootTreeDynamic(
 	gt.AddTree(it)
 }
 
+// the FeedRef is never followed
+func rootTreeFeedRef(gt gotree.Tree, fr *FeedRef) {
+
+	if fr.IsValid() == false {
+		gt.Add("*(feed) err: " + ErrInvalidFeedRef.Error())
+		return
+	}
+
+	if fr.IsBlank() == true {
+		gt.Add("*(feed) nil")
+		return
+	}
+
+	gt.Add("*(feed) " + fr.Short())
+}
+
 func rootTreeHash(
 	gt gotree.Tree, //     :
 	pack Pack, //          :
@@ -155,6 +171,8 @@ func rootTreeReferences(
 		rootTreeRef(gt, pack, sch, val)
 	case ReferenceTypeSlice:
 		rootTreeRefs(gt, pack, sch, val)
+	case ReferenceTypeMap:
+		rootTreeMap(gt, pack, sch, val)
 	case ReferenceTypeDynamic:
 		var (
 			dr  Dynamic
@@ -165,6 +183,16 @@ func rootTreeReferences(
 			return
 		}
 		rootTreeDynamic(gt, &dr, pack)
+	case ReferenceTypeFeed:
+		var (
+			fr  FeedRef
+			err error
+		)
+		if err = encoder.DeserializeRaw(val, &fr); err != nil {
+			gt.Add("*(feed) err: " + err.Error())
+			return
+		}
+		rootTreeFeedRef(gt, &fr)
 	default:
 		gt.Add(
 			fmt.Sprintf(
@@ -594,3 +622,66 @@ func rootTreeRefs(
 
 	gt.AddTree(it)
 }
+
+func rootTreeMap(
+	gt gotree.Tree, // :
+	pack Pack, //      :
+	sch Schema, //     :
+	val []byte, //     :
+) {
+
+	var (
+		m   Map
+		el  Schema
+		err error
+	)
+
+	if el = sch.Elem(); el == nil {
+		gt.Add("map[]*(<map>) err: missing schema of element")
+		return
+	}
+
+	if err = encoder.DeserializeRaw(val, &m); err != nil {
+		gt.Add(fmt.Sprintf("map[]*(-----) %s err: %s", el.String(),
+			err.Error()))
+		return
+	}
+
+	var ln int
+	if ln, err = m.Len(pack); err != nil {
+		gt.Add(
+			fmt.Sprintf("map[]*(%s) %s err: %s",
+				el.String(), m.Short(), err.Error()),
+		)
+		return
+	}
+
+	if ln == 0 {
+		gt.Add(fmt.Sprintf("map[]*(%s) %s nil", el.String(), m.Short()))
+		return
+	}
+
+	var it = gotree.New(
+		fmt.Sprintf("map[]*(%s) %s length: %d", el.String(), m.Short(), ln),
+	)
+
+	err = m.Ascend(pack, func(key []byte, hash cipher.SHA256) (err error) {
+
+		var kt = gotree.New(fmt.Sprintf("%q", key))
+
+		if hash == (cipher.SHA256{}) {
+			kt.Add("nil")
+		} else {
+			rootTreeHash(kt, pack, el, hash)
+		}
+
+		it.AddTree(kt)
+		return
+	})
+
+	if err != nil {
+		it.Add("err: " + err.Error())
+	}
+
+	gt.AddTree(it)
+}
@@ -12,6 +12,8 @@ var (
 	typeOfRef     = typeOf(Ref{})
 	typeOfRefs    = typeOf(Refs{})
 	typeOfDynamic = typeOf(Dynamic{})
+	typeOfMap     = typeOf(Map{})
+	typeOfFeedRef = typeOf(FeedRef{})
 )
 
 // A ReferenceType represents type of a reference
@@ -23,6 +25,8 @@ const (
 	ReferenceTypeSingle                // Ref (cipher.SHA256)
 	ReferenceTypeSlice                 // Refs (a'la []Ref)
 	ReferenceTypeDynamic               // Dynamic (struct{Object, Schema Ref.})
+	ReferenceTypeMap                   // Map (a'la map[string]Ref)
+	ReferenceTypeFeed                  // FeedRef (Root of another feed)
 )
 
 // A Schema represents schema of a CX object
@@ -44,7 +48,7 @@ type Schema interface {
 	Fields() []Field    // Fields if struct
 	// Elem if array, slice or pointer (reference). The Elem returns nil
 	// for other types and if it's Dynamic reference (because schema of
-	// element is not specified by schema)
+	// element is not specified by schema) or FeedRef
 	Elem() (s Schema)
 
 	RawName() []byte    // raw name if named
@@ -176,7 +180,7 @@ func (r *referenceSchema) HasReferences() bool {
 }
 
 func (r *referenceSchema) IsRegistered() bool {
-	return false // Ref, Refs and Dynamic are not regsitered
+	return false // Ref, Refs, Dynamic, Map and FeedRef are not regsitered
 }
 
 func (r *referenceSchema) IsReference() bool {
@@ -206,6 +210,10 @@ func (r *referenceSchema) Size(p []byte) (n int, err error) {
 		n = refsSize
 	case ReferenceTypeDynamic:
 		n = dynamicSize
+	case ReferenceTypeMap:
+		n = mapSize
+	case ReferenceTypeFeed:
+		n = feedRefSize
 	default:
 		err = fmt.Errorf("[ERR] reference with invalid ReferenceType: %d", rt)
 		return
@@ -220,7 +228,7 @@ func (r *referenceSchema) encodedSchema() (x encodedSchema) {
 	x.Kind = uint32(r.kind)
 	x.ReferenceType = uint32(r.typ)
 	// the schema of the Elem is registered allways
-	if r.typ != ReferenceTypeDynamic {
+	if r.typ != ReferenceTypeDynamic && r.typ != ReferenceTypeFeed {
 		x.Elem = (&schema{
 			SchemaRef{},
 			r.elem.Kind(),
@@ -246,6 +254,10 @@ func (r *referenceSchema) String() string {
 		return fmt.Sprintf("[]*%s", r.Elem().String())
 	case ReferenceTypeDynamic:
 		return "*(dynamic)"
+	case ReferenceTypeMap:
+		return fmt.Sprintf("map[]*%s", r.Elem().String())
+	case ReferenceTypeFeed:
+		return "*(feed)"
 	}
 	return "<invalid>"
 }
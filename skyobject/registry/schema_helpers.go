@@ -6,7 +6,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher/encoder"
 )
 
-var refSize, refsSize, dynamicSize int
+var refSize, refsSize, dynamicSize, mapSize, feedRefSize int
 
 func init() {
 	for _, x := range []struct {
@@ -16,6 +16,8 @@ func init() {
 		{&refSize, Ref{}},
 		{&refsSize, Refs{}},
 		{&dynamicSize, Dynamic{}},
+		{&mapSize, Map{}},
+		{&feedRefSize, FeedRef{}},
 	} {
 		*x.val = len(encoder.Serialize(x.obj))
 	}
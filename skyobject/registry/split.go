@@ -176,6 +176,22 @@ func splitSchemaReference(
 
 		refs.Split(s, el)
 
+	case ReferenceTypeMap: // Map
+
+		var el Schema
+		if el = sch.Elem(); el == nil {
+			s.Fail(fmt.Errorf("Schema of Map with nil element: %s", sch))
+			return
+		}
+
+		var m Map
+		if err = encoder.DeserializeRaw(val, &m); err != nil {
+			s.Fail(err)
+			return
+		}
+
+		m.Split(s, el)
+
 	case ReferenceTypeDynamic: // Dynamic
 
 		var dr Dynamic
@@ -186,6 +202,16 @@ func splitSchemaReference(
 
 		dr.Split(s)
 
+	case ReferenceTypeFeed: // FeedRef
+
+		var fr FeedRef
+		if err = encoder.DeserializeRaw(val, &fr); err != nil {
+			s.Fail(err)
+			return
+		}
+
+		fr.Split(s)
+
 	default:
 
 		s.Fail(fmt.Errorf("invalid ReferenceType %d to walk through", rt))
@@ -201,7 +227,7 @@ func splitArray(
 ) {
 
 	var el Schema // Schema of the element
-	if el = sch.Elem(); el != nil {
+	if el = sch.Elem(); el == nil {
 		s.Fail(fmt.Errorf("Schema of element of array %q is nil", sch))
 		return
 	}
@@ -227,7 +253,7 @@ func splitSlice(
 	}
 
 	var el Schema // Schema of the element
-	if el = sch.Elem(); el != nil {
+	if el = sch.Elem(); el == nil {
 		s.Fail(fmt.Errorf("Schema of element of slice %q is nil", sch))
 		return
 	}
@@ -78,6 +78,7 @@ func (r *Refs) Split(s Splitter, el Schema) {
 	}
 
 	r.splitNode(&fp, el, r.refsNode, r.depth)
+	r.splitFieldIndexes(s)
 
 }
 
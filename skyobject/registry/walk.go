@@ -110,6 +110,20 @@ func walkSchemaReference(
 
 		return refs.Walk(pack, el, walkFunc)
 
+	case ReferenceTypeMap: // Map
+
+		var el Schema
+		if el = sch.Elem(); el == nil {
+			return fmt.Errorf("sSchema of Map with nil element: %s", sch)
+		}
+
+		var m Map
+		if err = encoder.DeserializeRaw(val, &m); err != nil {
+			return
+		}
+
+		return m.Walk(pack, el, walkFunc)
+
 	case ReferenceTypeDynamic: // Dynamic
 
 		var dr Dynamic
@@ -118,6 +132,10 @@ func walkSchemaReference(
 		}
 		return dr.Walk(pack, walkFunc)
 
+	case ReferenceTypeFeed: // FeedRef
+
+		return // never follow FeedRef
+
 	default:
 
 		return fmt.Errorf("invalid ReferenceType %d to walk through", rt)
@@ -136,7 +154,7 @@ func walkArray(
 ) {
 
 	var el Schema // Schema of the element
-	if el = sch.Elem(); el != nil {
+	if el = sch.Elem(); el == nil {
 		// just avoid panic if the Scehma is invlaid;
 		// any invalid Schema shuld not break CXO, since
 		// we are not trusting remote nodes, even if they
@@ -165,7 +183,7 @@ func walkSlice(
 	}
 
 	var el Schema // Schema of the element
-	if el = sch.Elem(); el != nil {
+	if el = sch.Elem(); el == nil {
 		return fmt.Errorf("Schema of element of slice %q is nil", sch)
 	}
 
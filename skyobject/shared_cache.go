@@ -0,0 +1,287 @@
+package skyobject
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A SharedCache is process-wide cache that many
+// Containers can share (see Config.SharedCache).
+// The SharedCache has global limits of amount and
+// volume. Values are deduplicated by hash across
+// Containers, and a value is charged against the
+// limits once. Thus, an object cached by many
+// Containers kept in memory once. Every Cache still
+// keeps its own references counters, because
+// Containers use different DBs.
+//
+// The limits are divided between Caches of the
+// Containers using weights (see Config.CacheWeight).
+// E.g. if there are two Containers with weights 1
+// and 3, then the first gets 25% and the second gets
+// 75% of the limits. But a Cache can use more then
+// its share, while the SharedCache is not full. E.g.
+// a busy Container borrows shares of idle ones. If
+// the SharedCache is full, then a Cache that uses
+// more then its share cleaned down, giving back the
+// borrowed space. A Cache is never cleaned down
+// below its share by other Caches.
+//
+// The limits are soft: a Cache can't evict values
+// that are wanted or being filled, borrowed space
+// is given back in background, and values bigger
+// then the Config.CacheMaxItemSize are not cached
+// at all
+type SharedCache struct {
+	mx sync.Mutex
+
+	maxAmount int // global limit
+	maxVolume int // global limit
+
+	weights map[*Cache]float64 // attached Caches
+	total   float64            // total weight
+
+	vals   map[cipher.SHA256]*sharedValue // deduplicated values
+	volume int                            // volume of the values
+
+	reclaiming bool // Caches are being cleaned down
+}
+
+// a value and number of
+// Caches that use it
+type sharedValue struct {
+	val  []byte
+	refs int
+}
+
+// NewSharedCache creates SharedCache with given
+// global limits. Use zero to turn caching off
+func NewSharedCache(maxAmount, maxVolume int) (s *SharedCache) {
+	s = new(SharedCache)
+	s.maxAmount = maxAmount
+	s.maxVolume = maxVolume
+	s.weights = make(map[*Cache]float64)
+	s.vals = make(map[cipher.SHA256]*sharedValue)
+	return
+}
+
+// attach Cache with given weight
+func (s *SharedCache) attach(c *Cache, weight float64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if _, ok := s.weights[c]; ok == true {
+		return // already attached
+	}
+
+	s.weights[c] = weight
+	s.total += weight
+}
+
+// detach Cache
+func (s *SharedCache) detach(c *Cache) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var weight, ok = s.weights[c]
+
+	if ok == false {
+		return // not attached
+	}
+
+	delete(s.weights, c)
+	s.total -= weight
+}
+
+// is given Cache attached
+func (s *SharedCache) isAttached(c *Cache) (ok bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	_, ok = s.weights[c]
+	return
+}
+
+// reclaim borrowed space: clean down all
+// attached Caches that use more then their
+// shares, if the SharedCache is full
+func (s *SharedCache) reclaim() {
+
+	s.mx.Lock()
+	var cs = make([]*Cache, 0, len(s.weights))
+	for c := range s.weights {
+		cs = append(cs, c)
+	}
+	s.mx.Unlock()
+
+	// a Cache locks the SharedCache to get
+	// its limits, thus the Caches are
+	// cleaned down without the lock
+
+	for _, c := range cs {
+		c.reclaim()
+	}
+
+	s.mx.Lock()
+	s.reclaiming = false
+	s.mx.Unlock()
+
+}
+
+// isEnabled returns false if
+// caching is turned off
+func (s *SharedCache) isEnabled() bool {
+	return s.maxAmount > 0 && s.maxVolume > 0 // constants
+}
+
+// capacity of evictor of a Cache,
+// since a Cache can borrow shares
+// of other Caches
+func (s *SharedCache) capacity() int {
+	return s.maxAmount // constant
+}
+
+// share of the limits of given Cache, under lock
+func (s *SharedCache) share(c *Cache) (maxAmount, maxVolume int) {
+
+	var weight, ok = s.weights[c]
+
+	if ok == false || s.total <= 0 {
+		return // detached
+	}
+
+	var share = weight / s.total
+
+	maxAmount = int(float64(s.maxAmount) * share)
+	maxVolume = int(float64(s.maxVolume) * share)
+	return
+}
+
+// limits of given Cache that has given amount and
+// volume, and given cleaning factor: max amount and
+// max volume, and lower boundaries to clean down to.
+// The Cache can grow while the SharedCache is not
+// full. Otherwise, it should be cleaned down to free
+// the cleaning part of the SharedCache, but not below
+// its share. If the SharedCache is full and the Cache
+// doesn't use more then its share, then other Caches
+// give back borrowed space in background
+func (s *SharedCache) limits(
+	c *Cache, //          : the Cache
+	amount int, //        : amount of the Cache
+	volume int, //        : volume of the Cache
+	cleaning float64, //  : Config.CacheCleaning
+) (
+	maxAmount int, //     : max amount
+	maxVolume int, //     : max volume
+	amountc int, //       : clean down to
+	volumec int, //       : clean down to
+) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var shareAmount, shareVolume = s.share(c)
+
+	// grow using free space of the SharedCache
+
+	maxAmount = amount + s.maxAmount - len(s.vals)
+	maxVolume = volume + s.maxVolume - s.volume
+
+	// free the cleaning part of the SharedCache
+
+	amountc = amount - len(s.vals) +
+		int(float64(s.maxAmount)*(1.0-cleaning))
+	volumec = volume - s.volume +
+		int(float64(s.maxVolume)*(1.0-cleaning))
+
+	// but not below the share
+
+	amountc = maxInt(amountc, int(float64(shareAmount)*(1.0-cleaning)))
+	volumec = maxInt(volumec, int(float64(shareVolume)*(1.0-cleaning)))
+
+	var full = len(s.vals)+1 > s.maxAmount || s.volume >= s.maxVolume
+
+	if full == true && s.reclaiming == false &&
+		s.borrows(c, amount, volume) == false {
+
+		s.reclaiming = true
+		go s.reclaim() // without lock of the Cache
+	}
+
+	return
+}
+
+// borrows returns true if given Cache, that has given
+// amount and volume, uses more then its share, under lock
+func (s *SharedCache) borrows(c *Cache, amount, volume int) bool {
+	var shareAmount, shareVolume = s.share(c)
+	return amount > shareAmount || volume > shareVolume
+}
+
+// isBorrower returns true if given Cache, that has given
+// amount and volume, uses more then its share
+func (s *SharedCache) isBorrower(c *Cache, amount, volume int) bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return s.borrows(c, amount, volume)
+}
+
+// has returns true if value with
+// given key is already interned
+func (s *SharedCache) has(key cipher.SHA256) (ok bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	_, ok = s.vals[key]
+	return
+}
+
+// intern returns value to keep in a Cache; if
+// the value is already used by another Cache,
+// then the value of the another Cache returned
+func (s *SharedCache) intern(key cipher.SHA256, val []byte) []byte {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if sv, ok := s.vals[key]; ok == true {
+		sv.refs++
+		return sv.val
+	}
+
+	s.vals[key] = &sharedValue{val: val, refs: 1}
+	s.volume += len(val)
+	return val
+}
+
+// release value that is not used by
+// a Cache anymore
+func (s *SharedCache) release(key cipher.SHA256) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var sv, ok = s.vals[key]
+
+	if ok == false {
+		return
+	}
+
+	if sv.refs--; sv.refs > 0 {
+		return
+	}
+
+	delete(s.vals, key)
+	s.volume -= len(sv.val)
+}
+
+// Stat returns number of attached Caches
+// and amount and volume of deduplicated
+// values
+func (s *SharedCache) Stat() (caches, amount, volume int) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return len(s.weights), len(s.vals), s.volume
+}
@@ -0,0 +1,181 @@
+package skyobject
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestSharedCache_limits(t *testing.T) {
+
+	var (
+		s = NewSharedCache(1000, 4000)
+
+		a, b Cache
+	)
+
+	if s.isEnabled() == false {
+		t.Fatal("disabled")
+	}
+
+	s.attach(&a, 1)
+	s.attach(&b, 3)
+
+	if amnt, vol := s.share(&a); amnt != 250 || vol != 1000 {
+		t.Error("wrong share", amnt, vol)
+	}
+
+	if amnt, vol := s.share(&b); amnt != 750 || vol != 3000 {
+		t.Error("wrong share", amnt, vol)
+	}
+
+	// a Cache can use free space of the SharedCache
+
+	if amnt, vol, _, _ := s.limits(&a, 0, 0, 0.5); amnt != 1000 ||
+		vol != 4000 {
+
+		t.Error("wrong limits", amnt, vol)
+	}
+
+	// a deduplicated value charged once
+
+	var val = make([]byte, 100)
+	s.intern(testKey(1), val)
+	s.intern(testKey(1), val)
+
+	if amnt, vol, _, _ := s.limits(&b, 1, 100, 0.5); amnt != 1000 ||
+		vol != 4000 {
+
+		t.Error("wrong limits", amnt, vol)
+	}
+
+	// borrowed space is given back, if the SharedCache is full
+
+	s.intern(testKey(2), make([]byte, 3900))
+
+	var amnt, vol, amntc, volc = s.limits(&a, 1, 3900, 0.5)
+
+	if amnt != 999 || vol != 3900 || amntc != 499 || volc != 1900 {
+		t.Error("wrong limits", amnt, vol, amntc, volc)
+	}
+
+	s.detach(&a)
+
+	if amnt, vol := s.share(&b); amnt != 1000 || vol != 4000 {
+		t.Error("wrong share", amnt, vol)
+	}
+
+	if caches, _, _ := s.Stat(); caches != 1 {
+		t.Error("wrong number of Caches", caches)
+	}
+
+	if NewSharedCache(0, 4000).isEnabled() == true {
+		t.Error("enabled")
+	}
+}
+
+func TestSharedCache_intern(t *testing.T) {
+
+	var (
+		s   = NewSharedCache(1000, 4000)
+		key = testKey(1)
+
+		first  = []byte("value")
+		second = []byte("value")
+	)
+
+	if val := s.intern(key, first); &val[0] != &first[0] {
+		t.Error("value replaced")
+	}
+
+	if val := s.intern(key, second); &val[0] != &first[0] {
+		t.Error("value is not deduplicated")
+	}
+
+	if _, amnt, vol := s.Stat(); amnt != 1 || vol != len(first) {
+		t.Error("wrong stat", amnt, vol)
+	}
+
+	s.release(key)
+
+	if _, amnt, _ := s.Stat(); amnt != 1 {
+		t.Error("released value used by another Cache")
+	}
+
+	s.release(key)
+
+	if _, amnt, vol := s.Stat(); amnt != 0 || vol != 0 {
+		t.Error("value is not released", amnt, vol)
+	}
+}
+
+func TestSharedCache_reclaim(t *testing.T) {
+
+	var (
+		s = NewSharedCache(10, 1024*1024)
+
+		newContainer = func() (c *Container) {
+			var conf = getTestConfig()
+			conf.SharedCache = s
+			conf.CachePolicy = ARC
+			conf.CacheCleaning = 0.5
+
+			var err error
+			c, err = NewContainer(conf)
+			assertNil(t, err)
+			return
+		}
+
+		set = func(c *Container, vals ...byte) {
+			for _, v := range vals {
+				var val = []byte{v}
+				var _, err = c.Set(cipher.SumSHA256(val), val, 1)
+				assertNil(t, err)
+			}
+		}
+
+		a = newContainer()
+		b = newContainer()
+	)
+
+	defer a.Close()
+	defer b.Close()
+
+	// the a borrows share of idle b
+
+	set(a, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+
+	var amount, _ = a.amountVolume()
+	assertTrue(t, amount == 10, "wrong amount")
+
+	// deduplicated value takes no place
+
+	set(b, 0)
+
+	if _, amount, _ = s.Stat(); amount != 10 {
+		t.Error("value is not deduplicated", amount)
+	}
+
+	amount, _ = a.amountVolume()
+	assertTrue(t, amount == 10, "cleaned down")
+
+	// the a gives back borrowed space
+
+	set(b, 10)
+
+	var deadline = time.Now().Add(time.Second)
+
+	for time.Now().Before(deadline) {
+		if amount, _ = a.amountVolume(); amount <= 5 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assertTrue(t, amount <= 5, "not cleaned down")
+
+	amount, _ = b.amountVolume()
+	assertTrue(t, amount == 2, "wrong amount of b")
+
+}
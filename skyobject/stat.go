@@ -6,6 +6,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds/tiered"
 	"github.com/skycoin/cxo/skyobject/statutil"
 )
 
@@ -23,11 +24,31 @@ type Stat struct {
 	// CacheCleaning is average pause of Cache
 	// for cleaning
 	CacheCleaning time.Duration
+	// CacheFlushError is error of last flushing
+	// of write-back Cache on a timer, if any
+	// (see Config.CacheWriteBack)
+	CacheFlushError string
 
 	CacheObjects ObjectsStat // cached objects
 	AllObjects   ObjectsStat // all objects
 	UsedObjects  ObjectsStat // used objects
 
+	// PreviewObjects is statistic of objects
+	// received for feeds preview and kept
+	// in memory (not in DB)
+	PreviewObjects ObjectsStat
+
+	// SharedObjects is statistic of deduplicated
+	// objects of SharedCache the Container uses.
+	// It's nil if the Container doesn't use a
+	// SharedCache
+	SharedObjects *ObjectsStat
+
+	// Tiers is statistic of tiered CXDS. It's
+	// nil if CXDS of the Container is not a
+	// *tiered.Tiered
+	Tiers *TiersStat
+
 	// RootsPerSecond is average vlaue of new
 	// Root objects per second.
 	RootsPerSecond float64
@@ -43,6 +64,45 @@ type ObjectsStat struct {
 	Volume statutil.Volume
 }
 
+// A TiersStat represents statistic of
+// tiered CXDS (see data/cxds/tiered)
+type TiersStat struct {
+	Hot  ObjectsStat // all objects of hot tier
+	Cold ObjectsStat // all objects of cold tier
+
+	Promoted ObjectsStat // moved from cold tier to hot
+	Demoted  ObjectsStat // moved from hot tier to cold
+
+	LastMigration  time.Time // time of last migration
+	MigrationError string    // error of last migration, if any
+}
+
+func objectsStat(o tiered.Objects) ObjectsStat {
+	return ObjectsStat{
+		Amount: statutil.Amount(o.Amount),
+		Volume: statutil.Volume(o.Volume),
+	}
+}
+
+func tiersStat(t *tiered.Tiered) (s *TiersStat) {
+
+	var ts = t.Stat()
+
+	s = new(TiersStat)
+
+	s.Hot = objectsStat(ts.Hot)
+	s.Cold = objectsStat(ts.Cold)
+	s.Promoted = objectsStat(ts.Promoted)
+	s.Demoted = objectsStat(ts.Demoted)
+	s.LastMigration = ts.LastMigration
+
+	if ts.MigrationError != nil {
+		s.MigrationError = ts.MigrationError.Error()
+	}
+
+	return
+}
+
 // A ReadWriteStat represents read-write statistic
 type ReadWriteStat struct {
 	RPS float64 // reads per second
@@ -88,12 +148,26 @@ func (c *Container) Stat() (s *Stat) {
 	s.Cache.WPS = c.Cache.stat.cWPS()
 
 	s.CacheCleaning = c.Cache.stat.cacheCleaning()
+	s.CacheFlushError = c.Cache.flushError()
 
 	var amount, volume = c.amountVolume() // of cache
 
 	s.CacheObjects.Amount = statutil.Amount(amount)
 	s.CacheObjects.Volume = statutil.Volume(volume)
 
+	amount, volume = c.preview.amountVolume() // of preview cache
+
+	s.PreviewObjects.Amount = statutil.Amount(amount)
+	s.PreviewObjects.Volume = statutil.Volume(volume)
+
+	if c.Cache.shared != nil {
+		_, amount, volume = c.Cache.shared.Stat()
+		s.SharedObjects = &ObjectsStat{
+			Amount: statutil.Amount(amount),
+			Volume: statutil.Volume(volume),
+		}
+	}
+
 	var all, used = c.db.CXDS().Amount()
 
 	s.AllObjects.Amount = statutil.Amount(all)
@@ -104,6 +178,10 @@ func (c *Container) Stat() (s *Stat) {
 	s.AllObjects.Volume = statutil.Volume(all)
 	s.UsedObjects.Volume = statutil.Volume(used)
 
+	if t, ok := c.db.CXDS().(*tiered.Tiered); ok == true {
+		s.Tiers = tiersStat(t)
+	}
+
 	s.RootsPerSecond = c.Index.stat.rootsPerSecond()
 
 	s.Feeds = c.Index.feedsStat()
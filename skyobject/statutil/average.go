@@ -54,13 +54,15 @@ func NewDuration(n int) (d *Duration) {
 		d.mx.Lock()
 		defer d.mx.Unlock()
 
+		var x = float64(dur)
+
 		if c < n {
 			c++
+			average += (x - average) / float64(c) // not full yet
+		} else {
+			average += (x - bins[i]) / float64(c)
 		}
 
-		var x = float64(dur)
-
-		average += (x - bins[i]) / float64(c)
 		bins[i] = x
 		i = (i + 1) % n
 
@@ -115,13 +117,15 @@ func NewFloat(n int) (f *Float) {
 		f.mx.Lock()
 		defer f.mx.Unlock()
 
+		var x = float64(dur)
+
 		if c < n {
 			c++
+			average += (x - average) / float64(c) // not full yet
+		} else {
+			average += (x - bins[i]) / float64(c)
 		}
 
-		var x = float64(dur)
-
-		average += (x - bins[i]) / float64(c)
 		bins[i] = x
 		i = (i + 1) % n
 
@@ -0,0 +1,50 @@
+package statutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration_Add(t *testing.T) {
+	// Add(dur time.Duration) (avg time.Duration)
+
+	var d = NewDuration(3)
+
+	for i, want := range []time.Duration{
+		10, // 10
+		15, // (10 + 20) / 2
+		20, // (10 + 20 + 30) / 3
+		30, // (20 + 30 + 40) / 3
+		40, // (30 + 40 + 50) / 3
+	} {
+		if avg := d.Add(time.Duration(10 * (i + 1))); avg != want {
+			t.Errorf("wrong average %d: %d, want %d", i, avg, want)
+		}
+	}
+
+	if d.Value() != 40 {
+		t.Error("wrong value:", d.Value())
+	}
+
+}
+
+func TestFloat_Add(t *testing.T) {
+	// Add(val float64) (avg float64)
+
+	var f = NewFloat(2)
+
+	for i, want := range []float64{
+		1,   // 1
+		1.5, // (1 + 2) / 2
+		2.5, // (2 + 3) / 2
+	} {
+		if avg := f.Add(float64(i + 1)); avg != want {
+			t.Errorf("wrong average %d: %f, want %f", i, avg, want)
+		}
+	}
+
+	if f.Value() != 2.5 {
+		t.Error("wrong value:", f.Value())
+	}
+
+}
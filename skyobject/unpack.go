@@ -27,6 +27,8 @@ type Unpack struct {
 	// the created filed used if some features of node-to-node
 	// prtocol enabled, such as created_hashes or crated_objects
 	created []cipher.SHA256 // list of hashes of created object
+
+	indexes map[string][]string // schema name -> fields to index
 }
 
 func (u *Unpack) reset() {
@@ -60,12 +62,37 @@ func (u *Unpack) Set(key cipher.SHA256, val []byte) (err error) {
 // Add value
 func (u *Unpack) Add(val []byte) (key cipher.SHA256, err error) {
 
-	key = cipher.SumSHA256(val)
+	key = u.c.Sum(val)
 	err = u.Set(key, val) // use Set of the Unpack
 	return
 
 }
 
+// AddFieldIndex adds field index of Refs with elements
+// of given schema. The index will be added to Refs of
+// values saved using the Unpack (e.g. values passed to
+// SetValue, AppendValues, etc), in addition to indexes
+// defined by tags. See registry.Refs.AddFieldIndex
+func (u *Unpack) AddFieldIndex(schemaName, field string) {
+
+	if u.indexes == nil {
+		u.indexes = make(map[string][]string)
+	}
+
+	for _, f := range u.indexes[schemaName] {
+		if f == field {
+			return // already added
+		}
+	}
+
+	u.indexes[schemaName] = append(u.indexes[schemaName], field)
+}
+
+// FieldIndexes implements registry.FieldIndexer interface
+func (u *Unpack) FieldIndexes(el registry.Schema) (fields []string) {
+	return u.indexes[el.Name()]
+}
+
 // Unpack creates Unpack using given registry. Use
 // the Unapck to modify a Root object and to save
 // changes after.
@@ -105,17 +132,8 @@ func (u *Unpack) Created() []cipher.SHA256 {
 	return u.created
 }
 
-// Save changes of given Root updating seq number and
-// timestamp of the Root. The Root should have correct
-// Pub, and Nonce fields. The Seq field will be set
-// to next inside the Save. The Save also set Hash and
-// Prev fields of the Root, and signs the Root
-func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
-
-	// clear the created field
-	up.created = up.created[:0]
-
-	// save the Root recursive
+// check Pub and Nonce, and set Reg of given Root
+func (c *Container) checkRoot(up *Unpack, r *registry.Root) (err error) {
 
 	if r.Pub == (cipher.PubKey{}) {
 		return errors.New("blank Pub field of the Root")
@@ -127,7 +145,7 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 
 	// check out Registry
 
-	if rr := up.Registry().Reference(); r.Reg == (registry.RegistryRef{}) {
+	if rr := c.RegistryRef(up.Registry()); r.Reg == (registry.RegistryRef{}) {
 
 		r.Reg = rr // set
 
@@ -141,6 +159,42 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 
 	}
 
+	return
+}
+
+// Save changes of given Root updating seq number and
+// timestamp of the Root. The Root should have correct
+// Pub, and Nonce fields. The Seq field will be set
+// to next inside the Save. The Save also set Hash and
+// Prev fields of the Root, and signs the Root.
+//
+// If secret key of the Unpack is not secret key of the
+// feed, then the key should belong to authorized writer
+// of the feed (see AddWriter), or it should be a publishing
+// key with valid Delegation (set the Delegation field of the
+// Root). The Save sets Author field of the Root in this case.
+// The Parents, the Delegation, the Revocations and the Grants
+// fields of the Root are saved as is. The Parents must be
+// saved Root objects of the same feed
+//
+// A Root of M-of-N multisig feed (see SetMultisig) should
+// be prepared (see Prepare) and signed before the Save.
+// The Save doesn't change Seq, Prev, Time and Hash of such
+// Root, and it verifies the Sigs of the Root.
+//
+// If the head of the Root has retention (see SetHeadKeep),
+// then the Save removes outdated Root objects of the head
+func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
+
+	// clear the created field
+	up.created = up.created[:0]
+
+	// save the Root recursive
+
+	if err = c.checkRoot(up, r); err != nil {
+		return
+	}
+
 	// walk the Root first
 
 	defer func() {
@@ -217,6 +271,13 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 		return data.ErrNoSuchFeed
 	}
 
+	// in write-back mode, objects of the Root
+	// should be saved before the Root
+
+	if err = c.Flush(); err != nil {
+		return
+	}
+
 	// save into Index and IdxDB
 	var val []byte
 
@@ -257,7 +318,15 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 
 	}
 
-	return
+	// the Root and the Registry in write-back mode
+
+	if err = c.Flush(); err != nil {
+		return
+	}
+
+	// outdated Root objects of the head (see SetHeadKeep)
+
+	return c.retain(r.Pub, r.Nonce)
 }
 
 func (i *Index) saveRoot(
@@ -271,6 +340,29 @@ func (i *Index) saveRoot(
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
+	var writer = cipher.PubKeyFromSecKey(up.sk)
+
+	if writer == r.Pub {
+		r.Author = cipher.PubKey{} // the owner
+	} else {
+		r.Author = writer
+	}
+
+	if err = i.applyRevocations(r); err != nil {
+		return
+	}
+
+	if err = i.applyGrants(r); err != nil {
+		return
+	}
+
+	// M-of-N multisig feed, the Root must be prepared
+	var ms = i.multisigs[r.Pub]
+
+	if ms == nil && r.Multisig.IsBlank() == false {
+		return nil, ErrNotPrepared // use SetMultisig
+	}
+
 	// val []byte --> encoded Root
 	var dr = new(data.Root)
 
@@ -300,19 +392,66 @@ func (i *Index) saveRoot(
 			return
 		}
 
+		var (
+			seq  uint64
+			prev cipher.SHA256
+		)
+
 		if lastHash != (cipher.SHA256{}) {
-			r.Seq = lastSeq + 1
-			r.Prev = lastHash
+			seq = lastSeq + 1
+			prev = lastHash
 		}
 
 		// else -> 0 and blank
 
-		r.Time = time.Now().UnixNano()
+		if ms != nil {
+
+			// the Root is prepared and signed, and
+			// another Root can be saved after that
+
+			if r.Seq != seq || r.Prev != prev {
+				return ErrOutdatedRoot
+			}
+
+		} else {
+			r.Seq, r.Prev = seq, prev
+			r.Time = time.Now().UnixNano()
+		}
+
+		// owner, authorized writer or publishing key of the
+		// feed (the Delegation is checked using the Time)
+
+		if err = i.checkSigner(r, true); err != nil {
+			return
+		}
+
+		// the Parents should be saved Root objects of the feed
+
+		if err = i.checkParents(r, true); err != nil {
+			return
+		}
 
 		// hash of the Root
 
 		val = r.Encode()
-		r.Hash = cipher.SumSHA256(val)
+
+		var hash = i.c.Sum(val)
+
+		if ms != nil {
+
+			if hash != r.Hash {
+				return ErrNotPrepared
+			}
+
+			if err = ms.Verify(hash, r.Sigs); err != nil {
+				return
+			}
+
+		} else {
+			r.Sigs = nil
+		}
+
+		r.Hash = hash
 		r.IsFull = true
 
 		// sign
@@ -323,6 +462,7 @@ func (i *Index) saveRoot(
 		dr.Prev = r.Prev
 		dr.Hash = r.Hash
 		dr.Sig = r.Sig
+		dr.Sigs = r.Sigs
 		dr.Time = r.Time
 
 		return roots.Set(dr) // save
@@ -0,0 +1,259 @@
+package skyobject
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idxdb"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// counts batched writes
+type batchingCXDS struct {
+	data.CXDS
+
+	mx      sync.Mutex
+	batches int
+	saved   map[cipher.SHA256]bool
+	fail    error // returned by SetBatch, if set
+}
+
+func newBatchingCXDS() (b *batchingCXDS) {
+	b = new(batchingCXDS)
+	b.CXDS = cxds.NewMemoryCXDS()
+	b.saved = make(map[cipher.SHA256]bool)
+	return
+}
+
+func (b *batchingCXDS) SetBatch(items []data.BatchItem) ([]int64, error) {
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if b.fail != nil {
+		return nil, b.fail
+	}
+
+	b.batches++
+
+	for _, it := range items {
+		b.saved[it.Key] = true
+	}
+
+	return data.SetBatch(b.CXDS, items)
+}
+
+func (b *batchingCXDS) setFail(err error) {
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.fail = err
+}
+
+func (b *batchingCXDS) stat() (batches int, saved map[cipher.SHA256]bool) {
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	saved = make(map[cipher.SHA256]bool, len(b.saved))
+
+	for k := range b.saved {
+		saved[k] = true
+	}
+
+	return b.batches, saved
+}
+
+func getWriteBackContainer(
+	t *testing.T,
+	conf *Config,
+) (
+	c *Container,
+	b *batchingCXDS,
+) {
+
+	b = newBatchingCXDS()
+
+	conf.CacheWriteBack = true
+	conf.DB = data.NewDB(b, idxdb.NewMemeoryDB())
+
+	var err error
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	return
+}
+
+func setValues(t *testing.T, c *Container, vals ...string) (
+	keys []cipher.SHA256) {
+
+	for _, val := range vals {
+		var key = cipher.SumSHA256([]byte(val))
+
+		var _, err = c.Set(key, []byte(val), 1)
+		assertNil(t, err)
+
+		keys = append(keys, key)
+	}
+
+	return
+}
+
+func TestCache_Flush(t *testing.T) {
+	// Flush() (err error)
+
+	t.Run("max dirty", func(t *testing.T) {
+
+		var conf = getTestConfig()
+		conf.CacheFlushInterval = 0
+		conf.CacheMaxDirty = 3
+
+		var c, b = getWriteBackContainer(t, conf)
+		defer c.Close()
+
+		setValues(t, c, "one", "two")
+
+		var batches, _ = b.stat()
+		assertTrue(t, batches == 0, "flushed before the limit")
+		assertTrue(t, c.Dirty() == 2, "wrong number of dirty values")
+
+		var keys = setValues(t, c, "three")
+
+		var saved map[cipher.SHA256]bool
+		batches, saved = b.stat()
+		assertTrue(t, batches == 1, "not flushed by one batch")
+		assertTrue(t, len(saved) == 3 && saved[keys[0]], "not saved")
+		assertTrue(t, c.Dirty() == 0, "dirty values after flush")
+
+	})
+
+	t.Run("ticker", func(t *testing.T) {
+
+		var conf = getTestConfig()
+		conf.CacheFlushInterval = 10 * time.Millisecond
+
+		var c, b = getWriteBackContainer(t, conf)
+		defer c.Close()
+
+		setValues(t, c, "one", "two")
+
+		var deadline = time.Now().Add(time.Second)
+
+		for c.Dirty() != 0 && time.Now().Before(deadline) {
+			time.Sleep(conf.CacheFlushInterval)
+		}
+
+		var batches, saved = b.stat()
+		assertTrue(t, batches == 1, "not flushed by one batch")
+		assertTrue(t, len(saved) == 2, "not saved")
+
+	})
+
+	t.Run("ticker error", func(t *testing.T) {
+
+		var conf = getTestConfig()
+		conf.CacheFlushInterval = 10 * time.Millisecond
+
+		var c, b = getWriteBackContainer(t, conf)
+		defer c.Close()
+
+		var fail = errors.New("fail")
+		b.setFail(fail)
+
+		setValues(t, c, "one", "two")
+
+		var deadline = time.Now().Add(time.Second)
+
+		for c.Stat().CacheFlushError == "" && time.Now().Before(deadline) {
+			time.Sleep(conf.CacheFlushInterval)
+		}
+
+		assertTrue(t, c.Stat().CacheFlushError == fail.Error(),
+			"error is not recorded")
+		assertTrue(t, c.Dirty() == 2, "not dirty after error")
+
+		b.setFail(nil)
+
+		if err := c.Flush(); err != fail {
+			t.Error("wrong error:", err)
+		}
+
+		var _, saved = b.stat()
+		assertTrue(t, len(saved) == 2, "not saved")
+		assertTrue(t, c.Dirty() == 0, "dirty values after flush")
+
+		assertNil(t, c.Flush())
+
+	})
+
+	t.Run("close", func(t *testing.T) {
+
+		var conf = getTestConfig()
+		conf.CacheFlushInterval = 0
+
+		var c, b = getWriteBackContainer(t, conf)
+
+		setValues(t, c, "one", "two")
+		assertNil(t, c.Close())
+
+		var batches, saved = b.stat()
+		assertTrue(t, batches == 1, "not flushed by one batch")
+		assertTrue(t, len(saved) == 2, "not saved")
+
+	})
+
+	t.Run("save", func(t *testing.T) {
+
+		var conf = getTestConfig()
+		conf.CacheFlushInterval = 0
+
+		var c, b = getWriteBackContainer(t, conf)
+		defer c.Close()
+
+		var pk, sk = cipher.GenerateKeyPair()
+		assertNil(t, c.AddFeed(pk))
+
+		var up, err = c.Unpack(sk, testRegistry)
+		assertNil(t, err)
+
+		var user registry.Dynamic
+		assertNil(t, user.SetValue(up, &User{Name: "Alice", Age: 21}))
+
+		var r = &registry.Root{Pub: pk, Nonce: 1}
+		r.Refs = append(r.Refs, user)
+
+		assertNil(t, c.Save(up, r))
+
+		var _, saved = b.stat()
+		assertTrue(t, saved[user.Hash] == true, "not saved before Save returns")
+		assertTrue(t, c.Dirty() == 0, "dirty values after Save")
+
+	})
+
+	t.Run("evict", func(t *testing.T) {
+
+		var conf = getTestConfig()
+		conf.CacheFlushInterval = 0
+		conf.CacheMaxAmount = 2
+		conf.CacheCleaning = 0.5
+
+		var c, b = getWriteBackContainer(t, conf)
+		defer c.Close()
+
+		var keys = setValues(t, c, "one", "two", "three")
+
+		var batches, saved = b.stat()
+		assertTrue(t, batches == 1, "not flushed by one batch")
+		assertTrue(t, saved[keys[0]] && saved[keys[1]],
+			"evicted dirty value not saved")
+
+	})
+
+}
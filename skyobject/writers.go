@@ -0,0 +1,296 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// AddWriter adds given Grant, that authorizes a
+// writer to publish Root objects of a feed. Thus,
+// the feed becomes multi-writer feed. Owner of a
+// feed is always authorized. The Grant must be
+// signed by the owner (see registry.NewGrant).
+// Root objects of other writers have Author field
+// (see registry.Root). A Root of an unauthorized
+// writer is rejected with the ErrUnauthorizedWriter
+// error.
+//
+// A writer is expected to publish using its own
+// heads (nonces) of the feed, merging Root objects
+// of other writers using the Parents field of the
+// registry.Root, and merge helpers of the
+// registry.Refs, such as MergeSet and MergeLWW.
+//
+// Usually, grants are received with Root objects
+// (see Grants field of registry.Root). A publisher
+// should put them to Root objects it publishes to
+// spread them (see Grants). The grants are restored
+// from last Root objects of a feed, when the
+// Container created. Thus, a Grant added by the
+// AddWriter should be published to keep it. The
+// AddWriter authorizes a writer removed by the
+// DelWriter again. The feed must exist
+func (i *Index) AddWriter(g registry.Grant) (err error) {
+
+	if err = g.Verify(); err != nil {
+		return
+	}
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[g.Feed]; ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	if ds, ok := i.denied[g.Feed]; ok == true {
+
+		if _, ok = ds[g.Writer]; ok == true {
+
+			if delete(ds, g.Writer); len(ds) == 0 {
+				delete(i.denied, g.Feed)
+			}
+
+			i.addGrant(g)
+			return i.saveFeedInfo(g.Feed)
+		}
+
+	}
+
+	i.addGrant(g)
+	return
+}
+
+// DelWriter removes authorization of given writer
+// locally. Already received Root objects of the
+// writer are not removed. Grants of the writer
+// received with Root objects are ignored, until
+// the writer added by the AddWriter again. The
+// removal is saved in DB, if the IdxDB implements
+// data.FeedKeeper interface. Other nodes are not
+// affected, use registry.Revocation to revoke the
+// writer everywhere. The feed must exist
+func (i *Index) DelWriter(feed, writer cipher.PubKey) (err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, ok := i.feeds[feed]; ok == false {
+		return data.ErrNoSuchFeed
+	}
+
+	if i.deny(feed, writer) == false {
+		return // already removed
+	}
+
+	return i.saveFeedInfo(feed)
+}
+
+// under lock, deny given writer of given feed;
+// it returns false if the writer already denied
+func (i *Index) deny(feed, writer cipher.PubKey) (ok bool) {
+
+	if ws, has := i.writers[feed]; has == true {
+
+		if delete(ws, writer); len(ws) == 0 {
+			delete(i.writers, feed)
+		}
+
+	}
+
+	var ds = i.denied[feed]
+
+	if ds == nil {
+		ds = make(map[cipher.PubKey]struct{})
+		i.denied[feed] = ds
+	}
+
+	if _, ok = ds[writer]; ok == true {
+		return false
+	}
+
+	ds[writer] = struct{}{}
+	return true
+}
+
+// Grants returns grants of authorized writers of
+// given feed. A publisher should put them to Root
+// objects it publishes to spread them
+func (i *Index) Grants(feed cipher.PubKey) (gs []registry.Grant) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var ws = i.writers[feed]
+
+	if len(ws) == 0 {
+		return
+	}
+
+	gs = make([]registry.Grant, 0, len(ws))
+
+	for _, g := range ws {
+		gs = append(gs, g)
+	}
+
+	return
+}
+
+// Writers returns list of authorized writers of
+// given feed. The list doesn't include owner of
+// the feed
+func (i *Index) Writers(feed cipher.PubKey) (writers []cipher.PubKey) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var ws = i.writers[feed]
+
+	if len(ws) == 0 {
+		return
+	}
+
+	writers = make([]cipher.PubKey, 0, len(ws))
+
+	for pk := range ws {
+		writers = append(writers, pk)
+	}
+
+	return
+}
+
+// IsWriter returns true if given public key
+// is owner or authorized writer of given feed
+func (i *Index) IsWriter(feed, pk cipher.PubKey) (yep bool) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	return i.isWriter(feed, pk)
+}
+
+// under lock
+func (i *Index) isWriter(feed, pk cipher.PubKey) (yep bool) {
+
+	if feed == pk {
+		return true // the owner
+	}
+
+	_, yep = i.writers[feed][pk]
+	return
+}
+
+// under lock, the Grant must be verified
+func (i *Index) addGrant(g registry.Grant) {
+
+	var ws, ok = i.writers[g.Feed]
+
+	if ok == false {
+		ws = make(map[cipher.PubKey]registry.Grant)
+		i.writers[g.Feed] = ws
+	}
+
+	ws[g.Writer] = g
+}
+
+// under lock, apply grants of given Root;
+// grants of denied writers are skipped
+func (i *Index) applyGrants(r *registry.Root) (err error) {
+
+	for k := range r.Grants {
+
+		var g = &r.Grants[k]
+
+		if g.Feed != r.Pub {
+			return registry.ErrInvalidGrant
+		}
+
+		if err = g.Verify(); err != nil {
+			return
+		}
+
+	}
+
+	for _, g := range r.Grants {
+
+		if _, ok := i.denied[g.Feed][g.Writer]; ok == true {
+			continue // removed locally
+		}
+
+		i.addGrant(g)
+	}
+
+	return
+}
+
+// restore grants from last Root objects of all feeds
+func (i *Index) loadGrants() (err error) {
+
+	var r *registry.Root
+
+	for _, hs := range i.feeds {
+
+		for _, dr := range hs.h {
+
+			if dr == nil {
+				continue // blank head
+			}
+
+			if r, err = i.c.rootByHash(dr.Hash); err != nil {
+				return
+			}
+
+			if err = i.applyGrants(r); err != nil {
+				return
+			}
+
+		}
+
+	}
+
+	return
+}
+
+// under lock, check Parents of given Root. A parent
+// should be a Root of the same feed, and it can't be
+// the Prev. A parent, that is not received yet, can't
+// be checked, and it's accepted if the all is false
+func (i *Index) checkParents(r *registry.Root, all bool) (err error) {
+
+	var (
+		seen = make(map[cipher.SHA256]struct{}, len(r.Parents))
+		pr   *registry.Root
+	)
+
+	for _, hash := range r.Parents {
+
+		if hash == (cipher.SHA256{}) || hash == r.Prev {
+			return ErrInvalidParents
+		}
+
+		if _, ok := seen[hash]; ok == true {
+			return ErrInvalidParents // twice
+		}
+
+		seen[hash] = struct{}{}
+
+		if pr, err = i.c.rootByHash(hash); err != nil {
+
+			if err == data.ErrNotFound && all == false {
+				err = nil
+				continue // not received yet
+			}
+
+			return ErrInvalidParents
+		}
+
+		if pr.Pub != r.Pub {
+			return ErrInvalidParents
+		}
+
+	}
+
+	return
+}
@@ -0,0 +1,298 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idx/memory"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestIndex_AddWriter(t *testing.T) {
+	// AddWriter(g registry.Grant) (err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		wp, ws = cipher.GenerateKeyPair()
+		xp, _  = cipher.GenerateKeyPair()
+		grant  = registry.NewGrant(sk, wp)
+	)
+
+	defer c.Close()
+
+	if err := c.AddWriter(grant); err == nil {
+		t.Error("missing error")
+	}
+
+	assertNil(t, c.AddFeed(pk))
+
+	var forged = grant
+	forged.Writer = xp
+
+	if err := c.AddWriter(forged); err == nil {
+		t.Error("missing error")
+	}
+
+	assertNil(t, c.AddWriter(grant))
+
+	assertTrue(t, c.IsWriter(pk, pk), "owner is not writer")
+	assertTrue(t, c.IsWriter(pk, wp), "writer is not writer")
+	assertTrue(t, c.IsWriter(pk, xp) == false, "forged writer is writer")
+	assertTrue(t, len(c.Writers(pk)) == 1, "wrong number of writers")
+	assertTrue(t, len(c.Grants(pk)) == 1, "wrong number of grants")
+
+	// owner
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var or = &registry.Root{Pub: pk, Nonce: 1}
+	assertNil(t, c.Save(up, or))
+	assertTrue(t, or.IsMultiWriter() == false, "multi-writer Root of owner")
+
+	// writer
+
+	up, err = c.Unpack(ws, testRegistry)
+	assertNil(t, err)
+
+	var wr = &registry.Root{Pub: pk, Nonce: 2, Parents: []cipher.SHA256{
+		or.Hash,
+	}}
+
+	assertNil(t, c.Save(up, wr))
+	assertTrue(t, wr.Author == wp, "wrong Author")
+
+	var rr *registry.Root
+	rr, err = c.ReceivedRoot(pk, wr.Sig, wr.Encode())
+	assertNil(t, err)
+	assertTrue(t, rr.Hash == wr.Hash, "wrong hash")
+	assertTrue(t, rr.Signer() == wp, "wrong signer")
+
+	// unauthorized
+
+	assertNil(t, c.DelWriter(pk, wp))
+
+	if _, err = c.ReceivedRoot(pk, wr.Sig, wr.Encode()); err !=
+		ErrUnauthorizedWriter {
+
+		t.Error("wrong error:", err)
+	}
+
+	wr.Nonce = 3
+	if err = c.Save(up, wr); err != ErrUnauthorizedWriter {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestIndex_Grants(t *testing.T) {
+	// Grants(feed cipher.PubKey) (gs []registry.Grant)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		wp, ws = cipher.GenerateKeyPair()
+
+		db   = data.NewDB(cxds.NewMemoryCXDS(), memory.NewMemory(0))
+		conf = getTestConfig()
+	)
+
+	conf.DB = db
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	// the Grant travels with a Root of the owner
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var or = &registry.Root{Pub: pk, Nonce: 1, Grants: []registry.Grant{
+		registry.NewGrant(sk, wp),
+	}}
+	assertNil(t, c.Save(up, or))
+
+	up, err = c.Unpack(ws, testRegistry)
+	assertNil(t, err)
+
+	var wr = &registry.Root{Pub: pk, Nonce: 2}
+	assertNil(t, c.Save(up, wr))
+
+	var rc = getTestContainer()
+	defer rc.Close()
+
+	assertNil(t, rc.AddFeed(pk))
+
+	if _, err = rc.ReceivedRoot(pk, wr.Sig, wr.Encode()); err !=
+		ErrUnauthorizedWriter {
+
+		t.Error("wrong error:", err)
+	}
+
+	_, err = rc.ReceivedRoot(pk, or.Sig, or.Encode())
+	assertNil(t, err)
+	assertTrue(t, rc.IsWriter(pk, wp), "Grant of Root not applied")
+
+	_, err = rc.ReceivedRoot(pk, wr.Sig, wr.Encode())
+	assertNil(t, err)
+
+	// the Grant is restored from saved Root objects
+
+	var lc *Container
+	lc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer lc.Close()
+
+	assertTrue(t, lc.IsWriter(pk, wp), "Grant is not loaded")
+
+}
+
+func TestIndex_DelWriter(t *testing.T) {
+	// DelWriter(feed, writer cipher.PubKey) (err error)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		wp, ws = cipher.GenerateKeyPair()
+		grant  = registry.NewGrant(sk, wp)
+
+		db   = data.NewDB(cxds.NewMemoryCXDS(), memory.NewMemory(0))
+		conf = getTestConfig()
+	)
+
+	conf.DB = db
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	if err = c.DelWriter(pk, wp); err != data.ErrNoSuchFeed {
+		t.Error("wrong error:", err)
+	}
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var or = &registry.Root{Pub: pk, Nonce: 1, Grants: []registry.Grant{
+		grant,
+	}}
+	assertNil(t, c.Save(up, or))
+	assertTrue(t, c.IsWriter(pk, wp), "Grant of Root not applied")
+
+	up, err = c.Unpack(ws, testRegistry)
+	assertNil(t, err)
+
+	var wr = &registry.Root{Pub: pk, Nonce: 2}
+	assertNil(t, c.Save(up, wr))
+
+	assertNil(t, c.DelWriter(pk, wp))
+	assertTrue(t, c.IsWriter(pk, wp) == false, "writer is not removed")
+	assertTrue(t, len(c.Grants(pk)) == 0, "Grant is not removed")
+
+	// the Grant of a received Root is ignored
+
+	c.ReceivedRoot(pk, or.Sig, or.Encode()) // already have, grants applied
+	assertTrue(t, c.IsWriter(pk, wp) == false, "Grant of Root applied")
+
+	var nr = &registry.Root{Pub: pk, Nonce: 2}
+	if err = c.Save(up, nr); err != ErrUnauthorizedWriter {
+		t.Error("wrong error:", err)
+	}
+
+	// the removal is loaded, the Grant is not restored
+
+	var lc *Container
+	lc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer lc.Close()
+
+	assertTrue(t, lc.IsWriter(pk, wp) == false, "Grant is restored")
+
+	// the AddWriter authorizes the writer again
+
+	assertNil(t, c.AddWriter(grant))
+	assertTrue(t, c.IsWriter(pk, wp), "writer is not added")
+
+	lc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer lc.Close()
+
+	assertTrue(t, lc.IsWriter(pk, wp), "Grant is not loaded")
+
+}
+
+func TestIndex_checkParents(t *testing.T) {
+	// checkParents(r *registry.Root, all bool) (err error)
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		xp, xs = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+	assertNil(t, c.AddFeed(xp))
+
+	// Root of another feed
+
+	var up, err = c.Unpack(xs, testRegistry)
+	assertNil(t, err)
+
+	var xr = &registry.Root{Pub: xp, Nonce: 1}
+	assertNil(t, c.Save(up, xr))
+
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var or = &registry.Root{Pub: pk, Nonce: 1}
+	assertNil(t, c.Save(up, or))
+
+	for _, parents := range [][]cipher.SHA256{
+		{xr.Hash},               // another feed
+		{or.Hash, or.Hash},      // twice
+		{{}},                    // blank
+		{cipher.SHA256{1}},      // not saved
+		{cipher.SHA256(xr.Reg)}, // not a Root
+	} {
+		var r = &registry.Root{Pub: pk, Nonce: 2, Parents: parents}
+		if err = c.Save(up, r); err != ErrInvalidParents {
+			t.Error("wrong error:", err)
+		}
+	}
+
+	var r = &registry.Root{Pub: pk, Nonce: 2, Parents: []cipher.SHA256{
+		or.Hash,
+	}}
+	assertNil(t, c.Save(up, r))
+
+	// received Root with parent of another feed
+
+	var rc = getTestContainer()
+	defer rc.Close()
+
+	assertNil(t, rc.AddFeed(pk))
+
+	_, err = rc.ReceivedRoot(pk, r.Sig, r.Encode())
+	assertNil(t, err) // not received parent can't be checked
+
+	r.Parents = []cipher.SHA256{xr.Hash}
+	r.Sig = cipher.SignHash(c.Sum(r.Encode()), sk)
+
+	if _, err = c.ReceivedRoot(pk, r.Sig, r.Encode()); err !=
+		ErrInvalidParents {
+
+		t.Error("wrong error:", err)
+	}
+
+}